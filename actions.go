@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// workflowUse is one `uses:` reference to an action or reusable workflow
+// hosted in a GitHub repository.
+type workflowUse struct {
+	Owner string
+	Repo  string
+	Ref   string
+	File  string // workflow file, relative to the working directory
+	Line  int
+}
+
+// actionFinding is an archived repository referenced from the workflows,
+// with every place that uses it.
+type actionFinding struct {
+	Status RepoStatus
+	Uses   []workflowUse
+}
+
+// usesRe matches a workflow step's or job's `uses:` value, quoted or not.
+var usesRe = regexp.MustCompile(`^\s*(?:-\s+)?uses:\s*["']?([^"'\s#]+)`)
+
+// findWorkflowDir returns the .github/workflows directory of the repository
+// containing dir: the nearest one found walking up from dir, stopping at the
+// repository root (the directory holding .git). Returns "" if there is none.
+func findWorkflowDir(dir string) string {
+	for {
+		wf := filepath.Join(dir, ".github", "workflows")
+		if info, err := os.Stat(wf); err == nil && info.IsDir() {
+			return wf
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// findWorkflowUses parses every workflow file in wfDir for references to
+// actions in GitHub repositories. Local actions (./path) and Docker images
+// (docker://) are skipped.
+func findWorkflowUses(wfDir string) ([]workflowUse, error) {
+	var files []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		m, err := filepath.Glob(filepath.Join(wfDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, m...)
+	}
+	sort.Strings(files)
+
+	var uses []workflowUse
+	for _, path := range files {
+		found, err := parseWorkflowUses(path)
+		if err != nil {
+			return nil, err
+		}
+		uses = append(uses, found...)
+	}
+	return uses, nil
+}
+
+// parseWorkflowUses returns the GitHub-hosted `uses:` references in one
+// workflow file.
+func parseWorkflowUses(path string) ([]workflowUse, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var uses []workflowUse
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		m := usesRe.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		owner, repo, ref, ok := parseActionRef(m[1])
+		if !ok {
+			continue
+		}
+		uses = append(uses, workflowUse{Owner: owner, Repo: repo, Ref: ref, File: relToCwd(path), Line: line})
+	}
+	return uses, scanner.Err()
+}
+
+// parseActionRef splits "owner/repo[/path]@ref" into its repository and ref.
+// Reports false for local actions, Docker images, and malformed values.
+func parseActionRef(s string) (owner, repo, ref string, ok bool) {
+	if strings.HasPrefix(s, "./") || strings.HasPrefix(s, "docker://") {
+		return "", "", "", false
+	}
+	name, ref, found := strings.Cut(s, "@")
+	if !found || ref == "" {
+		return "", "", "", false
+	}
+	parts := strings.Split(name, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], ref, true
+}
+
+// actionModules returns one Module per distinct action repository, shaped
+// like a GitHub dependency so the usual repository check applies.
+func actionModules(uses []workflowUse) []Module {
+	seen := make(map[string]bool)
+	var mods []Module
+	for _, u := range uses {
+		key := strings.ToLower(u.Owner + "/" + u.Repo)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		mods = append(mods, Module{Path: "github.com/" + u.Owner + "/" + u.Repo, Owner: u.Owner, Repo: u.Repo})
+	}
+	return mods
+}
+
+// archivedActions matches repository check results back to the workflow
+// references, returning the archived repositories sorted by name.
+func archivedActions(uses []workflowUse, results []RepoStatus) []actionFinding {
+	byRepo := make(map[string]*actionFinding)
+	for _, r := range results {
+		if r.IsArchived {
+			byRepo[archiveCacheKey(r.Module)] = &actionFinding{Status: r}
+		}
+	}
+	for _, u := range uses {
+		if f := byRepo[strings.ToLower(u.Owner+"/"+u.Repo)]; f != nil {
+			f.Uses = append(f.Uses, u)
+		}
+	}
+
+	var out []actionFinding
+	for _, f := range byRepo {
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Status.Module.Path < out[j].Status.Module.Path
+	})
+	return out
+}
+
+// checkWorkflowActions checks the repositories of the actions used by the
+// workflows of the repository containing dir, for --actions. It uses the
+// same batched GitHub query and archive cache as module dependencies.
+func checkWorkflowActions(cfg *Config, dir string) []actionFinding {
+	wfDir := findWorkflowDir(dir)
+	if wfDir == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "No .github/workflows directory found; skipping GitHub Actions check.\n")
+		return nil
+	}
+	uses, err := findWorkflowUses(wfDir)
+	if err != nil {
+		warnDegraded(cfg, "workflows", "could not read workflows: %v", err)
+		return nil
+	}
+	mods := actionModules(uses)
+	if len(mods) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Checking %d GitHub Actions %s...\n", len(mods), pluralize(len(mods), "repo", "repos"))
+	results, err := CheckReposCached(cfg, mods)
+	if err != nil {
+		warnDegraded(cfg, "github", "could not check workflow actions: %v", err)
+		return nil
+	}
+	return archivedActions(uses, results)
+}
+
+var actionHeaders = []string{"Action", "Archived At", "Used In"}
+
+// actionRows formats archived actions as table rows; each use is shown as
+// file:line@ref.
+func actionRows(cfg *Config, found []actionFinding) [][]string {
+	rows := make([][]string, len(found))
+	for i, f := range found {
+		var used []string
+		for _, u := range f.Uses {
+			used = append(used, fmt.Sprintf("%s:%d@%s", u.File, u.Line, u.Ref))
+		}
+		rows[i] = []string{f.Status.Module.Owner + "/" + f.Status.Module.Repo, fmtArchivedAt(cfg, f.Status), strings.Join(used, ", ")}
+	}
+	return rows
+}
+
+// PrintActionsTable outputs archived GitHub Actions used by the workflows.
+func PrintActionsTable(cfg *Config, found []actionFinding) {
+	if len(found) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nARCHIVED GITHUB ACTIONS (%d %s used in .github/workflows)\n\n",
+		len(found), pluralize(len(found), "repository", "repositories"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(actionHeaders))
+	for _, row := range actionRows(cfg, found) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownActions outputs archived GitHub Actions in Markdown format.
+func PrintMarkdownActions(cfg *Config, found []actionFinding) {
+	if len(found) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## ARCHIVED GITHUB ACTIONS (%d)\n\n", len(found))
+	printMarkdownTable(os.Stdout, actionHeaders, actionRows(cfg, found))
+}
+
+// JSONArchivedAction is an archived action repository in JSON output.
+type JSONArchivedAction = report.ArchivedAction
+
+// JSONWorkflowUse is one workflow reference to an archived action.
+type JSONWorkflowUse = report.WorkflowUse
+
+// buildActionsJSON converts archived actions for JSON output.
+func buildActionsJSON(found []actionFinding) []JSONArchivedAction {
+	var out []JSONArchivedAction
+	for _, f := range found {
+		r := f.Status
+		ja := JSONArchivedAction{
+			FindingID: findingID(findingArchivedAction, r.Module.Path),
+			Action:    r.Module.Owner + "/" + r.Module.Repo,
+		}
+		if !r.ArchivedAt.IsZero() {
+			ja.ArchivedAt = r.ArchivedAt.Format("2006-01-02T15:04:05Z")
+		}
+		ja.ArchivedAtSource, ja.ArchivedAtPrecision = archivedAtProvenance(r)
+		if !r.PushedAt.IsZero() {
+			ja.PushedAt = r.PushedAt.Format("2006-01-02T15:04:05Z")
+		}
+		for _, u := range f.Uses {
+			ja.Uses = append(ja.Uses, JSONWorkflowUse{Workflow: u.File, Line: u.Line, Ref: u.Ref})
+		}
+		out = append(out, ja)
+	}
+	return out
+}
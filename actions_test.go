@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseActionRef(t *testing.T) {
+	tests := []struct {
+		in               string
+		owner, repo, ref string
+		ok               bool
+	}{
+		{"actions/checkout@v4", "actions", "checkout", "v4", true},
+		{"github/codeql-action/init@v3", "github", "codeql-action", "v3", true},
+		{"org/shared/.github/workflows/ci.yml@main", "org", "shared", "main", true},
+		{"actions/setup-go@0a12ed9d6a96ab950c8f026ed9f722fe0da7ef32", "actions", "setup-go", "0a12ed9d6a96ab950c8f026ed9f722fe0da7ef32", true},
+		{"./.github/actions/build", "", "", "", false},
+		{"docker://alpine:3.19", "", "", "", false},
+		{"actions/checkout", "", "", "", false},
+		{"checkout@v4", "", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			owner, repo, ref, ok := parseActionRef(tt.in)
+			if ok != tt.ok || owner != tt.owner || repo != tt.repo || ref != tt.ref {
+				t.Errorf("parseActionRef(%q) = %q, %q, %q, %v; want %q, %q, %q, %v",
+					tt.in, owner, repo, ref, ok, tt.owner, tt.repo, tt.ref, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFindWorkflowUses(t *testing.T) {
+	root := t.TempDir()
+	wf := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(wf, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ci := `name: CI
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Setup
+        uses: "actions/setup-go@v5" # pinned
+      - uses: ./.github/actions/local
+      - run: echo "uses: not/a-step@v1"
+  shared:
+    uses: org/shared/.github/workflows/lint.yml@main
+`
+	release := "jobs:\n  r:\n    steps:\n      - uses: 'actions/create-release@v1'\n"
+	if err := os.WriteFile(filepath.Join(wf, "ci.yml"), []byte(ci), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wf, "release.yaml"), []byte(release), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The workflows are found from a nested module directory.
+	sub := filepath.Join(root, "tools")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dir := findWorkflowDir(sub)
+	if dir != wf {
+		t.Fatalf("findWorkflowDir = %q, want %q", dir, wf)
+	}
+
+	uses, err := findWorkflowUses(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		repo string
+		line int
+	}{
+		{"actions/checkout", 7},
+		{"actions/setup-go", 9},
+		{"org/shared", 13},
+		{"actions/create-release", 4},
+	}
+	if len(uses) != len(want) {
+		t.Fatalf("got %d uses, want %d: %+v", len(uses), len(want), uses)
+	}
+	for i, w := range want {
+		if got := uses[i].Owner + "/" + uses[i].Repo; got != w.repo || uses[i].Line != w.line {
+			t.Errorf("use %d = %s line %d, want %s line %d", i, got, uses[i].Line, w.repo, w.line)
+		}
+	}
+
+	if mods := actionModules(uses); len(mods) != 4 || mods[0].Path != "github.com/actions/checkout" {
+		t.Errorf("actionModules = %+v", mods)
+	}
+}
+
+func TestFindWorkflowDir_StopsAtRepoRoot(t *testing.T) {
+	outer := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(outer, ".github", "workflows"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(outer, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if dir := findWorkflowDir(repo); dir != "" {
+		t.Errorf("findWorkflowDir = %q, want none past the repository root", dir)
+	}
+}
+
+func TestArchivedActions(t *testing.T) {
+	uses := []workflowUse{
+		{Owner: "actions", Repo: "checkout", Ref: "v4", File: "ci.yml", Line: 7},
+		{Owner: "actions", Repo: "create-release", Ref: "v1", File: "release.yml", Line: 4},
+		{Owner: "Actions", Repo: "Create-Release", Ref: "v1.1", File: "ci.yml", Line: 20},
+	}
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/actions/checkout", Owner: "actions", Repo: "checkout"}},
+		{Module: Module{Path: "github.com/actions/create-release", Owner: "actions", Repo: "create-release"},
+			IsArchived: true, ArchivedAt: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), ArchivedAtSource: archivedAtGitHub},
+	}
+
+	found := archivedActions(uses, results)
+	if len(found) != 1 {
+		t.Fatalf("got %d archived actions, want 1", len(found))
+	}
+	if n := len(found[0].Uses); n != 2 {
+		t.Errorf("got %d uses of the archived action, want 2 (owner/repo match case-insensitively)", n)
+	}
+
+	out := buildActionsJSON(found)
+	if out[0].Action != "actions/create-release" || out[0].FindingID != findingID(findingArchivedAction, "github.com/actions/create-release") {
+		t.Errorf("JSON = %+v", out[0])
+	}
+	if out[0].ArchivedAt != "2021-03-04T00:00:00Z" || out[0].ArchivedAtSource != archivedAtGitHub || len(out[0].Uses) != 2 {
+		t.Errorf("JSON = %+v", out[0])
+	}
+}
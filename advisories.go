@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// Advisory is an OSV vulnerability record affecting a dependency.
+type Advisory struct {
+	ID        string
+	Summary   string
+	Published time.Time
+}
+
+// fetchAdvisories fills LastRelease, LastReleaseTag, and Advisories on the
+// archived results (--advisories): the repository's latest GitHub release,
+// and the OSV advisories affecting the pinned version that were published
+// after it — vulnerabilities no upstream release will ever fix. Failures
+// are reported as degradations.
+func fetchAdvisories(cfg *Config, results []RepoStatus) {
+	if !cfg.Advisories || len(results) == 0 {
+		return
+	}
+	token, err := getGHToken()
+	if err != nil {
+		warnDegraded(cfg, "advisories", "could not fetch release dates: %v", err)
+		return
+	}
+	if err := fetchAdvisoriesWithClient(results, cfg.Workers, token, newGHClient(), endpoints.OSV); err != nil {
+		warnDegraded(cfg, "advisories", "could not fetch advisories: %v", err)
+	}
+}
+
+// fetchAdvisoriesWithClient is the internal implementation that accepts a
+// ghClient and OSV URL, allowing tests to inject mock HTTP servers.
+func fetchAdvisoriesWithClient(results []RepoStatus, batchSize int, token string, gc *ghClient, osvURL string) error {
+	var idx []int
+	for i, r := range results {
+		if r.IsArchived {
+			idx = append(idx, i)
+		}
+	}
+
+	for start := 0; start < len(idx); start += batchSize {
+		end := min(start+batchSize, len(idx))
+		batch := idx[start:end]
+		releases, err := gc.queryLatestReleases(token, results, batch)
+		if err != nil {
+			return err
+		}
+		for j, i := range batch {
+			results[i].LastReleaseTag = releases[j].tag
+			results[i].LastRelease = releases[j].published
+		}
+	}
+
+	opts := poolOptions{Workers: 10, Retries: 2, Backoff: 500 * time.Millisecond}
+	vulns, err := mapPool(context.Background(), idx, opts, func(_ context.Context, i int) ([]Advisory, error) {
+		return queryOSV(gc.client, osvURL, results[i].Module)
+	})
+	for j, i := range idx {
+		cutoff := advisoryCutoff(results[i])
+		for _, a := range vulns[j] {
+			if a.Published.After(cutoff) {
+				results[i].Advisories = append(results[i].Advisories, a)
+			}
+		}
+	}
+	return err
+}
+
+// advisoryCutoff is the point after which an advisory can no longer be
+// fixed upstream: the last release, or the last push if the repository
+// never published one.
+func advisoryCutoff(r RepoStatus) time.Time {
+	if !r.LastRelease.IsZero() {
+		return r.LastRelease
+	}
+	return r.PushedAt
+}
+
+type latestRelease struct {
+	tag       string
+	published time.Time
+}
+
+// queryLatestReleases fetches the latest release of results[batch...] in
+// one GraphQL request. Returns releases parallel to batch; repositories
+// without releases yield the zero value.
+func (g *ghClient) queryLatestReleases(token string, results []RepoStatus, batch []int) ([]latestRelease, error) {
+	var qb strings.Builder
+	qb.WriteString("{\n")
+	for j, i := range batch {
+		m := results[i].Module
+		fmt.Fprintf(&qb, "  r%d: repository(owner: %q, name: %q) {\n", j, m.Owner, m.Repo)
+		qb.WriteString("    latestRelease { tagName publishedAt }\n")
+		qb.WriteString("  }\n")
+	}
+	qb.WriteString("}\n")
+
+	body, err := g.post(token, qb.String())
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data map[string]*struct {
+			LatestRelease *struct {
+				TagName     string `json:"tagName"`
+				PublishedAt string `json:"publishedAt"`
+			} `json:"latestRelease"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	out := make([]latestRelease, len(batch))
+	for j := range batch {
+		rd := resp.Data[fmt.Sprintf("r%d", j)]
+		if rd == nil || rd.LatestRelease == nil {
+			continue
+		}
+		out[j].tag = rd.LatestRelease.TagName
+		out[j].published, _ = time.Parse(time.RFC3339, rd.LatestRelease.PublishedAt)
+	}
+	return out, nil
+}
+
+// osvVersion converts a Go module version to the form the OSV Go ecosystem
+// uses: no leading "v" and no +incompatible suffix.
+func osvVersion(v string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(v, "v"), "+incompatible")
+}
+
+// queryOSV returns the OSV advisories affecting m at its pinned version.
+func queryOSV(client *http.Client, url string, m Module) ([]Advisory, error) {
+	reqBody, err := json.Marshal(map[string]any{
+		"package": map[string]string{"name": m.Path, "ecosystem": "Go"},
+		"version": osvVersion(m.Version),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("OSV request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("OSV returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var out struct {
+		Vulns []struct {
+			ID        string `json:"id"`
+			Summary   string `json:"summary"`
+			Published string `json:"published"`
+		} `json:"vulns"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	var advisories []Advisory
+	for _, v := range out.Vulns {
+		a := Advisory{ID: v.ID, Summary: v.Summary}
+		a.Published, _ = time.Parse(time.RFC3339, v.Published)
+		advisories = append(advisories, a)
+	}
+	sort.Slice(advisories, func(i, j int) bool {
+		return advisories[i].Published.Before(advisories[j].Published)
+	})
+	return advisories, nil
+}
+
+var advisoryHeaders = []string{"Module", "Version", "Last Release", "Advisory", "Published", "Summary"}
+
+// advisoryRows formats the post-release advisories of results as table
+// rows, one per advisory, ordered by module path.
+func advisoryRows(cfg *Config, results []RepoStatus) [][]string {
+	var rows [][]string
+	for _, r := range sortedArchived(results) {
+		release := "none"
+		if !r.LastRelease.IsZero() {
+			release = r.LastReleaseTag + " (" + fmtDate(cfg, r.LastRelease) + ")"
+		}
+		for _, a := range r.Advisories {
+			rows = append(rows, []string{r.Module.Path, r.Module.Version, release, a.ID, fmtDate(cfg, a.Published), a.Summary})
+		}
+	}
+	return rows
+}
+
+// PrintAdvisoryTable outputs the advisories published after an archived
+// dependency's last release.
+func PrintAdvisoryTable(cfg *Config, results []RepoStatus) {
+	rows := advisoryRows(cfg, results)
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nVULNERABILITIES PUBLISHED AFTER LAST RELEASE (%d)\n\n", len(rows))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(advisoryHeaders))
+	for _, row := range rows {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownAdvisories outputs post-release advisories in Markdown format.
+func PrintMarkdownAdvisories(cfg *Config, results []RepoStatus) {
+	rows := advisoryRows(cfg, results)
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## VULNERABILITIES PUBLISHED AFTER LAST RELEASE (%d)\n\n", len(rows))
+	printMarkdownTable(os.Stdout, advisoryHeaders, rows)
+}
+
+// JSONAdvisory is an advisory published after a dependency's last release
+// in JSON output.
+type JSONAdvisory = report.Advisory
+
+// JSONRelease is a dependency's latest GitHub release in JSON output.
+type JSONRelease = report.Release
+
+// setJSONAdvisories populates LastRelease and AdvisoriesAfterRelease on a
+// JSONModule from an archived RepoStatus.
+func setJSONAdvisories(jm *JSONModule, r RepoStatus) {
+	if !r.LastRelease.IsZero() {
+		jm.LastRelease = &JSONRelease{Tag: r.LastReleaseTag, PublishedAt: r.LastRelease.Format("2006-01-02T15:04:05Z")}
+	}
+	for _, a := range r.Advisories {
+		jm.AdvisoriesAfterRelease = append(jm.AdvisoriesAfterRelease, JSONAdvisory{
+			ID:        a.ID,
+			Summary:   a.Summary,
+			Published: a.Published.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOSVVersion(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"v1.2.3", "1.2.3"},
+		{"v3.2.0+incompatible", "3.2.0"},
+		{"v0.0.0-20200101000000-abcdef123456", "0.0.0-20200101000000-abcdef123456"},
+	}
+	for _, tt := range tests {
+		if got := osvVersion(tt.in); got != tt.want {
+			t.Errorf("osvVersion(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFetchAdvisoriesWithClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/graphql":
+			_, _ = w.Write([]byte(`{"data":{
+				"r0":{"latestRelease":{"tagName":"v3.2.0","publishedAt":"2018-03-08T00:00:00Z"}},
+				"r1":{"latestRelease":null}}}`))
+		case "/osv":
+			var req struct {
+				Package struct{ Name string } `json:"package"`
+				Version string                `json:"version"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			switch req.Package.Name {
+			case "github.com/old/jwt":
+				if req.Version != "3.2.0" {
+					t.Errorf("OSV version = %q, want 3.2.0", req.Version)
+				}
+				_, _ = w.Write([]byte(`{"vulns":[
+					{"id":"GO-2020-0017","summary":"Bypass of audience check","published":"2021-04-14T20:04:52Z"},
+					{"id":"GO-2017-0001","summary":"Fixed long ago","published":"2017-01-01T00:00:00Z"}]}`))
+			default:
+				_, _ = w.Write([]byte(`{}`))
+			}
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	pushed := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/old/jwt", Version: "v3.2.0+incompatible", Owner: "old", Repo: "jwt"}, IsArchived: true},
+		{Module: Module{Path: "github.com/old/norel", Version: "v1.0.0", Owner: "old", Repo: "norel"}, IsArchived: true, PushedAt: pushed},
+		{Module: Module{Path: "github.com/live/lib", Version: "v1.0.0", Owner: "live", Repo: "lib"}},
+	}
+	gc := &ghClient{client: srv.Client(), graphqlURL: srv.URL + "/graphql", restURL: srv.URL}
+	if err := fetchAdvisoriesWithClient(results, 50, "tok", gc, srv.URL+"/osv"); err != nil {
+		t.Fatal(err)
+	}
+
+	if results[0].LastReleaseTag != "v3.2.0" || results[0].LastRelease.Year() != 2018 {
+		t.Errorf("results[0] release = %q %v", results[0].LastReleaseTag, results[0].LastRelease)
+	}
+	if len(results[0].Advisories) != 1 || results[0].Advisories[0].ID != "GO-2020-0017" {
+		t.Errorf("results[0].Advisories = %+v, want only GO-2020-0017", results[0].Advisories)
+	}
+	if !results[1].LastRelease.IsZero() || results[1].Advisories != nil {
+		t.Errorf("results[1] = %+v, want no release or advisories", results[1])
+	}
+	if advisoryCutoff(results[1]) != pushed {
+		t.Errorf("cutoff without a release should be the last push")
+	}
+}
+
+func TestPrintAdvisoryTable(t *testing.T) {
+	cfg := defaultTestConfig()
+	results := []RepoStatus{{
+		Module:         Module{Path: "github.com/old/jwt", Version: "v3.2.0+incompatible"},
+		IsArchived:     true,
+		LastRelease:    time.Date(2018, 3, 8, 0, 0, 0, 0, time.UTC),
+		LastReleaseTag: "v3.2.0",
+		Advisories:     []Advisory{{ID: "GO-2020-0017", Summary: "Bypass of audience check", Published: time.Date(2021, 4, 14, 0, 0, 0, 0, time.UTC)}},
+	}}
+
+	output := captureStdout(t, func() {
+		PrintAdvisoryTable(cfg, results)
+	})
+	for _, want := range []string{"GO-2020-0017", "v3.2.0 (2018-03-08)", "2021-04-14", "Bypass of audience check"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+
+	out := buildJSONOutput(cfg, results, nil, nil, nil)
+	jm := out.Archived[0]
+	if jm.LastRelease == nil || jm.LastRelease.Tag != "v3.2.0" {
+		t.Errorf("last_release = %+v", jm.LastRelease)
+	}
+	if len(jm.AdvisoriesAfterRelease) != 1 || jm.AdvisoriesAfterRelease[0].Published != "2021-04-14T00:00:00Z" {
+		t.Errorf("advisories_after_release = %+v", jm.AdvisoriesAfterRelease)
+	}
+}
+
+func TestPrintAdvisoryTable_None(t *testing.T) {
+	output := captureStdout(t, func() {
+		PrintAdvisoryTable(defaultTestConfig(), []RepoStatus{{Module: Module{Path: "a"}, IsArchived: true}})
+	})
+	if output != "" {
+		t.Errorf("no advisories should print nothing, got:\n%s", output)
+	}
+}
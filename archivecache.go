@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// archiveCacheFile is the archive-status cache under modrotCacheDir.
+const archiveCacheFile = "archived.json"
+
+// archiveCacheVersion is the current archive cache format version. A cache
+// with a different version is discarded.
+const archiveCacheVersion = 1
+
+// defaultArchivedSkipDays is how many days a repository must have been
+// archived before the cache answers for it instead of GitHub
+// (--archived-skip-days). Archiving is virtually never undone after this
+// long; --recheck-archived re-queries anyway.
+const defaultArchivedSkipDays = 30
+
+// defaultCacheTTL is how recently every repository in a run must have been
+// checked for the cache to answer the whole run without contacting GitHub
+// (--cache-ttl).
+const defaultCacheTTL = 6 * time.Hour
+
+// archiveCache records the archive status GitHub last reported for each
+// repository, keyed by lower-cased "owner/repo".
+type archiveCache struct {
+	Version int                          `json:"version"`
+	Repos   map[string]archiveCacheEntry `json:"repos"`
+}
+
+// archiveCacheEntry is one repository as last seen on GitHub. Active
+// entries were not archived; older caches only hold archived ones.
+type archiveCacheEntry struct {
+	Active           bool      `json:"active,omitempty"`
+	ArchivedAt       time.Time `json:"archived_at"`
+	ArchivedAtSource string    `json:"archived_at_source,omitempty"` // "" in older caches means GitHub
+	PushedAt         time.Time `json:"pushed_at,omitzero"`
+	CheckedAt        time.Time `json:"checked_at"`
+}
+
+// archiveCacheKey returns the cache key for a module's repository.
+func archiveCacheKey(m Module) string {
+	return repoKey(m)
+}
+
+// archiveCachePath returns the archive cache location, or "" if there is
+// no usable cache directory.
+func archiveCachePath() string {
+	dir, err := modrotCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, archiveCacheFile)
+}
+
+// loadArchiveCache reads the archive cache. A missing, unreadable, or
+// outdated cache yields an empty one: the cache only saves API calls.
+func loadArchiveCache(path string) *archiveCache {
+	empty := &archiveCache{Version: archiveCacheVersion, Repos: make(map[string]archiveCacheEntry)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return empty
+	}
+	var c archiveCache
+	if json.Unmarshal(data, &c) != nil || c.Version != archiveCacheVersion || c.Repos == nil {
+		return empty
+	}
+	return &c
+}
+
+// saveArchiveCache writes the archive cache atomically.
+func saveArchiveCache(path string, c *archiveCache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".archived-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// CheckReposCached is CheckRepos with the archive cache in front of it:
+// repositories the cache says have been archived for over
+// --archived-skip-days are answered from the cache unless --recheck-archived is set, and the
+// cache is updated from whatever GitHub was asked. When the cache covers
+// every repository with entries younger than --cache-ttl, GitHub is not
+// contacted at all.
+func CheckReposCached(cfg *Config, modules []Module) ([]RepoStatus, error) {
+	var stop func(RepoStatus) bool
+	if cfg.FailFast {
+		stop = func(r RepoStatus) bool { return failsFast(cfg, r) }
+	}
+	path := archiveCachePath()
+	if path == "" {
+		return CheckReposUntil(modules, cfg.Workers, stop)
+	}
+	return checkReposCachedWith(cfg, path, modules, func(ms []Module) ([]RepoStatus, error) {
+		return CheckReposUntil(ms, cfg.Workers, stop)
+	})
+}
+
+// checkReposCachedWith is the internal implementation that accepts the
+// cache path and the GitHub check, allowing tests to stub both.
+func checkReposCachedWith(cfg *Config, path string, modules []Module, check func([]Module) ([]RepoStatus, error)) ([]RepoStatus, error) {
+	cache := loadArchiveCache(path)
+	if results, ok := serveFromCache(cfg, cache, modules); ok {
+		return results, nil
+	}
+
+	results := make([]RepoStatus, len(modules))
+	var query []Module
+	var queryIdx []int
+	for i, m := range modules {
+		e, ok := cache.Repos[archiveCacheKey(m)]
+		if ok && longArchived(cfg, e) {
+			results[i] = RepoStatus{Module: m, IsArchived: true, ArchivedAt: e.ArchivedAt, ArchivedAtSource: archivedAtGitHub, PushedAt: e.PushedAt}
+			continue
+		}
+		query = append(query, m)
+		queryIdx = append(queryIdx, i)
+	}
+	if skipped := len(modules) - len(query); skipped > 0 {
+		days := archivedSkipDays(cfg)
+		_, _ = fmt.Fprintf(os.Stderr, "Skipping %d %s archived for over %d %s (cached; --recheck-archived to re-query).\n",
+			skipped, pluralize(skipped, "repo", "repos"), days, pluralize(days, "day", "days"))
+	}
+	if cfg.FailFast {
+		// A cached finding already decides the run; otherwise ask about
+		// the modules that can fail it first.
+		if slices.ContainsFunc(results, func(r RepoStatus) bool { return failsFast(cfg, r) }) {
+			return checkedOnly(results), nil
+		}
+		failFastOrder(cfg, query, queryIdx)
+	}
+
+	queried, err := check(query)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for j, r := range queried {
+		results[queryIdx[j]] = r
+		key := archiveCacheKey(r.Module)
+		switch {
+		case r.IsArchived:
+			cache.Repos[key] = archiveCacheEntry{ArchivedAt: r.ArchivedAt, ArchivedAtSource: r.ArchivedAtSource, PushedAt: r.PushedAt, CheckedAt: cfg.Now}
+			changed = true
+		case r.Unknown:
+			// A failed check says nothing about the repository.
+		case r.Disabled:
+			if _, ok := cache.Repos[key]; ok {
+				delete(cache.Repos, key)
+				changed = true
+			}
+		case !r.NotFound:
+			cache.Repos[key] = archiveCacheEntry{Active: true, PushedAt: r.PushedAt, CheckedAt: cfg.Now}
+			changed = true
+		}
+	}
+	if changed {
+		// Best effort: a cache that cannot be written only costs API calls.
+		_ = saveArchiveCache(path, cache)
+	}
+	if len(queried) < len(query) {
+		// --fail-fast stopped the query early.
+		return checkedOnly(results), nil
+	}
+	return results, nil
+}
+
+// archivedSkipDays returns --archived-skip-days, or its default when unset.
+func archivedSkipDays(cfg *Config) int {
+	if cfg.ArchivedSkipDays <= 0 {
+		return defaultArchivedSkipDays
+	}
+	return cfg.ArchivedSkipDays
+}
+
+// longArchived reports whether a cache entry records a repository archived
+// for over --archived-skip-days, which the cache answers for unless
+// --recheck-archived is set. Only GitHub's own timestamp qualifies: without
+// it, nothing says how long ago archiving happened.
+func longArchived(cfg *Config, e archiveCacheEntry) bool {
+	exact := e.ArchivedAtSource == "" || e.ArchivedAtSource == archivedAtGitHub
+	skipAge := time.Duration(archivedSkipDays(cfg)) * 24 * time.Hour
+	return !cfg.RecheckArchived && !e.Active && exact && !e.ArchivedAt.IsZero() && cfg.Now.Sub(e.ArchivedAt) > skipAge
+}
+
+// serveFromCache answers a whole run from the cache when every repository
+// has an entry checked within --cache-ttl (or is long archived), so editor
+// integrations and pre-commit hooks need no GitHub round trip. It records
+// the age of the oldest fresh entry in cfg.ServedFromCache for the summary
+// line. Not-found and disabled repositories are never cached, so a run
+// with any of them always queries GitHub.
+func serveFromCache(cfg *Config, cache *archiveCache, modules []Module) ([]RepoStatus, bool) {
+	if cfg.CacheTTL <= 0 || cfg.RecheckArchived || len(modules) == 0 {
+		return nil, false
+	}
+	results := make([]RepoStatus, len(modules))
+	var oldest time.Time
+	for i, m := range modules {
+		e, ok := cache.Repos[archiveCacheKey(m)]
+		if !ok {
+			return nil, false
+		}
+		if !longArchived(cfg, e) {
+			if cfg.Now.Sub(e.CheckedAt) > cfg.CacheTTL {
+				return nil, false
+			}
+			if oldest.IsZero() || e.CheckedAt.Before(oldest) {
+				oldest = e.CheckedAt
+			}
+		}
+		results[i] = RepoStatus{Module: m, PushedAt: e.PushedAt}
+		if !e.Active {
+			results[i].IsArchived = true
+			results[i].ArchivedAt = e.ArchivedAt
+			results[i].ArchivedAtSource = e.ArchivedAtSource
+			if results[i].ArchivedAtSource == "" {
+				results[i].ArchivedAtSource = archivedAtGitHub
+			}
+		}
+	}
+	if oldest.IsZero() {
+		oldest = cfg.Now
+	}
+	cfg.ServedFromCache = oldest
+	return results, true
+}
+
+// cacheNote returns the summary-line suffix for a run served from the
+// cache, e.g. "; served from cache (age: 2h)", or "" otherwise.
+func cacheNote(cfg *Config) string {
+	if cfg.ServedFromCache.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("; served from cache (age: %s)", fmtCacheAge(cfg.Now.Sub(cfg.ServedFromCache)))
+}
+
+// fmtCacheAge formats a cache age coarsely: "<1m", "45m", "2h", "3d".
+func fmtCacheAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckReposCachedWith(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	longAgo := now.AddDate(-2, 0, 0)
+	recently := now.AddDate(0, 0, -3)
+
+	path := filepath.Join(t.TempDir(), "archived.json")
+	if err := saveArchiveCache(path, &archiveCache{
+		Version: archiveCacheVersion,
+		Repos: map[string]archiveCacheEntry{
+			"old/archived":  {ArchivedAt: longAgo, CheckedAt: longAgo},
+			"new/archived":  {ArchivedAt: recently, CheckedAt: recently},
+			"was/archived":  {ArchivedAt: recently, CheckedAt: recently},
+			"unrelated/one": {ArchivedAt: longAgo, CheckedAt: longAgo},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	modules := []Module{
+		{Path: "github.com/old/archived", Owner: "old", Repo: "archived"},
+		{Path: "github.com/new/archived", Owner: "new", Repo: "archived"},
+		{Path: "github.com/was/archived", Owner: "was", Repo: "archived"},
+		{Path: "github.com/fresh/archived", Owner: "fresh", Repo: "archived"},
+		{Path: "github.com/active/repo", Owner: "Active", Repo: "Repo"},
+	}
+	var queried []string
+	check := func(ms []Module) ([]RepoStatus, error) {
+		out := make([]RepoStatus, len(ms))
+		for i, m := range ms {
+			queried = append(queried, m.Path)
+			out[i] = RepoStatus{Module: m}
+			switch m.Owner {
+			case "new", "fresh":
+				out[i].IsArchived = true
+				out[i].ArchivedAt = recently
+			}
+		}
+		return out, nil
+	}
+
+	cfg := &Config{Now: now}
+	results, err := checkReposCachedWith(cfg, path, modules, check)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantQueried := []string{"github.com/new/archived", "github.com/was/archived", "github.com/fresh/archived", "github.com/active/repo"}
+	if len(queried) != len(wantQueried) {
+		t.Fatalf("queried %v, want %v", queried, wantQueried)
+	}
+	for i := range wantQueried {
+		if queried[i] != wantQueried[i] {
+			t.Errorf("queried[%d] = %s, want %s", i, queried[i], wantQueried[i])
+		}
+	}
+
+	if len(results) != len(modules) {
+		t.Fatalf("got %d results, want %d", len(results), len(modules))
+	}
+	for i, r := range results {
+		if r.Module.Path != modules[i].Path {
+			t.Errorf("results[%d] = %s, want %s (order preserved)", i, r.Module.Path, modules[i].Path)
+		}
+	}
+	if !results[0].IsArchived || !results[0].ArchivedAt.Equal(longAgo) {
+		t.Errorf("cached result = %+v, want archived at %v", results[0], longAgo)
+	}
+	if results[2].IsArchived {
+		t.Error("was/archived should reflect GitHub's answer (unarchived)")
+	}
+
+	cache := loadArchiveCache(path)
+	for key, want := range map[string]bool{
+		"old/archived":   true,
+		"new/archived":   true,
+		"fresh/archived": true,
+		"was/archived":   false, // unarchived on GitHub
+		"active/repo":    false,
+		"unrelated/one":  true, // not scanned, kept
+	} {
+		e, ok := cache.Repos[key]
+		if !ok {
+			t.Errorf("cache has no entry for %s", key)
+			continue
+		}
+		if archived := !e.Active; archived != want {
+			t.Errorf("cache has %s archived = %v, want %v", key, archived, want)
+		}
+	}
+	if e := cache.Repos["active/repo"]; !e.CheckedAt.Equal(now) {
+		t.Errorf("active/repo checked at %v, want %v", e.CheckedAt, now)
+	}
+}
+
+func TestCheckReposCachedWith_FastPath(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "archived.json")
+	if err := saveArchiveCache(path, &archiveCache{
+		Version: archiveCacheVersion,
+		Repos: map[string]archiveCacheEntry{
+			"old/archived": {ArchivedAt: now.AddDate(-2, 0, 0), CheckedAt: now.AddDate(-1, 0, 0)},
+			"new/archived": {ArchivedAt: now.AddDate(0, 0, -3), CheckedAt: now.Add(-2 * time.Hour)},
+			"active/repo":  {Active: true, PushedAt: now.AddDate(0, -1, 0), CheckedAt: now.Add(-30 * time.Minute)},
+			"stale/repo":   {Active: true, CheckedAt: now.Add(-7 * time.Hour)},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	old := Module{Path: "github.com/old/archived", Owner: "old", Repo: "archived"}
+	recent := Module{Path: "github.com/new/archived", Owner: "new", Repo: "archived"}
+	active := Module{Path: "github.com/active/repo", Owner: "active", Repo: "repo"}
+	stale := Module{Path: "github.com/stale/repo", Owner: "stale", Repo: "repo"}
+	missing := Module{Path: "github.com/not/cached", Owner: "not", Repo: "cached"}
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		recheck bool
+		modules []Module
+		queried int
+		note    string
+	}{
+		{"covered", defaultCacheTTL, false, []Module{old, recent, active}, 0, "; served from cache (age: 2h)"},
+		{"entry too old", defaultCacheTTL, false, []Module{old, stale}, 1, ""},
+		{"repo not cached", defaultCacheTTL, false, []Module{active, missing}, 2, ""},
+		{"disabled", 0, false, []Module{old, recent, active}, 2, ""},
+		{"recheck", defaultCacheTTL, true, []Module{recent, active}, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queried := 0
+			check := func(ms []Module) ([]RepoStatus, error) {
+				queried += len(ms)
+				out := make([]RepoStatus, len(ms))
+				for i, m := range ms {
+					out[i] = RepoStatus{Module: m}
+				}
+				return out, nil
+			}
+			cfg := &Config{Now: now, CacheTTL: tt.ttl, RecheckArchived: tt.recheck}
+			results, err := checkReposCachedWith(cfg, path, tt.modules, check)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if queried != tt.queried {
+				t.Errorf("queried %d repos, want %d", queried, tt.queried)
+			}
+			if got := cacheNote(cfg); got != tt.note {
+				t.Errorf("cacheNote = %q, want %q", got, tt.note)
+			}
+			if tt.queried == 0 {
+				if !results[0].IsArchived || results[0].ArchivedAtSource != archivedAtGitHub || !results[1].IsArchived {
+					t.Errorf("archived results = %+v", results[:2])
+				}
+				if results[2].IsArchived || !results[2].PushedAt.Equal(now.AddDate(0, -1, 0)) {
+					t.Errorf("active result = %+v", results[2])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckReposCachedWith_Recheck(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "archived.json")
+	if err := saveArchiveCache(path, &archiveCache{
+		Version: archiveCacheVersion,
+		Repos:   map[string]archiveCacheEntry{"old/archived": {ArchivedAt: now.AddDate(-2, 0, 0)}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	modules := []Module{{Path: "github.com/old/archived", Owner: "old", Repo: "archived"}}
+
+	calls := 0
+	check := func(ms []Module) ([]RepoStatus, error) {
+		calls += len(ms)
+		return []RepoStatus{{Module: ms[0]}}, nil
+	}
+	cfg := &Config{Now: now, RecheckArchived: true}
+	results, err := checkReposCachedWith(cfg, path, modules, check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("--recheck-archived queried %d repos, want 1", calls)
+	}
+	if results[0].IsArchived {
+		t.Error("result should come from GitHub, not the cache")
+	}
+	if e := loadArchiveCache(path).Repos["old/archived"]; !e.Active {
+		t.Error("unarchived repo should be cached as active")
+	}
+}
+
+func TestCheckReposCachedWith_ArchivedSkipDays(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "archived.json")
+	if err := saveArchiveCache(path, &archiveCache{
+		Version: archiveCacheVersion,
+		Repos:   map[string]archiveCacheEntry{"old/archived": {ArchivedAt: now.AddDate(0, 0, -10)}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	modules := []Module{{Path: "github.com/old/archived", Owner: "old", Repo: "archived"}}
+	check := func(ms []Module) ([]RepoStatus, error) {
+		out := make([]RepoStatus, len(ms))
+		for i, m := range ms {
+			out[i] = RepoStatus{Module: m, IsArchived: true, ArchivedAt: now.AddDate(0, 0, -10), ArchivedAtSource: archivedAtGitHub}
+		}
+		return out, nil
+	}
+
+	tests := []struct {
+		days    int
+		skipped bool
+		msg     string
+	}{
+		{0, false, ""}, // the 30-day default
+		{7, true, "Skipping 1 repo archived for over 7 days"},
+		{1, true, "Skipping 1 repo archived for over 1 day "},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Now: now, ArchivedSkipDays: tt.days}
+		calls := 0
+		stderr := captureStderr(t, func() {
+			if _, err := checkReposCachedWith(cfg, path, modules, func(ms []Module) ([]RepoStatus, error) {
+				calls += len(ms)
+				return check(ms)
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if skipped := calls == 0; skipped != tt.skipped {
+			t.Errorf("days=%d: skipped = %v, want %v", tt.days, skipped, tt.skipped)
+		}
+		if tt.msg != "" && !strings.Contains(stderr, tt.msg) {
+			t.Errorf("days=%d: stderr = %q, want %q", tt.days, stderr, tt.msg)
+		}
+	}
+}
+
+func TestCheckReposCachedWith_UndatedNotSkipped(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	longAgo := now.AddDate(-2, 0, 0)
+	path := filepath.Join(t.TempDir(), "archived.json")
+	if err := saveArchiveCache(path, &archiveCache{Version: archiveCacheVersion, Repos: map[string]archiveCacheEntry{}}); err != nil {
+		t.Fatal(err)
+	}
+	modules := []Module{{Path: "github.com/old/archived", Owner: "old", Repo: "archived"}}
+
+	calls := 0
+	check := func(ms []Module) ([]RepoStatus, error) {
+		calls += len(ms)
+		return []RepoStatus{{Module: ms[0], IsArchived: true, PushedAt: longAgo, ArchivedAtSource: archivedAtUnknown}}, nil
+	}
+	cfg := &Config{Now: now}
+	for run := 0; run < 2; run++ {
+		results, err := checkReposCachedWith(cfg, path, modules, check)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results[0].ArchivedAtSource != archivedAtUnknown || !results[0].ArchivedAt.IsZero() {
+			t.Errorf("run %d: ArchivedAt = %v (%q), want zero and unknown", run, results[0].ArchivedAt, results[0].ArchivedAtSource)
+		}
+	}
+	// An archived repo without a date is cached but never skips the query:
+	// a push two years ago says nothing about when it was archived.
+	if calls != 2 {
+		t.Errorf("GitHub queried %d times, want 2", calls)
+	}
+	if e := loadArchiveCache(path).Repos["old/archived"]; e.ArchivedAtSource != archivedAtUnknown {
+		t.Errorf("cached source = %q, want unknown", e.ArchivedAtSource)
+	}
+}
+
+func TestFmtCacheAge(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		20 * time.Second:  "<1m",
+		45 * time.Minute:  "45m",
+		2 * time.Hour:     "2h",
+		47 * time.Hour:    "47h",
+		72 * time.Hour:    "3d",
+		time.Duration(0):  "<1m",
+		150 * time.Minute: "2h",
+	} {
+		if got := fmtCacheAge(d); got != want {
+			t.Errorf("fmtCacheAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestLoadArchiveCache_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"missing":     "",
+		"garbage":     "not json",
+		"old version": `{"version": 0, "repos": {"a/b": {}}}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name+".json")
+			if content != "" {
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := loadArchiveCache(path)
+			if c.Repos == nil || len(c.Repos) != 0 {
+				t.Errorf("loadArchiveCache = %+v, want empty cache", c)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// badgeCounts are the unique-repository counts behind the summary badge.
+type badgeCounts struct {
+	Archived       int
+	ArchivedDirect int
+	Disabled       int
+	Deprecated     int // deprecated module paths
+	Unknown        int // repos whose status could not be checked
+	Active         int
+}
+
+// computeBadgeCounts counts the run's findings across every project. Repos
+// not found or not checked count as neither archived nor active.
+func computeBadgeCounts(s *runSummary) badgeCounts {
+	type repo struct{ archived, direct, disabled, unchecked, unknown bool }
+	repos := make(map[string]*repo)
+	for _, rs := range s.results {
+		for _, r := range rs {
+			key := repoKey(r.Module)
+			st, ok := repos[key]
+			if !ok {
+				st = &repo{}
+				repos[key] = st
+			}
+			st.archived = st.archived || r.IsArchived
+			st.direct = st.direct || r.Module.Direct
+			st.disabled = st.disabled || r.Disabled
+			st.unchecked = st.unchecked || r.Unknown
+			st.unknown = st.unknown || r.NotFound || r.Error != ""
+		}
+	}
+
+	var c badgeCounts
+	for _, st := range repos {
+		switch {
+		case st.disabled:
+			c.Disabled++
+		case st.archived:
+			c.Archived++
+			if st.direct {
+				c.ArchivedDirect++
+			}
+		case st.unchecked:
+			c.Unknown++
+		case !st.unknown:
+			c.Active++
+		}
+	}
+	c.Deprecated = len(s.deprecated)
+	return c
+}
+
+// badgeMarkers are the severity markers of the summary badge, with the
+// plain-text fallback --no-emoji selects.
+var (
+	emojiMarkers = [3]string{"❌", "⚠️", "✅"}
+	plainMarkers = [3]string{"[FAIL]", "[WARN]", "[OK]"}
+)
+
+// summaryBadge formats the verdict line printed at the end of text output:
+//
+//	❌ 3 archived (2 direct) · ⚠️ 4 deprecated · ✅ 132 active
+//
+// Disabled, deprecated, and unknown parts only appear when there are such
+// findings.
+func summaryBadge(c badgeCounts, emoji bool) string {
+	markers, sep := emojiMarkers, " · "
+	if !emoji {
+		markers, sep = plainMarkers, " | "
+	}
+	fail, warn, ok := markers[0], markers[1], markers[2]
+
+	var parts []string
+	if c.Archived > 0 {
+		parts = append(parts, fmt.Sprintf("%s %d archived (%d direct)", fail, c.Archived, c.ArchivedDirect))
+	} else {
+		parts = append(parts, ok+" 0 archived")
+	}
+	if c.Disabled > 0 {
+		parts = append(parts, fmt.Sprintf("%s %d disabled", fail, c.Disabled))
+	}
+	if c.Deprecated > 0 {
+		parts = append(parts, fmt.Sprintf("%s %d deprecated", warn, c.Deprecated))
+	}
+	if c.Unknown > 0 {
+		parts = append(parts, fmt.Sprintf("%s %d unknown", warn, c.Unknown))
+	}
+	parts = append(parts, fmt.Sprintf("%s %d active", ok, c.Active))
+	return strings.Join(parts, sep)
+}
+
+// printSummaryBadge prints the summary badge after text (table) output.
+// Machine-readable formats and runs --fail-fast cut short have none.
+func printSummaryBadge(cfg *Config) {
+	if cfg.OutputFormat != "table" || cfg.FailedFast || len(cfg.Summary.projects) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\n%s\n", summaryBadge(computeBadgeCounts(&cfg.Summary), !cfg.NoEmoji))
+}
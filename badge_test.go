@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeBadgeCounts(t *testing.T) {
+	var s runSummary
+	s.add("example.com/a", "a/go.mod", []RepoStatus{
+		{Module: Module{Path: "github.com/foo/bar", Direct: true, Owner: "foo", Repo: "bar"}, IsArchived: true},
+		{Module: Module{Path: "github.com/foo/bar/v2", Owner: "foo", Repo: "bar"}, IsArchived: true},
+		{Module: Module{Path: "github.com/baz/qux", Owner: "baz", Repo: "qux"}, IsArchived: true},
+		{Module: Module{Path: "github.com/ok/one", Owner: "ok", Repo: "one"}},
+		{Module: Module{Path: "github.com/gone/repo", Owner: "gone", Repo: "repo"}, NotFound: true},
+		{Module: Module{Path: "github.com/dmca/repo", Owner: "dmca", Repo: "repo"}, Disabled: true},
+	})
+	s.add("example.com/b", "b/go.mod", []RepoStatus{
+		{Module: Module{Path: "github.com/OK/one", Owner: "OK", Repo: "one"}},
+		{Module: Module{Path: "github.com/ok/two", Owner: "ok", Repo: "two"}},
+	})
+	s.addDeprecated([]Module{{Path: "github.com/old/x"}})
+	s.addDeprecated([]Module{{Path: "github.com/old/x"}, {Path: "github.com/old/y"}})
+
+	got := computeBadgeCounts(&s)
+	want := badgeCounts{Archived: 2, ArchivedDirect: 1, Disabled: 1, Deprecated: 2, Active: 2}
+	if got != want {
+		t.Errorf("computeBadgeCounts() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummaryBadge(t *testing.T) {
+	tests := []struct {
+		name  string
+		c     badgeCounts
+		emoji bool
+		want  string
+	}{
+		{
+			name:  "findings",
+			c:     badgeCounts{Archived: 3, ArchivedDirect: 2, Deprecated: 4, Active: 132},
+			emoji: true,
+			want:  "❌ 3 archived (2 direct) · ⚠️ 4 deprecated · ✅ 132 active",
+		},
+		{
+			name:  "clean",
+			c:     badgeCounts{Active: 10},
+			emoji: true,
+			want:  "✅ 0 archived · ✅ 10 active",
+		},
+		{
+			name: "no emoji",
+			c:    badgeCounts{Archived: 1, Disabled: 1, Active: 5},
+			want: "[FAIL] 1 archived (0 direct) | [FAIL] 1 disabled | [OK] 5 active",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summaryBadge(tt.c, tt.emoji); got != tt.want {
+				t.Errorf("summaryBadge() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintSummaryBadge_TableOnly(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Summary.add("example.com/a", "go.mod", []RepoStatus{
+		{Module: Module{Path: "github.com/foo/bar", Owner: "foo", Repo: "bar"}, IsArchived: true},
+	})
+
+	cfg.OutputFormat = "json"
+	if out := captureStderr(t, func() { printSummaryBadge(cfg) }); out != "" {
+		t.Errorf("expected no badge for JSON output, got %q", out)
+	}
+	cfg.OutputFormat = "table"
+	cfg.NoEmoji = true
+	if out := captureStderr(t, func() { printSummaryBadge(cfg) }); !strings.Contains(out, "[FAIL] 1 archived (0 direct)") {
+		t.Errorf("expected plain-text badge, got %q", out)
+	}
+}
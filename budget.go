@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// httpRequests counts the HTTP requests the run has sent to GitHub, the Go
+// module proxy, and vanity import hosts, for --max-requests.
+var httpRequests atomic.Int64
+
+// countingTransport counts every request it sends in httpRequests.
+type countingTransport struct {
+	base http.RoundTripper // nil means http.DefaultTransport
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	httpRequests.Add(1)
+	if req.Context().Done() == nil && runCtx.Done() != nil {
+		req = req.WithContext(runCtx)
+	}
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// runCtx expires when --max-duration runs out. Requests sent through
+// countingTransport and the external commands of a scan (go mod graph,
+// go list, go mod download, rg, gh) run under it, so a phase that started
+// within the budget cannot overrun it.
+var runCtx = context.Background()
+
+// Shares of the budget after which optional phases are skipped. The least
+// valuable analysis goes first, so a run nearing its budget still spends
+// the remainder on what it needs most: the GitHub query is never skipped.
+const (
+	budgetShareEnrichment = 0.5  // proxy enrichment: freshness, newer majors, non-GitHub data
+	budgetShareDeprecated = 0.75 // --deprecated checks
+	budgetShareFiles      = 1.0  // --files source scanning
+)
+
+// runBudget bounds a run's wall time and request count (--max-duration,
+// --max-requests). Optional phases ask it before starting; past their
+// share of either budget they are skipped and reported as degradations.
+// Past the whole --max-duration, runCtx cancels whatever is still in
+// flight, so pre-merge checks on very large repositories finish in
+// bounded time.
+type runBudget struct {
+	start       time.Time
+	MaxDuration time.Duration // 0 means unlimited
+	MaxRequests int64         // 0 means unlimited
+	skipped     map[string]bool
+}
+
+// startDeadline makes runCtx expire at the end of --max-duration. The
+// returned function releases it.
+func (b *runBudget) startDeadline() context.CancelFunc {
+	if b.MaxDuration <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), b.start.Add(b.MaxDuration))
+	runCtx = ctx
+	return cancel
+}
+
+// budgetError explains err as the end of --max-duration when the deadline
+// is what cut it short.
+func budgetError(cfg *Config, err error) error {
+	if err != nil && runCtx.Err() != nil {
+		return fmt.Errorf("--max-duration %s ran out: %w", cfg.Budget.MaxDuration, err)
+	}
+	return err
+}
+
+// used returns the larger of the spent fractions of the time and request
+// budgets, and which budget that is.
+func (b *runBudget) used(now time.Time) (float64, string) {
+	var frac float64
+	var which string
+	if b.MaxDuration > 0 {
+		frac = float64(now.Sub(b.start)) / float64(b.MaxDuration)
+		which = fmt.Sprintf("%s of --max-duration %s used", now.Sub(b.start).Round(time.Second), b.MaxDuration)
+	}
+	if b.MaxRequests > 0 {
+		n := httpRequests.Load()
+		if f := float64(n) / float64(b.MaxRequests); f > frac {
+			frac = f
+			which = fmt.Sprintf("%d of --max-requests %d sent", n, b.MaxRequests)
+		}
+	}
+	return frac, which
+}
+
+// budgetAllows reports whether phase may run with share of the budget
+// spent. When it may not, the skip is reported once per phase.
+func budgetAllows(cfg *Config, phase string, share float64) bool {
+	b := &cfg.Budget
+	if b.MaxDuration <= 0 && b.MaxRequests <= 0 {
+		return true
+	}
+	frac, which := b.used(time.Now())
+	if frac < share {
+		return true
+	}
+	if !b.skipped[phase] {
+		if b.skipped == nil {
+			b.skipped = make(map[string]bool)
+		}
+		b.skipped[phase] = true
+		warnDegraded(cfg, "budget", "skipped %s: %s", phase, which)
+	}
+	return false
+}
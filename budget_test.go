@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBudgetAllows_Duration(t *testing.T) {
+	tests := []struct {
+		elapsed                      time.Duration
+		enrichment, deprecated, file bool
+	}{
+		{0, true, true, true},
+		{6 * time.Minute, false, true, true},
+		{8 * time.Minute, false, false, true},
+		{11 * time.Minute, false, false, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig()
+		cfg.Budget = runBudget{start: time.Now().Add(-tt.elapsed), MaxDuration: 10 * time.Minute}
+		got := [3]bool{
+			budgetAllows(cfg, "proxy enrichment", budgetShareEnrichment),
+			budgetAllows(cfg, "deprecation checks", budgetShareDeprecated),
+			budgetAllows(cfg, "--files source scanning", budgetShareFiles),
+		}
+		if want := [3]bool{tt.enrichment, tt.deprecated, tt.file}; got != want {
+			t.Errorf("after %s: allows = %v, want %v", tt.elapsed, got, want)
+		}
+	}
+}
+
+func TestBudgetAllows_Unlimited(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Budget = runBudget{start: time.Now().Add(-24 * time.Hour)}
+	if !budgetAllows(cfg, "--files source scanning", budgetShareFiles) {
+		t.Error("a run without a budget must allow every phase")
+	}
+}
+
+func TestBudgetAllows_RequestsReportedOnce(t *testing.T) {
+	defer httpRequests.Store(httpRequests.Load())
+	httpRequests.Store(900)
+
+	cfg := NewDefaultConfig()
+	cfg.Budget = runBudget{start: time.Now(), MaxRequests: 1000}
+	captureStderr(t, func() {
+		for range 3 {
+			if budgetAllows(cfg, "deprecation checks", budgetShareDeprecated) {
+				t.Error("deprecation checks allowed with 90% of --max-requests sent")
+			}
+		}
+	})
+	if !budgetAllows(cfg, "--files source scanning", budgetShareFiles) {
+		t.Error("--files scanning skipped before --max-requests was reached")
+	}
+	if len(cfg.Degradations) != 1 || cfg.Degradations[0].Component != "budget" {
+		t.Errorf("Degradations = %+v, want one budget entry", cfg.Degradations)
+	}
+}
+
+func TestCountingTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: countingTransport{base: srv.Client().Transport}}
+	before := httpRequests.Load()
+	for range 2 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	if n := httpRequests.Load() - before; n != 2 {
+		t.Errorf("counted %d requests, want 2", n)
+	}
+}
+
+func TestStartDeadline_CancelsInFlightWork(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cfg := defaultTestConfig()
+	cfg.Budget = runBudget{start: time.Now().Add(-time.Minute), MaxDuration: time.Second}
+	stop := cfg.Budget.startDeadline()
+	defer func() {
+		stop()
+		runCtx = context.Background()
+	}()
+
+	client := &http.Client{Transport: countingTransport{base: srv.Client().Transport}}
+	_, err := client.Get(srv.URL)
+	if err == nil {
+		t.Fatal("expected a request past --max-duration to be cancelled")
+	}
+	if got := budgetError(cfg, err).Error(); !strings.Contains(got, "--max-duration 1s ran out") {
+		t.Errorf("budgetError = %q, want the --max-duration explanation", got)
+	}
+
+	if err := exec.CommandContext(runCtx, "go", "version").Run(); err == nil {
+		t.Error("expected a command past --max-duration not to run")
+	}
+}
+
+func TestStartDeadline_Unlimited(t *testing.T) {
+	var b runBudget
+	b.startDeadline()()
+	if runCtx.Done() != nil {
+		t.Error("runCtx should never expire without --max-duration")
+	}
+}
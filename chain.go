@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// archivedVia maps each archived indirect module path to the direct
+// dependencies that pull it in, as sorted "path@version" strings. Archived
+// direct dependencies are not included; their Module.Direct says enough.
+func archivedVia(results []RepoStatus, graph map[string][]string, allModules []Module) map[string][]string {
+	entries, ctx := buildTree(results, graph, allModules)
+	via := make(map[string][]string)
+	for _, e := range entries {
+		direct := e.directPath
+		if v := ctx.versionByPath[direct]; v != "" {
+			direct += "@" + v
+		}
+		for _, a := range e.archived {
+			via[a] = append(via[a], direct)
+		}
+	}
+	for _, v := range via {
+		sort.Strings(v)
+	}
+	return via
+}
+
+// filesViaForModule parses the module graph in dir and returns archivedVia
+// for the --files sections. Returns nil (after recording a degradation) if
+// the graph is unavailable, so the sections fall back to omitting the chain.
+func filesViaForModule(cfg *Config, gomodPath string, results []RepoStatus, allModules []Module) map[string][]string {
+	graph, err := loadModGraph(cfg, gomodPath)
+	if err != nil {
+		warnDegraded(cfg, "go mod graph", "could not run go mod graph: %v", err)
+		return nil
+	}
+	return archivedVia(results, graph, allModules)
+}
+
+// fileChainLabel returns the heading for an archived module in the source
+// files section: its path, file count, how it enters the build, and the
+// go.mod line that requires it.
+func fileChainLabel(r RepoStatus, fileCount int, via map[string][]string) string {
+	label := fmt.Sprintf("%s (%d %s", r.Module.Path, fileCount, pluralize(fileCount, "file", "files"))
+	switch {
+	case r.Module.Direct:
+		label += ", direct)"
+	case len(via[r.Module.Path]) > 0:
+		label += ") via " + strings.Join(via[r.Module.Path], ", ")
+	default:
+		label += ")"
+	}
+	if at := requiredAt(r.Module); at != "" {
+		label += " [" + at + "]"
+	}
+	return label
+}
+
+// setJSONVia sets Via on the archived JSON modules from archivedVia.
+func setJSONVia(archived []JSONModule, via map[string][]string) {
+	for i := range archived {
+		archived[i].Via = via[archived[i].Module]
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func chainFixture() ([]RepoStatus, map[string][]string, []Module) {
+	allModules := []Module{
+		{Path: "github.com/a/b", Version: "v1.0.0", Direct: true, Owner: "a", Repo: "b"},
+		{Path: "github.com/c/d", Version: "v1.2.0", Direct: true, Owner: "c", Repo: "d"},
+		{Path: "github.com/old/direct", Version: "v0.1.0", Direct: true, Owner: "old", Repo: "direct"},
+		{Path: "github.com/x/y", Version: "v1.0.0", Owner: "x", Repo: "y"},
+	}
+	graph := map[string][]string{
+		"root":                  {"github.com/a/b@v1.0.0", "github.com/c/d@v1.2.0", "github.com/old/direct@v0.1.0"},
+		"github.com/a/b@v1.0.0": {"github.com/x/y@v1.0.0"},
+		"github.com/c/d@v1.2.0": {"github.com/x/y@v1.0.0"},
+		"github.com/x/y@v1.0.0": {},
+	}
+	results := []RepoStatus{
+		{Module: allModules[2], IsArchived: true},
+		{Module: allModules[3], IsArchived: true},
+	}
+	return results, graph, allModules
+}
+
+func TestArchivedVia(t *testing.T) {
+	results, graph, allModules := chainFixture()
+	via := archivedVia(results, graph, allModules)
+
+	got := strings.Join(via["github.com/x/y"], " ")
+	if got != "github.com/a/b@v1.0.0 github.com/c/d@v1.2.0" {
+		t.Errorf("via[x/y] = %q", got)
+	}
+	if v, ok := via["github.com/old/direct"]; ok {
+		t.Errorf("direct archived module should have no via entry, got %v", v)
+	}
+}
+
+func TestPrintFiles_Chain(t *testing.T) {
+	results, graph, allModules := chainFixture()
+	fileMatches := map[string][]FileMatch{
+		"github.com/old/direct": {{File: "main.go", Line: 3}},
+		"github.com/x/y":        {{File: "a.go", Line: 7}, {File: "b.go", Line: 9}},
+	}
+	via := archivedVia(results, graph, allModules)
+
+	output := captureStdout(t, func() {
+		PrintFiles(results, fileMatches, via)
+	})
+	for _, want := range []string{
+		"github.com/old/direct (1 file, direct)",
+		"github.com/x/y (2 files) via github.com/a/b@v1.0.0, github.com/c/d@v1.2.0",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+
+	md := captureStdout(t, func() {
+		PrintMarkdownFiles(results, fileMatches, via)
+	})
+	if !strings.Contains(md, "### github.com/x/y (2 files) via github.com/a/b@v1.0.0") {
+		t.Errorf("markdown missing chain:\n%s", md)
+	}
+}
+
+func TestFileChainLabel_NoGraph(t *testing.T) {
+	r := RepoStatus{Module: Module{Path: "github.com/x/y"}, IsArchived: true}
+	if got := fileChainLabel(r, 0, nil); got != "github.com/x/y (0 files)" {
+		t.Errorf("fileChainLabel = %q", got)
+	}
+}
+
+func TestSetJSONVia(t *testing.T) {
+	results, graph, allModules := chainFixture()
+	out := buildJSONOutput(defaultTestConfig(), results, nil, map[string][]FileMatch{}, nil)
+	setJSONVia(out.Archived, archivedVia(results, graph, allModules))
+	for _, jm := range out.Archived {
+		switch jm.Module {
+		case "github.com/x/y":
+			if len(jm.Via) != 2 {
+				t.Errorf("x/y via = %v, want 2 entries", jm.Via)
+			}
+		case "github.com/old/direct":
+			if jm.Via != nil {
+				t.Errorf("direct module via = %v, want nil", jm.Via)
+			}
+		}
+	}
+}
+
+func TestFileChainLabel_RequiredAt(t *testing.T) {
+	r := RepoStatus{Module: Module{Path: "github.com/x/y", Direct: true, GoMod: "go.mod", Line: 9}, IsArchived: true}
+	if got := fileChainLabel(r, 2, nil); got != "github.com/x/y (2 files, direct) [go.mod:9]" {
+		t.Errorf("fileChainLabel = %q", got)
+	}
+
+	out := buildJSONOutput(defaultTestConfig(), []RepoStatus{r}, []Module{{Path: "golang.org/x/mod", GoMod: "tools/go.mod", Line: 4}}, nil, nil)
+	if out.Archived[0].RequiredAt != "go.mod:9" {
+		t.Errorf("archived required_at = %q", out.Archived[0].RequiredAt)
+	}
+	if out.NonGitHubModules[0].RequiredAt != "tools/go.mod:4" {
+		t.Errorf("non-GitHub required_at = %q", out.NonGitHubModules[0].RequiredAt)
+	}
+}
+
+func TestBuildTreeJSONOutput_RequiredAt(t *testing.T) {
+	allModules := []Module{
+		{Path: "github.com/a/b", Version: "v1.0.0", Direct: true, Owner: "a", Repo: "b", GoMod: "go.mod", Line: 5},
+		{Path: "github.com/x/y", Version: "v0.1.0", Owner: "x", Repo: "y", GoMod: "go.mod", Line: 12},
+	}
+	results := []RepoStatus{
+		{Module: allModules[0]},
+		{Module: allModules[1], IsArchived: true},
+	}
+	graph := map[string][]string{
+		"mymodule":              {"github.com/a/b@v1.0.0"},
+		"github.com/a/b@v1.0.0": {"github.com/x/y@v0.1.0"},
+	}
+
+	out := buildTreeJSONOutput(defaultTestConfig(), results, graph, allModules, nil, nil)
+	if len(out.Tree) != 1 || len(out.Tree[0].ArchivedDependencies) != 1 {
+		t.Fatalf("tree = %+v", out.Tree)
+	}
+	if got := out.Tree[0].RequiredAt; got != "go.mod:5" {
+		t.Errorf("direct required_at = %q, want go.mod:5", got)
+	}
+	if got := out.Tree[0].ArchivedDependencies[0].RequiredAt; got != "go.mod:12" {
+		t.Errorf("archived dependency required_at = %q, want go.mod:12", got)
+	}
+}
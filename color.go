@@ -31,6 +31,10 @@ var levelStyles = []struct {
 	{colorBoldMagentaUL, "✖"}, // critical: long-standing
 }
 
+// defaultColorThreshold is used when --color-threshold is not given.
+// Non-linear and front-loaded for new issues.
+const defaultColorThreshold = "3m,1y,2y,5y"
+
 // isTerminal returns true if stdout is a terminal (character device).
 func isTerminal() bool {
 	fi, err := os.Stdout.Stat()
@@ -57,9 +61,8 @@ func initColor(cfg *Config, noColor bool, threshold string) error {
 
 	cfg.Color.Enabled = true
 
-	// Default: 3m,1y,2y,5y (non-linear, front-loaded for new issues)
 	if threshold == "" {
-		threshold = "3m,1y,2y,5y"
+		threshold = defaultColorThreshold
 	}
 
 	thresholds, err := parseColorThreshold(threshold)
@@ -129,7 +132,21 @@ func colorize(cfg *Config, s string, t time.Time) string {
 	if !cfg.Color.Enabled {
 		return s
 	}
-	level := classifyAge(cfg, t)
+	return colorizeLevel(cfg, s, classifyAge(cfg, t))
+}
+
+// colorizeSevere wraps a string in the oldest (most severe) level's style,
+// regardless of age. Returns the string unchanged if color is disabled.
+func colorizeSevere(cfg *Config, s string) string {
+	if !cfg.Color.Enabled {
+		return s
+	}
+	return colorizeLevel(cfg, s, len(cfg.Color.Thresholds))
+}
+
+// colorizeLevel wraps a string in the style of the given level; a negative
+// level leaves it unchanged.
+func colorizeLevel(cfg *Config, s string, level int) string {
 	if level < 0 {
 		return s
 	}
@@ -6,7 +6,7 @@ import "time"
 // Created once after flag parsing; passed by pointer to all functions.
 type Config struct {
 	// Output
-	OutputFormat string // "table", "json", "markdown", "mermaid", "quickfix"
+	OutputFormat string // "table", "json", "markdown", "mermaid", "quickfix", "plain", "heatmap"
 	DateFmt      string // "2006-01-02" or "2006-01-02 15:04:05"
 
 	// Filtering
@@ -16,13 +16,25 @@ type Config struct {
 	ShowIgnored  bool
 	NoIgnore     bool
 
+	Filters []ModuleFilter // --filter expressions selecting the modules to report
+
+	IncludeVendoredForked bool // count archived modules patched in vendor/ as failures
+	RequireGoOnly         bool // don't report package.json etc. next to go.mod as unchecked
+
 	// Analysis
-	Resolve    bool
-	Deprecated bool
-	Freshness  bool
-	Duration   DurationConfig
-	Stale      StaleConfig
-	Age        AgeConfig
+	Resolve       bool
+	Deprecated    bool
+	DeprecatedAll bool // check every module, not just direct + archived/stale indirect
+	Freshness     bool
+	UpgradePaths  bool         // classify archived indirect deps by whether a direct upgrade drops them
+	Remediations  bool         // group archived findings by suggested remediation action
+	Actions       bool         // also check GitHub Actions used by .github/workflows
+	Dockerfiles   bool         // also check repos behind ghcr.io images used by Dockerfiles
+	Policy        []PolicyRule // --policy rules checked against dependency repo topics/properties
+	Advisories    bool         // report OSV advisories published after archived deps' last release
+	Duration      DurationConfig
+	Stale         StaleConfig
+	Age           AgeConfig
 
 	// Display
 	ShowAll     bool
@@ -40,11 +52,66 @@ type Config struct {
 	GoVersion   string
 	GoToolchain string
 	Recursive   bool
+	Strict      bool
+	FailOn      string  // which archived findings fail the run: "archived", "direct", "never" (--fail-on)
+	TokenEnv    string  // environment variable holding the GitHub token; "" means gh auth token (--token-env)
+	ProxyRPS    float64 // Go module proxy requests per second; 0 means unlimited (--proxy-rps)
+	History     string  // archive timeline file updated after each run (--history)
+	Team        string  // owning team recorded with history episodes (--team)
+	Pushgateway string  // Prometheus pushgateway base URL for run metrics (--pushgateway)
+	Remote      RemoteConfig
+
+	RecheckArchived  bool   // re-query repos the archive cache says are long archived
+	ArchivedSkipDays int    // days archived before the cache answers for a repo; 0 means the default (--archived-skip-days)
+	PhaseStats       bool   // report time and heap use per pipeline phase (--phase-stats)
+	Sign             string // Ed25519 private key signing the JSON report (--sign)
+	Signature        string // file receiving the detached report signature (--signature)
+
+	HostConcurrency int     // simultaneous requests per vanity host; 0 means unlimited (--host-concurrency)
+	HostRPS         float64 // requests per second per vanity host; 0 means unlimited (--host-rps)
+	DisabledRepos   string  // treatment of disabled or taken-down repos: "fail", "report", "ignore" (--disabled-repos)
+	UnknownRepos    string  // treatment of repos whose status could not be checked: "report", "fail" (--unknown-repos)
+
+	CacheTTL        time.Duration // max age of cache entries that answer a run without GitHub; 0 disables (--cache-ttl)
+	ServedFromCache time.Time     // check time of the oldest cache entry when the cache answered the run
+
+	FailFast      bool            // stop the GitHub query at the first archived direct dependency (--fail-fast)
+	FailFastRepos map[string]bool // owner/repo keys whose archiving ends a --fail-fast run
+	FailedFast    bool            // set when --fail-fast ended the run early
+
+	SelectedOnly bool // prune the module graph to MVS-selected versions (--selected-only)
+
+	NoEmoji bool // plain-text markers in the summary badge (--no-emoji)
+
+	LintVanity bool // check that vanity import hosts still serve go-import tags (--lint-vanity)
+
+	Budget runBudget // wall time and request bounds for optional phases (--max-duration, --max-requests)
+
+	// InternalOrgs are the GitHub organizations whose repositories replace
+	// directives may substitute for upstream modules (internal_orgs in
+	// .modrot.yaml); such internal forks are not checked.
+	InternalOrgs []string
+
+	// Tags assigns criticality tags to modules by pattern (tags in .modrot.yaml).
+	Tags []moduleTag
+
+	// Degradations collects tool-environment problems seen during the run.
+	Degradations []Degradation
+
+	// Summary collects each project's final results during the run.
+	Summary runSummary
 
 	// Time
 	Now time.Time // reference "now" for all time-relative calculations
 }
 
+// RemoteConfig controls scanning a remote repository URL.
+type RemoteConfig struct {
+	Hosts   []string      // allowed hosts (--remote-hosts); nil means defaultRemoteHosts
+	Depth   int           // git clone depth (--clone-depth)
+	Timeout time.Duration // clone timeout (--clone-timeout)
+}
+
 // DurationConfig controls the --duration feature.
 type DurationConfig struct {
 	Enabled bool
@@ -87,6 +154,13 @@ func NewDefaultConfig() *Config {
 		DateFmt:      "2006-01-02",
 		SortMode:     "name",
 		Workers:      50,
+		ProxyRPS:     defaultProxyRPS,
+		FailOn:       "archived",
 		Now:          time.Now(),
+
+		HostConcurrency: defaultHostConcurrency,
+		DisabledRepos:   "fail",
+		UnknownRepos:    "report",
+		CacheTTL:        defaultCacheTTL,
 	}
 }
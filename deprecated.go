@@ -4,68 +4,54 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
-	"sync"
-	"time"
 
 	"golang.org/x/mod/module"
 )
 
-// CheckDeprecations fetches go.mod files from the proxy for all modules
-// and populates Module.Deprecated with the deprecation message if present.
-// Returns count of deprecated modules found.
-func CheckDeprecations(modules []Module, maxWorkers int) int {
-	return checkDeprecationsWithResolver(modules, maxWorkers, newResolver())
-}
-
-// checkDeprecationsWithResolver is the internal implementation that accepts
-// a resolver, allowing tests to inject mock HTTP servers.
+// checkDeprecationsWithResolver fetches go.mod files from the proxy for all
+// modules and populates Module.Deprecated with the deprecation message if
+// present. Returns count of deprecated modules found.
 func checkDeprecationsWithResolver(modules []Module, maxWorkers int, r *resolver) int {
-	type result struct {
-		idx     int
-		message string
-	}
-	results := make(chan result, len(modules))
-
-	sem := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
+	return checkDeprecationsSelected(modules, maxWorkers, r, nil)
+}
 
+// checkDeprecationsSelected checks only the modules for which include returns
+// true (all modules when include is nil). Fetched go.mod bodies are cached on r,
+// so calling it again with a different selection never refetches a module, and
+// modules an earlier pass already found deprecated are not counted again.
+func checkDeprecationsSelected(modules []Module, maxWorkers int, r *resolver, include func(Module) bool) int {
+	var indices []int
 	for i := range modules {
-		wg.Add(1)
-		go func(idx int) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			msg := r.fetchGoModDeprecation(modules[idx].Path, modules[idx].Version)
-			if msg != "" {
-				results <- result{idx: idx, message: msg}
-			}
-		}(i)
+		if modules[i].Deprecated == "" && (include == nil || include(modules[i])) {
+			indices = append(indices, i)
+		}
 	}
 
-	wg.Wait()
-	close(results)
+	messages, _ := mapPool(context.Background(), indices, poolOptions{Workers: maxWorkers}, func(_ context.Context, i int) (string, error) {
+		return r.fetchGoModDeprecation(modules[i].Path, modules[i].Version), nil
+	})
 
 	count := 0
-	for res := range results {
-		modules[res.idx].Deprecated = res.message
-		count++
+	for j, msg := range messages {
+		if msg != "" {
+			modules[indices[j]].Deprecated = msg
+			count++
+		}
 	}
 	return count
 }
 
-// checkDeprecationsAcrossModules checks deprecation across multiple
+// checkDeprecationsAcrossModulesWithResolver checks deprecation across multiple
 // moduleInfo entries (for --recursive), deduplicating by path+version.
-func checkDeprecationsAcrossModules(modules []moduleInfo) int {
-	return checkDeprecationsAcrossModulesWithResolver(modules, newResolver())
+func checkDeprecationsAcrossModulesWithResolver(modules []moduleInfo, r *resolver) int {
+	return checkDeprecationsAcrossModulesSelected(modules, r, nil)
 }
 
-// checkDeprecationsAcrossModulesWithResolver is the internal implementation that accepts
-// a resolver, allowing tests to inject mock HTTP servers.
-func checkDeprecationsAcrossModulesWithResolver(modules []moduleInfo, r *resolver) int {
+// checkDeprecationsAcrossModulesSelected is the recursive-mode counterpart of
+// checkDeprecationsSelected. A path+version is checked if include returns true
+// for any of its occurrences (all when include is nil).
+func checkDeprecationsAcrossModulesSelected(modules []moduleInfo, r *resolver, include func(Module) bool) int {
 	// Collect unique module path+version and their locations.
 	type location struct {
 		miIdx  int // index into modules slice
@@ -78,98 +64,105 @@ func checkDeprecationsAcrossModulesWithResolver(modules []moduleInfo, r *resolve
 	}
 
 	keyLocations := make(map[modKey][]location)
+	selected := make(map[modKey]bool)
+	counted := make(map[modKey]bool)
 	for i := range modules {
 		for j := range modules[i].allModules {
 			m := &modules[i].allModules[j]
 			key := modKey{path: m.Path, version: m.Version}
 			keyLocations[key] = append(keyLocations[key], location{miIdx: i, modIdx: j})
+			if m.Deprecated != "" {
+				counted[key] = true
+			}
+			if include == nil || include(*m) {
+				selected[key] = true
+			}
 		}
 	}
-
-	if len(keyLocations) == 0 {
-		return 0
+	for k := range counted {
+		delete(selected, k)
 	}
 
-	// Build list of unique keys to check.
-	type checkItem struct {
-		key modKey
-	}
-	items := make([]checkItem, 0, len(keyLocations))
-	for k := range keyLocations {
-		items = append(items, checkItem{key: k})
+	if len(selected) == 0 {
+		return 0
 	}
 
-	// Check concurrently with bounded workers.
-	type result struct {
-		key     modKey
-		message string
+	// Check unique keys concurrently with bounded workers.
+	keys := make([]modKey, 0, len(selected))
+	for k := range selected {
+		keys = append(keys, k)
 	}
-	results := make(chan result, len(items))
 
 	const maxWorkers = 20
-	sem := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
-
-	for _, item := range items {
-		wg.Add(1)
-		go func(k modKey) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			msg := r.fetchGoModDeprecation(k.path, k.version)
-			if msg != "" {
-				results <- result{key: k, message: msg}
-			}
-		}(item.key)
-	}
-
-	wg.Wait()
-	close(results)
+	messages, _ := mapPool(context.Background(), keys, poolOptions{Workers: maxWorkers}, func(_ context.Context, k modKey) (string, error) {
+		return r.fetchGoModDeprecation(k.path, k.version), nil
+	})
 
 	count := 0
-	for res := range results {
-		for _, loc := range keyLocations[res.key] {
-			modules[loc.miIdx].allModules[loc.modIdx].Deprecated = res.message
+	for j, msg := range messages {
+		if msg == "" {
+			continue
+		}
+		for _, loc := range keyLocations[keys[j]] {
+			modules[loc.miIdx].allModules[loc.modIdx].Deprecated = msg
 		}
 		count++
 	}
 	return count
 }
 
-// fetchGoModDeprecation fetches a module's go.mod from the proxy and
-// extracts any "// Deprecated:" comment from the module directive.
-func (r *resolver) fetchGoModDeprecation(modulePath, version string) string {
-	escaped, err := module.EscapePath(modulePath)
-	if err != nil {
-		return ""
+// deprecationTier1 selects the modules checked before the GitHub query:
+// all modules with --deprecated-all, otherwise direct dependencies only.
+func deprecationTier1(cfg *Config) func(Module) bool {
+	if cfg.DeprecatedAll {
+		return nil
 	}
+	return func(m Module) bool { return m.Direct }
+}
 
-	url := fmt.Sprintf("%s/%s/@v/%s.mod", r.proxyBaseURL, escaped, version)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return ""
+// deprecationTier2 selects the indirect modules checked after the GitHub
+// query: those whose repo turned out to be archived or stale. Returns nil
+// when no second pass is needed (--deprecated-all or --direct-only).
+func deprecationTier2(cfg *Config, results []RepoStatus, stale []RepoStatus) func(Module) bool {
+	if cfg.DeprecatedAll || cfg.DirectOnly {
+		return nil
+	}
+	flagged := make(map[string]bool)
+	for _, r := range results {
+		if r.IsArchived {
+			flagged[repoKey(r.Module)] = true
+		}
 	}
-
-	resp, err := r.client.Do(req)
-	if err != nil {
-		return ""
+	for _, r := range stale {
+		flagged[repoKey(r.Module)] = true
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != 200 {
-		return ""
+	if len(flagged) == 0 {
+		return nil
+	}
+	return func(m Module) bool {
+		return !m.Direct && m.Owner != "" && flagged[repoKey(m)]
 	}
+}
 
-	body, err := io.ReadAll(resp.Body)
+// fetchGoMod fetches a module's go.mod from the proxy. The response is
+// cached on r, so deprecation and later analyses share a single request per
+// path@version. Returns "" if the file is unavailable.
+func (r *resolver) fetchGoMod(modulePath, version string) string {
+	escaped, err := module.EscapePath(modulePath)
 	if err != nil {
 		return ""
 	}
+	body, ok := r.get(fmt.Sprintf("%s/%s/@v/%s.mod", r.proxyBaseURL, escaped, version))
+	if !ok {
+		return ""
+	}
+	return string(body)
+}
 
-	return parseDeprecation(string(body))
+// fetchGoModDeprecation fetches a module's go.mod from the proxy and
+// extracts any "// Deprecated:" comment from the module directive.
+func (r *resolver) fetchGoModDeprecation(modulePath, version string) string {
+	return parseDeprecation(r.fetchGoMod(modulePath, version))
 }
 
 // parseDeprecation extracts the deprecation message from a go.mod file body.
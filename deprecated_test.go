@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 )
 
@@ -339,3 +340,125 @@ func TestCheckDeprecationsAcrossModules_WorkerPool_Empty(t *testing.T) {
 		t.Errorf("count = %d, want 0 for empty modules", count)
 	}
 }
+
+func TestCheckDeprecationsSelected_Tiers(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/github.com/golang/protobuf/@v/v1.5.4.mod":
+			_, _ = fmt.Fprint(w, "// Deprecated: Use google.golang.org/protobuf instead.\nmodule github.com/golang/protobuf\n")
+		case "/github.com/old/thing/@v/v0.5.0.mod":
+			_, _ = fmt.Fprint(w, "module github.com/old/thing // Deprecated: Use github.com/new/thing.\n")
+		default:
+			_, _ = fmt.Fprint(w, "module x\n")
+		}
+	}))
+	defer srv.Close()
+
+	modules := []Module{
+		{Path: "github.com/direct/dep", Version: "v1.0.0", Direct: true, Owner: "direct", Repo: "dep"},
+		{Path: "github.com/golang/protobuf", Version: "v1.5.4", Owner: "golang", Repo: "protobuf"},
+		{Path: "github.com/old/thing", Version: "v0.5.0", Owner: "old", Repo: "thing"},
+		{Path: "github.com/healthy/indirect", Version: "v1.0.0", Owner: "healthy", Repo: "indirect"},
+	}
+	cfg := defaultTestConfig()
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+
+	// Tier 1: direct only
+	count := checkDeprecationsSelected(modules, 4, r, deprecationTier1(cfg))
+	if count != 0 {
+		t.Errorf("tier 1 count = %d, want 0", count)
+	}
+	if len(requests) != 1 {
+		t.Errorf("tier 1 fetched %d go.mod files, want 1 (direct only)", len(requests))
+	}
+
+	// Tier 2: indirect modules whose repos are archived or stale
+	results := []RepoStatus{{Module: modules[1], IsArchived: true}}
+	stale := []RepoStatus{{Module: modules[2]}}
+	include := deprecationTier2(cfg, results, stale)
+	if include == nil {
+		t.Fatal("tier 2 should select archived/stale indirect modules")
+	}
+	count = checkDeprecationsSelected(modules, 4, r, include)
+	if count != 2 {
+		t.Errorf("tier 2 count = %d, want 2", count)
+	}
+	if modules[3].Deprecated != "" || requests["/github.com/healthy/indirect/@v/v1.0.0.mod"] != 0 {
+		t.Error("healthy indirect module should not be checked")
+	}
+
+	// Re-checking everything reuses cached go.mod bodies
+	checkDeprecationsSelected(modules, 4, r, nil)
+	for path, n := range requests {
+		if n != 1 {
+			t.Errorf("%s fetched %d times, want 1", path, n)
+		}
+	}
+}
+
+func TestCheckDeprecationsAcrossModulesSelected_TiersOverlap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/github.com/golang/protobuf/@v/v1.5.4.mod" {
+			_, _ = fmt.Fprint(w, "// Deprecated: Use google.golang.org/protobuf instead.\nmodule github.com/golang/protobuf\n")
+			return
+		}
+		_, _ = fmt.Fprint(w, "module x\n")
+	}))
+	defer srv.Close()
+
+	// protobuf is direct in one go.mod and an archived indirect in another,
+	// so both tiers select it.
+	protobuf := Module{Path: "github.com/golang/protobuf", Version: "v1.5.4", Owner: "golang", Repo: "protobuf"}
+	direct := protobuf
+	direct.Direct = true
+	modules := []moduleInfo{
+		{allModules: []Module{direct}},
+		{allModules: []Module{protobuf}},
+	}
+	cfg := defaultTestConfig()
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+
+	count := checkDeprecationsAcrossModulesSelected(modules, r, deprecationTier1(cfg))
+	include := deprecationTier2(cfg, []RepoStatus{{Module: protobuf, IsArchived: true}}, nil)
+	if include == nil || !include(protobuf) {
+		t.Fatal("tier 2 should select the archived indirect protobuf")
+	}
+	count += checkDeprecationsAcrossModulesSelected(modules, r, include)
+	if count != 1 {
+		t.Errorf("count = %d, want 1 (protobuf counted once across tiers)", count)
+	}
+	for i := range modules {
+		if modules[i].allModules[0].Deprecated == "" {
+			t.Errorf("modules[%d] protobuf not marked deprecated", i)
+		}
+	}
+}
+
+func TestDeprecationTiers_Flags(t *testing.T) {
+	results := []RepoStatus{{Module: Module{Owner: "a", Repo: "b"}, IsArchived: true}}
+
+	cfg := defaultTestConfig()
+	cfg.DeprecatedAll = true
+	if deprecationTier1(cfg) != nil {
+		t.Error("--deprecated-all: tier 1 should select all modules (nil filter)")
+	}
+	if deprecationTier2(cfg, results, nil) != nil {
+		t.Error("--deprecated-all: no second tier needed")
+	}
+
+	cfg = defaultTestConfig()
+	cfg.DirectOnly = true
+	if deprecationTier2(cfg, results, nil) != nil {
+		t.Error("--direct-only: no second tier needed")
+	}
+
+	cfg = defaultTestConfig()
+	if deprecationTier2(cfg, nil, nil) != nil {
+		t.Error("no archived or stale repos: no second tier needed")
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// defaultDigestSince is the period `modrot digest` covers unless --since
+// says otherwise: one week, for a weekly team-channel post.
+const defaultDigestSince = "7d"
+
+// defaultDigestTop is how many of the oldest outstanding findings the
+// digest lists.
+const defaultDigestTop = 5
+
+// digest summarizes the history file over one period.
+type digest struct {
+	Since, Until time.Time
+	NewArchives  []HistoryEntry // episodes first seen in the period
+	Remediated   []HistoryEntry // episodes fixed in the period
+	Oldest       []HistoryEntry // longest-open outstanding episodes
+	OpenBefore   int            // outstanding findings at Since
+	OpenNow      int            // outstanding findings at Until
+}
+
+// openAt reports whether an episode was outstanding at t.
+func (e HistoryEntry) openAt(t time.Time) bool {
+	return !e.FirstSeen.After(t) && (e.FixedAt == nil || e.FixedAt.After(t))
+}
+
+// buildDigest collects the episodes of team ("" for all teams) that
+// changed between since and now, and the top oldest outstanding ones.
+func buildDigest(h *History, team string, since, now time.Time, top int) digest {
+	d := digest{Since: since, Until: now}
+	for _, e := range h.Episodes {
+		if team != "" && e.Team != team {
+			continue
+		}
+		if !e.FirstSeen.Before(since) && !e.FirstSeen.After(now) {
+			d.NewArchives = append(d.NewArchives, e)
+		}
+		if e.FixedAt != nil && !e.FixedAt.Before(since) && !e.FixedAt.After(now) {
+			d.Remediated = append(d.Remediated, e)
+		}
+		if e.openAt(since) {
+			d.OpenBefore++
+		}
+		if e.openAt(now) {
+			d.OpenNow++
+			d.Oldest = append(d.Oldest, e)
+		}
+	}
+	sort.SliceStable(d.NewArchives, func(i, j int) bool {
+		return d.NewArchives[i].FirstSeen.Before(d.NewArchives[j].FirstSeen)
+	})
+	sort.SliceStable(d.Remediated, func(i, j int) bool {
+		return d.Remediated[i].FixedAt.Before(*d.Remediated[j].FixedAt)
+	})
+	sort.SliceStable(d.Oldest, func(i, j int) bool {
+		return d.Oldest[i].FirstSeen.Before(d.Oldest[j].FirstSeen)
+	})
+	if len(d.Oldest) > top {
+		d.Oldest = d.Oldest[:top]
+	}
+	return d
+}
+
+// scoreDelta formats the change in outstanding findings, e.g. "12 → 10 (-2)".
+// Fewer is better.
+func (d digest) scoreDelta() string {
+	delta := d.OpenNow - d.OpenBefore
+	sign := ""
+	if delta > 0 {
+		sign = "+"
+	}
+	return fmt.Sprintf("%d → %d (%s%d)", d.OpenBefore, d.OpenNow, sign, delta)
+}
+
+// digestWhere describes where an episode was seen: "project" or
+// "project (team)".
+func digestWhere(e HistoryEntry) string {
+	if e.Team == "" {
+		return e.Project
+	}
+	return e.Project + " (" + e.Team + ")"
+}
+
+// daysOpen returns how many whole days an episode was outstanding by end.
+func daysOpen(e HistoryEntry, end time.Time) int {
+	if e.FixedAt != nil {
+		end = *e.FixedAt
+	}
+	return int(end.Sub(e.FirstSeen).Hours() / 24)
+}
+
+// writeDigestMarkdown renders the digest as GitHub-flavored Markdown.
+func writeDigestMarkdown(w io.Writer, d digest) {
+	_, _ = fmt.Fprintf(w, "# modrot digest: %s to %s\n\n", d.Since.Format("2006-01-02"), d.Until.Format("2006-01-02"))
+	_, _ = fmt.Fprintf(w, "**Outstanding archived findings:** %s\n", d.scoreDelta())
+
+	_, _ = fmt.Fprintf(w, "\n## New archives (%d)\n\n", len(d.NewArchives))
+	if len(d.NewArchives) == 0 {
+		_, _ = fmt.Fprintln(w, "None.")
+	}
+	for _, e := range d.NewArchives {
+		line := fmt.Sprintf("- `%s` in %s", e.Module, digestWhere(e))
+		if !e.ArchivedAt.IsZero() {
+			line += ", archived upstream " + e.ArchivedAt.Format("2006-01-02")
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+
+	_, _ = fmt.Fprintf(w, "\n## Remediated (%d)\n\n", len(d.Remediated))
+	if len(d.Remediated) == 0 {
+		_, _ = fmt.Fprintln(w, "None.")
+	}
+	for _, e := range d.Remediated {
+		_, _ = fmt.Fprintf(w, "- `%s` in %s after %d days\n", e.Module, digestWhere(e), daysOpen(e, d.Until))
+	}
+
+	_, _ = fmt.Fprintf(w, "\n## Oldest outstanding (%d of %d)\n\n", len(d.Oldest), d.OpenNow)
+	if len(d.Oldest) == 0 {
+		_, _ = fmt.Fprintln(w, "None.")
+		return
+	}
+	rows := make([][]string, len(d.Oldest))
+	for i, e := range d.Oldest {
+		rows[i] = []string{"`" + e.Module + "`", e.Project, teamLabel(e.Team),
+			e.FirstSeen.Format("2006-01-02"), strconv.Itoa(daysOpen(e, d.Until))}
+	}
+	printMarkdownTable(w, []string{"Module", "Project", "Team", "First Seen", "Days Open"}, rows)
+}
+
+// writeDigestSlack renders the digest in Slack mrkdwn, which has no
+// headings or tables: sections are bold lines and entries are bullets.
+func writeDigestSlack(w io.Writer, d digest) {
+	_, _ = fmt.Fprintf(w, "*modrot digest: %s to %s*\n", d.Since.Format("2006-01-02"), d.Until.Format("2006-01-02"))
+	_, _ = fmt.Fprintf(w, "Outstanding archived findings: *%s*\n", d.scoreDelta())
+
+	_, _ = fmt.Fprintf(w, "\n*New archives (%d)*\n", len(d.NewArchives))
+	for _, e := range d.NewArchives {
+		line := fmt.Sprintf("• `%s` in %s", e.Module, digestWhere(e))
+		if !e.ArchivedAt.IsZero() {
+			line += ", archived upstream " + e.ArchivedAt.Format("2006-01-02")
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+
+	_, _ = fmt.Fprintf(w, "\n*Remediated (%d)*\n", len(d.Remediated))
+	for _, e := range d.Remediated {
+		_, _ = fmt.Fprintf(w, "• `%s` in %s after %d days\n", e.Module, digestWhere(e), daysOpen(e, d.Until))
+	}
+
+	_, _ = fmt.Fprintf(w, "\n*Oldest outstanding (%d of %d)*\n", len(d.Oldest), d.OpenNow)
+	for _, e := range d.Oldest {
+		_, _ = fmt.Fprintf(w, "• `%s` in %s, open %d days\n", e.Module, digestWhere(e), daysOpen(e, d.Until))
+	}
+}
+
+// runDigest implements `modrot digest [--since 7d] [--format markdown|slack]
+// [--team NAME] [--top N] [file]`: summarizes the --history file over a
+// recent period for posting to a team channel.
+// Returns exit code: 0 = success, 2 = error.
+func runDigest(args []string) int {
+	fs := flag.NewFlagSet("digest", flag.ContinueOnError)
+	sinceFlag := fs.String("since", defaultDigestSince, "Period to cover, e.g. 7d, 1m, 1y")
+	formatFlag := fs.String("format", "markdown", "Output format: markdown or slack")
+	teamFlag := fs.String("team", "", "Only include episodes recorded with this --team")
+	topFlag := fs.Int("top", defaultDigestTop, "Number of oldest outstanding findings to list")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot digest [--since 7d] [--format markdown|slack] [--team NAME] [--top N] [file]
+
+Summarize the archive history recorded by --history over a recent period:
+new archives, remediated findings, the oldest outstanding findings, and the
+change in outstanding findings (default file: %s).
+
+  --since PERIOD   Period to cover: years, months, days, e.g. 7d, 1m, 1y6m (default %s)
+  --format string  markdown or slack (default markdown)
+  --team NAME      Only include episodes recorded with this --team
+  --top N          Oldest outstanding findings to list (default %d)
+`, defaultHistoryFile, defaultDigestSince, defaultDigestTop)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	y, m, d, err := parseThreshold(*sinceFlag)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --since: %v\n", err)
+		return 2
+	}
+	if *formatFlag != "markdown" && *formatFlag != "slack" {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: invalid --format %q (want markdown or slack)\n", *formatFlag)
+		return 2
+	}
+	if *topFlag < 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --top must be 0 or more\n")
+		return 2
+	}
+	path := defaultHistoryFile
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+	if _, err := os.Stat(path); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	h, err := loadHistory(path)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	now := time.Now().UTC()
+	dg := buildDigest(h, *teamFlag, now.AddDate(-y, -m, -d), now, *topFlag)
+	if *formatFlag == "slack" {
+		writeDigestSlack(os.Stdout, dg)
+	} else {
+		writeDigestMarkdown(os.Stdout, dg)
+	}
+	return 0
+}
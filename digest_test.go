@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildDigest(t *testing.T) {
+	now := time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -7)
+	at := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	fixed := func(days int) *time.Time { t := at(days); return &t }
+
+	h := &History{Episodes: []HistoryEntry{
+		{Project: "app", Team: "payments", Module: "github.com/a/oldest", FirstSeen: at(100)},
+		{Project: "app", Team: "payments", Module: "github.com/b/older", FirstSeen: at(50)},
+		{Project: "app", Team: "payments", Module: "github.com/c/new", FirstSeen: at(2), ArchivedAt: at(400)},
+		{Project: "app", Team: "payments", Module: "github.com/d/fixed", FirstSeen: at(30), FixedAt: fixed(3)},
+		{Project: "app", Team: "payments", Module: "github.com/e/fixed-long-ago", FirstSeen: at(60), FixedAt: fixed(20)},
+		{Project: "app", Team: "payments", Module: "github.com/f/blip", FirstSeen: at(5), FixedAt: fixed(1)},
+		{Project: "web", Team: "search", Module: "github.com/g/other", FirstSeen: at(1)},
+	}}
+
+	d := buildDigest(h, "payments", since, now, 2)
+	modules := func(es []HistoryEntry) string {
+		var out []string
+		for _, e := range es {
+			out = append(out, e.Module)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := modules(d.NewArchives); got != "github.com/f/blip,github.com/c/new" {
+		t.Errorf("NewArchives = %s", got)
+	}
+	if got := modules(d.Remediated); got != "github.com/d/fixed,github.com/f/blip" {
+		t.Errorf("Remediated = %s", got)
+	}
+	if got := modules(d.Oldest); got != "github.com/a/oldest,github.com/b/older" {
+		t.Errorf("Oldest = %s", got)
+	}
+	if d.OpenBefore != 3 || d.OpenNow != 3 || d.scoreDelta() != "3 → 3 (0)" {
+		t.Errorf("open before=%d now=%d delta=%q, want 3, 3", d.OpenBefore, d.OpenNow, d.scoreDelta())
+	}
+
+	all := buildDigest(h, "", since, now, defaultDigestTop)
+	if all.OpenNow != 4 || all.scoreDelta() != "3 → 4 (+1)" {
+		t.Errorf("all teams: open now=%d delta=%q, want 4, \"3 → 4 (+1)\"", all.OpenNow, all.scoreDelta())
+	}
+}
+
+func TestWriteDigest(t *testing.T) {
+	now := time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)
+	fixedAt := now.AddDate(0, 0, -1)
+	d := buildDigest(&History{Episodes: []HistoryEntry{
+		{Project: "app", Team: "payments", Module: "github.com/a/new", FirstSeen: now.AddDate(0, 0, -2), ArchivedAt: time.Date(2024, 7, 22, 0, 0, 0, 0, time.UTC)},
+		{Project: "app", Module: "github.com/b/fixed", FirstSeen: now.AddDate(0, 0, -11), FixedAt: &fixedAt},
+	}}, "", now.AddDate(0, 0, -7), now, defaultDigestTop)
+
+	var md bytes.Buffer
+	writeDigestMarkdown(&md, d)
+	for _, want := range []string{
+		"# modrot digest: 2025-06-02 to 2025-06-09",
+		"**Outstanding archived findings:** 1 → 1 (0)",
+		"- `github.com/a/new` in app (payments), archived upstream 2024-07-22",
+		"- `github.com/b/fixed` in app after 10 days",
+		"| `github.com/a/new` | app | payments | 2025-06-07 | 2 |",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown digest missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var slack bytes.Buffer
+	writeDigestSlack(&slack, d)
+	for _, want := range []string{
+		"*modrot digest: 2025-06-02 to 2025-06-09*",
+		"*New archives (1)*",
+		"• `github.com/b/fixed` in app after 10 days",
+		"• `github.com/a/new` in app (payments), open 2 days",
+	} {
+		if !strings.Contains(slack.String(), want) {
+			t.Errorf("slack digest missing %q:\n%s", want, slack.String())
+		}
+	}
+	if strings.Contains(slack.String(), "|") || strings.Contains(slack.String(), "#") {
+		t.Errorf("slack digest uses Markdown tables or headings:\n%s", slack.String())
+	}
+}
+
+func TestRunDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := saveHistory(path, &History{Episodes: []HistoryEntry{
+		{Project: "app", Module: "github.com/a/old", FirstSeen: time.Now().AddDate(0, 0, -1)},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	var code int
+	output := captureStdout(t, func() { code = runDigest([]string{"--format", "slack", path}) })
+	if code != 0 || !strings.Contains(output, "github.com/a/old") {
+		t.Errorf("exit %d, output:\n%s", code, output)
+	}
+
+	for _, args := range [][]string{
+		{"--since", "7w", path},
+		{"--format", "html", path},
+		{filepath.Join(t.TempDir(), "missing.json")},
+	} {
+		if code := runDigest(args); code != 2 {
+			t.Errorf("runDigest(%v) = %d, want 2", args, code)
+		}
+	}
+}
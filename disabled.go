@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Reasons a repository is unusable, reported as disabled_reason in JSON.
+const (
+	disabledByGitHub = "disabled" // GitHub's isDisabled: the repository was suspended
+	disabledTakedown = "takedown" // access blocked, e.g. by a DMCA takedown notice
+)
+
+// disabledModes are the values --disabled-repos accepts: "fail" reports
+// disabled repositories in their own section and fails the run whatever
+// --fail-on says, "report" lists them without affecting the exit code, and
+// "ignore" treats them as the GitHub check did before they were classified.
+var disabledModes = []string{"fail", "report", "ignore"}
+
+// isTakedownError reports whether a GraphQL error for a repository means
+// GitHub blocks access to it rather than that it does not exist.
+func isTakedownError(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "access blocked") || strings.Contains(msg, "dmca")
+}
+
+// applyDisabledMode undoes the disabled classification for --disabled-repos
+// ignore: suspended repositories count as active again and taken-down ones
+// as not found.
+func applyDisabledMode(cfg *Config, results []RepoStatus) {
+	if cfg.DisabledRepos != "ignore" {
+		return
+	}
+	for i := range results {
+		r := &results[i]
+		if r.DisabledReason == disabledTakedown {
+			r.NotFound = true
+		}
+		r.Disabled, r.DisabledReason = false, ""
+	}
+}
+
+// disabledExitCode fails a run that found disabled repositories under
+// --disabled-repos fail. It runs after --fail-on, which only decides
+// about archived findings: a disabled dependency breaks any build that
+// fetches it from VCS, so no fail policy lets it through.
+func disabledExitCode(cfg *Config, code int) int {
+	if code != 0 || cfg.DisabledRepos != "fail" {
+		return code
+	}
+	for _, results := range cfg.Summary.results {
+		for _, r := range results {
+			if r.Disabled {
+				return 1
+			}
+		}
+	}
+	return code
+}
+
+// splitDisabled returns the disabled repositories among results, sorted by
+// module path.
+func splitDisabled(results []RepoStatus) []RepoStatus {
+	var disabled []RepoStatus
+	for _, r := range results {
+		if r.Disabled {
+			disabled = append(disabled, r)
+		}
+	}
+	sort.Slice(disabled, func(i, j int) bool {
+		return disabled[i].Module.Path < disabled[j].Module.Path
+	})
+	return disabled
+}
+
+var disabledHeaders = []string{"Module", "Version", "Direct", "Reason"}
+
+// disabledRows formats disabled repositories as table rows.
+func disabledRows(disabled []RepoStatus) [][]string {
+	rows := make([][]string, len(disabled))
+	for i, r := range disabled {
+		reason := "disabled by GitHub"
+		if r.DisabledReason == disabledTakedown {
+			reason = "access blocked (takedown)"
+		}
+		rows[i] = []string{r.Module.Path, r.Module.Version, directLabel(r.Module), reason}
+	}
+	return rows
+}
+
+// PrintDisabledTable outputs disabled repositories. They come first in the
+// report: unlike archived ones, they cannot be fetched at all.
+func PrintDisabledTable(cfg *Config, disabled []RepoStatus) {
+	if len(disabled) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nDISABLED REPOSITORIES (%d %s, builds fetching them from GitHub will fail)\n\n",
+		len(disabled), pluralize(len(disabled), "module", "modules"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(disabledHeaders))
+	for _, row := range disabledRows(disabled) {
+		row[3] = colorizeSevere(cfg, row[3])
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownDisabled outputs disabled repositories in Markdown format.
+func PrintMarkdownDisabled(disabled []RepoStatus) {
+	if len(disabled) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "## DISABLED REPOSITORIES (%d)\n\n", len(disabled))
+	printMarkdownTable(os.Stdout, disabledHeaders, disabledRows(disabled))
+	_, _ = fmt.Fprintln(os.Stdout)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGraphQLResponse_Disabled(t *testing.T) {
+	modules := []Module{
+		{Path: "github.com/a/suspended", Owner: "a", Repo: "suspended"},
+		{Path: "github.com/b/dmca", Owner: "b", Repo: "dmca"},
+		{Path: "github.com/c/gone", Owner: "c", Repo: "gone"},
+	}
+	resp := gqlResponse{
+		Data: map[string]*repoData{
+			"r0": {IsDisabled: true, PushedAt: "2024-01-01T00:00:00Z"},
+		},
+		Errors: []gqlError{
+			{Message: "Repository access blocked", Path: []string{"r1"}},
+			{Message: "Could not resolve to a Repository with the name 'c/gone'.", Path: []string{"r2"}},
+		},
+	}
+
+	results := parseGraphQLResponse(resp, modules)
+	tests := []struct {
+		disabled bool
+		reason   string
+		notFound bool
+	}{
+		{true, disabledByGitHub, false},
+		{true, disabledTakedown, false},
+		{false, "", true},
+	}
+	for i, tt := range tests {
+		r := results[i]
+		if r.Disabled != tt.disabled || r.DisabledReason != tt.reason || r.NotFound != tt.notFound {
+			t.Errorf("%s: disabled=%v reason=%q notFound=%v, want %v %q %v",
+				r.Module.Path, r.Disabled, r.DisabledReason, r.NotFound, tt.disabled, tt.reason, tt.notFound)
+		}
+	}
+	if !strings.Contains(buildGraphQLQuery(modules), "isDisabled") {
+		t.Error("query does not request isDisabled")
+	}
+}
+
+func TestApplyDisabledMode(t *testing.T) {
+	fresh := func() []RepoStatus {
+		return []RepoStatus{
+			{Module: Module{Path: "github.com/a/suspended"}, Disabled: true, DisabledReason: disabledByGitHub},
+			{Module: Module{Path: "github.com/b/dmca"}, Disabled: true, DisabledReason: disabledTakedown, Error: "Repository access blocked"},
+		}
+	}
+
+	cfg := NewDefaultConfig()
+	results := fresh()
+	applyDisabledMode(cfg, results)
+	if !results[0].Disabled || !results[1].Disabled {
+		t.Errorf("fail mode changed results: %+v", results)
+	}
+
+	cfg.DisabledRepos = "ignore"
+	results = fresh()
+	applyDisabledMode(cfg, results)
+	if results[0].Disabled || results[0].NotFound {
+		t.Errorf("ignored suspended repo = %+v, want active", results[0])
+	}
+	if results[1].Disabled || !results[1].NotFound {
+		t.Errorf("ignored taken-down repo = %+v, want not found", results[1])
+	}
+}
+
+func TestDisabledExitCode(t *testing.T) {
+	disabled := []RepoStatus{{Module: Module{Path: "github.com/a/b"}, Disabled: true}}
+	active := []RepoStatus{{Module: Module{Path: "github.com/a/c"}}}
+	tests := []struct {
+		mode    string
+		results []RepoStatus
+		code    int
+		want    int
+	}{
+		{"fail", disabled, 0, 1},
+		{"fail", active, 0, 0},
+		{"fail", disabled, 2, 2},
+		{"report", disabled, 0, 0},
+		{"ignore", disabled, 0, 0},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig()
+		cfg.DisabledRepos = tt.mode
+		cfg.Summary.add("example.com/m", "go.mod", tt.results)
+		if got := disabledExitCode(cfg, tt.code); got != tt.want {
+			t.Errorf("disabledExitCode(%s, %d) = %d, want %d", tt.mode, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestPrintTable_DisabledSection(t *testing.T) {
+	cfg := NewDefaultConfig()
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/b/dmca", Version: "v1.0.0", Direct: true}, Disabled: true, DisabledReason: disabledTakedown},
+		{Module: Module{Path: "github.com/a/suspended", Version: "v0.2.0"}, Disabled: true, DisabledReason: disabledByGitHub},
+		{Module: Module{Path: "github.com/c/old", Version: "v1.0.0"}, IsArchived: true},
+	}
+	output := captureStdout(t, func() {
+		PrintTable(cfg, results, nil)
+	})
+
+	a := strings.Index(output, "github.com/a/suspended")
+	b := strings.Index(output, "github.com/b/dmca")
+	c := strings.Index(output, "github.com/c/old")
+	if a < 0 || b < 0 || c < 0 || !(a < b && b < c) {
+		t.Errorf("want disabled repos sorted and ahead of archived ones, got:\n%s", output)
+	}
+	if !strings.Contains(output, "access blocked (takedown)") || !strings.Contains(output, "disabled by GitHub") {
+		t.Errorf("missing disabled reasons, got:\n%s", output)
+	}
+}
+
+func TestBuildJSONOutput_Disabled(t *testing.T) {
+	cfg := NewDefaultConfig()
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/b/dmca", Owner: "b", Repo: "dmca"}, Disabled: true, DisabledReason: disabledTakedown, Error: "Repository access blocked"},
+	}
+	out := buildJSONOutput(cfg, results, nil, nil, nil)
+	if len(out.Disabled) != 1 || len(out.NotFound) != 0 || len(out.Archived) != 0 {
+		t.Fatalf("disabled=%d not_found=%d archived=%d, want 1 0 0", len(out.Disabled), len(out.NotFound), len(out.Archived))
+	}
+	jm := out.Disabled[0]
+	if jm.DisabledReason != disabledTakedown || jm.FindingID != findingID(findingDisabled, "github.com/b/dmca") {
+		t.Errorf("disabled entry = %+v", jm)
+	}
+}
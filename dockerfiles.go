@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// imageUse is one Dockerfile reference (FROM or COPY --from) to an image
+// published to ghcr.io, whose path names the GitHub repository it is built
+// from.
+type imageUse struct {
+	Owner string
+	Repo  string
+	Image string // full reference, e.g. ghcr.io/owner/repo:1.2
+	File  string // Dockerfile, relative to the working directory
+	Line  int
+}
+
+// imageFinding is an archived repository whose ghcr.io image a Dockerfile
+// uses, with every place that uses it.
+type imageFinding struct {
+	Status RepoStatus
+	Uses   []imageUse
+}
+
+var (
+	// fromRe matches a FROM instruction: image, then an optional stage name.
+	fromRe = regexp.MustCompile(`(?i)^\s*FROM\s+(?:--platform=\S+\s+)?(\S+)(?:\s+AS\s+(\S+))?`)
+	// copyFromRe matches the --from of a COPY instruction.
+	copyFromRe = regexp.MustCompile(`(?i)^\s*COPY\s+.*--from=(\S+)`)
+)
+
+// skipDockerfileDirs are directories findDockerfiles does not descend into.
+var skipDockerfileDirs = map[string]bool{".git": true, "vendor": true, "node_modules": true, "testdata": true}
+
+// isDockerfile reports whether a file name is a Dockerfile by convention:
+// Dockerfile, Dockerfile.*, *.Dockerfile, or Containerfile.
+func isDockerfile(name string) bool {
+	return name == "Dockerfile" || name == "Containerfile" ||
+		strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile")
+}
+
+// findDockerfiles returns the Dockerfiles under dir, sorted.
+func findDockerfiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && skipDockerfileDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isDockerfile(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// parseDockerfileImages returns the ghcr.io images one Dockerfile builds
+// from or copies from. Build stages named with AS are not images, and
+// references containing build arguments ($) cannot be resolved statically.
+func parseDockerfileImages(path string) ([]imageUse, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	stages := make(map[string]bool)
+	var uses []imageUse
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		var ref, stage string
+		if m := fromRe.FindStringSubmatch(text); m != nil {
+			ref, stage = m[1], strings.ToLower(m[2])
+		} else if m := copyFromRe.FindStringSubmatch(text); m != nil {
+			ref = m[1]
+		} else {
+			continue
+		}
+		if owner, repo, ok := parseGHCRImage(ref); ok && !stages[strings.ToLower(ref)] {
+			uses = append(uses, imageUse{Owner: owner, Repo: repo, Image: ref, File: relToCwd(path), Line: line})
+		}
+		if stage != "" {
+			stages[stage] = true
+		}
+	}
+	return uses, scanner.Err()
+}
+
+// parseGHCRImage extracts owner and repository from a ghcr.io image
+// reference ("ghcr.io/owner/repo[/name][:tag][@digest]"). Reports false for
+// other registries, build stages, and references using build arguments.
+func parseGHCRImage(ref string) (owner, repo string, ok bool) {
+	if strings.Contains(ref, "$") {
+		return "", "", false
+	}
+	rest, found := strings.CutPrefix(strings.ToLower(ref), "ghcr.io/")
+	if !found {
+		return "", "", false
+	}
+	rest, _, _ = strings.Cut(rest, "@")
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	owner = parts[0]
+	repo, _, _ = strings.Cut(parts[1], ":")
+	if owner == "" || repo == "" {
+		return "", "", false
+	}
+	return owner, repo, true
+}
+
+// imageModules returns one Module per distinct image repository, shaped
+// like a GitHub dependency so the usual repository check applies.
+func imageModules(uses []imageUse) []Module {
+	seen := make(map[string]bool)
+	var mods []Module
+	for _, u := range uses {
+		key := strings.ToLower(u.Owner + "/" + u.Repo)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		mods = append(mods, Module{Path: "github.com/" + u.Owner + "/" + u.Repo, Owner: u.Owner, Repo: u.Repo})
+	}
+	return mods
+}
+
+// archivedImages matches repository check results back to the Dockerfile
+// references, returning the archived repositories sorted by name.
+func archivedImages(uses []imageUse, results []RepoStatus) []imageFinding {
+	byRepo := make(map[string]*imageFinding)
+	for _, r := range results {
+		if r.IsArchived {
+			byRepo[archiveCacheKey(r.Module)] = &imageFinding{Status: r}
+		}
+	}
+	for _, u := range uses {
+		if f := byRepo[strings.ToLower(u.Owner+"/"+u.Repo)]; f != nil {
+			f.Uses = append(f.Uses, u)
+		}
+	}
+
+	var out []imageFinding
+	for _, f := range byRepo {
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Status.Module.Path < out[j].Status.Module.Path
+	})
+	return out
+}
+
+// checkDockerfileImages checks the source repositories of the ghcr.io
+// images used by the Dockerfiles under dir, for --dockerfiles. It uses the
+// same batched GitHub query and archive cache as module dependencies.
+func checkDockerfileImages(cfg *Config, dir string) []imageFinding {
+	files, err := findDockerfiles(dir)
+	if err != nil {
+		warnDegraded(cfg, "dockerfiles", "could not search for Dockerfiles: %v", err)
+		return nil
+	}
+	var uses []imageUse
+	for _, path := range files {
+		found, err := parseDockerfileImages(path)
+		if err != nil {
+			warnDegraded(cfg, "dockerfiles", "could not read %s: %v", relToCwd(path), err)
+			continue
+		}
+		uses = append(uses, found...)
+	}
+	mods := imageModules(uses)
+	if len(mods) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Checking %d ghcr.io image %s...\n", len(mods), pluralize(len(mods), "repo", "repos"))
+	results, err := CheckReposCached(cfg, mods)
+	if err != nil {
+		warnDegraded(cfg, "github", "could not check Dockerfile images: %v", err)
+		return nil
+	}
+	return archivedImages(uses, results)
+}
+
+var imageHeaders = []string{"Repository", "Archived At", "Used In"}
+
+// imageRows formats archived image repositories as table rows; each use is
+// shown as file:line image.
+func imageRows(cfg *Config, found []imageFinding) [][]string {
+	rows := make([][]string, len(found))
+	for i, f := range found {
+		var used []string
+		for _, u := range f.Uses {
+			used = append(used, fmt.Sprintf("%s:%d %s", u.File, u.Line, u.Image))
+		}
+		rows[i] = []string{f.Status.Module.Owner + "/" + f.Status.Module.Repo, fmtArchivedAt(cfg, f.Status), strings.Join(used, ", ")}
+	}
+	return rows
+}
+
+// PrintImagesTable outputs archived repositories behind Dockerfile images.
+func PrintImagesTable(cfg *Config, found []imageFinding) {
+	if len(found) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nARCHIVED IMAGE SOURCES (%d %s behind ghcr.io images in Dockerfiles)\n\n",
+		len(found), pluralize(len(found), "repository", "repositories"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(imageHeaders))
+	for _, row := range imageRows(cfg, found) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownImages outputs archived image repositories in Markdown format.
+func PrintMarkdownImages(cfg *Config, found []imageFinding) {
+	if len(found) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## ARCHIVED IMAGE SOURCES (%d)\n\n", len(found))
+	printMarkdownTable(os.Stdout, imageHeaders, imageRows(cfg, found))
+}
+
+// JSONArchivedImage is an archived repository behind a Dockerfile image in
+// JSON output.
+type JSONArchivedImage = report.ArchivedImage
+
+// JSONImageUse is one Dockerfile reference to an archived repository's image.
+type JSONImageUse = report.ImageUse
+
+// buildImagesJSON converts archived image repositories for JSON output.
+func buildImagesJSON(found []imageFinding) []JSONArchivedImage {
+	var out []JSONArchivedImage
+	for _, f := range found {
+		r := f.Status
+		ji := JSONArchivedImage{
+			FindingID:  findingID(findingArchivedImage, r.Module.Path),
+			Repository: r.Module.Owner + "/" + r.Module.Repo,
+		}
+		if !r.ArchivedAt.IsZero() {
+			ji.ArchivedAt = r.ArchivedAt.Format("2006-01-02T15:04:05Z")
+		}
+		ji.ArchivedAtSource, ji.ArchivedAtPrecision = archivedAtProvenance(r)
+		if !r.PushedAt.IsZero() {
+			ji.PushedAt = r.PushedAt.Format("2006-01-02T15:04:05Z")
+		}
+		for _, u := range f.Uses {
+			ji.Uses = append(ji.Uses, JSONImageUse{Dockerfile: u.File, Line: u.Line, Image: u.Image})
+		}
+		out = append(out, ji)
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseGHCRImage(t *testing.T) {
+	tests := []struct {
+		ref         string
+		owner, repo string
+		ok          bool
+	}{
+		{"ghcr.io/acme/builder", "acme", "builder", true},
+		{"ghcr.io/acme/builder:1.2", "acme", "builder", true},
+		{"GHCR.io/Acme/Builder:latest", "acme", "builder", true},
+		{"ghcr.io/acme/tools/linter:v3", "acme", "tools", true},
+		{"ghcr.io/acme/builder@sha256:abc", "acme", "builder", true},
+		{"ghcr.io/acme", "", "", false},
+		{"golang:1.25", "", "", false},
+		{"docker.io/library/alpine", "", "", false},
+		{"ghcr.io/${OWNER}/builder", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			owner, repo, ok := parseGHCRImage(tt.ref)
+			if ok != tt.ok || owner != tt.owner || repo != tt.repo {
+				t.Errorf("parseGHCRImage(%q) = %q, %q, %v; want %q, %q, %v", tt.ref, owner, repo, ok, tt.owner, tt.repo, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIsDockerfile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Dockerfile":        true,
+		"Dockerfile.dev":    true,
+		"build.Dockerfile":  true,
+		"Containerfile":     true,
+		"dockerfile.go":     false,
+		"Dockerfile_backup": false,
+	} {
+		if got := isDockerfile(name); got != want {
+			t.Errorf("isDockerfile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseDockerfileImages(t *testing.T) {
+	dir := t.TempDir()
+	df := `ARG BASE=ghcr.io/acme/base:1
+FROM ghcr.io/acme/builder:1.4 AS builder
+RUN go build ./...
+
+FROM --platform=linux/amd64 gcr.io/distroless/static AS runtime
+COPY --from=builder /out/app /app
+COPY --from=ghcr.io/acme/assets:2 /assets /assets
+FROM ${BASE}
+from ghcr.io/Acme/Runtime
+`
+	path := filepath.Join(dir, "Dockerfile")
+	if err := os.WriteFile(path, []byte(df), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	uses, err := parseDockerfileImages(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		repo string
+		line int
+	}{
+		{"acme/builder", 2},
+		{"acme/assets", 7},
+		{"acme/runtime", 9},
+	}
+	if len(uses) != len(want) {
+		t.Fatalf("got %d uses, want %d: %+v", len(uses), len(want), uses)
+	}
+	for i, w := range want {
+		if got := uses[i].Owner + "/" + uses[i].Repo; got != w.repo || uses[i].Line != w.line {
+			t.Errorf("use %d = %s line %d, want %s line %d", i, got, uses[i].Line, w.repo, w.line)
+		}
+	}
+}
+
+func TestFindDockerfiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{
+		"Dockerfile",
+		"deploy/api.Dockerfile",
+		"vendor/github.com/x/y/Dockerfile",
+		"testdata/Dockerfile",
+		"main.go",
+	} {
+		full := filepath.Join(dir, p)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := findDockerfiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "Dockerfile"), filepath.Join(dir, "deploy", "api.Dockerfile")}
+	if len(files) != len(want) || files[0] != want[0] || files[1] != want[1] {
+		t.Errorf("findDockerfiles = %v, want %v", files, want)
+	}
+}
+
+func TestArchivedImages(t *testing.T) {
+	uses := []imageUse{
+		{Owner: "acme", Repo: "builder", Image: "ghcr.io/acme/builder:1", File: "Dockerfile", Line: 1},
+		{Owner: "acme", Repo: "runtime", Image: "ghcr.io/acme/runtime", File: "Dockerfile", Line: 5},
+		{Owner: "acme", Repo: "builder", Image: "ghcr.io/acme/builder:2", File: "deploy/Dockerfile", Line: 1},
+	}
+	if mods := imageModules(uses); len(mods) != 2 || mods[0].Path != "github.com/acme/builder" {
+		t.Errorf("imageModules = %+v", mods)
+	}
+
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/acme/builder", Owner: "acme", Repo: "builder"},
+			IsArchived: true, ArchivedAt: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), ArchivedAtSource: archivedAtGitHub},
+		{Module: Module{Path: "github.com/acme/runtime", Owner: "acme", Repo: "runtime"}},
+	}
+	found := archivedImages(uses, results)
+	if len(found) != 1 || len(found[0].Uses) != 2 {
+		t.Fatalf("archivedImages = %+v", found)
+	}
+
+	out := buildImagesJSON(found)
+	if out[0].Repository != "acme/builder" || out[0].FindingID != findingID(findingArchivedImage, "github.com/acme/builder") {
+		t.Errorf("JSON = %+v", out[0])
+	}
+	if out[0].Uses[1].Dockerfile != "deploy/Dockerfile" || out[0].Uses[1].Image != "ghcr.io/acme/builder:2" {
+		t.Errorf("JSON uses = %+v", out[0].Uses)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// doctorCheck is the outcome of a single environment check.
+type doctorCheck struct {
+	Name   string
+	OK     bool
+	Detail string // what was found (version, error message)
+	Fix    string // how to resolve a failure; empty when OK
+}
+
+// modrotCacheDir returns the directory modrot uses for on-disk caches.
+func modrotCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "modrot"), nil
+}
+
+// runDoctor checks the local environment for everything modrot relies on
+// and prints pass/fail with suggested fixes. With --token-env VAR, the
+// GitHub token check looks at VAR instead of gh, as scans run with the same
+// flag do.
+// Returns exit code: 0 = all checks passed, 1 = at least one failed.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	tokenEnvFlag := fs.String("token-env", "", "Check this environment variable for the GitHub token instead of gh auth token")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot doctor [--token-env VAR]
+
+Check gh, rg, go, network access, and the cache directory.
+
+  --token-env VAR   Check that VAR holds a GitHub token instead of requiring gh
+                    (for CI runs that scan with --token-env)
+`)
+	}
+	if len(args) > 0 && args[0] == "help" {
+		fs.Usage()
+		return 0
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	checks := []doctorCheck{
+		checkGHAuth(*tokenEnvFlag),
+		checkRipgrep(),
+		checkGoToolchain(),
+		checkReachable(client, "api.github.com", "https://api.github.com"),
+		checkReachable(client, "proxy.golang.org", "https://proxy.golang.org"),
+	}
+	if dir, err := modrotCacheDir(); err != nil {
+		checks = append(checks, doctorCheck{
+			Name:   "cache directory",
+			Detail: err.Error(),
+			Fix:    "Set HOME or XDG_CACHE_HOME to a writable location",
+		})
+	} else {
+		checks = append(checks, checkCacheDir(dir))
+	}
+
+	return printDoctorReport(os.Stdout, checks)
+}
+
+// printDoctorReport writes one line per check plus fixes for failures.
+// Returns 0 if every check passed, 1 otherwise.
+func printDoctorReport(w io.Writer, checks []doctorCheck) int {
+	failed := 0
+	for _, c := range checks {
+		mark := "PASS"
+		if !c.OK {
+			mark = "FAIL"
+			failed++
+		}
+		_, _ = fmt.Fprintf(w, "[%s] %-18s %s\n", mark, c.Name, c.Detail)
+		if !c.OK && c.Fix != "" {
+			_, _ = fmt.Fprintf(w, "       fix: %s\n", c.Fix)
+		}
+	}
+	if failed > 0 {
+		_, _ = fmt.Fprintf(w, "\n%d of %d %s failed.\n", failed, len(checks), pluralize(len(checks), "check", "checks"))
+		return 1
+	}
+	_, _ = fmt.Fprintf(w, "\nAll %d checks passed.\n", len(checks))
+	return 0
+}
+
+// checkGHAuth verifies gh is installed and can produce an auth token, or,
+// when envVar is set (--token-env), that envVar holds a token.
+func checkGHAuth(envVar string) doctorCheck {
+	if envVar != "" {
+		c := doctorCheck{Name: "GitHub token"}
+		if strings.TrimSpace(os.Getenv(envVar)) == "" {
+			c.Detail = envVar + " is empty or not set"
+			c.Fix = "Set " + envVar + " to a GitHub token, or drop --token-env to use `gh auth token`"
+			return c
+		}
+		c.OK = true
+		c.Detail = "set in " + envVar
+		return c
+	}
+	c := doctorCheck{Name: "gh auth"}
+	if _, err := exec.LookPath("gh"); err != nil {
+		c.Detail = "gh not found in PATH"
+		c.Fix = "Install the GitHub CLI from https://cli.github.com/ and run `gh auth login`"
+		return c
+	}
+	token, err := getGHToken()
+	if err != nil || token == "" {
+		c.Detail = "gh is installed but not authenticated"
+		c.Fix = "Run `gh auth login`"
+		return c
+	}
+	c.OK = true
+	c.Detail = "authenticated"
+	return c
+}
+
+// checkRipgrep verifies rg is available (needed for --files).
+func checkRipgrep() doctorCheck {
+	c := doctorCheck{Name: "rg"}
+	path, err := exec.LookPath("rg")
+	if err != nil {
+		c.Detail = "rg not found in PATH (needed for --files)"
+		c.Fix = "Install ripgrep from https://github.com/BurntSushi/ripgrep"
+		return c
+	}
+	c.OK = true
+	c.Detail = path
+	return c
+}
+
+// checkGoToolchain verifies the go command works (needed for --tree).
+func checkGoToolchain() doctorCheck {
+	c := doctorCheck{Name: "go toolchain"}
+	v := goToolchainVersion()
+	if v == "go (unknown)" {
+		c.Detail = "go not found or `go version` failed (needed for --tree)"
+		c.Fix = "Install Go from https://go.dev/dl/"
+		return c
+	}
+	c.OK = true
+	c.Detail = v
+	return c
+}
+
+// checkReachable verifies an HTTPS endpoint answers at all. Any HTTP
+// response counts as reachable; only transport errors fail.
+func checkReachable(client *http.Client, name, url string) doctorCheck {
+	c := doctorCheck{Name: name}
+	resp, err := client.Head(url)
+	if err != nil {
+		c.Detail = fmt.Sprintf("unreachable: %v", err)
+		c.Fix = "Check network access, proxy settings (HTTPS_PROXY), and firewall rules"
+		return c
+	}
+	_ = resp.Body.Close()
+	c.OK = true
+	c.Detail = fmt.Sprintf("reachable (HTTP %d)", resp.StatusCode)
+	return c
+}
+
+// checkCacheDir verifies the cache directory can be created and written to.
+func checkCacheDir(dir string) doctorCheck {
+	c := doctorCheck{Name: "cache directory"}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		c.Detail = fmt.Sprintf("cannot create %s: %v", dir, err)
+		c.Fix = "Fix permissions on the parent directory or set XDG_CACHE_HOME"
+		return c
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		c.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+		c.Fix = "Fix permissions on " + dir
+		return c
+	}
+	name := f.Name()
+	_ = f.Close()
+	_ = os.Remove(name)
+	c.OK = true
+	c.Detail = dir
+	return c
+}
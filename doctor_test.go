@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintDoctorReport_AllPass(t *testing.T) {
+	var buf bytes.Buffer
+	code := printDoctorReport(&buf, []doctorCheck{
+		{Name: "rg", OK: true, Detail: "/usr/bin/rg"},
+		{Name: "go toolchain", OK: true, Detail: "go1.25.0"},
+	})
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "[PASS] rg") {
+		t.Errorf("expected PASS line for rg, got:\n%s", out)
+	}
+	if !strings.Contains(out, "All 2 checks passed.") {
+		t.Errorf("expected all-passed summary, got:\n%s", out)
+	}
+	if strings.Contains(out, "fix:") {
+		t.Error("no fixes should be printed when all checks pass")
+	}
+}
+
+func TestPrintDoctorReport_Failure(t *testing.T) {
+	var buf bytes.Buffer
+	code := printDoctorReport(&buf, []doctorCheck{
+		{Name: "gh auth", Detail: "gh not found in PATH", Fix: "Install the GitHub CLI"},
+		{Name: "rg", OK: true, Detail: "/usr/bin/rg"},
+	})
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "[FAIL] gh auth") {
+		t.Errorf("expected FAIL line for gh auth, got:\n%s", out)
+	}
+	if !strings.Contains(out, "fix: Install the GitHub CLI") {
+		t.Errorf("expected fix line, got:\n%s", out)
+	}
+	if !strings.Contains(out, "1 of 2 checks failed.") {
+		t.Errorf("expected failure summary, got:\n%s", out)
+	}
+}
+
+func TestCheckReachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 2 * time.Second}
+
+	// Any HTTP response counts as reachable
+	c := checkReachable(client, "test", server.URL)
+	if !c.OK {
+		t.Errorf("expected reachable, got %+v", c)
+	}
+	if !strings.Contains(c.Detail, "404") {
+		t.Errorf("Detail = %q, want status code", c.Detail)
+	}
+
+	// Closed server is unreachable
+	url := server.URL
+	server.Close()
+	c = checkReachable(client, "test", url)
+	if c.OK {
+		t.Error("expected unreachable after server closed")
+	}
+	if c.Fix == "" {
+		t.Error("expected a fix suggestion for unreachable endpoint")
+	}
+}
+
+func TestCheckCacheDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "modrot")
+	c := checkCacheDir(dir)
+	if !c.OK {
+		t.Fatalf("expected writable cache dir, got %+v", c)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("cache dir should have been created: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("probe file should be removed, found %d entries", len(entries))
+	}
+}
+
+func TestCheckCacheDir_NotWritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission checks do not apply to root")
+	}
+	parent := t.TempDir()
+	if err := os.Chmod(parent, 0o500); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chmod(parent, 0o755) }()
+
+	c := checkCacheDir(filepath.Join(parent, "modrot"))
+	if c.OK {
+		t.Error("expected failure for non-writable parent")
+	}
+}
+
+func TestCheckGHAuth_TokenEnv(t *testing.T) {
+	t.Setenv("MODROT_DOCTOR_TOKEN", "ghp_example")
+	if c := checkGHAuth("MODROT_DOCTOR_TOKEN"); !c.OK || strings.Contains(c.Detail, "ghp_example") {
+		t.Errorf("set variable: %+v, want OK without echoing the token", c)
+	}
+
+	t.Setenv("MODROT_DOCTOR_TOKEN", "")
+	if c := checkGHAuth("MODROT_DOCTOR_TOKEN"); c.OK || !strings.Contains(c.Fix, "MODROT_DOCTOR_TOKEN") {
+		t.Errorf("empty variable: %+v, want a failure naming the variable", c)
+	}
+}
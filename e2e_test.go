@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recording is a set of GitHub, Go proxy, and OSV responses captured for
+// one scenario, stored in testdata/recorded/<scenario>.json.
+type recording struct {
+	// Repos maps owner/repo to its GraphQL repository object. GraphQL
+	// queries are answered by alias from it; absent repos are NOT_FOUND.
+	Repos map[string]json.RawMessage `json:"repos"`
+	// Proxy maps a Go module proxy request path to its response body.
+	Proxy map[string]string `json:"proxy"`
+	// OSV maps a module path to its /v1/query response.
+	OSV map[string]json.RawMessage `json:"osv"`
+	// GraphQLStatus, when set, fails every GraphQL request with that status.
+	GraphQLStatus int `json:"graphql_status"`
+}
+
+// fixtureServer replays a recording over HTTP and counts the requests it
+// served per service.
+type fixtureServer struct {
+	*httptest.Server
+	rec recording
+
+	mu       sync.Mutex
+	requests map[string]int // "graphql", "proxy", "osv" → request count
+}
+
+// repoAliasRe matches one aliased repository selection in a batched query.
+var repoAliasRe = regexp.MustCompile(`(r\d+): repository\(owner: "([^"]+)", name: "([^"]+)"\)`)
+
+// newFixtureServer starts a server replaying testdata/recorded/<scenario>.json.
+func newFixtureServer(t *testing.T, scenario string) *fixtureServer {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "recorded", scenario+".json"))
+	if err != nil {
+		t.Fatalf("reading recording: %v", err)
+	}
+	fs := &fixtureServer{requests: make(map[string]int)}
+	if err := json.Unmarshal(data, &fs.rec); err != nil {
+		t.Fatalf("parsing recording %s: %v", scenario, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", fs.serveGraphQL)
+	mux.HandleFunc("POST /osv", fs.serveOSV)
+	mux.HandleFunc("/proxy/", fs.serveProxy)
+	fs.Server = httptest.NewServer(mux)
+	t.Cleanup(fs.Close)
+	return fs
+}
+
+func (fs *fixtureServer) count(service string) {
+	fs.mu.Lock()
+	fs.requests[service]++
+	fs.mu.Unlock()
+}
+
+// served returns how many requests the server answered for a service.
+func (fs *fixtureServer) served(service string) int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.requests[service]
+}
+
+func (fs *fixtureServer) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	fs.count("graphql")
+	if fs.rec.GraphQLStatus != 0 {
+		http.Error(w, `{"message":"recorded failure"}`, fs.rec.GraphQLStatus)
+		return
+	}
+	var req graphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data := make(map[string]json.RawMessage)
+	var errs []map[string]any
+	for _, m := range repoAliasRe.FindAllStringSubmatch(req.Query, -1) {
+		alias, key := m[1], m[2]+"/"+m[3]
+		if repo, ok := fs.rec.Repos[key]; ok {
+			data[alias] = repo
+			continue
+		}
+		data[alias] = json.RawMessage("null")
+		errs = append(errs, map[string]any{
+			"type":    "NOT_FOUND",
+			"path":    []string{alias},
+			"message": fmt.Sprintf("Could not resolve to a Repository with the name '%s'.", key),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data, "errors": errs})
+}
+
+func (fs *fixtureServer) serveProxy(w http.ResponseWriter, r *http.Request) {
+	fs.count("proxy")
+	body, ok := fs.rec.Proxy[strings.TrimPrefix(r.URL.Path, "/proxy")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = io.WriteString(w, body)
+}
+
+func (fs *fixtureServer) serveOSV(w http.ResponseWriter, r *http.Request) {
+	fs.count("osv")
+	var req struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if resp, ok := fs.rec.OSV[req.Package.Name]; ok {
+		_, _ = w.Write(resp)
+		return
+	}
+	_, _ = io.WriteString(w, "{}")
+}
+
+// endpointsFile writes an --endpoints-from file pointing every service at
+// the fixture server and returns its path.
+func (fs *fixtureServer) endpointsFile(t *testing.T) string {
+	t.Helper()
+	data, err := json.Marshal(endpointSet{
+		GitHubGraphQL: fs.URL + "/graphql",
+		GitHubREST:    fs.URL + "/rest",
+		GoProxy:       fs.URL + "/proxy",
+		OSV:           fs.URL + "/osv",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runModrotE2E runs the binary against the fixture server with an isolated
+// cache directory and a fake token, so no real service or credential is used.
+func runModrotE2E(t *testing.T, binary string, fs *fixtureServer, args ...string) (stdout, stderr string, exitCode int) {
+	t.Helper()
+	args = append([]string{"--endpoints-from", fs.endpointsFile(t), "--token-env", "MODROT_E2E_TOKEN", "--no-color"}, args...)
+	cmd := exec.Command(binary, args...)
+	home := t.TempDir()
+	cmd.Env = append(os.Environ(), "MODROT_E2E_TOKEN=fixture-token", "HOME="+home, "XDG_CACHE_HOME="+filepath.Join(home, ".cache"))
+	var outBuf, errBuf strings.Builder
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+	return outBuf.String(), errBuf.String(), exitCode
+}
+
+func TestE2E_MixedArchived(t *testing.T) {
+	binary := buildBinary(t)
+	fs := newFixtureServer(t, "mixed-archived")
+	fixture := filepath.Join("testdata", "fixtures", "mixed-archived", "go.mod")
+
+	stdout, stderr, code := runModrotE2E(t, binary, fs, fixture)
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1; stderr: %s", code, stderr)
+	}
+	for _, want := range []string{"github.com/pkg/errors", "github.com/mitchellh/mapstructure"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout missing %s:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "github.com/spf13/cobra") {
+		t.Errorf("stdout should not list active modules without --all:\n%s", stdout)
+	}
+	if fs.served("graphql") == 0 {
+		t.Error("expected GraphQL requests to reach the fixture server")
+	}
+}
+
+func TestE2E_FailOnNever(t *testing.T) {
+	binary := buildBinary(t)
+	fs := newFixtureServer(t, "mixed-archived")
+	fixture := filepath.Join("testdata", "fixtures", "mixed-archived", "go.mod")
+
+	_, stderr, code := runModrotE2E(t, binary, fs, "--fail-on", "never", fixture)
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0; stderr: %s", code, stderr)
+	}
+}
+
+func TestE2E_AllClean(t *testing.T) {
+	binary := buildBinary(t)
+	fs := newFixtureServer(t, "all-clean")
+	fixture := filepath.Join("testdata", "fixtures", "all-clean", "go.mod")
+
+	_, stderr, code := runModrotE2E(t, binary, fs, fixture)
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0; stderr: %s", code, stderr)
+	}
+}
+
+func TestE2E_JSON(t *testing.T) {
+	binary := buildBinary(t)
+	fs := newFixtureServer(t, "mixed-archived")
+	fixture := filepath.Join("testdata", "fixtures", "mixed-archived", "go.mod")
+
+	stdout, stderr, code := runModrotE2E(t, binary, fs, "--json", "--deprecated", "--advisories", fixture)
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1; stderr: %s", code, stderr)
+	}
+	var out JSONOutput
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(out.Archived) != 2 {
+		t.Fatalf("archived = %d, want 2", len(out.Archived))
+	}
+	byPath := make(map[string]JSONModule)
+	for _, m := range out.Archived {
+		byPath[m.Module] = m
+	}
+
+	ms := byPath["github.com/mitchellh/mapstructure"]
+	if !ms.Retired || ms.RetractionRationale != "Moved to github.com/go-viper/mapstructure/v2." {
+		t.Errorf("mapstructure: retired=%v rationale=%q", ms.Retired, ms.RetractionRationale)
+	}
+	if len(ms.AdvisoriesAfterRelease) != 1 || ms.AdvisoriesAfterRelease[0].ID != "GO-2025-3787" {
+		t.Errorf("mapstructure advisories = %+v", ms.AdvisoriesAfterRelease)
+	}
+	if pe := byPath["github.com/pkg/errors"]; pe.Retired {
+		t.Error("pkg/errors should not be retired")
+	}
+	if len(out.Deprecated) != 1 || out.Deprecated[0].Module != "github.com/pkg/errors" {
+		t.Errorf("deprecated = %+v, want pkg/errors", out.Deprecated)
+	}
+	if fs.served("proxy") == 0 || fs.served("osv") == 0 {
+		t.Errorf("expected proxy and OSV requests, got proxy=%d osv=%d", fs.served("proxy"), fs.served("osv"))
+	}
+}
+
+func TestE2E_GitHubFailure(t *testing.T) {
+	binary := buildBinary(t)
+	fs := newFixtureServer(t, "github-unavailable")
+	fixture := filepath.Join("testdata", "fixtures", "mixed-archived", "go.mod")
+
+	_, stderr, code := runModrotE2E(t, binary, fs, fixture)
+	if code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+	if !strings.Contains(stderr, "GitHub API returned 502") {
+		t.Errorf("stderr should report the API failure, got: %s", stderr)
+	}
+}
+
+func TestE2E_BadEndpointsFile(t *testing.T) {
+	binary := buildBinary(t)
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	if err := os.WriteFile(path, []byte(`{"goproxy": "ftp://example.com"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixture := filepath.Join("testdata", "fixtures", "mixed-archived", "go.mod")
+
+	_, stderr, code := runModrot(t, binary, "--endpoints-from", path, fixture)
+	if code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+	if !strings.Contains(stderr, "goproxy must be an http(s) URL") {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
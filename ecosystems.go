@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// ecosystemManifest is a non-Go dependency manifest found next to go.mod.
+type ecosystemManifest = report.Manifest
+
+// otherManifests lists dependency manifests of other ecosystems, in the
+// order they are reported.
+var otherManifests = []ecosystemManifest{
+	{Ecosystem: "npm", Manifest: "package.json"},
+	{Ecosystem: "Python", Manifest: "requirements.txt"},
+	{Ecosystem: "Python", Manifest: "pyproject.toml"},
+	{Ecosystem: "Python", Manifest: "Pipfile"},
+	{Ecosystem: "Ruby", Manifest: "Gemfile"},
+	{Ecosystem: "Rust", Manifest: "Cargo.toml"},
+	{Ecosystem: "Maven", Manifest: "pom.xml"},
+	{Ecosystem: "Gradle", Manifest: "build.gradle"},
+	{Ecosystem: "Gradle", Manifest: "build.gradle.kts"},
+	{Ecosystem: "PHP", Manifest: "composer.json"},
+	{Ecosystem: ".NET", Manifest: "packages.config"},
+}
+
+// detectOtherEcosystems returns the non-Go dependency manifests in dir.
+// modrot only checks Go dependencies; reporting these avoids false
+// confidence in mixed-language repositories. Returns nil when
+// --require-go-only is set.
+func detectOtherEcosystems(cfg *Config, dir string) []ecosystemManifest {
+	if cfg.RequireGoOnly {
+		return nil
+	}
+	var found []ecosystemManifest
+	for _, m := range otherManifests {
+		if info, err := os.Stat(filepath.Join(dir, m.Manifest)); err == nil && !info.IsDir() {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+var ecosystemHeaders = []string{"Ecosystem", "Manifest"}
+
+// ecosystemRows formats detected manifests as table rows.
+func ecosystemRows(found []ecosystemManifest) [][]string {
+	rows := make([][]string, len(found))
+	for i, m := range found {
+		rows[i] = []string{m.Ecosystem, m.Manifest}
+	}
+	return rows
+}
+
+// PrintOtherEcosystemsTable notes dependency manifests modrot did not check.
+func PrintOtherEcosystemsTable(found []ecosystemManifest) {
+	if len(found) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nNOT CHECKED (only Go dependencies were checked; %d other %s found, use --require-go-only to hide)\n\n",
+		len(found), pluralize(len(found), "manifest", "manifests"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(ecosystemHeaders))
+	for _, row := range ecosystemRows(found) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownOtherEcosystems notes unchecked manifests in Markdown format.
+func PrintMarkdownOtherEcosystems(found []ecosystemManifest) {
+	if len(found) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## NOT CHECKED\n\nOnly Go dependencies were checked. These dependency manifests of other ecosystems were found:\n\n")
+	printMarkdownTable(os.Stdout, ecosystemHeaders, ecosystemRows(found))
+}
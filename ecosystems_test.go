@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectOtherEcosystems(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "package.json", "requirements.txt", "Cargo.toml"} {
+		writeTestFile(t, filepath.Join(dir, name), "x")
+	}
+	// A directory named like a manifest is not a manifest
+	if err := os.Mkdir(filepath.Join(dir, "Gemfile"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewDefaultConfig()
+	got := detectOtherEcosystems(cfg, dir)
+	want := []ecosystemManifest{
+		{Ecosystem: "npm", Manifest: "package.json"},
+		{Ecosystem: "Python", Manifest: "requirements.txt"},
+		{Ecosystem: "Rust", Manifest: "Cargo.toml"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("detectOtherEcosystems() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	cfg.RequireGoOnly = true
+	if got := detectOtherEcosystems(cfg, dir); got != nil {
+		t.Errorf("--require-go-only should suppress detection, got %v", got)
+	}
+}
+
+func TestDetectOtherEcosystems_GoOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module x\n")
+	if got := detectOtherEcosystems(NewDefaultConfig(), dir); got != nil {
+		t.Errorf("Go-only dir should report nothing, got %v", got)
+	}
+}
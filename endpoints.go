@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// endpointSet holds the base URLs of the remote services modrot queries.
+type endpointSet struct {
+	GitHubGraphQL string `json:"github_graphql"`
+	GitHubREST    string `json:"github_rest"`
+	GoProxy       string `json:"goproxy"`
+	OSV           string `json:"osv"`
+}
+
+// defaultEndpoints are the production services.
+var defaultEndpoints = endpointSet{
+	GitHubGraphQL: "https://api.github.com/graphql",
+	GitHubREST:    "https://api.github.com",
+	GoProxy:       "https://proxy.golang.org",
+	OSV:           "https://api.osv.dev/v1/query",
+}
+
+// endpoints is the set in effect for this run. --endpoints-from replaces
+// entries, pointing the whole pipeline at a fixture server or mirror.
+var endpoints = defaultEndpoints
+
+// loadEndpoints reads an --endpoints-from JSON file. Entries it omits keep
+// their production defaults; unknown keys and non-HTTP URLs are errors.
+func loadEndpoints(path string) (endpointSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return endpointSet{}, fmt.Errorf("reading endpoints: %w", err)
+	}
+	e := defaultEndpoints
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&e); err != nil {
+		return endpointSet{}, fmt.Errorf("parsing endpoints %s: %w", path, err)
+	}
+	for name, raw := range map[string]string{
+		"github_graphql": e.GitHubGraphQL,
+		"github_rest":    e.GitHubREST,
+		"goproxy":        e.GoProxy,
+		"osv":            e.OSV,
+	} {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return endpointSet{}, fmt.Errorf("endpoints %s: %s must be an http(s) URL, got %q", path, name, raw)
+		}
+	}
+	e.GitHubREST = strings.TrimSuffix(e.GitHubREST, "/")
+	e.GoProxy = strings.TrimSuffix(e.GoProxy, "/")
+	return e, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    endpointSet
+		wantErr string
+	}{
+		{
+			name:    "partial override keeps defaults",
+			content: `{"goproxy": "http://127.0.0.1:8080/proxy/"}`,
+			want: endpointSet{
+				GitHubGraphQL: defaultEndpoints.GitHubGraphQL,
+				GitHubREST:    defaultEndpoints.GitHubREST,
+				GoProxy:       "http://127.0.0.1:8080/proxy",
+				OSV:           defaultEndpoints.OSV,
+			},
+		},
+		{
+			name: "all endpoints",
+			content: `{"github_graphql": "http://f/graphql", "github_rest": "http://f/rest/",
+				"goproxy": "http://f/proxy", "osv": "http://f/osv"}`,
+			want: endpointSet{
+				GitHubGraphQL: "http://f/graphql",
+				GitHubREST:    "http://f/rest",
+				GoProxy:       "http://f/proxy",
+				OSV:           "http://f/osv",
+			},
+		},
+		{
+			name:    "empty object",
+			content: `{}`,
+			want:    defaultEndpoints,
+		},
+		{
+			name:    "unknown key",
+			content: `{"proxy": "http://f"}`,
+			wantErr: `unknown field "proxy"`,
+		},
+		{
+			name:    "non-http scheme",
+			content: `{"osv": "file:///tmp/osv"}`,
+			wantErr: "osv must be an http(s) URL",
+		},
+		{
+			name:    "empty value",
+			content: `{"github_graphql": ""}`,
+			wantErr: "github_graphql must be an http(s) URL",
+		},
+		{
+			name:    "malformed JSON",
+			content: `{"osv":`,
+			wantErr: "parsing endpoints",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fixtures.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loadEndpoints(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadEndpoints() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadEndpoints() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("loadEndpoints() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadEndpoints_MissingFile(t *testing.T) {
+	if _, err := loadEndpoints(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestEndpointsApplyToClients(t *testing.T) {
+	saved := endpoints
+	t.Cleanup(func() { endpoints = saved })
+	endpoints = endpointSet{
+		GitHubGraphQL: "http://f/graphql",
+		GitHubREST:    "http://f/rest",
+		GoProxy:       "http://f/proxy",
+		OSV:           "http://f/osv",
+	}
+
+	gc := newGHClient()
+	if gc.graphqlURL != "http://f/graphql" || gc.restURL != "http://f/rest" {
+		t.Errorf("newGHClient() URLs = %q, %q", gc.graphqlURL, gc.restURL)
+	}
+	if r := newResolver(); r.proxyBaseURL != "http://f/proxy" {
+		t.Errorf("newResolver().proxyBaseURL = %q", r.proxyBaseURL)
+	}
+}
@@ -4,9 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"sync"
 	"time"
 
 	"golang.org/x/mod/module"
@@ -18,16 +15,10 @@ type versionInfo struct {
 	Time    time.Time `json:"Time"`
 }
 
-// EnrichNonGitHub enriches non-GitHub modules in-place with data from the Go module proxy.
+// enrichNonGitHubWithResolver enriches non-GitHub modules in-place with data from the Go module proxy.
 // For each module where Owner == "", it fetches:
 //   - /@latest → LatestVersion and SourceURL
 //   - /@v/{version}.info → VersionTime
-func EnrichNonGitHub(modules []Module, maxWorkers int) {
-	enrichNonGitHubWithResolver(modules, maxWorkers, newResolver())
-}
-
-// enrichNonGitHubWithResolver is the internal implementation that accepts
-// a resolver, allowing tests to inject mock HTTP servers.
 func enrichNonGitHubWithResolver(modules []Module, maxWorkers int, r *resolver) {
 	// Collect indices of non-GitHub modules.
 	var indices []int
@@ -41,51 +32,29 @@ func enrichNonGitHubWithResolver(modules []Module, maxWorkers int, r *resolver)
 	}
 
 	type result struct {
-		idx           int
 		latestVersion string
 		latestTime    time.Time
 		sourceURL     string
 		versionTime   time.Time
 	}
-	results := make(chan result, len(indices))
-
-	sem := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
+	results, _ := mapPool(context.Background(), indices, poolOptions{Workers: maxWorkers}, func(_ context.Context, i int) (result, error) {
+		var res result
+		res.latestVersion, res.latestTime, res.sourceURL = r.fetchLatestInfo(modules[i].Path)
+		res.versionTime = r.fetchVersionInfo(modules[i].Path, modules[i].Version)
+		return res, nil
+	})
 
-	for _, idx := range indices {
-		wg.Add(1)
-		go func(i int) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			m := modules[i]
-			res := result{idx: i}
-			res.latestVersion, res.latestTime, res.sourceURL = r.fetchLatestInfo(m.Path)
-			res.versionTime = r.fetchVersionInfo(m.Path, m.Version)
-			results <- res
-		}(idx)
-	}
-
-	wg.Wait()
-	close(results)
-
-	for res := range results {
-		modules[res.idx].LatestVersion = res.latestVersion
-		modules[res.idx].LatestTime = res.latestTime
-		modules[res.idx].SourceURL = res.sourceURL
-		modules[res.idx].VersionTime = res.versionTime
+	for j, res := range results {
+		m := &modules[indices[j]]
+		m.LatestVersion = res.latestVersion
+		m.LatestTime = res.latestTime
+		m.SourceURL = res.sourceURL
+		m.VersionTime = res.versionTime
 	}
 }
 
-// enrichAcrossModules enriches non-GitHub modules across multiple moduleInfo
+// enrichAcrossModulesWithResolver enriches non-GitHub modules across multiple moduleInfo
 // entries (for --recursive), deduplicating by module path+version.
-func enrichAcrossModules(modules []moduleInfo) {
-	enrichAcrossModulesWithResolver(modules, newResolver())
-}
-
-// enrichAcrossModulesWithResolver is the internal implementation that accepts
-// a resolver, allowing tests to inject mock HTTP servers.
 func enrichAcrossModulesWithResolver(modules []moduleInfo, r *resolver) {
 	type location struct {
 		miIdx  int
@@ -112,56 +81,40 @@ func enrichAcrossModulesWithResolver(modules []moduleInfo, r *resolver) {
 		return
 	}
 
+	keys := make([]modKey, 0, len(keyLocations))
+	for k := range keyLocations {
+		keys = append(keys, k)
+	}
+
 	type enrichResult struct {
-		key           modKey
 		latestVersion string
 		latestTime    time.Time
 		sourceURL     string
 		versionTime   time.Time
 	}
-	results := make(chan enrichResult, len(keyLocations))
-
 	const maxWorkers = 20
-	sem := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
-
-	for k := range keyLocations {
-		wg.Add(1)
-		go func(key modKey) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			res := enrichResult{key: key}
-			res.latestVersion, res.latestTime, res.sourceURL = r.fetchLatestInfo(key.path)
-			res.versionTime = r.fetchVersionInfo(key.path, key.version)
-			results <- res
-		}(k)
-	}
-
-	wg.Wait()
-	close(results)
-
-	for res := range results {
-		for _, loc := range keyLocations[res.key] {
-			modules[loc.miIdx].nonGHModules[loc.modIdx].LatestVersion = res.latestVersion
-			modules[loc.miIdx].nonGHModules[loc.modIdx].LatestTime = res.latestTime
-			modules[loc.miIdx].nonGHModules[loc.modIdx].SourceURL = res.sourceURL
-			modules[loc.miIdx].nonGHModules[loc.modIdx].VersionTime = res.versionTime
+	results, _ := mapPool(context.Background(), keys, poolOptions{Workers: maxWorkers}, func(_ context.Context, key modKey) (enrichResult, error) {
+		var res enrichResult
+		res.latestVersion, res.latestTime, res.sourceURL = r.fetchLatestInfo(key.path)
+		res.versionTime = r.fetchVersionInfo(key.path, key.version)
+		return res, nil
+	})
+
+	for j, res := range results {
+		for _, loc := range keyLocations[keys[j]] {
+			m := &modules[loc.miIdx].nonGHModules[loc.modIdx]
+			m.LatestVersion = res.latestVersion
+			m.LatestTime = res.latestTime
+			m.SourceURL = res.sourceURL
+			m.VersionTime = res.versionTime
 		}
 	}
 }
 
-// EnrichFreshness enriches all modules in-place with freshness data from the
+// enrichFreshnessWithResolver enriches all modules in-place with freshness data from the
 // Go module proxy. For each module where LatestVersion is empty, it fetches
 // /@latest → LatestVersion, LatestTime and /@v/{version}.info → VersionTime.
 // Modules already enriched (e.g. non-GitHub modules) are skipped.
-func EnrichFreshness(modules []Module, maxWorkers int) {
-	enrichFreshnessWithResolver(modules, maxWorkers, newResolver())
-}
-
-// enrichFreshnessWithResolver is the internal implementation that accepts
-// a resolver, allowing tests to inject mock HTTP servers.
 func enrichFreshnessWithResolver(modules []Module, maxWorkers int, r *resolver) {
 	// Collect indices of modules needing freshness enrichment.
 	var indices []int
@@ -175,55 +128,34 @@ func enrichFreshnessWithResolver(modules []Module, maxWorkers int, r *resolver)
 	}
 
 	type result struct {
-		idx           int
 		latestVersion string
 		latestTime    time.Time
 		versionTime   time.Time
 	}
-	results := make(chan result, len(indices))
-
-	sem := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
-
-	for _, idx := range indices {
-		wg.Add(1)
-		go func(i int) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			m := modules[i]
-			res := result{idx: i}
-			res.latestVersion, res.latestTime, _ = r.fetchLatestInfo(m.Path)
-			if res.latestVersion != "" && res.latestVersion != m.Version {
-				res.versionTime = r.fetchVersionInfo(m.Path, m.Version)
-			}
-			results <- res
-		}(idx)
-	}
-
-	wg.Wait()
-	close(results)
+	results, _ := mapPool(context.Background(), indices, poolOptions{Workers: maxWorkers}, func(_ context.Context, i int) (result, error) {
+		m := modules[i]
+		var res result
+		res.latestVersion, res.latestTime, _ = r.fetchLatestInfo(m.Path)
+		if res.latestVersion != "" && res.latestVersion != m.Version {
+			res.versionTime = r.fetchVersionInfo(m.Path, m.Version)
+		}
+		return res, nil
+	})
 
-	for res := range results {
+	for j, res := range results {
+		m := &modules[indices[j]]
 		if res.latestVersion != "" {
-			modules[res.idx].LatestVersion = res.latestVersion
-			modules[res.idx].LatestTime = res.latestTime
+			m.LatestVersion = res.latestVersion
+			m.LatestTime = res.latestTime
 		}
 		if !res.versionTime.IsZero() {
-			modules[res.idx].VersionTime = res.versionTime
+			m.VersionTime = res.versionTime
 		}
 	}
 }
 
-// enrichFreshnessAcrossModules enriches all modules across multiple moduleInfo
+// enrichFreshnessAcrossModulesWithResolver enriches all modules across multiple moduleInfo
 // entries (for --recursive --freshness), deduplicating by module path+version.
-func enrichFreshnessAcrossModules(modules []moduleInfo) {
-	enrichFreshnessAcrossModulesWithResolver(modules, newResolver())
-}
-
-// enrichFreshnessAcrossModulesWithResolver is the internal implementation that accepts
-// a resolver, allowing tests to inject mock HTTP servers.
 func enrichFreshnessAcrossModulesWithResolver(modules []moduleInfo, r *resolver) {
 	type location struct {
 		miIdx  int
@@ -250,45 +182,35 @@ func enrichFreshnessAcrossModulesWithResolver(modules []moduleInfo, r *resolver)
 		return
 	}
 
+	keys := make([]modKey, 0, len(keyLocations))
+	for k := range keyLocations {
+		keys = append(keys, k)
+	}
+
 	type enrichResult struct {
-		key           modKey
 		latestVersion string
 		latestTime    time.Time
 		versionTime   time.Time
 	}
-	results := make(chan enrichResult, len(keyLocations))
-
 	const maxWorkers = 20
-	sem := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
-
-	for k := range keyLocations {
-		wg.Add(1)
-		go func(key modKey) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			res := enrichResult{key: key}
-			res.latestVersion, res.latestTime, _ = r.fetchLatestInfo(key.path)
-			if res.latestVersion != "" && res.latestVersion != key.version {
-				res.versionTime = r.fetchVersionInfo(key.path, key.version)
-			}
-			results <- res
-		}(k)
-	}
-
-	wg.Wait()
-	close(results)
+	results, _ := mapPool(context.Background(), keys, poolOptions{Workers: maxWorkers}, func(_ context.Context, key modKey) (enrichResult, error) {
+		var res enrichResult
+		res.latestVersion, res.latestTime, _ = r.fetchLatestInfo(key.path)
+		if res.latestVersion != "" && res.latestVersion != key.version {
+			res.versionTime = r.fetchVersionInfo(key.path, key.version)
+		}
+		return res, nil
+	})
 
-	for res := range results {
-		for _, loc := range keyLocations[res.key] {
+	for j, res := range results {
+		for _, loc := range keyLocations[keys[j]] {
+			m := &modules[loc.miIdx].allModules[loc.modIdx]
 			if res.latestVersion != "" {
-				modules[loc.miIdx].allModules[loc.modIdx].LatestVersion = res.latestVersion
-				modules[loc.miIdx].allModules[loc.modIdx].LatestTime = res.latestTime
+				m.LatestVersion = res.latestVersion
+				m.LatestTime = res.latestTime
 			}
 			if !res.versionTime.IsZero() {
-				modules[loc.miIdx].allModules[loc.modIdx].VersionTime = res.versionTime
+				m.VersionTime = res.versionTime
 			}
 		}
 	}
@@ -302,27 +224,8 @@ func (r *resolver) fetchLatestInfo(modulePath string) (latestVersion string, lat
 		return "", time.Time{}, ""
 	}
 
-	url := fmt.Sprintf("%s/%s/@latest", r.proxyBaseURL, escaped)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", time.Time{}, ""
-	}
-
-	resp, err := r.client.Do(req)
-	if err != nil {
-		return "", time.Time{}, ""
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != 200 {
-		return "", time.Time{}, ""
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	body, ok := r.get(fmt.Sprintf("%s/%s/@latest", r.proxyBaseURL, escaped))
+	if !ok {
 		return "", time.Time{}, ""
 	}
 
@@ -347,27 +250,8 @@ func (r *resolver) fetchVersionInfo(modulePath, version string) time.Time {
 		return time.Time{}
 	}
 
-	url := fmt.Sprintf("%s/%s/@v/%s.info", r.proxyBaseURL, escaped, version)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return time.Time{}
-	}
-
-	resp, err := r.client.Do(req)
-	if err != nil {
-		return time.Time{}
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != 200 {
-		return time.Time{}
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	body, ok := r.get(fmt.Sprintf("%s/%s/@v/%s.info", r.proxyBaseURL, escaped, version))
+	if !ok {
 		return time.Time{}
 	}
 
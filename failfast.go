@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// addFailFastRepos records in cfg.FailFastRepos the repositories among
+// githubModules whose archiving fails the run under --fail-fast: direct
+// dependencies that the go.mod's ignore list does not cover.
+func addFailFastRepos(cfg *Config, gomodPath string, githubModules []Module) {
+	if !cfg.FailFast {
+		return
+	}
+	if cfg.FailFastRepos == nil {
+		cfg.FailFastRepos = make(map[string]bool)
+	}
+	ignoreList := NewIgnoreList()
+	if !cfg.NoIgnore {
+		ignoreList = BuildIgnoreList(filepath.Dir(gomodPath), cfg.IgnoreFile, cfg.IgnoreInline)
+	}
+	for _, m := range githubModules {
+		if m.Direct && !ignoreList.IsIgnored(m.Path) {
+			cfg.FailFastRepos[archiveCacheKey(m)] = true
+		}
+	}
+}
+
+// failsFast reports whether r is an archived finding that ends a
+// --fail-fast run.
+func failsFast(cfg *Config, r RepoStatus) bool {
+	return r.IsArchived && cfg.FailFastRepos[archiveCacheKey(r.Module)]
+}
+
+// failFastOrder moves the modules that can fail a --fail-fast run to the
+// front of query, keeping queryIdx parallel, so the first batches answer
+// whether the run fails.
+func failFastOrder(cfg *Config, query []Module, queryIdx []int) {
+	order := make([]int, len(query))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return cfg.FailFastRepos[archiveCacheKey(query[order[a]])] && !cfg.FailFastRepos[archiveCacheKey(query[order[b]])]
+	})
+	mods := make([]Module, len(query))
+	idx := make([]int, len(query))
+	for i, j := range order {
+		mods[i], idx[i] = query[j], queryIdx[j]
+	}
+	copy(query, mods)
+	copy(queryIdx, idx)
+}
+
+// checkedOnly drops the results of modules a stopped query never reached.
+func checkedOnly(results []RepoStatus) []RepoStatus {
+	var out []RepoStatus
+	for _, r := range results {
+		if r.Module.Path != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// failFastFindings returns the results that end a --fail-fast run.
+func failFastFindings(cfg *Config, results []RepoStatus) []RepoStatus {
+	if !cfg.FailFast {
+		return nil
+	}
+	var found []RepoStatus
+	for _, r := range results {
+		if failsFast(cfg, r) {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// failFast ends a run at the archived direct dependencies found before the
+// query stopped: it outputs the minimal --fail-fast report in the requested
+// format and returns exit code 1, which --fail-on leaves alone.
+func failFast(cfg *Config, found []RepoStatus, checked, total int) int {
+	cfg.FailedFast = true
+	_, _ = fmt.Fprintf(os.Stderr, "Fail fast: archived direct %s found after checking %d of %d %s; skipping the rest of the analysis.\n",
+		pluralize(len(found), "dependency", "dependencies"), checked, total, pluralize(total, "repo", "repos"))
+	switch cfg.OutputFormat {
+	case "json":
+		out := buildJSONOutput(cfg, found, nil, nil, nil)
+		out.Errors = strictErrors(cfg)
+		writeJSON(out)
+	case "markdown":
+		PrintMarkdown(cfg, found, nil)
+	default:
+		PrintTable(cfg, found, nil)
+	}
+	return 1
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckReposWithClientUntil_StopsAfterBatch(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		// Every batch has one module; the second batch is archived.
+		archived := requests == 2
+		_, _ = fmt.Fprintf(w, `{"data": {"r0": {"isArchived": %v, "pushedAt": "2025-03-01T10:00:00Z"}}}`, archived)
+	}))
+	defer srv.Close()
+
+	gc := &ghClient{client: srv.Client(), graphqlURL: srv.URL}
+	modules := []Module{
+		{Path: "github.com/a/one", Owner: "a", Repo: "one", Direct: true},
+		{Path: "github.com/b/two", Owner: "b", Repo: "two", Direct: true},
+		{Path: "github.com/c/three", Owner: "c", Repo: "three", Direct: true},
+	}
+	stop := func(r RepoStatus) bool { return r.IsArchived }
+	results, err := checkReposWithClientUntil(modules, 1, "test-token", gc, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 || len(results) != 2 || !results[1].IsArchived {
+		t.Errorf("requests=%d results=%d, want the query to stop after the archived batch", requests, len(results))
+	}
+}
+
+func TestCheckReposCachedWith_FailFast(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "archived.json")
+	modules := []Module{
+		{Path: "github.com/x/indirect", Owner: "x", Repo: "indirect"},
+		{Path: "github.com/a/direct", Owner: "a", Repo: "direct", Direct: true},
+		{Path: "github.com/b/ignored", Owner: "b", Repo: "ignored", Direct: true},
+		{Path: "github.com/c/direct", Owner: "c", Repo: "direct", Direct: true},
+	}
+	cfg := &Config{Now: now, FailFast: true, IgnoreInline: "github.com/b/ignored"}
+	addFailFastRepos(cfg, filepath.Join(t.TempDir(), "go.mod"), modules)
+	if len(cfg.FailFastRepos) != 2 || !cfg.FailFastRepos["a/direct"] || !cfg.FailFastRepos["c/direct"] {
+		t.Fatalf("FailFastRepos = %v, want a/direct and c/direct", cfg.FailFastRepos)
+	}
+
+	var queried []string
+	check := func(ms []Module) ([]RepoStatus, error) {
+		// Simulates CheckReposUntil with a batch size of one.
+		var out []RepoStatus
+		for _, m := range ms {
+			queried = append(queried, m.Path)
+			r := RepoStatus{Module: m, IsArchived: m.Owner == "a"}
+			out = append(out, r)
+			if failsFast(cfg, r) {
+				break
+			}
+		}
+		return out, nil
+	}
+	results, err := checkReposCachedWith(cfg, path, modules, check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(queried, ",") != "github.com/a/direct" {
+		t.Errorf("queried %v, want only the first failing direct dependency", queried)
+	}
+	found := failFastFindings(cfg, results)
+	if len(results) != 1 || len(found) != 1 || found[0].Module.Path != "github.com/a/direct" {
+		t.Errorf("results = %+v, found = %+v", results, found)
+	}
+}
+
+func TestFailFastOrder(t *testing.T) {
+	cfg := &Config{FailFastRepos: map[string]bool{"b/direct": true, "d/direct": true}}
+	query := []Module{
+		{Owner: "a", Repo: "x"}, {Owner: "b", Repo: "direct"}, {Owner: "c", Repo: "y"}, {Owner: "d", Repo: "direct"},
+	}
+	idx := []int{10, 11, 12, 13}
+	failFastOrder(cfg, query, idx)
+	var got []string
+	for i, m := range query {
+		got = append(got, fmt.Sprintf("%s:%d", m.Owner, idx[i]))
+	}
+	if strings.Join(got, " ") != "b:11 d:13 a:10 c:12" {
+		t.Errorf("order = %v", got)
+	}
+}
+
+func TestFailFast_Report(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.FailFast = true
+	cfg.FailOn = "direct"
+	found := []RepoStatus{{Module: Module{Path: "github.com/a/direct", Owner: "a", Repo: "direct", Version: "v1.0.0", Direct: true}, IsArchived: true}}
+
+	var code int
+	output := captureStdout(t, func() { code = failFast(cfg, found, 3, 40) })
+	if code != 1 || !strings.Contains(output, "github.com/a/direct") {
+		t.Errorf("exit %d, output:\n%s", code, output)
+	}
+	// The run's results were never recorded, which must not let --fail-on
+	// direct turn the failure into success.
+	if got := failOnExitCode(cfg, code); got != 1 {
+		t.Errorf("failOnExitCode = %d, want 1", got)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultFetchConcurrency caps simultaneous HTTP requests across every
+// subsystem sharing a resolver (vanity resolution, enrichment, deprecation,
+// upgrade analysis).
+const defaultFetchConcurrency = 20
+
+// defaultProxyRPS caps requests per second to the Go module proxy, so large
+// recursive scans stay courteous to the public proxy.golang.org.
+const defaultProxyRPS = 50
+
+// defaultHostConcurrency caps simultaneous requests to any one vanity
+// import host. The global limit alone lets every slot land on the same small
+// server, which then throttles or blocks the run; the proxy is exempt.
+const defaultHostConcurrency = 2
+
+// defaultCacheBudget caps the bytes of response bodies a resolver keeps for
+// reuse. Go proxy responses for a go.mod with thousands of requires add up;
+// past the budget, responses are still shared with concurrent callers but
+// not kept, so a later request for the same URL fetches it again.
+const defaultCacheBudget = 256 << 20
+
+// proxyRPS is the proxy request rate newResolver applies (--proxy-rps);
+// zero or less disables pacing.
+var proxyRPS float64 = defaultProxyRPS
+
+// hostConcurrency and hostRPS are the per-host limits newResolver applies
+// to vanity hosts (--host-concurrency, --host-rps); zero or less disables
+// the respective limit.
+var (
+	hostConcurrency         = defaultHostConcurrency
+	hostRPS         float64 = 0
+)
+
+// rateLimiter spaces requests evenly at a fixed rate. A nil *rateLimiter
+// does not limit.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time // earliest start of the next request
+}
+
+// newRateLimiter returns a limiter allowing rps requests per second, or nil
+// when rps is not positive.
+func newRateLimiter(rps float64) *rateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+// wait blocks until the caller may start its request.
+func (l *rateLimiter) wait() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+	time.Sleep(time.Until(at))
+}
+
+// hostLimit holds the concurrency slots and pacing for one vanity host.
+type hostLimit struct {
+	slots chan struct{} // nil means unlimited
+	limit *rateLimiter  // nil means unpaced
+}
+
+// hostLimitFor returns the limits for url's host, creating them on first
+// use. Returns nil for module proxy URLs, which r.limit paces instead, and
+// when no per-host limit is configured.
+func (r *resolver) hostLimitFor(url string) *hostLimit {
+	if strings.HasPrefix(url, r.proxyBaseURL) || (r.hostSlots <= 0 && r.hostRPS <= 0) {
+		return nil
+	}
+	host := url
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+	host = strings.ToLower(host)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hosts == nil {
+		r.hosts = make(map[string]*hostLimit)
+	}
+	h, ok := r.hosts[host]
+	if !ok {
+		h = &hostLimit{limit: newRateLimiter(r.hostRPS)}
+		if r.hostSlots > 0 {
+			h.slots = make(chan struct{}, r.hostSlots)
+		}
+		r.hosts[host] = h
+	}
+	return h
+}
+
+// fetchCall is an in-flight or completed GET, shared by every caller that
+// asks for the same URL.
+type fetchCall struct {
+	done chan struct{}
+	body []byte
+	ok   bool // true for a 200 response whose body was read completely
+}
+
+// get fetches url through the resolver's shared fetch layer. Each URL is
+// requested at most once per resolver: concurrent callers wait for the
+// in-flight request and later callers get the cached response, while the
+// cache holds less than r.budget bytes (0 = unbounded). Requests
+// from all subsystems draw from one pool of r.slots, so running phases
+// back to back or side by side never exceeds the global limit; requests
+// to the module proxy are also paced by r.limit, and requests to any other
+// host are capped per host (see hostLimitFor). A request takes its host
+// slot before a global one, so callers queued on a busy host never hold
+// global slots that requests to other hosts could use.
+// Returns the body and true for a 200 response.
+func (r *resolver) get(url string) ([]byte, bool) {
+	r.mu.Lock()
+	if r.calls == nil {
+		r.calls = make(map[string]*fetchCall)
+	}
+	if c, ok := r.calls[url]; ok {
+		r.mu.Unlock()
+		<-c.done
+		return c.body, c.ok
+	}
+	c := &fetchCall{done: make(chan struct{})}
+	r.calls[url] = c
+	r.mu.Unlock()
+
+	h := r.hostLimitFor(url)
+	if h != nil && h.slots != nil {
+		h.slots <- struct{}{}
+	}
+	if r.slots != nil {
+		r.slots <- struct{}{}
+	}
+	if h != nil {
+		h.limit.wait()
+	} else if strings.HasPrefix(url, r.proxyBaseURL) {
+		r.limit.wait()
+	}
+	c.body, c.ok = r.doGet(url)
+	if r.slots != nil {
+		<-r.slots
+	}
+	if h != nil && h.slots != nil {
+		<-h.slots
+	}
+	r.mu.Lock()
+	if r.budget > 0 && r.cached+len(c.body) > r.budget {
+		delete(r.calls, url)
+	} else {
+		r.cached += len(c.body)
+	}
+	r.mu.Unlock()
+	close(c.done)
+	return c.body, c.ok
+}
+
+// doGet performs a single GET with the standard per-request timeout.
+func (r *resolver) doGet(url string) ([]byte, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, false
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != 200 {
+		return nil, false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolverGet_DeduplicatesInFlight(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer srv.Close()
+
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, ok := r.get(srv.URL + "/x")
+			if !ok || string(body) != "body" {
+				t.Errorf("get() = %q, %v", body, ok)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server hits = %d, want 1 for concurrent identical requests", got)
+	}
+}
+
+func TestResolverGet_CachesAcrossPhases(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/golang.org/x/text/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v0.21.0","Time":"2024-12-04T00:00:00Z","Origin":{"VCS":"git","URL":"https://github.com/golang/text"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+
+	// Vanity resolution and freshness both read @latest
+	if owner, repo := r.resolveViaProxy("golang.org/x/text"); owner != "golang" || repo != "text" {
+		t.Errorf("resolveViaProxy() = %s/%s", owner, repo)
+	}
+	if v, _, _ := r.fetchLatestInfo("golang.org/x/text"); v != "v0.21.0" {
+		t.Errorf("fetchLatestInfo() version = %q", v)
+	}
+	// Failed responses are remembered too
+	r.fetchGoMod("golang.org/x/text", "v0.1.0")
+	r.fetchGoMod("golang.org/x/text", "v0.1.0")
+
+	if got := hits.Load(); got != 2 {
+		t.Errorf("server hits = %d, want 2 (one @latest, one .mod)", got)
+	}
+}
+
+func TestResolverGet_GlobalLimit(t *testing.T) {
+	var cur, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := cur.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		cur.Add(-1)
+	}))
+	defer srv.Close()
+
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL, slots: make(chan struct{}, 2)}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.get(srv.URL + "/" + string(rune('a'+i)))
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak concurrent requests = %d, want <= 2", got)
+	}
+}
+
+func TestRateLimiter_Paces(t *testing.T) {
+	l := newRateLimiter(100) // 10ms apart
+	start := time.Now()
+	for range 6 {
+		l.wait()
+	}
+	// The first request starts immediately; five more wait 10ms each.
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Errorf("6 requests at 100 rps took %v, want at least 50ms", elapsed)
+	}
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	for _, rps := range []float64{0, -1} {
+		if l := newRateLimiter(rps); l != nil {
+			t.Errorf("newRateLimiter(%g) = %+v, want nil", rps, l)
+		}
+	}
+	var l *rateLimiter
+	l.wait() // must not block or panic
+}
+
+func TestGet_PacesOnlyProxyRequests(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL + "/proxy", limit: newRateLimiter(20)} // 50ms apart
+	start := time.Now()
+	for i := range 3 {
+		r.get(fmt.Sprintf("%s/vanity/%d?go-get=1", srv.URL, i))
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("non-proxy requests took %v; they should not be paced", elapsed)
+	}
+
+	start = time.Now()
+	for i := range 3 {
+		r.get(fmt.Sprintf("%s/proxy/mod%d/@latest", srv.URL, i))
+	}
+	r.get(srv.URL + "/proxy/mod0/@latest") // cached: no request, no wait
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 proxy requests at 20 rps took %v, want at least 100ms", elapsed)
+	}
+	if len(times) != 6 {
+		t.Errorf("server saw %d requests, want 6", len(times))
+	}
+}
+
+func TestResolverGet_CacheBudget(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer srv.Close()
+
+	// Room for two 10-byte bodies; the third is served but not kept.
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL, budget: 25}
+	for _, path := range []string{"/a", "/b", "/c", "/a", "/b", "/c"} {
+		if body, ok := r.get(srv.URL + path); !ok || string(body) != "0123456789" {
+			t.Fatalf("get(%s) = %q, %v", path, body, ok)
+		}
+	}
+
+	if got := hits.Load(); got != 4 {
+		t.Errorf("server hits = %d, want 4 (a and b cached, c fetched twice)", got)
+	}
+	if r.cached != 20 {
+		t.Errorf("cached = %d bytes, want 20", r.cached)
+	}
+}
+
+func TestResolverGet_PerHostLimit(t *testing.T) {
+	var total, totalPeak atomic.Int32
+	track := func(cur, peak *atomic.Int32) {
+		n := cur.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+	}
+	newHost := func(cur, peak *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			track(cur, peak)
+			track(&total, &totalPeak)
+			time.Sleep(20 * time.Millisecond)
+			total.Add(-1)
+			cur.Add(-1)
+		}))
+	}
+	var curA, peakA, curB, peakB atomic.Int32
+	a, b := newHost(&curA, &peakA), newHost(&curB, &peakB)
+	defer a.Close()
+	defer b.Close()
+
+	r := &resolver{client: a.Client(), proxyBaseURL: "http://proxy.invalid", slots: make(chan struct{}, 10), hostSlots: 2}
+	var wg sync.WaitGroup
+	for i := range 6 {
+		for _, srv := range []*httptest.Server{a, b} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.get(fmt.Sprintf("%s/mod%d?go-get=1", srv.URL, i))
+			}()
+		}
+	}
+	wg.Wait()
+
+	if got := peakA.Load(); got > 2 {
+		t.Errorf("host A peak concurrent requests = %d, want <= 2", got)
+	}
+	if got := peakB.Load(); got > 2 {
+		t.Errorf("host B peak concurrent requests = %d, want <= 2", got)
+	}
+	if got := totalPeak.Load(); got < 3 {
+		t.Errorf("peak concurrent requests across hosts = %d, want hosts to run in parallel", got)
+	}
+}
+
+func TestResolverGet_ProxyExemptFromHostLimit(t *testing.T) {
+	r := &resolver{proxyBaseURL: "https://proxy.golang.org", hostSlots: 2}
+	if h := r.hostLimitFor("https://proxy.golang.org/golang.org/x/mod/@latest"); h != nil {
+		t.Errorf("proxy URL got host limit %+v, want none", h)
+	}
+	h1 := r.hostLimitFor("https://go.uber.org/zap?go-get=1")
+	h2 := r.hostLimitFor("https://GO.UBER.ORG/atomic?go-get=1")
+	if h1 == nil || h1 != h2 {
+		t.Errorf("same host got limits %p and %p, want one shared limit", h1, h2)
+	}
+	if cap(h1.slots) != 2 {
+		t.Errorf("host slots = %d, want 2", cap(h1.slots))
+	}
+}
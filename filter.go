@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ModuleFilter is one --filter expression. A module is reported only if it
+// matches at least one filter of every kind given, so
+// "owner=aws,owner=hashicorp,direct" keeps direct dependencies owned by
+// either org.
+//
+//	owner=NAME     GitHub owner (case-insensitive)
+//	module=GLOB    module path; * matches any run of characters, including /
+//	direct         direct dependencies only
+//	indirect       indirect dependencies only
+type ModuleFilter struct {
+	Kind  string // "owner", "module", "direct", or "indirect"
+	Value string // owner name or module glob; empty for direct/indirect
+	re    *regexp.Regexp
+}
+
+// String returns the filter in --filter syntax.
+func (f ModuleFilter) String() string {
+	if f.Value == "" {
+		return f.Kind
+	}
+	return f.Kind + "=" + f.Value
+}
+
+// parseFilters parses a comma-separated --filter value.
+func parseFilters(s string) ([]ModuleFilter, error) {
+	var filters []ModuleFilter
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kind, value, hasValue := strings.Cut(part, "=")
+		switch kind {
+		case "direct", "indirect":
+			if hasValue {
+				return nil, fmt.Errorf("invalid filter %q: %s takes no value", part, kind)
+			}
+			filters = append(filters, ModuleFilter{Kind: kind})
+		case "owner", "module":
+			if value == "" {
+				return nil, fmt.Errorf("invalid filter %q: want %s=VALUE", part, kind)
+			}
+			f := ModuleFilter{Kind: kind, Value: value}
+			if kind == "module" {
+				f.Value = canonicalModulePath(value)
+				f.re = globRegexp(f.Value)
+			}
+			filters = append(filters, f)
+		default:
+			return nil, fmt.Errorf("invalid filter %q (want owner=NAME, module=GLOB, direct, or indirect)", part)
+		}
+	}
+	return filters, nil
+}
+
+// globRegexp compiles a module glob, where * matches any run of characters.
+func globRegexp(glob string) *regexp.Regexp {
+	parts := strings.Split(glob, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// match reports whether a single filter matches m.
+func (f ModuleFilter) match(m Module) bool {
+	switch f.Kind {
+	case "owner":
+		return m.Owner != "" && strings.EqualFold(m.Owner, f.Value)
+	case "module":
+		return f.re.MatchString(m.Path)
+	case "direct":
+		return m.Direct
+	case "indirect":
+		return !m.Direct
+	}
+	return false
+}
+
+// matchesFilters reports whether m passes the --filter expressions: for
+// every kind present, at least one filter of that kind must match.
+func matchesFilters(filters []ModuleFilter, m Module) bool {
+	matched := make(map[string]bool)
+	for _, f := range filters {
+		if _, ok := matched[f.Kind]; !ok {
+			matched[f.Kind] = false
+		}
+		if f.match(m) {
+			matched[f.Kind] = true
+		}
+	}
+	for _, ok := range matched {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// filterModules returns the modules that pass cfg.Filters.
+func filterModules(cfg *Config, modules []Module) []Module {
+	if len(cfg.Filters) == 0 {
+		return modules
+	}
+	var out []Module
+	for _, m := range modules {
+		if matchesFilters(cfg.Filters, m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// filterString returns filters in --filter syntax.
+func filterString(filters []ModuleFilter) string {
+	parts := make([]string, len(filters))
+	for i, f := range filters {
+		parts[i] = f.String()
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string // filterString of the result
+		wantErr string
+	}{
+		{"owner=hashicorp", "owner=hashicorp", ""},
+		{"module=github.com/aws/*", "module=github.com/aws/*", ""},
+		{"direct", "direct", ""},
+		{" owner=aws , indirect ", "owner=aws,indirect", ""},
+		{"", "", ""},
+		{"owner=", "", "want owner=VALUE"},
+		{"module", "", "want module=VALUE"},
+		{"direct=true", "", "takes no value"},
+		{"repo=foo", "", "invalid filter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseFilters(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseFilters(%q) error = %v, want containing %q", tt.in, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFilters(%q): %v", tt.in, err)
+			}
+			if s := filterString(got); s != tt.want {
+				t.Errorf("parseFilters(%q) = %q, want %q", tt.in, s, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesFilters(t *testing.T) {
+	awsDirect := Module{Path: "github.com/aws/aws-sdk-go-v2/service/s3", Owner: "aws", Repo: "aws-sdk-go-v2", Direct: true}
+	hcIndirect := Module{Path: "github.com/hashicorp/go-multierror", Owner: "hashicorp", Repo: "go-multierror"}
+	vanity := Module{Path: "golang.org/x/mod", Direct: true}
+
+	tests := []struct {
+		filter string
+		want   []bool // awsDirect, hcIndirect, vanity
+	}{
+		{"", []bool{true, true, true}},
+		{"owner=hashicorp", []bool{false, true, false}},
+		{"owner=HashiCorp", []bool{false, true, false}},
+		{"owner=aws,owner=hashicorp", []bool{true, true, false}},
+		{"module=github.com/aws/*", []bool{true, false, false}},
+		{"module=golang.org/x/*", []bool{false, false, true}},
+		{"module=*multierror", []bool{false, true, false}},
+		{"direct", []bool{true, false, true}},
+		{"indirect", []bool{false, true, false}},
+		{"owner=aws,indirect", []bool{false, false, false}},
+		{"module=github.com/*,direct", []bool{true, false, false}},
+	}
+	for _, tt := range tests {
+		filters, err := parseFilters(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, m := range []Module{awsDirect, hcIndirect, vanity} {
+			if got := matchesFilters(filters, m); got != tt.want[i] {
+				t.Errorf("matchesFilters(%q, %s) = %v, want %v", tt.filter, m.Path, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestFilterModules(t *testing.T) {
+	modules := []Module{
+		{Path: "github.com/aws/smithy-go", Owner: "aws", Repo: "smithy-go"},
+		{Path: "github.com/pkg/errors", Owner: "pkg", Repo: "errors"},
+	}
+	cfg := NewDefaultConfig()
+	if got := filterModules(cfg, modules); len(got) != 2 {
+		t.Errorf("no filters kept %d modules, want 2", len(got))
+	}
+	cfg.Filters, _ = parseFilters("owner=aws")
+	got := filterModules(cfg, modules)
+	if len(got) != 1 || got[0].Owner != "aws" {
+		t.Errorf("owner=aws kept %v", got)
+	}
+}
+
+func TestCollectDeprecated_Filter(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Deprecated = true
+	cfg.Filters, _ = parseFilters("owner=aws")
+	modules := []Module{
+		{Path: "github.com/aws/old", Owner: "aws", Repo: "old", Deprecated: "use new"},
+		{Path: "github.com/pkg/old", Owner: "pkg", Repo: "old", Deprecated: "gone"},
+	}
+	got := collectDeprecated(cfg, modules)
+	if len(got) != 1 || got[0].Path != "github.com/aws/old" {
+		t.Errorf("collectDeprecated = %v, want only github.com/aws/old", got)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Finding types hashed into finding IDs. Each string is part of every ID of
+// its type, so renaming one breaks existing baselines; add a new type instead.
+const (
+	findingArchived       = "archived"
+	findingStale          = "stale"
+	findingDeprecated     = "deprecated"
+	findingNotFound       = "not_found"
+	findingDisabled       = "disabled"
+	findingUnknown        = "unknown"
+	findingVendoredForked = "vendored_forked"
+	findingArchivedAction = "archived_action"
+	findingArchivedImage  = "archived_image"
+	findingDeadVanity     = "dead_vanity_host"
+	findingPolicy         = "policy" // qualified by rule: "policy:topic:deprecated"
+)
+
+// findingID returns the stable ID JSON output attaches to a finding: the
+// finding type's base name and the first 16 hex digits of SHA-256 over the
+// full type and the module path, e.g. "archived-3f1c9a0e5b7d2c84". The
+// version is deliberately left out so an ID survives upgrades that leave
+// the finding in place, and nothing else about the run goes in, so the same
+// finding gets the same ID across runs, machines, and modrot versions.
+func findingID(kind, modulePath string) string {
+	sum := sha256.Sum256([]byte(kind + "\x00" + modulePath))
+	base, _, _ := strings.Cut(kind, ":")
+	return base + "-" + hex.EncodeToString(sum[:8])
+}
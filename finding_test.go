@@ -0,0 +1,42 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFindingID(t *testing.T) {
+	// Pinned: a change here breaks every baseline that references the ID.
+	if got, want := findingID(findingArchived, "github.com/mitchellh/copystructure"), "archived-ffdb59109be3d623"; got != want {
+		t.Errorf("findingID = %q, want %q", got, want)
+	}
+
+	format := regexp.MustCompile(`^[a-z_]+-[0-9a-f]{16}$`)
+	ids := make(map[string]string)
+	for _, tc := range []struct{ kind, path string }{
+		{findingArchived, "github.com/a/b"},
+		{findingStale, "github.com/a/b"},
+		{findingDeprecated, "github.com/a/b"},
+		{findingNotFound, "github.com/a/b"},
+		{findingVendoredForked, "github.com/a/b"},
+		{findingPolicy + ":topic:deprecated", "github.com/a/b"},
+		{findingPolicy + ":topic:unmaintained", "github.com/a/b"},
+		{findingArchived, "github.com/a/c"},
+	} {
+		id := findingID(tc.kind, tc.path)
+		if !format.MatchString(id) {
+			t.Errorf("findingID(%q, %q) = %q, want type-hex16", tc.kind, tc.path, id)
+		}
+		if prev, dup := ids[id]; dup {
+			t.Errorf("findingID(%q, %q) = %q collides with %s", tc.kind, tc.path, id, prev)
+		}
+		ids[id] = tc.kind + " " + tc.path
+		if again := findingID(tc.kind, tc.path); again != id {
+			t.Errorf("findingID not deterministic: %q then %q", id, again)
+		}
+	}
+
+	if id := findingID(findingPolicy+":topic:deprecated", "github.com/a/b"); id[:7] != "policy-" {
+		t.Errorf("policy finding ID %q should use the base type as prefix", id)
+	}
+}
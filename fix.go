@@ -0,0 +1,583 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// plannedUpgrade is a direct dependency upgrade that drops one or more
+// archived indirect modules.
+type plannedUpgrade struct {
+	Path  string
+	From  string
+	To    string
+	Drops []string // archived modules the new version no longer requires
+}
+
+// plannedReplacement is an archived direct dependency swapped for its
+// successor: imports are rewritten and the successor is required instead.
+type plannedReplacement struct {
+	Path   string // archived module
+	With   string // successor module path
+	Source string // where the successor came from, as in replacementFor
+}
+
+// runFix implements `modrot fix [--write | --pr] [path]`.
+// It plans direct dependency upgrades that drop archived indirect modules
+// (the "actionable via upgrade" bucket of --upgrade-paths) and replacements
+// of archived direct dependencies with a known successor and, with --write,
+// applies them; --pr does so on a new branch and opens a pull request with
+// the report and the plan as its description.
+// Returns exit code: 0 = nothing to fix (or fixed), 1 = fixes available
+// (without --write/--pr), 2 = error.
+func runFix(args []string) int {
+	fs := flag.NewFlagSet("fix", flag.ContinueOnError)
+	write := fs.Bool("write", false, "Apply the upgrades and replacements in the working tree")
+	pr := fs.Bool("pr", false, "Apply on a new branch, commit, push, and open a pull request (implies --write)")
+	only := fs.String("module", "", "Comma-separated archived module paths to fix (default: all actionable)")
+	check := fs.Bool("check", false, "Run go build ./... and go test ./... after applying; abort on failure")
+	branch := fs.String("branch", "", "Branch name for --pr (default: modrot/fix-archived-YYYYMMDD)")
+	workers := fs.Int("workers", 50, "Number of repos per GitHub GraphQL batch request")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot fix [flags] [path/to/go.mod | path/to/dir]
+
+Upgrade direct dependencies whose latest release no longer requires an
+archived indirect module, and replace archived direct dependencies that
+have a known successor. Without --write or --pr, only prints the plan.
+
+  --write          Apply the upgrades and replacements in the working tree
+  --pr             Apply on a new branch, commit, push, and open a pull request (implies --write)
+  --module string  Comma-separated archived module paths to fix (default: all actionable)
+  --check          Run go build ./... and go test ./... after applying; abort on failure
+  --branch string  Branch name for --pr (default: modrot/fix-archived-YYYYMMDD)
+  --workers int    Number of repos per GitHub GraphQL batch request (default 50)
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	gomodPath := "go.mod"
+	if fs.NArg() > 0 {
+		gomodPath = goModFile(fs.Arg(0))
+	}
+	dir := filepath.Dir(gomodPath)
+
+	allModules, err := ParseGoMod(gomodPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	githubModules, _ := FilterGitHub(allModules, false)
+	if len(githubModules) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "No GitHub modules found in %s\n", gomodPath)
+		return 0
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Checking %d GitHub modules...\n", len(githubModules))
+	results, err := CheckRepos(githubModules, *workers)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	if hasArchived, _ := findArchived(results); !hasArchived {
+		_, _ = fmt.Fprintf(os.Stderr, "No archived dependencies found.\n")
+		return 0
+	}
+
+	graph, err := parseModGraph(dir, "")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: could not run go mod graph: %v\n", err)
+		return 2
+	}
+	findings := analyzeUpgrades(results, graph, allModules, newResolver(), 20)
+
+	selected := parseModuleList(*only)
+	plan := planUpgrades(findings, selected)
+	replacements := planReplacements(results, allModules, plan, selected)
+	if len(plan) == 0 && len(replacements) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "No archived dependency can be dropped by upgrading a direct dependency or replaced by a known successor.\n")
+		return 0
+	}
+	printUpgradePlan(plan)
+	printReplacementPlan(replacements)
+
+	changes := len(plan) + len(replacements)
+	if !*write && !*pr {
+		_, _ = fmt.Fprintf(os.Stderr, "\nRun with --write to apply %s, or --pr to open a pull request.\n",
+			pluralize(changes, "it", "them"))
+		return 1
+	}
+
+	// undo reverts a failed fix: the edited files, and with --pr the new
+	// branch. Once the changes are committed, switching back to the
+	// original branch reverts them.
+	var restore, rollback func()
+	undo := func(err error) int {
+		if restore != nil {
+			restore()
+		}
+		if rollback != nil {
+			rollback()
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v (changes reverted)\n", err)
+		return 2
+	}
+
+	branchName := *branch
+	if *pr {
+		if branchName == "" {
+			branchName = "modrot/fix-archived-" + time.Now().Format("20060102")
+		}
+		rb, err := gitStartBranch(dir, branchName)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		rollback = rb
+	}
+
+	restoreMod, err := snapshotGoMod(gomodPath)
+	if err != nil {
+		return undo(err)
+	}
+	rewritten, restoreSources, err := rewriteImports(dir, replacements)
+	restore = func() {
+		restoreSources()
+		restoreMod()
+	}
+	if err != nil {
+		return undo(err)
+	}
+	if err := applyFixes(dir, plan, replacements); err != nil {
+		return undo(err)
+	}
+	if *check {
+		if err := runGoChecks(dir); err != nil {
+			return undo(err)
+		}
+	}
+
+	remaining, err := stillRequired(gomodPath, plan, replacements)
+	if err != nil {
+		return undo(err)
+	}
+	for _, p := range remaining {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: %s is still required after the fix (another dependency needs it)\n", p)
+	}
+
+	if !*pr {
+		_, _ = fmt.Fprintf(os.Stderr, "\nApplied %d %s to %s.\n",
+			changes, pluralize(changes, "fix", "fixes"), gomodPath)
+		return 0
+	}
+
+	title := "Drop archived dependencies"
+	if err := gitCommit(dir, title, append([]string{"go.mod", "go.sum"}, rewritten...)); err != nil {
+		return undo(err)
+	}
+	restore = nil
+	cfg := NewDefaultConfig()
+	body := fixPRBody(cfg, results, plan, replacements, remaining, *check)
+	url, err := gitPushAndOpenPR(dir, branchName, title, body)
+	if err != nil {
+		return undo(err)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nOpened %s\n", url)
+	return 0
+}
+
+// parseModuleList splits a comma-separated list of module paths into a set.
+// Returns nil for an empty list.
+func parseModuleList(s string) map[string]bool {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			set[p] = true
+		}
+	}
+	return set
+}
+
+// planUpgrades groups the dropping upgrades of actionable findings by
+// direct dependency. When selected is non-nil, only those archived modules
+// are considered.
+func planUpgrades(findings []UpgradeFinding, selected map[string]bool) []plannedUpgrade {
+	byPath := make(map[string]*plannedUpgrade)
+	for _, f := range findings {
+		if selected != nil && !selected[f.Module] {
+			continue
+		}
+		for _, v := range f.Via {
+			if !v.Drops {
+				continue
+			}
+			pu, ok := byPath[v.Path]
+			if !ok {
+				pu = &plannedUpgrade{Path: v.Path, From: v.Version, To: v.Latest}
+				byPath[v.Path] = pu
+			}
+			pu.Drops = append(pu.Drops, f.Module)
+		}
+	}
+
+	plan := make([]plannedUpgrade, 0, len(byPath))
+	for _, pu := range byPath {
+		sort.Strings(pu.Drops)
+		plan = append(plan, *pu)
+	}
+	sort.Slice(plan, func(i, j int) bool {
+		return plan[i].Path < plan[j].Path
+	})
+	return plan
+}
+
+// planReplacements picks the archived direct dependencies that have a
+// successor module, as found by replacementFor, and are not already dropped
+// by a planned upgrade. Successors that are not module paths, such as
+// standard library packages, need hand edits and are left out. When
+// selected is non-nil, only those archived modules are considered.
+func planReplacements(results []RepoStatus, allModules []Module, plan []plannedUpgrade, selected map[string]bool) []plannedReplacement {
+	dropped := make(map[string]bool)
+	for _, pu := range plan {
+		for _, d := range pu.Drops {
+			dropped[d] = true
+		}
+	}
+	deprecated := make(map[string]string)
+	for _, m := range allModules {
+		if m.Deprecated != "" {
+			deprecated[m.Path] = m.Deprecated
+		}
+	}
+
+	var out []plannedReplacement
+	for _, r := range results {
+		path := r.Module.Path
+		if !r.IsArchived || !r.Module.Direct || dropped[path] || (selected != nil && !selected[path]) {
+			continue
+		}
+		with, source := replacementFor(r, deprecated[path])
+		if with == "" || module.CheckPath(with) != nil {
+			continue
+		}
+		out = append(out, plannedReplacement{Path: path, With: with, Source: source})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// upgradePlanRows formats the plan as table rows.
+func upgradePlanRows(plan []plannedUpgrade) [][]string {
+	rows := make([][]string, len(plan))
+	for i, pu := range plan {
+		rows[i] = []string{pu.Path, pu.From, pu.To, strings.Join(pu.Drops, ", ")}
+	}
+	return rows
+}
+
+// printUpgradePlan outputs the planned upgrades.
+func printUpgradePlan(plan []plannedUpgrade) {
+	if len(plan) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nPLANNED UPGRADES (%d %s)\n\n", len(plan), pluralize(len(plan), "upgrade", "upgrades"))
+	for _, row := range upgradePlanRows(plan) {
+		_, _ = fmt.Fprintf(os.Stdout, "%s\t%s → %s\tdrops %s\n", row[0], row[1], row[2], row[3])
+	}
+}
+
+// replacementPlanRows formats the planned replacements as table rows.
+func replacementPlanRows(replacements []plannedReplacement) [][]string {
+	rows := make([][]string, len(replacements))
+	for i, pr := range replacements {
+		rows[i] = []string{pr.Path, pr.With, pr.Source}
+	}
+	return rows
+}
+
+// printReplacementPlan outputs the planned replacements.
+func printReplacementPlan(replacements []plannedReplacement) {
+	if len(replacements) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nPLANNED REPLACEMENTS (%d %s)\n\n", len(replacements), pluralize(len(replacements), "replacement", "replacements"))
+	for _, row := range replacementPlanRows(replacements) {
+		_, _ = fmt.Fprintf(os.Stdout, "%s\t→ %s\t(%s)\n", row[0], row[1], row[2])
+	}
+}
+
+// fixPRBody renders the pull request description for a fix: the archived
+// dependencies report, then the upgrades and replacements applied.
+func fixPRBody(cfg *Config, results []RepoStatus, plan []plannedUpgrade, replacements []plannedReplacement, remaining []string, checked bool) string {
+	var b bytes.Buffer
+	_, _ = fmt.Fprintf(&b, "Drops archived dependencies by upgrading the direct dependencies that pull them in and replacing those with a known successor.\n\n")
+
+	var archived [][]string
+	var sorted []RepoStatus
+	for _, r := range results {
+		if r.IsArchived {
+			sorted = append(sorted, r)
+		}
+	}
+	sortResults(cfg, sorted)
+	for _, r := range sorted {
+		archived = append(archived, archivedRow(cfg, r))
+	}
+	_, _ = fmt.Fprintf(&b, "## Archived dependencies (%d of %d github.com modules)\n\n", len(archived), len(results))
+	printMarkdownTable(&b, archivedHeaders(cfg), archived)
+	_, _ = fmt.Fprintf(&b, "\n%s\n", computeRepoTotals(results))
+
+	if len(plan) > 0 {
+		_, _ = fmt.Fprintf(&b, "\n## Upgrades\n\n")
+		printMarkdownTable(&b, []string{"Module", "From", "To", "Drops archived"}, upgradePlanRows(plan))
+	}
+	if len(replacements) > 0 {
+		_, _ = fmt.Fprintf(&b, "\n## Replacements\n\n")
+		printMarkdownTable(&b, []string{"Archived", "Replaced with", "Source"}, replacementPlanRows(replacements))
+	}
+	if len(remaining) > 0 {
+		_, _ = fmt.Fprintf(&b, "\nStill required through other dependencies: %s\n", strings.Join(remaining, ", "))
+	}
+	if checked {
+		_, _ = fmt.Fprintf(&b, "\n`go build ./...` and `go test ./...` passed after the fix.\n")
+	} else {
+		_, _ = fmt.Fprintf(&b, "\nBuild and tests were not run by modrot.\n")
+	}
+	_, _ = fmt.Fprintf(&b, "\nGenerated by `modrot fix --pr`.\n")
+	return b.String()
+}
+
+// applyFixes runs go get for each planned upgrade and for the latest
+// version of each successor, then go mod tidy, which also drops the
+// requires of replaced modules nothing imports anymore.
+func applyFixes(dir string, plan []plannedUpgrade, replacements []plannedReplacement) error {
+	args := []string{"get"}
+	for _, pu := range plan {
+		args = append(args, pu.Path+"@"+pu.To)
+	}
+	for _, pr := range replacements {
+		args = append(args, pr.With+"@latest")
+	}
+	if err := runCommand(dir, "go", args...); err != nil {
+		return err
+	}
+	return runCommand(dir, "go", "mod", "tidy")
+}
+
+// rewriteImports rewrites the imports of each replaced module, and of its
+// packages, to the successor in every Go file of the module rooted at dir.
+// Vendored code, testdata, and nested modules are left alone. It returns
+// the rewritten files relative to dir and a function that restores them,
+// which is valid even when rewriting fails partway.
+func rewriteImports(dir string, replacements []plannedReplacement) (rewritten []string, restore func(), err error) {
+	originals := make(map[string][]byte)
+	restore = func() {
+		for path, data := range originals {
+			_ = os.WriteFile(path, data, 0o644)
+		}
+	}
+	if len(replacements) == 0 {
+		return nil, restore, nil
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			name := d.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		updated, changed := rewriteFileImports(data, replacements)
+		if !changed {
+			return nil
+		}
+		originals[path] = data
+		if err := os.WriteFile(path, updated, 0o644); err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rewritten = append(rewritten, rel)
+		return nil
+	})
+	sort.Strings(rewritten)
+	return rewritten, restore, err
+}
+
+// rewriteFileImports returns src with the import paths of replaced modules
+// pointed at their successors, and whether anything changed. Files that do
+// not parse are returned unchanged.
+func rewriteFileImports(src []byte, replacements []plannedReplacement) ([]byte, bool) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return src, false
+	}
+	out := src
+	changed := false
+	// Edit from the last import up so earlier offsets stay valid
+	for i := len(f.Imports) - 1; i >= 0; i-- {
+		spec := f.Imports[i]
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		for _, pr := range replacements {
+			rest, ok := strings.CutPrefix(p, pr.Path)
+			if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+				continue
+			}
+			start := fset.Position(spec.Path.Pos()).Offset
+			end := fset.Position(spec.Path.End()).Offset
+			out = append(append(append([]byte{}, out[:start]...), strconv.Quote(pr.With+rest)...), out[end:]...)
+			changed = true
+			break
+		}
+	}
+	return out, changed
+}
+
+// runGoChecks runs go build and go test for the whole module.
+func runGoChecks(dir string) error {
+	if err := runCommand(dir, "go", "build", "./..."); err != nil {
+		return err
+	}
+	return runCommand(dir, "go", "test", "./...")
+}
+
+// stillRequired returns the archived modules from plan and replacements
+// that go.mod still requires after the fix.
+func stillRequired(gomodPath string, plan []plannedUpgrade, replacements []plannedReplacement) ([]string, error) {
+	mods, err := ParseGoMod(gomodPath)
+	if err != nil {
+		return nil, err
+	}
+	required := make(map[string]bool)
+	for _, m := range mods {
+		required[m.Path] = true
+	}
+	seen := make(map[string]bool)
+	var remaining []string
+	check := func(p string) {
+		if required[p] && !seen[p] {
+			seen[p] = true
+			remaining = append(remaining, p)
+		}
+	}
+	for _, pu := range plan {
+		for _, d := range pu.Drops {
+			check(d)
+		}
+	}
+	for _, pr := range replacements {
+		check(pr.Path)
+	}
+	sort.Strings(remaining)
+	return remaining, nil
+}
+
+// gitStartBranch refuses to proceed with uncommitted changes to go.mod,
+// go.sum, or Go source files, and creates and switches to a new branch.
+// The returned rollback switches back to the original branch and deletes
+// the new one; it expects the fix's uncommitted edits to be reverted first.
+func gitStartBranch(dir, branch string) (rollback func(), err error) {
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--", "go.mod", "go.sum", "*.go").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w (is %s a git repository?)", err, dir)
+	}
+	if len(bytes.TrimSpace(out)) > 0 {
+		return nil, fmt.Errorf("go.mod, go.sum, or Go source files have uncommitted changes; commit or stash them first")
+	}
+	// A detached HEAD has no branch name; return to its commit instead
+	orig, err := exec.Command("git", "-C", dir, "symbolic-ref", "--short", "-q", "HEAD").Output()
+	if err != nil {
+		orig, err = exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+		if err != nil {
+			return nil, fmt.Errorf("git rev-parse HEAD: %w", err)
+		}
+	}
+	if err := runCommand(dir, "git", "checkout", "-b", branch); err != nil {
+		return nil, err
+	}
+	origRef := strings.TrimSpace(string(orig))
+	return func() {
+		if err := runCommand(dir, "git", "checkout", "-q", origRef); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: could not switch back to %s: %v\n", origRef, err)
+			return
+		}
+		if err := runCommand(dir, "git", "branch", "-D", branch); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: could not delete branch %s: %v\n", branch, err)
+		}
+	}, nil
+}
+
+// gitCommit commits files, relative to dir, with message as the subject.
+func gitCommit(dir, message string, files []string) error {
+	if err := runCommand(dir, "git", append([]string{"add", "--"}, files...)...); err != nil {
+		return err
+	}
+	return runCommand(dir, "git", "commit", "-m", message)
+}
+
+// gitPushAndOpenPR pushes the branch and opens a pull request with gh.
+// Returns the pull request URL.
+func gitPushAndOpenPR(dir, branch, title, body string) (string, error) {
+	if err := runCommand(dir, "git", "push", "-u", "origin", branch); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("gh", "pr", "create", "--head", branch, "--title", title, "--body-file", "-")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(body)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("gh pr create: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// runCommand runs name with args in dir, including its combined output in
+// the error on failure.
+func runCommand(dir, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s: %v\n%s", name, strings.Join(args, " "), err, out)
+	}
+	return nil
+}
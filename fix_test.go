@@ -0,0 +1,278 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fixTestFindings() []UpgradeFinding {
+	return []UpgradeFinding{
+		{Module: "github.com/x/old", Version: "v0.1.0", Via: []UpgradeVia{
+			{Path: "github.com/a/fixable", Version: "v1.0.0", Latest: "v1.1.0", Drops: true},
+			{Path: "github.com/b/stuck", Version: "v1.0.0", Latest: "v2.0.0"},
+		}},
+		{Module: "github.com/x/older", Version: "v0.3.0", Via: []UpgradeVia{
+			{Path: "github.com/a/fixable", Version: "v1.0.0", Latest: "v1.1.0", Drops: true},
+		}},
+		{Module: "github.com/y/dead", Version: "v0.2.0", Via: []UpgradeVia{
+			{Path: "github.com/b/stuck", Version: "v1.0.0", Latest: "v2.0.0"},
+		}},
+	}
+}
+
+func TestPlanUpgrades(t *testing.T) {
+	plan := planUpgrades(fixTestFindings(), nil)
+	if len(plan) != 1 {
+		t.Fatalf("len(plan) = %d, want 1: %+v", len(plan), plan)
+	}
+	pu := plan[0]
+	if pu.Path != "github.com/a/fixable" || pu.From != "v1.0.0" || pu.To != "v1.1.0" {
+		t.Errorf("plan[0] = %+v", pu)
+	}
+	if strings.Join(pu.Drops, ",") != "github.com/x/old,github.com/x/older" {
+		t.Errorf("Drops = %v, want both archived modules grouped", pu.Drops)
+	}
+}
+
+func TestPlanUpgrades_Selected(t *testing.T) {
+	plan := planUpgrades(fixTestFindings(), parseModuleList("github.com/x/older"))
+	if len(plan) != 1 || len(plan[0].Drops) != 1 || plan[0].Drops[0] != "github.com/x/older" {
+		t.Errorf("plan = %+v, want only x/older", plan)
+	}
+
+	plan = planUpgrades(fixTestFindings(), parseModuleList("github.com/y/dead"))
+	if len(plan) != 0 {
+		t.Errorf("unavoidable module should yield no plan, got %+v", plan)
+	}
+}
+
+func TestParseModuleList(t *testing.T) {
+	if got := parseModuleList(""); got != nil {
+		t.Errorf("parseModuleList(\"\") = %v, want nil", got)
+	}
+	got := parseModuleList(" github.com/a/b , ,github.com/c/d")
+	if len(got) != 2 || !got["github.com/a/b"] || !got["github.com/c/d"] {
+		t.Errorf("parseModuleList() = %v", got)
+	}
+}
+
+func TestPlanReplacements(t *testing.T) {
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/boltdb/bolt", Version: "v1.3.1", Direct: true}, IsArchived: true},
+		{Module: Module{Path: "github.com/pkg/errors", Version: "v0.9.1", Direct: true}, IsArchived: true},
+		{Module: Module{Path: "github.com/ghodss/yaml", Version: "v1.0.0"}, IsArchived: true},
+		{Module: Module{Path: "github.com/x/old", Version: "v0.1.0", Direct: true}, IsArchived: true},
+		{Module: Module{Path: "github.com/x/dep", Version: "v0.2.0", Direct: true}, IsArchived: true},
+		{Module: Module{Path: "github.com/satori/go.uuid", Version: "v1.2.0", Direct: true}},
+	}
+	allModules := []Module{{Path: "github.com/x/dep", Deprecated: "use example.com/dep/v2 instead"}}
+	plan := []plannedUpgrade{{Path: "github.com/a/fixable", Drops: []string{"github.com/x/old"}}}
+
+	got := planReplacements(results, allModules, plan, nil)
+	// pkg/errors' successor is the standard library, ghodss/yaml is
+	// indirect, x/old is dropped by the upgrade, and satori is not archived
+	want := []plannedReplacement{
+		{Path: "github.com/boltdb/bolt", With: "go.etcd.io/bbolt", Source: "known successor"},
+		{Path: "github.com/x/dep", With: "example.com/dep/v2", Source: "deprecation notice"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("planReplacements() = %+v, want %+v", got, want)
+	}
+
+	got = planReplacements(results, allModules, plan, parseModuleList("github.com/x/dep"))
+	if len(got) != 1 || got[0].Path != "github.com/x/dep" {
+		t.Errorf("selected planReplacements() = %+v, want only x/dep", got)
+	}
+}
+
+func TestRewriteImports(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	bolt "github.com/boltdb/bolt"
+	"github.com/boltdb/boltdb/other"
+	"github.com/golang/mock/gomock"
+)
+
+func main() { fmt.Println(bolt.Open, other.X, gomock.Any) }
+`,
+		"vendor/github.com/boltdb/bolt/db.go": "package bolt\n\nimport _ \"github.com/boltdb/bolt\"\n",
+		"tools/go.mod":                        "module example.com/tools\n",
+		"tools/tools.go":                      "package tools\n\nimport _ \"github.com/boltdb/bolt\"\n",
+		"clean.go":                            "package main\n\nimport \"strings\"\n\nvar _ = strings.ToUpper\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replacements := []plannedReplacement{
+		{Path: "github.com/boltdb/bolt", With: "go.etcd.io/bbolt"},
+		{Path: "github.com/golang/mock", With: "go.uber.org/mock"},
+	}
+	rewritten, restore, err := rewriteImports(dir, replacements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewritten) != 1 || rewritten[0] != "main.go" {
+		t.Fatalf("rewritten = %v, want [main.go]", rewritten)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{`bolt "go.etcd.io/bbolt"`, `"github.com/boltdb/boltdb/other"`, `"go.uber.org/mock/gomock"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("main.go missing %s:\n%s", want, got)
+		}
+	}
+	for _, name := range []string{"vendor/github.com/boltdb/bolt/db.go", "tools/tools.go"} {
+		if data, _ := os.ReadFile(filepath.Join(dir, name)); string(data) != files[name] {
+			t.Errorf("%s was rewritten:\n%s", name, data)
+		}
+	}
+
+	restore()
+	if data, _ := os.ReadFile(filepath.Join(dir, "main.go")); string(data) != files["main.go"] {
+		t.Errorf("restore did not put main.go back:\n%s", data)
+	}
+}
+
+func TestFixPRBody(t *testing.T) {
+	plan := planUpgrades(fixTestFindings(), nil)
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/x/old", Version: "v0.1.0", Owner: "x", Repo: "old"}, IsArchived: true},
+		{Module: Module{Path: "github.com/boltdb/bolt", Version: "v1.3.1", Direct: true, Owner: "boltdb", Repo: "bolt"}, IsArchived: true},
+		{Module: Module{Path: "github.com/a/fixable", Version: "v1.0.0", Direct: true, Owner: "a", Repo: "fixable"}},
+	}
+	replacements := []plannedReplacement{{Path: "github.com/boltdb/bolt", With: "go.etcd.io/bbolt", Source: "known successor"}}
+	body := fixPRBody(defaultTestConfig(), results, plan, replacements, []string{"github.com/x/old"}, true)
+
+	for _, want := range []string{
+		"## Archived dependencies (2 of 3 github.com modules)",
+		"| github.com/x/old | v0.1.0 | indirect |",
+		"| github.com/boltdb/bolt | v1.3.1 | direct |",
+		"| Archived | Replaced with | Source |",
+		"| github.com/boltdb/bolt | go.etcd.io/bbolt | known successor |",
+		"| Module | From | To | Drops archived |",
+		"| github.com/a/fixable | v1.0.0 | v1.1.0 | github.com/x/old, github.com/x/older |",
+		"Still required through other dependencies: github.com/x/old",
+		"`go test ./...` passed",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+
+	if body := fixPRBody(defaultTestConfig(), results, plan, nil, nil, false); !strings.Contains(body, "were not run") {
+		t.Errorf("unchecked body should say checks were not run:\n%s", body)
+	}
+}
+
+func TestStillRequired(t *testing.T) {
+	dir := t.TempDir()
+	gomodPath := filepath.Join(dir, "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n\nrequire (\n\tgithub.com/a/fixable v1.1.0\n\tgithub.com/boltdb/bolt v1.3.1\n\tgithub.com/x/older v0.3.0 // indirect\n)\n"
+	if err := os.WriteFile(gomodPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	replacements := []plannedReplacement{
+		{Path: "github.com/boltdb/bolt", With: "go.etcd.io/bbolt"},
+		{Path: "github.com/golang/mock", With: "go.uber.org/mock"},
+	}
+	remaining, err := stillRequired(gomodPath, planUpgrades(fixTestFindings(), nil), replacements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(remaining, ",") != "github.com/boltdb/bolt,github.com/x/older" {
+		t.Errorf("remaining = %v, want [github.com/boltdb/bolt github.com/x/older]", remaining)
+	}
+}
+
+func TestGitStartBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "go.mod")
+	git("commit", "-q", "-m", "init")
+
+	// Uncommitted go.mod changes are refused
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitStartBranch(dir, "modrot/fix"); err == nil {
+		t.Error("expected error for dirty go.mod")
+	}
+	git("checkout", "--", "go.mod")
+
+	// So are untracked Go files a fix could rewrite
+	if err := os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitStartBranch(dir, "modrot/fix"); err == nil {
+		t.Error("expected error for untracked Go file")
+	}
+	if err := os.Remove(filepath.Join(dir, "new.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	currentBranch := func() string {
+		t.Helper()
+		out, err := exec.Command("git", "-C", dir, "branch", "--show-current").Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	orig := currentBranch()
+	rollback, err := gitStartBranch(dir, "modrot/fix")
+	if err != nil {
+		t.Fatalf("gitStartBranch: %v", err)
+	}
+	if got := currentBranch(); got != "modrot/fix" {
+		t.Errorf("current branch = %q, want modrot/fix", got)
+	}
+
+	// A commit on the new branch is undone by switching back
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-q", "-am", "fix")
+	rollback()
+	if got := currentBranch(); got != orig {
+		t.Errorf("current branch after rollback = %q, want %q", got, orig)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "go.mod")); string(data) != "module example.com/app\n" {
+		t.Errorf("go.mod after rollback = %q", data)
+	}
+	if out, _ := exec.Command("git", "-C", dir, "branch", "--list", "modrot/fix").Output(); len(out) > 0 {
+		t.Errorf("branch modrot/fix still exists after rollback")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"time"
 )
@@ -19,11 +21,95 @@ type RepoStatus struct {
 	PushedAt   time.Time
 	NotFound   bool
 	Error      string
+
+	// Unknown results could not be checked (a GraphQL timeout, rate limit,
+	// or server error for the repo, or no response covering it); Error says
+	// why. They count as neither archived nor active.
+	Unknown bool
+
+	// Disabled repos exist but cannot be fetched: GitHub suspended them, or
+	// blocked access after a takedown notice (DisabledReason says which).
+	Disabled       bool
+	DisabledReason string
+
+	// ArchivedAtSource says where ArchivedAt came from (archivedAtGitHub,
+	// or archivedAtUnknown when GitHub has no date and ArchivedAt is zero);
+	// set for archived repos only.
+	ArchivedAtSource string
+
+	// Repository metadata, fetched only when --policy rules need it.
+	Topics     []string
+	Properties map[string]string // custom property name → value
+
+	// Release and advisory data, fetched only for archived repos with --advisories.
+	LastRelease    time.Time
+	LastReleaseTag string
+	Advisories     []Advisory // OSV advisories published after the last release
+
+	// Retirement, checked only for archived modules with --deprecated.
+	Retired          bool   // latest go.mod retracts every published version
+	RetiredRationale string // rationale of the retraction covering the latest version
+
+	// Not-found triage (triageRenamed, triageProxyCached, triageNeverFound)
+	// and, for renamed repos, the new module path.
+	Triage    string
+	RenamedTo string
 }
 
-// getGHToken retrieves the GitHub auth token via `gh auth token`.
+// tokenEnv, when set (--token-env or token_env in .modrot.yaml), names the
+// environment variable getGHToken reads instead of running `gh auth token`.
+var tokenEnv string
+
+// Sources of RepoStatus.ArchivedAt, reported as archived_at_source in JSON.
+const (
+	archivedAtGitHub  = "github"  // GitHub's archivedAt timestamp
+	archivedAtUnknown = "unknown" // GitHub has no archivedAt for the repo
+)
+
+// archivedAtProvenance returns the source and precision of an archived
+// repo's ArchivedAt for JSON output: precision is "second" for GitHub's
+// timestamp and empty when the date is unknown. Both are empty for repos
+// that are not archived.
+func archivedAtProvenance(r RepoStatus) (source, precision string) {
+	if !r.IsArchived {
+		return "", ""
+	}
+	source = r.ArchivedAtSource
+	if source == "" {
+		source = archivedAtGitHub
+		if r.ArchivedAt.IsZero() {
+			source = archivedAtUnknown
+		}
+	}
+	if source == archivedAtGitHub {
+		precision = "second"
+	}
+	return source, precision
+}
+
+// fmtArchivedAt formats an archived repo's ArchivedAt for tables. When
+// GitHub has no archive date, the last push is shown with a leading "~"
+// instead: the repo was archived on or after it.
+func fmtArchivedAt(cfg *Config, r RepoStatus) string {
+	if r.ArchivedAt.IsZero() && r.IsArchived {
+		if d := fmtDate(cfg, r.PushedAt); d != "" {
+			return "~" + d
+		}
+	}
+	return fmtDate(cfg, r.ArchivedAt)
+}
+
+// getGHToken retrieves the GitHub auth token via `gh auth token`, or from
+// the tokenEnv environment variable when one is configured.
 func getGHToken() (string, error) {
-	cmd := exec.Command("gh", "auth", "token")
+	if tokenEnv != "" {
+		token := strings.TrimSpace(os.Getenv(tokenEnv))
+		if token == "" {
+			return "", fmt.Errorf("GitHub token environment variable %s is not set", tokenEnv)
+		}
+		return token, nil
+	}
+	cmd := exec.CommandContext(runCtx, "gh", "auth", "token")
 	out, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get GitHub token (is gh installed and authenticated?): %w", err)
@@ -36,23 +122,32 @@ type graphQLRequest struct {
 	Query string `json:"query"`
 }
 
-// ghClient holds an HTTP client and configurable GraphQL URL for GitHub API queries.
+// ghClient holds an HTTP client and configurable API URLs for GitHub queries.
 type ghClient struct {
 	client     *http.Client
 	graphqlURL string
+	restURL    string // REST API base, for data GraphQL does not expose
 }
 
-// newGHClient creates a ghClient with production defaults.
+// newGHClient creates a ghClient for the endpoints in effect.
 func newGHClient() *ghClient {
 	return &ghClient{
-		client:     &http.Client{Timeout: 2 * time.Minute},
-		graphqlURL: "https://api.github.com/graphql",
+		client:     &http.Client{Timeout: 2 * time.Minute, Transport: countingTransport{}},
+		graphqlURL: endpoints.GitHubGraphQL,
+		restURL:    endpoints.GitHubREST,
 	}
 }
 
 // CheckRepos queries GitHub for the archived status of the given modules.
 // Modules are batched into groups of batchSize per GraphQL request.
 func CheckRepos(modules []Module, batchSize int) ([]RepoStatus, error) {
+	return CheckReposUntil(modules, batchSize, nil)
+}
+
+// CheckReposUntil is CheckRepos that stops after the first batch in which
+// stop reports true for a result (--fail-fast). The results then cover
+// only the modules queried so far. A nil stop checks every module.
+func CheckReposUntil(modules []Module, batchSize int, stop func(RepoStatus) bool) ([]RepoStatus, error) {
 	if len(modules) == 0 {
 		return nil, nil
 	}
@@ -62,12 +157,18 @@ func CheckRepos(modules []Module, batchSize int) ([]RepoStatus, error) {
 		return nil, err
 	}
 
-	return checkReposWithClient(modules, batchSize, token, newGHClient())
+	return checkReposWithClientUntil(modules, batchSize, token, newGHClient(), stop)
 }
 
 // checkReposWithClient is the internal implementation that accepts a ghClient,
 // allowing tests to inject mock HTTP servers.
 func checkReposWithClient(modules []Module, batchSize int, token string, gc *ghClient) ([]RepoStatus, error) {
+	return checkReposWithClientUntil(modules, batchSize, token, gc, nil)
+}
+
+// checkReposWithClientUntil is checkReposWithClient with CheckReposUntil's
+// stop condition.
+func checkReposWithClientUntil(modules []Module, batchSize int, token string, gc *ghClient, stop func(RepoStatus) bool) ([]RepoStatus, error) {
 	var results []RepoStatus
 	for i := 0; i < len(modules); i += batchSize {
 		end := i + batchSize
@@ -81,6 +182,9 @@ func checkReposWithClient(modules []Module, batchSize int, token string, gc *ghC
 			return nil, fmt.Errorf("querying batch starting at index %d: %w", i, err)
 		}
 		results = append(results, statuses...)
+		if stop != nil && slices.ContainsFunc(statuses, stop) {
+			break
+		}
 	}
 	return results, nil
 }
@@ -92,6 +196,7 @@ func buildGraphQLQuery(modules []Module) string {
 	for i, m := range modules {
 		fmt.Fprintf(&qb, "  r%d: repository(owner: %q, name: %q) {\n", i, m.Owner, m.Repo)
 		qb.WriteString("    isArchived\n")
+		qb.WriteString("    isDisabled\n")
 		qb.WriteString("    archivedAt\n")
 		qb.WriteString("    pushedAt\n")
 		qb.WriteString("  }\n")
@@ -103,18 +208,25 @@ func buildGraphQLQuery(modules []Module) string {
 // gqlResponse represents the GitHub GraphQL API response.
 type gqlResponse struct {
 	Data   map[string]*repoData `json:"data"`
-	Errors []struct {
-		Message string   `json:"message"`
-		Path    []string `json:"path"`
-	} `json:"errors"`
+	Errors []gqlError           `json:"errors"`
+}
+
+// gqlError is one entry of a GraphQL response's errors. Path names the
+// repository alias it is about; Type is GitHub's error class, such as
+// NOT_FOUND.
+type gqlError struct {
+	Type    string   `json:"type"`
+	Message string   `json:"message"`
+	Path    []string `json:"path"`
 }
 
 // parseGraphQLResponse converts a parsed GraphQL response into RepoStatus results.
 func parseGraphQLResponse(gqlResp gqlResponse, modules []Module) []RepoStatus {
-	errorAliases := make(map[string]string)
+	type aliasError struct{ errType, msg string }
+	errorAliases := make(map[string]aliasError)
 	for _, e := range gqlResp.Errors {
 		if len(e.Path) > 0 {
-			errorAliases[e.Path[0]] = e.Message
+			errorAliases[e.Path[0]] = aliasError{e.Type, e.Message}
 		}
 	}
 
@@ -123,11 +235,21 @@ func parseGraphQLResponse(gqlResp gqlResponse, modules []Module) []RepoStatus {
 		alias := fmt.Sprintf("r%d", i)
 		rs := RepoStatus{Module: m}
 
-		if errMsg, ok := errorAliases[alias]; ok {
-			rs.NotFound = true
-			rs.Error = errMsg
+		if e, ok := errorAliases[alias]; ok {
+			switch {
+			case isTakedownError(e.msg):
+				rs.Disabled, rs.DisabledReason = true, disabledTakedown
+			case isNotFoundError(e.errType, e.msg):
+				rs.NotFound = true
+			default:
+				rs.Unknown = true
+			}
+			rs.Error = e.msg
 		} else if rd, ok := gqlResp.Data[alias]; ok && rd != nil {
 			rs.IsArchived = rd.IsArchived
+			if rd.IsDisabled {
+				rs.Disabled, rs.DisabledReason = true, disabledByGitHub
+			}
 			// Parse errors are intentionally ignored — malformed timestamps
 			// from GitHub are extremely rare, and zero time is safe downstream
 			// (checked via .IsZero() before display or comparison).
@@ -137,6 +259,16 @@ func parseGraphQLResponse(gqlResp gqlResponse, modules []Module) []RepoStatus {
 			if rd.PushedAt != "" {
 				rs.PushedAt, _ = time.Parse(time.RFC3339, rd.PushedAt)
 			}
+			// GitHub lacks archivedAt for some long-archived repos. ArchivedAt
+			// stays zero for them rather than borrowing the last push, which
+			// is only a lower bound; tables show that push marked with "~".
+			switch {
+			case !rs.IsArchived:
+			case !rs.ArchivedAt.IsZero():
+				rs.ArchivedAtSource = archivedAtGitHub
+			default:
+				rs.ArchivedAtSource = archivedAtUnknown
+			}
 		} else {
 			rs.NotFound = true
 			rs.Error = "repository not found"
@@ -148,13 +280,25 @@ func parseGraphQLResponse(gqlResp gqlResponse, modules []Module) []RepoStatus {
 }
 
 func (g *ghClient) queryBatch(token string, modules []Module) ([]RepoStatus, error) {
-	query := buildGraphQLQuery(modules)
+	body, err := g.post(token, buildGraphQLQuery(modules))
+	if err != nil {
+		return nil, err
+	}
+
+	var gqlResp gqlResponse
+	if err := json.Unmarshal(body, &gqlResp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	return parseGraphQLResponse(gqlResp, modules), nil
+}
 
+// post sends a GraphQL query and returns the raw response body.
+func (g *ghClient) post(token, query string) ([]byte, error) {
 	reqBody, err := json.Marshal(graphQLRequest{Query: query})
 	if err != nil {
 		return nil, err
 	}
-
 	req, err := http.NewRequest("POST", g.graphqlURL, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, err
@@ -172,21 +316,15 @@ func (g *ghClient) queryBatch(token string, modules []Module) ([]RepoStatus, err
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
-
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("GitHub API returned %d: %s", resp.StatusCode, string(body))
 	}
-
-	var gqlResp gqlResponse
-	if err := json.Unmarshal(body, &gqlResp); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
-	}
-
-	return parseGraphQLResponse(gqlResp, modules), nil
+	return body, nil
 }
 
 type repoData struct {
 	IsArchived bool   `json:"isArchived"`
+	IsDisabled bool   `json:"isDisabled"`
 	ArchivedAt string `json:"archivedAt"`
 	PushedAt   string `json:"pushedAt"`
 }
@@ -93,6 +93,87 @@ func TestParseGraphQLResponse_Archived(t *testing.T) {
 	}
 }
 
+func TestParseGraphQLResponse_ArchivedAtSource(t *testing.T) {
+	tests := []struct {
+		name         string
+		rd           repoData
+		wantSource   string
+		wantArchived time.Time
+	}{
+		{
+			name:         "github timestamp",
+			rd:           repoData{IsArchived: true, ArchivedAt: "2024-07-22T20:44:18Z", PushedAt: "2019-03-01T12:00:00Z"},
+			wantSource:   archivedAtGitHub,
+			wantArchived: time.Date(2024, 7, 22, 20, 44, 18, 0, time.UTC),
+		},
+		{
+			name:       "missing archivedAt is not borrowed from the last push",
+			rd:         repoData{IsArchived: true, PushedAt: "2019-03-01T12:00:00Z"},
+			wantSource: archivedAtUnknown,
+		},
+		{
+			name:       "no dates at all",
+			rd:         repoData{IsArchived: true},
+			wantSource: archivedAtUnknown,
+		},
+		{
+			name: "not archived",
+			rd:   repoData{PushedAt: "2019-03-01T12:00:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rd := tt.rd
+			resp := gqlResponse{Data: map[string]*repoData{"r0": &rd}}
+			r := parseGraphQLResponse(resp, []Module{{Path: "github.com/foo/bar", Owner: "foo", Repo: "bar"}})[0]
+			if r.ArchivedAtSource != tt.wantSource {
+				t.Errorf("ArchivedAtSource = %q, want %q", r.ArchivedAtSource, tt.wantSource)
+			}
+			if !r.ArchivedAt.Equal(tt.wantArchived) {
+				t.Errorf("ArchivedAt = %v, want %v", r.ArchivedAt, tt.wantArchived)
+			}
+		})
+	}
+}
+
+func TestArchivedAtProvenance(t *testing.T) {
+	at := time.Date(2024, 7, 22, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		r             RepoStatus
+		wantSource    string
+		wantPrecision string
+	}{
+		{"github", RepoStatus{IsArchived: true, ArchivedAt: at, ArchivedAtSource: archivedAtGitHub}, "github", "second"},
+		{"unknown", RepoStatus{IsArchived: true, ArchivedAtSource: archivedAtUnknown}, "unknown", ""},
+		{"unset with date (fixtures, older data)", RepoStatus{IsArchived: true, ArchivedAt: at}, "github", "second"},
+		{"unset without date", RepoStatus{IsArchived: true}, "unknown", ""},
+		{"not archived", RepoStatus{ArchivedAt: at}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, precision := archivedAtProvenance(tt.r)
+			if source != tt.wantSource || precision != tt.wantPrecision {
+				t.Errorf("archivedAtProvenance() = (%q, %q), want (%q, %q)", source, precision, tt.wantSource, tt.wantPrecision)
+			}
+		})
+	}
+}
+
+func TestFmtArchivedAt(t *testing.T) {
+	cfg := NewDefaultConfig()
+	at := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	if got := fmtArchivedAt(cfg, RepoStatus{IsArchived: true, PushedAt: at, ArchivedAtSource: archivedAtUnknown}); got != "~2019-03-01" {
+		t.Errorf("unknown with last push = %q, want ~2019-03-01", got)
+	}
+	if got := fmtArchivedAt(cfg, RepoStatus{IsArchived: true, ArchivedAt: at, ArchivedAtSource: archivedAtGitHub}); got != "2019-03-01" {
+		t.Errorf("github = %q, want 2019-03-01", got)
+	}
+	if got := fmtArchivedAt(cfg, RepoStatus{IsArchived: true, ArchivedAtSource: archivedAtUnknown}); got != "" {
+		t.Errorf("unknown = %q, want empty", got)
+	}
+}
+
 func TestParseGraphQLResponse_NotArchived(t *testing.T) {
 	modules := []Module{
 		{Path: "github.com/foo/bar", Owner: "foo", Repo: "bar"},
@@ -128,10 +209,7 @@ func TestParseGraphQLResponse_NotFound(t *testing.T) {
 			"r0": nil, // null in JSON
 			"r1": {IsArchived: false, PushedAt: "2025-01-01T00:00:00Z"},
 		},
-		Errors: []struct {
-			Message string   `json:"message"`
-			Path    []string `json:"path"`
-		}{
+		Errors: []gqlError{
 			{Message: "Could not resolve to a Repository", Path: []string{"r0"}},
 		},
 	}
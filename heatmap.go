@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// heatmapBarWidth is the widest bar PrintHeatmap draws; larger buckets are
+// scaled down proportionally.
+const heatmapBarWidth = 40
+
+// heatmapBucket counts the dependencies whose age falls into one column.
+type heatmapBucket struct {
+	Label    string
+	Level    int // age level for coloring (0 = newest); -1 for unknown ages
+	Total    int
+	Archived int
+}
+
+// heatmapRow buckets every dependency by one age basis, newest first. The
+// final bucket collects dependencies whose age is unknown.
+type heatmapRow struct {
+	Basis   string
+	Buckets []heatmapBucket
+}
+
+// buildHeatmap buckets GitHub results and non-GitHub modules by the age of
+// their last push and of their latest release, using cfg.Color.Thresholds as
+// bucket edges. Non-GitHub modules have no push date and count as unknown there.
+func buildHeatmap(cfg *Config, results []RepoStatus, nonGHModules []Module) []heatmapRow {
+	push := heatmapRow{Basis: "LAST PUSH", Buckets: heatmapBuckets(cfg.Color.Thresholds)}
+	release := heatmapRow{Basis: "LAST RELEASE", Buckets: heatmapBuckets(cfg.Color.Thresholds)}
+
+	for _, r := range results {
+		push.add(cfg, r.PushedAt, r.IsArchived)
+		release.add(cfg, lastRelease(r.Module), r.IsArchived)
+	}
+	for _, m := range nonGHModules {
+		push.add(cfg, time.Time{}, false)
+		release.add(cfg, lastRelease(m), false)
+	}
+	return []heatmapRow{push, release}
+}
+
+// heatmapBuckets returns empty buckets labeled from the thresholds:
+// "<3m", "3m-1y", ..., ">5y", then "unknown".
+func heatmapBuckets(thresholds []ColorThreshold) []heatmapBucket {
+	labels := make([]string, len(thresholds))
+	for i, th := range thresholds {
+		labels[i] = formatColorThreshold(th)
+	}
+	buckets := make([]heatmapBucket, 0, len(thresholds)+2)
+	for i := 0; i <= len(thresholds); i++ {
+		var label string
+		switch {
+		case i == 0:
+			label = "<" + labels[0]
+		case i == len(thresholds):
+			label = ">" + labels[i-1]
+		default:
+			label = labels[i-1] + "-" + labels[i]
+		}
+		buckets = append(buckets, heatmapBucket{Label: label, Level: i})
+	}
+	return append(buckets, heatmapBucket{Label: "unknown", Level: -1})
+}
+
+// add counts one dependency in the bucket its age falls into.
+func (row *heatmapRow) add(cfg *Config, t time.Time, archived bool) {
+	i := len(row.Buckets) - 1 // unknown
+	if level := classifyAge(cfg, t); level >= 0 {
+		i = level
+	}
+	row.Buckets[i].Total++
+	if archived {
+		row.Buckets[i].Archived++
+	}
+}
+
+// lastRelease returns the publish time of a module's latest version, falling
+// back to its current version when the latest is unknown.
+func lastRelease(m Module) time.Time {
+	if !m.LatestTime.IsZero() {
+		return m.LatestTime
+	}
+	return m.VersionTime
+}
+
+// formatColorThreshold formats a color threshold compactly (e.g. "1y6m").
+func formatColorThreshold(th ColorThreshold) string {
+	var s string
+	if th.Y > 0 {
+		s += fmt.Sprintf("%dy", th.Y)
+	}
+	if th.M > 0 {
+		s += fmt.Sprintf("%dm", th.M)
+	}
+	if th.D > 0 || s == "" {
+		s += fmt.Sprintf("%dd", th.D)
+	}
+	return s
+}
+
+// PrintHeatmap outputs the dependency age heatmap for --format heatmap: one
+// bar per age bucket, with archived modules drawn in their own shade.
+func PrintHeatmap(cfg *Config, results []RepoStatus, nonGHModules []Module) {
+	writeHeatmap(os.Stdout, cfg, buildHeatmap(cfg, results, nonGHModules), len(results)+len(nonGHModules), len(getArchivedPaths(results)))
+}
+
+// writeHeatmap renders heatmap rows to w.
+func writeHeatmap(w io.Writer, cfg *Config, rows []heatmapRow, total, archived int) {
+	_, _ = fmt.Fprintf(w, "DEPENDENCY AGE HEATMAP (%d %s, %d archived)\n",
+		total, pluralize(total, "module", "modules"), archived)
+
+	labelWidth, countWidth := 0, 1
+	for _, row := range rows {
+		for _, b := range row.Buckets {
+			labelWidth = max(labelWidth, len(b.Label))
+			countWidth = max(countWidth, len(fmt.Sprint(b.Total)))
+		}
+	}
+
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(w, "\n%s\n", row.Basis)
+		for _, b := range row.Buckets {
+			line := fmt.Sprintf("  %-*s  %*d", labelWidth, b.Label, countWidth, b.Total)
+			if bar := heatmapBar(cfg, b, total); bar != "" {
+				line += "  " + bar
+			}
+			if b.Archived > 0 {
+				line += fmt.Sprintf("  (%d archived)", b.Archived)
+			}
+			_, _ = fmt.Fprintln(w, line)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "\n█ active  ▓ archived\n")
+}
+
+// heatmapBar draws a bucket's bar scaled so the whole project fits in
+// heatmapBarWidth cells. Any non-zero count, and any archived module, gets
+// at least one cell so small buckets stay visible.
+func heatmapBar(cfg *Config, b heatmapBucket, total int) string {
+	if b.Total == 0 {
+		return ""
+	}
+	scale := 1.0
+	if total > heatmapBarWidth {
+		scale = float64(heatmapBarWidth) / float64(total)
+	}
+	archived := scaledCells(b.Archived, scale)
+	active := scaledCells(b.Total-b.Archived, scale)
+
+	bar := strings.Repeat("█", active)
+	if cfg.Color.Enabled && b.Level >= 0 && active > 0 {
+		color, _ := selectStyle(b.Level, len(cfg.Color.Thresholds)+1)
+		bar = color + bar + colorReset
+	}
+	if archived > 0 {
+		shade := strings.Repeat("▓", archived)
+		if cfg.Color.Enabled {
+			shade = colorBoldMagentaUL + shade + colorReset
+		}
+		bar += shade
+	}
+	return bar
+}
+
+// scaledCells returns how many bar cells n modules take at the given scale,
+// rounding to the nearest cell but never below one for a non-zero count.
+func scaledCells(n int, scale float64) int {
+	if n == 0 {
+		return 0
+	}
+	return max(1, int(float64(n)*scale+0.5))
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func heatmapTestConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg := NewDefaultConfig()
+	cfg.Now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	thresholds, err := parseColorThreshold(defaultColorThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Color.Thresholds = thresholds
+	return cfg
+}
+
+func TestHeatmapBuckets(t *testing.T) {
+	cfg := heatmapTestConfig(t)
+	var labels []string
+	for _, b := range heatmapBuckets(cfg.Color.Thresholds) {
+		labels = append(labels, b.Label)
+	}
+	want := "<3m 3m-1y 1y-2y 2y-5y >5y unknown"
+	if got := strings.Join(labels, " "); got != want {
+		t.Errorf("labels = %q, want %q", got, want)
+	}
+}
+
+func TestBuildHeatmap(t *testing.T) {
+	cfg := heatmapTestConfig(t)
+	ago := func(d time.Duration) time.Time { return cfg.Now.Add(-d) }
+	day := 24 * time.Hour
+
+	results := []RepoStatus{
+		{Module: Module{Path: "a", LatestTime: ago(10 * day)}, PushedAt: ago(5 * day)},
+		{Module: Module{Path: "b", VersionTime: ago(400 * day)}, PushedAt: ago(200 * day)},
+		{Module: Module{Path: "c", LatestTime: ago(3 * 365 * day)}, PushedAt: ago(3 * 365 * day), IsArchived: true},
+		{Module: Module{Path: "d"}, PushedAt: ago(7 * 365 * day), IsArchived: true},
+	}
+	nonGH := []Module{{Path: "e", LatestTime: ago(20 * day)}}
+
+	rows := buildHeatmap(cfg, results, nonGH)
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	counts := func(row heatmapRow) (total, archived []int) {
+		for _, b := range row.Buckets {
+			total = append(total, b.Total)
+			archived = append(archived, b.Archived)
+		}
+		return total, archived
+	}
+	tests := []struct {
+		row          heatmapRow
+		wantTotal    []int
+		wantArchived []int
+	}{
+		// Non-GitHub modules have no push date.
+		{rows[0], []int{1, 1, 0, 1, 1, 1}, []int{0, 0, 0, 1, 1, 0}},
+		// b falls back to its current version's publish time; d has none.
+		{rows[1], []int{2, 0, 1, 1, 0, 1}, []int{0, 0, 0, 1, 0, 1}},
+	}
+	for _, tt := range tests {
+		total, archived := counts(tt.row)
+		if !slices.Equal(total, tt.wantTotal) {
+			t.Errorf("%s totals = %v, want %v", tt.row.Basis, total, tt.wantTotal)
+		}
+		if !slices.Equal(archived, tt.wantArchived) {
+			t.Errorf("%s archived = %v, want %v", tt.row.Basis, archived, tt.wantArchived)
+		}
+	}
+}
+
+func TestHeatmapBar(t *testing.T) {
+	cfg := heatmapTestConfig(t)
+	tests := []struct {
+		name  string
+		b     heatmapBucket
+		total int
+		want  string
+	}{
+		{"empty", heatmapBucket{}, 10, ""},
+		{"unscaled", heatmapBucket{Total: 3, Archived: 1}, 10, "██▓"},
+		{"scaled down", heatmapBucket{Total: 40, Archived: 0}, 80, strings.Repeat("█", 20)},
+		{"archived stays visible", heatmapBucket{Total: 50, Archived: 1}, 200, strings.Repeat("█", 10) + "▓"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := heatmapBar(cfg, tt.b, tt.total); got != tt.want {
+				t.Errorf("heatmapBar = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteHeatmap(t *testing.T) {
+	cfg := heatmapTestConfig(t)
+	results := []RepoStatus{
+		{Module: Module{Path: "a", LatestTime: cfg.Now.AddDate(0, -1, 0)}, PushedAt: cfg.Now.AddDate(0, -1, 0)},
+		{Module: Module{Path: "b", LatestTime: cfg.Now.AddDate(-6, 0, 0)}, PushedAt: cfg.Now.AddDate(-6, 0, 0), IsArchived: true},
+	}
+
+	var buf bytes.Buffer
+	writeHeatmap(&buf, cfg, buildHeatmap(cfg, results, nil), 2, 1)
+	got := buf.String()
+
+	for _, want := range []string{
+		"DEPENDENCY AGE HEATMAP (2 modules, 1 archived)",
+		"LAST PUSH\n  <3m      1  █\n",
+		"  >5y      1  ▓  (1 archived)\n",
+		"  unknown  0\n",
+		"█ active  ▓ archived",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
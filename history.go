@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// defaultHistoryFile is the history file used by `modrot history` when no
+// path is given.
+const defaultHistoryFile = ".modrot-history.json"
+
+// historyVersion is the current history file format version.
+const historyVersion = 1
+
+// History is the on-disk archive timeline written by --history. Each entry
+// is one episode: a module observed archived in a project from FirstSeen
+// until it was checked and found unarchived or was no longer required
+// (FixedAt). A module that reappears
+// after being fixed starts a new episode.
+type History struct {
+	Version  int            `json:"version"`
+	Episodes []HistoryEntry `json:"episodes"`
+}
+
+// HistoryEntry is one archived-dependency episode.
+type HistoryEntry struct {
+	Project    string     `json:"project"`
+	Team       string     `json:"team,omitempty"`
+	Module     string     `json:"module"`
+	ArchivedAt time.Time  `json:"archived_at,omitzero"` // upstream archive date from GitHub
+	FirstSeen  time.Time  `json:"first_seen"`
+	FixedAt    *time.Time `json:"fixed_at,omitempty"`
+}
+
+// archiveObservation is one archived module seen in one project during a run.
+type archiveObservation struct {
+	Project    string
+	Module     string
+	ArchivedAt time.Time
+}
+
+// loadHistory reads a history file. A missing file yields an empty history.
+func loadHistory(path string) (*History, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &History{Version: historyVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var h History
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if h.Version > historyVersion {
+		return nil, fmt.Errorf("%s has version %d; this modrot supports up to %d", path, h.Version, historyVersion)
+	}
+	return &h, nil
+}
+
+// saveHistory writes a history file atomically.
+func saveHistory(path string, h *History) error {
+	h.Version = historyVersion
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".modrot-history-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// projectScan is what one run learned about a project for closing history
+// episodes: the modules its go.mod requires, and the GitHub results for the
+// modules that were actually queried (--filter and --direct-only leave some
+// out), before ignore lists or the vendored-forked split.
+type projectScan struct {
+	Project  string
+	Required map[string]bool
+	Checked  map[string]RepoStatus
+}
+
+// remediated reports whether an open episode for module is fixed according
+// to this scan: the module was queried and came back not archived, or the
+// project no longer requires it at all. A module that is still required but
+// was not queried, or whose check failed (Unknown), tells us nothing, so
+// its episode stays open.
+func (ps projectScan) remediated(module string) bool {
+	if r, ok := ps.Checked[module]; ok {
+		return !r.IsArchived && !r.Unknown && !r.NotFound && !r.Disabled
+	}
+	return !ps.Required[module]
+}
+
+// Record applies one run's observations for team across the scanned
+// projects: newly archived modules open an episode, and open episodes of
+// those projects are closed as fixed when their scan shows the module
+// remediated.
+func (h *History) Record(team string, scans []projectScan, obs []archiveObservation, now time.Time) (opened, fixed int) {
+	key := func(project, module string) string { return project + "\x00" + module }
+
+	scanned := make(map[string]projectScan)
+	for _, ps := range scans {
+		scanned[ps.Project] = ps
+	}
+	seen := make(map[string]bool)
+	for _, o := range obs {
+		seen[key(o.Project, o.Module)] = true
+	}
+
+	open := make(map[string]bool)
+	for i := range h.Episodes {
+		e := &h.Episodes[i]
+		if e.FixedAt != nil || e.Team != team {
+			continue
+		}
+		ps, ok := scanned[e.Project]
+		if !ok {
+			continue
+		}
+		if seen[key(e.Project, e.Module)] || !ps.remediated(e.Module) {
+			open[key(e.Project, e.Module)] = true
+			continue
+		}
+		t := now
+		e.FixedAt = &t
+		fixed++
+	}
+
+	for _, o := range obs {
+		if open[key(o.Project, o.Module)] {
+			continue
+		}
+		open[key(o.Project, o.Module)] = true
+		h.Episodes = append(h.Episodes, HistoryEntry{
+			Project:    o.Project,
+			Team:       team,
+			Module:     o.Module,
+			ArchivedAt: o.ArchivedAt,
+			FirstSeen:  now,
+		})
+		opened++
+	}
+	return opened, fixed
+}
+
+// observe records what a project's run saw for --history: the modules its
+// go.mod requires and the unfiltered results of the GitHub query. The
+// project is named as in add.
+func (s *runSummary) observe(project, fallback string, required []Module, checked []RepoStatus) {
+	if project == "" {
+		project = fallback
+	}
+	ps := projectScan{
+		Project:  project,
+		Required: make(map[string]bool, len(required)),
+		Checked:  make(map[string]RepoStatus, len(checked)),
+	}
+	for _, m := range required {
+		ps.Required[m.Path] = true
+	}
+	for _, r := range checked {
+		ps.Checked[r.Module.Path] = r
+	}
+	s.scans = append(s.scans, ps)
+}
+
+// archivedObservations returns the archived modules in each project of the
+// run summary.
+func (s *runSummary) archivedObservations() []archiveObservation {
+	var obs []archiveObservation
+	for i, project := range s.projects {
+		for _, r := range s.results[i] {
+			if r.IsArchived {
+				obs = append(obs, archiveObservation{Project: project, Module: r.Module.Path, ArchivedAt: r.ArchivedAt})
+			}
+		}
+	}
+	return obs
+}
+
+// recordHistory updates the --history file with this run's observations.
+// Failures are reported as degradations: the scan itself still succeeded.
+func recordHistory(cfg *Config) {
+	if cfg.History == "" || len(cfg.Summary.scans) == 0 {
+		return
+	}
+	h, err := loadHistory(cfg.History)
+	if err != nil {
+		warnDegraded(cfg, "history", "could not read history: %v", err)
+		return
+	}
+	opened, fixed := h.Record(cfg.Team, cfg.Summary.scans, cfg.Summary.archivedObservations(), cfg.Now)
+	if err := saveHistory(cfg.History, h); err != nil {
+		warnDegraded(cfg, "history", "could not write history: %v", err)
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "History: %d newly archived, %d fixed (%s).\n", opened, fixed, cfg.History)
+}
+
+// teamMTTR summarizes remediation for one team.
+type teamMTTR struct {
+	Team  string        `json:"team"`
+	Fixed int           `json:"fixed"`
+	Open  int           `json:"open"`
+	Mean  time.Duration `json:"-"`
+	Days  float64       `json:"mttr_days"`
+}
+
+// mttrByTeam computes mean time to remediation (FirstSeen → FixedAt) per
+// team, sorted by team name.
+func mttrByTeam(h *History) []teamMTTR {
+	byTeam := make(map[string]*teamMTTR)
+	var total = make(map[string]time.Duration)
+	for _, e := range h.Episodes {
+		t, ok := byTeam[e.Team]
+		if !ok {
+			t = &teamMTTR{Team: e.Team}
+			byTeam[e.Team] = t
+		}
+		if e.FixedAt == nil {
+			t.Open++
+			continue
+		}
+		t.Fixed++
+		total[e.Team] += e.FixedAt.Sub(e.FirstSeen)
+	}
+
+	out := make([]teamMTTR, 0, len(byTeam))
+	for team, t := range byTeam {
+		if t.Fixed > 0 {
+			t.Mean = total[team] / time.Duration(t.Fixed)
+			t.Days = t.Mean.Hours() / 24
+		}
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Team < out[j].Team
+	})
+	return out
+}
+
+// runHistory implements `modrot history [--json] [file]`: prints the
+// archive timeline and mean time to remediation per team.
+// Returns exit code: 0 = success, 2 = error.
+func runHistory(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "Output as JSON")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot history [--json] [file]
+
+Show the archive timeline recorded by --history and the mean time to
+remediation per team (default file: %s).
+
+  --json  Output as JSON
+`, defaultHistoryFile)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	path := defaultHistoryFile
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+	if _, err := os.Stat(path); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	h, err := loadHistory(path)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if *jsonOut {
+		writeJSON(struct {
+			Episodes []HistoryEntry `json:"episodes"`
+			MTTR     []teamMTTR     `json:"mttr"`
+		}{h.Episodes, mttrByTeam(h)})
+		return 0
+	}
+	printHistory(h, time.Now())
+	return 0
+}
+
+// printHistory outputs the timeline and MTTR tables.
+func printHistory(h *History, now time.Time) {
+	episodes := append([]HistoryEntry(nil), h.Episodes...)
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodes[i].FirstSeen.Before(episodes[j].FirstSeen)
+	})
+
+	_, _ = fmt.Fprintf(os.Stderr, "\nARCHIVE TIMELINE (%d %s)\n\n", len(episodes), pluralize(len(episodes), "episode", "episodes"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, []string{"PROJECT", "TEAM", "MODULE", "ARCHIVED AT", "FIRST SEEN", "FIXED", "DAYS OPEN"})
+	for _, e := range episodes {
+		archived, fixedAt, end := "-", "open", now
+		if !e.ArchivedAt.IsZero() {
+			archived = e.ArchivedAt.Format("2006-01-02")
+		}
+		if e.FixedAt != nil {
+			fixedAt = e.FixedAt.Format("2006-01-02")
+			end = *e.FixedAt
+		}
+		days := int(end.Sub(e.FirstSeen).Hours() / 24)
+		writeTabRow(w, []string{e.Project, teamLabel(e.Team), e.Module, archived,
+			e.FirstSeen.Format("2006-01-02"), fixedAt, strconv.Itoa(days)})
+	}
+	_ = w.Flush()
+
+	_, _ = fmt.Fprintf(os.Stderr, "\nMEAN TIME TO REMEDIATION\n\n")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, []string{"TEAM", "FIXED", "OPEN", "MTTR"})
+	for _, t := range mttrByTeam(h) {
+		mttr := "-"
+		if t.Fixed > 0 {
+			mttr = fmt.Sprintf("%.1fd", t.Days)
+		}
+		writeTabRow(w, []string{teamLabel(t.Team), strconv.Itoa(t.Fixed), strconv.Itoa(t.Open), mttr})
+	}
+	_ = w.Flush()
+}
+
+// teamLabel returns the display name for a team; episodes recorded without
+// --team are grouped under "(none)".
+func teamLabel(team string) string {
+	if team == "" {
+		return "(none)"
+	}
+	return team
+}
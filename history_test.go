@@ -0,0 +1,201 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// scanOf returns a single-project scan whose go.mod requires and checked
+// exactly results.
+func scanOf(project string, results ...RepoStatus) []projectScan {
+	var s runSummary
+	required := make([]Module, len(results))
+	for i, r := range results {
+		required[i] = r.Module
+	}
+	s.observe(project, "", required, results)
+	return s.scans
+}
+
+func TestHistoryRecord(t *testing.T) {
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 10)
+	day3 := day1.AddDate(0, 0, 30)
+	archivedAt := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	h := &History{}
+	opened, fixed := h.Record("payments", scanOf("example.com/app", archivedStatus("github.com/a/old", "", "", false), archivedStatus("github.com/b/gone", "", "", false)), []archiveObservation{
+		{Project: "example.com/app", Module: "github.com/a/old", ArchivedAt: archivedAt},
+		{Project: "example.com/app", Module: "github.com/b/gone"},
+	}, day1)
+	if opened != 2 || fixed != 0 {
+		t.Fatalf("run 1: opened=%d fixed=%d, want 2, 0", opened, fixed)
+	}
+
+	// b/gone is unarchived; a/old stays open without a duplicate episode
+	unarchived := RepoStatus{Module: Module{Path: "github.com/b/gone"}}
+	opened, fixed = h.Record("payments", scanOf("example.com/app", archivedStatus("github.com/a/old", "", "", false), unarchived), []archiveObservation{
+		{Project: "example.com/app", Module: "github.com/a/old", ArchivedAt: archivedAt},
+	}, day2)
+	if opened != 0 || fixed != 1 {
+		t.Fatalf("run 2: opened=%d fixed=%d, want 0, 1", opened, fixed)
+	}
+	if len(h.Episodes) != 2 {
+		t.Fatalf("len(Episodes) = %d, want 2", len(h.Episodes))
+	}
+	if e := h.Episodes[0]; e.FixedAt != nil || !e.FirstSeen.Equal(day1) || !e.ArchivedAt.Equal(archivedAt) {
+		t.Errorf("a/old episode = %+v, want open since day1", e)
+	}
+	if e := h.Episodes[1]; e.FixedAt == nil || !e.FixedAt.Equal(day2) {
+		t.Errorf("b/gone episode = %+v, want fixed on day2", e)
+	}
+
+	// b/gone comes back: a new episode opens
+	opened, _ = h.Record("payments", scanOf("example.com/app", archivedStatus("github.com/a/old", "", "", false), archivedStatus("github.com/b/gone", "", "", false)), []archiveObservation{
+		{Project: "example.com/app", Module: "github.com/a/old"},
+		{Project: "example.com/app", Module: "github.com/b/gone"},
+	}, day3)
+	if opened != 1 || len(h.Episodes) != 3 {
+		t.Errorf("reopen: opened=%d episodes=%d, want 1, 3", opened, len(h.Episodes))
+	}
+}
+
+func TestHistoryRecord_ScopedToScannedProjects(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := &History{Episodes: []HistoryEntry{
+		{Project: "example.com/other", Team: "payments", Module: "github.com/a/old", FirstSeen: now},
+		{Project: "example.com/app", Team: "search", Module: "github.com/a/old", FirstSeen: now},
+	}}
+
+	// Neither episode belongs to (payments, example.com/app), so neither is fixed
+	_, fixed := h.Record("payments", scanOf("example.com/app"), nil, now.AddDate(0, 0, 1))
+	if fixed != 0 {
+		t.Errorf("fixed = %d, want 0 (other project and other team are untouched)", fixed)
+	}
+}
+
+func TestHistoryRecord_FilteredRunKeepsEpisodesOpen(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := &History{Episodes: []HistoryEntry{
+		{Project: "example.com/app", Team: "payments", Module: "github.com/a/indirect", FirstSeen: now},
+		{Project: "example.com/app", Team: "payments", Module: "github.com/b/direct", FirstSeen: now},
+		{Project: "example.com/app", Team: "payments", Module: "github.com/c/removed", FirstSeen: now},
+	}}
+
+	// A --direct-only run: a/indirect is still required but was not queried,
+	// b/direct was queried and is still archived, c/removed left go.mod
+	var s runSummary
+	s.observe("example.com/app", "go.mod", []Module{
+		{Path: "github.com/a/indirect"},
+		{Path: "github.com/b/direct", Direct: true},
+	}, []RepoStatus{archivedStatus("github.com/b/direct", "", "", false)})
+	s.add("example.com/app", "go.mod", []RepoStatus{archivedStatus("github.com/b/direct", "", "", false)})
+
+	opened, fixed := h.Record("payments", s.scans, s.archivedObservations(), now.AddDate(0, 0, 1))
+	if opened != 0 || fixed != 1 {
+		t.Fatalf("opened=%d fixed=%d, want 0, 1", opened, fixed)
+	}
+	for _, e := range h.Episodes {
+		if wantFixed := e.Module == "github.com/c/removed"; (e.FixedAt != nil) != wantFixed {
+			t.Errorf("%s: FixedAt = %v, want fixed=%v", e.Module, e.FixedAt, wantFixed)
+		}
+	}
+
+	// A --filter matching no GitHub module queries nothing and fixes nothing
+	var none runSummary
+	none.observe("example.com/app", "go.mod", []Module{{Path: "github.com/a/indirect"}, {Path: "github.com/b/direct"}}, nil)
+	none.add("example.com/app", "go.mod", nil)
+	if _, fixed := h.Record("payments", none.scans, none.archivedObservations(), now.AddDate(0, 0, 2)); fixed != 0 {
+		t.Errorf("empty filtered run fixed %d episodes, want 0", fixed)
+	}
+}
+
+func TestHistoryRecord_UnknownKeepsEpisodesOpen(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := &History{Episodes: []HistoryEntry{
+		{Project: "example.com/app", Team: "payments", Module: "github.com/a/old", FirstSeen: now},
+	}}
+
+	// The check was rate limited: not archived as far as we know, but unknown
+	failed := RepoStatus{Module: Module{Path: "github.com/a/old"}, Unknown: true, Error: "rate limited"}
+	if _, fixed := h.Record("payments", scanOf("example.com/app", failed), nil, now.AddDate(0, 0, 1)); fixed != 0 {
+		t.Fatalf("fixed = %d, want 0 for a module whose check failed", fixed)
+	}
+	if h.Episodes[0].FixedAt != nil {
+		t.Errorf("episode = %+v, want still open", h.Episodes[0])
+	}
+}
+
+func TestMTTRByTeam(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fixedAt := func(days int) *time.Time {
+		t := start.AddDate(0, 0, days)
+		return &t
+	}
+	h := &History{Episodes: []HistoryEntry{
+		{Team: "search", Module: "m1", FirstSeen: start, FixedAt: fixedAt(10)},
+		{Team: "search", Module: "m2", FirstSeen: start, FixedAt: fixedAt(20)},
+		{Team: "search", Module: "m3", FirstSeen: start},
+		{Team: "", Module: "m4", FirstSeen: start},
+	}}
+
+	got := mttrByTeam(h)
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2: %+v", len(got), got)
+	}
+	if got[0].Team != "" || got[0].Fixed != 0 || got[0].Open != 1 || got[0].Days != 0 {
+		t.Errorf("unassigned team = %+v", got[0])
+	}
+	if got[1].Team != "search" || got[1].Fixed != 2 || got[1].Open != 1 || got[1].Days != 15 {
+		t.Errorf("search team = %+v, want 2 fixed, 1 open, 15 days", got[1])
+	}
+}
+
+func TestRunSummary_ArchivedObservations(t *testing.T) {
+	var s runSummary
+	s.add("", "sub/go.mod", []RepoStatus{
+		{Module: Module{Path: "github.com/a/old"}, IsArchived: true},
+		{Module: Module{Path: "github.com/b/fine"}},
+	})
+	s.add("example.com/clean", "go.mod", nil)
+
+	if len(s.projects) != 2 || s.projects[0] != "sub/go.mod" {
+		t.Errorf("projects = %v, want fallback to go.mod path first", s.projects)
+	}
+	obs := s.archivedObservations()
+	if len(obs) != 1 || obs[0].Module != "github.com/a/old" || obs[0].Project != "sub/go.mod" {
+		t.Errorf("obs = %+v, want only the archived module", obs)
+	}
+}
+
+func TestHistorySaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	h, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("loadHistory(missing) error: %v", err)
+	}
+	if len(h.Episodes) != 0 {
+		t.Fatalf("missing file should yield empty history, got %+v", h)
+	}
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	h.Record("infra", scanOf("example.com/app", archivedStatus("github.com/a/old", "", "", false)), []archiveObservation{
+		{Project: "example.com/app", Module: "github.com/a/old"},
+	}, now)
+	if err := saveHistory(path, h); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != historyVersion || len(got.Episodes) != 1 {
+		t.Fatalf("round trip = %+v", got)
+	}
+	if e := got.Episodes[0]; e.Team != "infra" || !e.FirstSeen.Equal(now) || e.FixedAt != nil || !e.ArchivedAt.IsZero() {
+		t.Errorf("episode = %+v", e)
+	}
+}
@@ -20,7 +20,7 @@ func NewIgnoreList() *IgnoreList {
 // Add adds one or more module paths to the ignore list with no reason.
 func (il *IgnoreList) Add(paths ...string) {
 	for _, p := range paths {
-		p = strings.TrimSpace(p)
+		p = canonicalModulePath(strings.TrimSpace(p))
 		if p != "" {
 			il.paths[p] = ""
 		}
@@ -29,7 +29,7 @@ func (il *IgnoreList) Add(paths ...string) {
 
 // AddWithReason adds a module path with an optional reason.
 func (il *IgnoreList) AddWithReason(path, reason string) {
-	path = strings.TrimSpace(path)
+	path = canonicalModulePath(strings.TrimSpace(path))
 	if path != "" {
 		il.paths[path] = reason
 	}
@@ -31,6 +31,14 @@ func TestIgnoreList_Add(t *testing.T) {
 	}
 }
 
+func TestIgnoreList_Add_BangEncoded(t *testing.T) {
+	il := NewIgnoreList()
+	il.Add("github.com/!azure/go-autorest")
+	if !il.IsIgnored("github.com/Azure/go-autorest") {
+		t.Error("expected the bang-encoded entry to match github.com/Azure/go-autorest")
+	}
+}
+
 func TestIgnoreList_Add_SkipsEmpty(t *testing.T) {
 	il := NewIgnoreList()
 	il.Add("", "  ", "github.com/foo/bar")
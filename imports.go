@@ -14,7 +14,9 @@ import (
 type FileMatch struct {
 	File       string // relative path from project root
 	Line       int    // line number of the import
+	Col        int    // 1-based byte column of the quoted import path
 	ImportPath string // full import path found in source
+	UsageKind  string // "call", "type-only", "side-effect", or "unknown"
 }
 
 // ScanImports uses rg (ripgrep) to find Go source files that import any of
@@ -33,7 +35,7 @@ func ScanImports(projectDir string, modulePaths []string) (map[string][]FileMatc
 	pattern := buildImportPattern(modulePaths)
 
 	// Run rg in one pass over all .go files, excluding vendor/
-	cmd := exec.Command("rg", "-n", "--no-heading",
+	cmd := exec.CommandContext(runCtx, "rg", "-n", "--no-heading",
 		"--glob", "*.go",
 		"--glob", "!vendor/",
 		"-e", pattern,
@@ -48,7 +50,9 @@ func ScanImports(projectDir string, modulePaths []string) (map[string][]FileMatc
 		return nil, fmt.Errorf("running rg: %w", err)
 	}
 
-	return parseRgOutput(string(out), projectDir, modulePaths), nil
+	matches := parseRgOutput(string(out), projectDir, modulePaths)
+	annotateUsageKinds(projectDir, matches)
+	return matches, nil
 }
 
 // buildImportPattern constructs a regex that matches import lines containing
@@ -98,11 +102,11 @@ func parseRgOutput(output, projectDir string, modulePaths []string) map[string][
 		relFile := strings.TrimPrefix(file, projectDir)
 
 		// Extract import path from the content
-		match := importRe.FindStringSubmatch(content)
-		if match == nil {
+		loc := importRe.FindStringSubmatchIndex(content)
+		if loc == nil {
 			continue
 		}
-		importPath := match[1]
+		importPath := content[loc[2]:loc[3]]
 
 		// Find the module this import belongs to (longest-prefix match)
 		modulePath := matchModule(importPath, sorted)
@@ -113,6 +117,7 @@ func parseRgOutput(output, projectDir string, modulePaths []string) map[string][
 		results[modulePath] = append(results[modulePath], FileMatch{
 			File:       relFile,
 			Line:       lineNum,
+			Col:        loc[0] + 1,
 			ImportPath: importPath,
 		})
 	}
@@ -137,11 +137,11 @@ func TestParseRgOutput(t *testing.T) {
 	got := parseRgOutput(rgOutput, "/proj", modulePaths)
 
 	wantCopy := []FileMatch{
-		{File: "audit/hashstructure.go", Line: 14, ImportPath: "github.com/mitchellh/copystructure"},
-		{File: "vault/policy.go", Line: 17, ImportPath: "github.com/mitchellh/copystructure"},
+		{File: "audit/hashstructure.go", Line: 14, Col: 2, ImportPath: "github.com/mitchellh/copystructure"},
+		{File: "vault/policy.go", Line: 17, Col: 2, ImportPath: "github.com/mitchellh/copystructure"},
 	}
 	wantWalk := []FileMatch{
-		{File: "audit/hashstructure.go", Line: 15, ImportPath: "github.com/mitchellh/reflectwalk"},
+		{File: "audit/hashstructure.go", Line: 15, Col: 2, ImportPath: "github.com/mitchellh/reflectwalk"},
 	}
 
 	if !reflect.DeepEqual(got["github.com/mitchellh/copystructure"], wantCopy) {
@@ -163,7 +163,7 @@ func TestParseRgOutput_Subpackage(t *testing.T) {
 	got := parseRgOutput(rgOutput, "/proj", modulePaths)
 
 	want := []FileMatch{
-		{File: "foo.go", Line: 5, ImportPath: "github.com/hashicorp/go-discover/provider/aws"},
+		{File: "foo.go", Line: 5, Col: 2, ImportPath: "github.com/hashicorp/go-discover/provider/aws"},
 	}
 
 	if !reflect.DeepEqual(got["github.com/hashicorp/go-discover"], want) {
@@ -371,3 +371,16 @@ func TestParseRgOutput_SortedByFileThenLine(t *testing.T) {
 		t.Errorf("third match should be z.go:10, got %s:%d", matches[2].File, matches[2].Line)
 	}
 }
+
+func TestParseRgOutput_AliasedImportColumn(t *testing.T) {
+	rgOutput := `/proj/foo.go:7:	errs "github.com/pkg/errors"
+`
+	got := parseRgOutput(rgOutput, "/proj", []string{"github.com/pkg/errors"})
+
+	want := []FileMatch{
+		{File: "foo.go", Line: 7, Col: 7, ImportPath: "github.com/pkg/errors"},
+	}
+	if !reflect.DeepEqual(got["github.com/pkg/errors"], want) {
+		t.Errorf("got %+v, want %+v", got["github.com/pkg/errors"], want)
+	}
+}
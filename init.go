@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// initWorkflowFile is the GitHub Actions workflow modrot init can create,
+// relative to the project directory.
+var initWorkflowFile = filepath.Join(".github", "workflows", "modrot.yml")
+
+// runInit implements `modrot init [--yes] [--force] [dir]`. It asks a few
+// questions and writes .modrot.yaml (and optionally a GitHub Actions
+// workflow) in dir. Returns exit code: 0 = written, 2 = error.
+func runInit(args []string) int {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "Accept the defaults without prompting")
+	force := fs.Bool("force", false, "Overwrite an existing .modrot.yaml or workflow file")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot init [--yes] [--force] [dir]
+
+Interactively create .modrot.yaml: output format, which archived deps fail
+the run, where the GitHub token comes from, and optionally an ignore list
+seeded from the current scan and a GitHub Actions workflow.
+
+  --yes    Accept the defaults without prompting
+  --force  Overwrite an existing .modrot.yaml or workflow file
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	w := &initWizard{in: bufio.NewReader(os.Stdin), out: os.Stderr, yes: *yes}
+	if err := w.run(dir, *force, seedIgnores); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// initWizard asks the modrot init questions. With yes set, every question
+// takes its default without reading input.
+type initWizard struct {
+	in  *bufio.Reader
+	out io.Writer
+	yes bool
+}
+
+// ask prints question with its default and returns the trimmed answer, or
+// def for an empty answer, end of input, or yes mode.
+func (w *initWizard) ask(question, def string) string {
+	if w.yes {
+		return def
+	}
+	_, _ = fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	line, _ := w.in.ReadString('\n')
+	if line = strings.TrimSpace(line); line == "" {
+		return def
+	}
+	return line
+}
+
+// choose asks until the answer is one of choices.
+func (w *initWizard) choose(question string, choices []string, def string) string {
+	q := fmt.Sprintf("%s (%s)", question, strings.Join(choices, ", "))
+	for {
+		answer := w.ask(q, def)
+		if slices.Contains(choices, answer) {
+			return answer
+		}
+		_, _ = fmt.Fprintf(w.out, "Please answer one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// confirm asks a yes/no question.
+func (w *initWizard) confirm(question string, def bool) bool {
+	d := "y/N"
+	if def {
+		d = "Y/n"
+	}
+	switch strings.ToLower(w.ask(question, d)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// run asks the questions and writes the files into dir. seed returns the
+// archived module paths of the go.mod it is given, for the ignore list.
+func (w *initWizard) run(dir string, force bool, seed func(gomodPath string) ([]string, error)) error {
+	configPath := filepath.Join(dir, projectConfigFile)
+	if _, err := os.Stat(configPath); err == nil && !force {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", configPath)
+	}
+
+	pc := projectConfig{
+		Format: w.choose("Output format", outputFormats, "table"),
+		FailOn: w.choose("Fail the run (exit 1) on archived deps", failOnModes, "archived"),
+	}
+	if w.choose("GitHub token source", []string{"gh", "env"}, "gh") == "env" {
+		pc.TokenEnv = w.ask("Environment variable holding the token", "GITHUB_TOKEN")
+	}
+
+	if w.confirm("Scan now and ignore the dependencies that are already archived?", false) {
+		tokenEnv = pc.TokenEnv
+		paths, err := seed(goModFile(dir))
+		if err != nil {
+			_, _ = fmt.Fprintf(w.out, "Warning: could not seed the ignore list: %v\n", err)
+		} else {
+			pc.Ignore = paths
+			_, _ = fmt.Fprintf(w.out, "Ignoring %d archived %s.\n", len(paths), pluralize(len(paths), "dependency", "dependencies"))
+		}
+	}
+	writeWorkflow := w.confirm("Create a GitHub Actions workflow ("+filepath.ToSlash(initWorkflowFile)+")?", false)
+
+	if err := os.WriteFile(configPath, []byte(formatProjectConfig(pc)), 0o644); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(w.out, "Wrote %s\n", configPath)
+
+	if writeWorkflow {
+		path := filepath.Join(dir, initWorkflowFile)
+		if _, err := os.Stat(path); err == nil && !force {
+			_, _ = fmt.Fprintf(w.out, "%s already exists; left unchanged (use --force to overwrite)\n", path)
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(initWorkflow(pc)), 0o644); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w.out, "Wrote %s\n", path)
+	}
+	return nil
+}
+
+// seedIgnores scans gomodPath and returns the paths of its archived GitHub
+// dependencies, sorted.
+func seedIgnores(gomodPath string) ([]string, error) {
+	allModules, err := ParseGoMod(gomodPath)
+	if err != nil {
+		return nil, err
+	}
+	githubModules, _ := FilterGitHub(allModules, false)
+	if len(githubModules) == 0 {
+		return nil, errors.New("no GitHub modules found in " + gomodPath)
+	}
+	results, err := CheckRepos(githubModules, 50)
+	if err != nil {
+		return nil, err
+	}
+	paths := getArchivedPaths(results)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// initWorkflow returns a GitHub Actions workflow that runs modrot on pull
+// requests and weekly, passing the job token the way pc expects it.
+func initWorkflow(pc projectConfig) string {
+	env := "GH_TOKEN" // read by gh auth token
+	if pc.TokenEnv != "" {
+		env = pc.TokenEnv
+	}
+	return `name: modrot
+
+on:
+  pull_request:
+  schedule:
+    - cron: "0 6 * * 1"
+
+permissions:
+  contents: read
+
+jobs:
+  modrot:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v6
+
+      - uses: actions/setup-go@v6
+        with:
+          go-version-file: go.mod
+
+      - name: Install modrot
+        run: go install github.com/norman-abramovitz/modrot@latest
+
+      - name: Check for archived dependencies
+        run: modrot
+        env:
+          ` + env + `: ${{ github.token }}
+`
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitWizard(t *testing.T) {
+	defer func(prev string) { tokenEnv = prev }(tokenEnv)
+	dir := t.TempDir()
+	answers := strings.Join([]string{
+		"xml",      // invalid format, asked again
+		"json",     // format
+		"",         // fail_on: default
+		"env",      // token source
+		"CI_TOKEN", // token variable
+		"y",        // seed ignores
+		"yes",      // workflow
+	}, "\n") + "\n"
+
+	var seeded string
+	w := &initWizard{in: bufio.NewReader(strings.NewReader(answers)), out: io.Discard}
+	err := w.run(dir, false, func(gomodPath string) ([]string, error) {
+		seeded = gomodPath
+		return []string{"github.com/pkg/errors"}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seeded != filepath.Join(dir, "go.mod") {
+		t.Errorf("seeded from %q", seeded)
+	}
+	if tokenEnv != "CI_TOKEN" {
+		t.Errorf("seed scan should use the chosen token source, tokenEnv = %q", tokenEnv)
+	}
+
+	pc, err := loadProjectConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.Format != "json" || pc.FailOn != "archived" || pc.TokenEnv != "CI_TOKEN" ||
+		len(pc.Ignore) != 1 || pc.Ignore[0] != "github.com/pkg/errors" {
+		t.Errorf(".modrot.yaml = %+v", pc)
+	}
+
+	wf, err := os.ReadFile(filepath.Join(dir, initWorkflowFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(wf), "CI_TOKEN: ${{ github.token }}") {
+		t.Errorf("workflow should pass the token as CI_TOKEN:\n%s", wf)
+	}
+}
+
+func TestInitWizard_Defaults(t *testing.T) {
+	dir := t.TempDir()
+	w := &initWizard{out: io.Discard, yes: true}
+	seed := func(string) ([]string, error) {
+		t.Error("--yes should not scan")
+		return nil, nil
+	}
+	if err := w.run(dir, false, seed); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, projectConfigFile))
+	if !strings.Contains(string(data), "format: table\nfail_on: archived\n") {
+		t.Errorf(".modrot.yaml = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, initWorkflowFile)); !os.IsNotExist(err) {
+		t.Error("--yes should not create a workflow")
+	}
+
+	// An existing file is only replaced with --force.
+	if err := w.run(dir, false, seed); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("second run error = %v, want --force hint", err)
+	}
+	if err := w.run(dir, true, seed); err != nil {
+		t.Errorf("--force run: %v", err)
+	}
+}
+
+func TestInitWizard_SeedFailure(t *testing.T) {
+	dir := t.TempDir()
+	w := &initWizard{in: bufio.NewReader(strings.NewReader("\n\n\ny\nn\n")), out: io.Discard}
+	err := w.run(dir, false, func(string) ([]string, error) { return nil, errors.New("no token") })
+	if err != nil {
+		t.Fatalf("a failed seed scan should not abort init: %v", err)
+	}
+	if pc, _ := loadProjectConfig(dir); pc.Ignore != nil {
+		t.Errorf("Ignore = %v, want none", pc.Ignore)
+	}
+}
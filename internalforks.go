@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// isInternalFork reports whether a replace directive substitutes m with a
+// GitHub repository owned by one of the internal_orgs in .modrot.yaml: an
+// internal fork republished under the upstream path. The upstream's archive
+// status says nothing about code the organization already maintains.
+func isInternalFork(cfg *Config, m Module) bool {
+	if m.ReplacedBy == "" || len(cfg.InternalOrgs) == 0 {
+		return false
+	}
+	owner, _ := extractGitHub(m.ReplacedBy)
+	return owner != "" && slices.ContainsFunc(cfg.InternalOrgs, func(org string) bool {
+		return strings.EqualFold(org, owner)
+	})
+}
+
+// skipInternalForks splits the internal forks off modules so their upstream
+// repositories are never queried.
+func skipInternalForks(cfg *Config, modules []Module) (kept, forks []Module) {
+	if len(cfg.InternalOrgs) == 0 {
+		return modules, nil
+	}
+	kept = make([]Module, 0, len(modules))
+	for _, m := range modules {
+		if isInternalFork(cfg, m) {
+			forks = append(forks, m)
+		} else {
+			kept = append(kept, m)
+		}
+	}
+	return kept, forks
+}
+
+// reportInternalForks notes on stderr which modules were skipped as
+// internal forks.
+func reportInternalForks(forks []Module) {
+	if len(forks) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Skipping %d internal %s (replaced by a repo in internal_orgs):\n",
+		len(forks), pluralize(len(forks), "fork", "forks"))
+	for _, m := range forks {
+		_, _ = fmt.Fprintf(os.Stderr, "  %s => %s\n", m.Path, m.ReplacedBy)
+	}
+}
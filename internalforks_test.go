@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestSkipInternalForks(t *testing.T) {
+	modules := []Module{
+		{Path: "github.com/upstream/lib", Owner: "upstream", Repo: "lib", ReplacedBy: "github.com/Acme/lib"},
+		{Path: "github.com/upstream/local", Owner: "upstream", Repo: "local", ReplacedBy: "../forks/local"},
+		{Path: "github.com/upstream/elsewhere", Owner: "upstream", Repo: "elsewhere", ReplacedBy: "github.com/someone/elsewhere"},
+		{Path: "github.com/plain/dep", Owner: "plain", Repo: "dep"},
+	}
+
+	cfg := defaultTestConfig()
+	if kept, forks := skipInternalForks(cfg, modules); len(kept) != 4 || forks != nil {
+		t.Errorf("without internal_orgs: kept %d, forks %v; want everything kept", len(kept), forks)
+	}
+
+	cfg.InternalOrgs = []string{"acme"}
+	kept, forks := skipInternalForks(cfg, modules)
+	if len(forks) != 1 || forks[0].Path != "github.com/upstream/lib" {
+		t.Errorf("forks = %v, want github.com/upstream/lib", forks)
+	}
+	if len(kept) != 3 {
+		t.Errorf("kept %d modules, want 3", len(kept))
+	}
+}
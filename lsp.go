@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LSP DiagnosticSeverity values.
+const (
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+)
+
+// lspPosition is a zero-based line/character position, as in LSP.
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// lspRange is a half-open LSP range.
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+// lspDiagnostic is one LSP-style diagnostic for an archived import. The
+// range/severity/source/code/message fields follow the LSP Diagnostic shape
+// so editor extensions can pass them through unchanged.
+type lspDiagnostic struct {
+	File     string   `json:"file"` // relative to the module directory
+	URI      string   `json:"uri"`
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Module   string   `json:"module"`
+}
+
+// lspOutput is the top-level JSON document written by lsp-diagnostics.
+type lspOutput struct {
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+// runLSPDiagnostics implements `modrot lsp-diagnostics [path]`.
+// It emits LSP-style diagnostics for every source import of an archived
+// module so editors can underline them in place.
+// Returns exit code: 0 = success (with or without diagnostics), 2 = error.
+func runLSPDiagnostics(args []string) int {
+	fs := flag.NewFlagSet("lsp-diagnostics", flag.ContinueOnError)
+	workers := fs.Int("workers", 50, "Number of repos per GitHub GraphQL batch request")
+	ignoreFile := fs.String("ignore-file", "", "Path to ignore file (default: .modrotignore next to go.mod)")
+	threshold := fs.String("color-threshold", "", "Age thresholds: 2–4 comma-separated values (default: 3m,1y,2y,5y)")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot lsp-diagnostics [flags] [path/to/go.mod | path/to/dir]
+
+Print LSP diagnostics (JSON) for source imports of archived modules.
+Severity follows the --color-threshold age levels: imports of modules in the
+oldest level are errors, all others are warnings.
+
+  --workers int            Number of repos per GitHub GraphQL batch request (default 50)
+  --ignore-file string     Path to ignore file (default: .modrotignore next to go.mod)
+  --color-threshold string Age thresholds: 2–4 comma-separated values (default: 3m,1y,2y,5y)
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.IgnoreFile = *ignoreFile
+	if *threshold == "" {
+		*threshold = defaultColorThreshold
+	}
+	thresholds, err := parseColorThreshold(*threshold)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	cfg.Color.Thresholds = thresholds
+
+	inputPath := "."
+	if fs.NArg() > 0 {
+		inputPath = fs.Arg(0)
+	}
+	absPath, err := filepath.Abs(inputPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	gomodPath := goModFile(absPath)
+	dir := filepath.Dir(gomodPath)
+
+	allModules, err := ParseGoMod(gomodPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	out := lspOutput{Diagnostics: []lspDiagnostic{}}
+	githubModules, _ := FilterGitHub(allModules, false)
+	if len(githubModules) > 0 {
+		results, err := CheckRepos(githubModules, *workers)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		results, _, _ = applyIgnoreList(cfg, results, gomodPath)
+
+		if hasArchived, archivedPaths := findArchived(results); hasArchived {
+			fileMatches, err := ScanImports(dir, archivedPaths)
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 2
+			}
+			out.Diagnostics = buildLSPDiagnostics(cfg, dir, results, fileMatches)
+		}
+	}
+
+	writeJSON(out)
+	return 0
+}
+
+// buildLSPDiagnostics converts import matches of archived modules into
+// diagnostics sorted by file and position.
+func buildLSPDiagnostics(cfg *Config, dir string, results []RepoStatus, fileMatches map[string][]FileMatch) []lspDiagnostic {
+	diags := []lspDiagnostic{}
+	for _, rs := range results {
+		if !rs.IsArchived {
+			continue
+		}
+		severity := archivedSeverity(cfg, rs)
+		for _, m := range fileMatches[rs.Module.Path] {
+			start := lspPosition{Line: m.Line - 1, Character: max(m.Col-1, 0)}
+			end := lspPosition{Line: start.Line, Character: start.Character + len(m.ImportPath) + 2}
+			diags = append(diags, lspDiagnostic{
+				File:     m.File,
+				URI:      "file://" + filepath.ToSlash(filepath.Join(dir, m.File)),
+				Range:    lspRange{Start: start, End: end},
+				Severity: severity,
+				Source:   "modrot",
+				Code:     "archived-import",
+				Message:  archivedImportMessage(cfg, rs),
+				Module:   rs.Module.Path,
+			})
+		}
+	}
+	sort.Slice(diags, func(i, j int) bool {
+		if diags[i].File != diags[j].File {
+			return diags[i].File < diags[j].File
+		}
+		return diags[i].Range.Start.Line < diags[j].Range.Start.Line
+	})
+	return diags
+}
+
+// archivedSeverity maps how long a repo has been archived onto an LSP
+// severity using the color age levels: the oldest level is an error,
+// everything else (including an unknown archive date) a warning.
+func archivedSeverity(cfg *Config, rs RepoStatus) int {
+	if classifyAge(cfg, rs.ArchivedAt) == len(cfg.Color.Thresholds) {
+		return lspSeverityError
+	}
+	return lspSeverityWarning
+}
+
+// archivedImportMessage describes an import of an archived module for
+// editor and CI annotations.
+func archivedImportMessage(cfg *Config, rs RepoStatus) string {
+	msg := "import of archived module " + rs.Module.Path
+	if d := fmtDate(cfg, rs.ArchivedAt); d != "" {
+		msg += " (archived " + d + ")"
+	}
+	return msg
+}
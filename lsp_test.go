@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func lspTestConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg := defaultTestConfig()
+	thresholds, err := parseColorThreshold(defaultColorThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Color.Thresholds = thresholds
+	cfg.Now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return cfg
+}
+
+func TestBuildLSPDiagnostics(t *testing.T) {
+	cfg := lspTestConfig(t)
+	results := []RepoStatus{
+		{
+			Module:     Module{Path: "github.com/pkg/errors", Owner: "pkg", Repo: "errors"},
+			IsArchived: true,
+			ArchivedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{Module: Module{Path: "github.com/ok/lib", Owner: "ok", Repo: "lib"}},
+	}
+	fileMatches := map[string][]FileMatch{
+		"github.com/pkg/errors": {
+			{File: "z.go", Line: 4, Col: 2, ImportPath: "github.com/pkg/errors"},
+			{File: "a.go", Line: 10, Col: 9, ImportPath: "github.com/pkg/errors"},
+		},
+		"github.com/ok/lib": {
+			{File: "a.go", Line: 3, Col: 2, ImportPath: "github.com/ok/lib"},
+		},
+	}
+
+	diags := buildLSPDiagnostics(cfg, "/proj", results, fileMatches)
+	if len(diags) != 2 {
+		t.Fatalf("len(diags) = %d, want 2 (non-archived imports skipped)", len(diags))
+	}
+
+	d := diags[0]
+	if d.File != "a.go" || d.URI != "file:///proj/a.go" {
+		t.Errorf("first diagnostic file = %q uri = %q, want a.go sorted first", d.File, d.URI)
+	}
+	wantRange := lspRange{
+		Start: lspPosition{Line: 9, Character: 8},
+		End:   lspPosition{Line: 9, Character: 8 + len(`"github.com/pkg/errors"`)},
+	}
+	if d.Range != wantRange {
+		t.Errorf("Range = %+v, want %+v", d.Range, wantRange)
+	}
+	if d.Severity != lspSeverityWarning {
+		t.Errorf("Severity = %d, want warning for recently archived", d.Severity)
+	}
+	if d.Message != "import of archived module github.com/pkg/errors (archived 2025-06-01)" {
+		t.Errorf("Message = %q", d.Message)
+	}
+	if d.Source != "modrot" || d.Code != "archived-import" || d.Module != "github.com/pkg/errors" {
+		t.Errorf("unexpected source/code/module: %+v", d)
+	}
+}
+
+func TestArchivedSeverity(t *testing.T) {
+	cfg := lspTestConfig(t)
+	tests := []struct {
+		name       string
+		archivedAt time.Time
+		want       int
+	}{
+		{"unknown date", time.Time{}, lspSeverityWarning},
+		{"archived last month", time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), lspSeverityWarning},
+		{"archived 3 years ago", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), lspSeverityWarning},
+		{"archived 6 years ago", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), lspSeverityError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := RepoStatus{IsArchived: true, ArchivedAt: tt.archivedAt}
+			if got := archivedSeverity(cfg, rs); got != tt.want {
+				t.Errorf("archivedSeverity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchivedImportMessage_NoDate(t *testing.T) {
+	cfg := defaultTestConfig()
+	rs := RepoStatus{Module: Module{Path: "github.com/x/y"}, IsArchived: true}
+	if got := archivedImportMessage(cfg, rs); got != "import of archived module github.com/x/y" {
+		t.Errorf("archivedImportMessage() = %q", got)
+	}
+}
@@ -7,15 +7,54 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
 
+// subcommands maps a leading positional argument to its handler.
+// Each handler receives the remaining arguments and returns an exit code.
+var subcommands = map[string]func(args []string) int{
+	"digest":          runDigest,
+	"doctor":          runDoctor,
+	"fix":             runFix,
+	"history":         runHistory,
+	"init":            runInit,
+	"lsp-diagnostics": runLSPDiagnostics,
+	"matrix":          runMatrix,
+	"serve":           runServe,
+	"tidy-archived":   runTidyArchived,
+	"verify-report":   runVerifyReport,
+	"warm-cache":      runWarmCache,
+}
+
 func main() {
-	cfg := parseFlags()
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
 
-	inputPath := resolveInputPath()
+	cfg := parseFlags()
+	start := time.Now()
+	stopDeadline := cfg.Budget.startDeadline()
+
+	// A remote repository URL is cloned into a temporary workspace that is
+	// removed before exiting.
+	var ws *workspace
+	var inputPath string
+	if flag.NArg() > 0 && isRemoteSpec(cfg, flag.Arg(0)) {
+		dir, w, err := fetchRemote(cfg, flag.Arg(0))
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		ws, inputPath = w, dir
+	} else {
+		inputPath = resolveInputPath()
+	}
 
+	var code int
 	if cfg.Recursive {
 		rootDir := inputPath
 		if info, statErr := os.Stat(rootDir); statErr != nil {
@@ -24,10 +63,23 @@ func main() {
 		} else if !info.IsDir() {
 			rootDir = filepath.Dir(rootDir)
 		}
-		os.Exit(runRecursive(rootDir, cfg))
+		code = runRecursive(rootDir, cfg)
+	} else {
+		code = runSingleModule(cfg, inputPath)
 	}
 
-	os.Exit(runSingleModule(cfg, inputPath))
+	if code != 2 {
+		printSummaryBadge(cfg)
+		recordHistory(cfg)
+	}
+	code = failOnExitCode(cfg, code)
+	code = disabledExitCode(cfg, code)
+	code = unknownExitCode(cfg, code)
+	code = strictExitCode(cfg, code)
+	stopDeadline()
+	pushMetrics(cfg, filepath.Dir(goModFile(inputPath)), time.Since(start), code)
+	ws.Cleanup()
+	os.Exit(code)
 }
 
 // parseFlags defines all CLI flags, parses them, and returns a fully
@@ -52,7 +104,7 @@ func parseFlags() *Config {
 	reorderArgs()
 
 	// Output format flags
-	formatFlag := flag.String("format", "table", "Output format: table, json, markdown, mermaid, quickfix")
+	formatFlag := flag.String("format", "table", "Output format: table, json, markdown, mermaid, quickfix, plain, heatmap")
 	jsonFlag := flag.Bool("json", false, "Output as JSON (alias for --format=json)")
 	markdownFlag := flag.Bool("markdown", false, "Output as GitHub-flavored Markdown (alias for --format=markdown)")
 	mermaidFlag := flag.Bool("mermaid", false, "Output Mermaid flowchart diagram (alias for --format=mermaid)")
@@ -64,15 +116,29 @@ func parseFlags() *Config {
 	ignoreFlag := flag.String("ignore", "", "Comma-separated list of module paths to ignore")
 	showIgnoredFlag := flag.Bool("show-ignored", false, "Show ignored modules and their current state")
 	noIgnoreFlag := flag.Bool("no-ignore", false, "Disable ignore lists (.modrotignore and --ignore)")
+	requireGoOnlyFlag := flag.Bool("require-go-only", false, "Don't note other ecosystems' manifests (package.json, requirements.txt, ...) found next to go.mod")
+	filterFlag := flag.String("filter", "", "Only report modules matching: owner=NAME, module=GLOB, direct, indirect (comma-separated)")
+	includeVendoredForkedFlag := flag.Bool("include-vendored-forked", false, "Count archived modules patched in vendor/ as archived failures")
 
 	// Analysis flags
 	resolveFlag := flag.Bool("resolve", false, "Resolve vanity import paths (e.g. google.golang.org/grpc) to GitHub repos")
+	allowInsecureFlag := flag.Bool("allow-insecure-hosts", false, "With --resolve, fetch vanity import pages of GOINSECURE hosts over http when https fails")
+	preflightFlag := flag.Bool("preflight-hosts", false, "With --resolve, check each vanity host is reachable once and skip its modules if not")
 	deprecatedFlag := flag.Bool("deprecated", false, "Check for deprecated modules via the Go module proxy")
+	deprecatedAllFlag := flag.Bool("deprecated-all", false, "Check every module for deprecation, not just direct and archived/stale indirect ones")
 	freshnessFlag := flag.Bool("freshness", false, "Show latest available version and how far behind each dependency is")
+	policyFlag := flag.String("policy", "", "Warn about deps whose repo or version matches rules: topic:NAME, property:NAME[=VALUE], version:OPVERSION, each optionally @TAG (comma-separated)")
+	advisoriesFlag := flag.Bool("advisories", false, "For archived deps, report OSV advisories published after the last GitHub release")
+	upgradePathsFlag := flag.Bool("upgrade-paths", false, "Classify archived indirect deps as actionable via a direct dep upgrade or unavoidable (uses go mod graph)")
+	remediationsFlag := flag.Bool("remediations", false, "Group archived findings by suggested action: upgrade, replace, fork, remove unused require")
+	actionsFlag := flag.Bool("actions", false, "Also check GitHub Actions used in .github/workflows for archived repos")
+	dockerfilesFlag := flag.Bool("dockerfiles", false, "Also check the GitHub repos behind ghcr.io images used in Dockerfiles")
+	lintVanityFlag := flag.Bool("lint-vanity", false, "Flag dependencies whose vanity import host no longer serves go-import meta tags")
 
 	// Display flags
 	allFlag := flag.Bool("all", false, "Show all modules, not just archived ones")
 	treeFlag := flag.Bool("tree", false, "Show ASCII dependency tree for archived modules (uses go mod graph)")
+	selectedOnlyFlag := flag.Bool("selected-only", false, "Prune the module graph to the versions MVS selects (uses go list -m all)")
 	filesFlag := flag.Bool("files", false, "Show source files that import archived modules")
 	sortFlag := flag.String("sort", "name", "Sort: name[:asc|desc], duration[:asc|desc], pushed[:asc|desc]; name defaults asc, duration/pushed default desc")
 	timeFlag := flag.Bool("time", false, "Include time in date output (2006-01-02 15:04:05 instead of 2006-01-02)")
@@ -80,16 +146,43 @@ func parseFlags() *Config {
 
 	// Execution flags
 	workers := flag.Int("workers", 50, "Number of repos per GitHub GraphQL batch request")
+	proxyRPSFlag := flag.Float64("proxy-rps", defaultProxyRPS, "Maximum Go module proxy requests per second, shared by every phase (0 = unlimited)")
+	hostConcurrencyFlag := flag.Int("host-concurrency", defaultHostConcurrency, "Maximum simultaneous requests to any one vanity import host (0 = unlimited)")
+	hostRPSFlag := flag.Float64("host-rps", 0, "Maximum requests per second to any one vanity import host (0 = unlimited)")
 	goVersionFlag := flag.String("go-version", "", "Override the Go toolchain version from go.mod (e.g. 1.21.0)")
 	recursiveFlag := flag.Bool("recursive", false, "Scan all go.mod files in the directory tree")
 	noColorFlag := flag.Bool("no-color", false, "Disable colored output (also respects NO_COLOR env var)")
+	noEmojiFlag := flag.Bool("no-emoji", false, "Use plain-text markers instead of emoji in the summary badge")
 	colorThresholdFlag := flag.String("color-threshold", "", "Age thresholds for color: 2–4 values (default: 3m,1y,2y,5y)")
+	strictFlag := flag.Bool("strict", false, "Fail with exit code 3 when a missing tool or environment problem degrades the analysis")
+	failOnFlag := flag.String("fail-on", "archived", "Which archived deps fail the run (exit 1): archived, direct, never")
+	disabledReposFlag := flag.String("disabled-repos", "fail", "Treatment of disabled or taken-down repos: fail, report, ignore")
+	unknownReposFlag := flag.String("unknown-repos", "report", "Treatment of repos whose status could not be checked: report, fail")
+	failFastFlag := flag.Bool("fail-fast", false, "Stop at the first archived direct dependency and exit 1 with a minimal report")
+	tokenEnvFlag := flag.String("token-env", "", "Read the GitHub token from this environment variable instead of gh auth token")
+	endpointsFromFlag := flag.String("endpoints-from", "", "Read GitHub, Go proxy, and OSV base URLs from this JSON file (fixture servers, mirrors)")
+	historyFlag := flag.String("history", "", "Record when archived deps first appear and disappear in this JSON file")
+	teamFlag := flag.String("team", "", "Team name recorded with --history entries (for per-team remediation metrics)")
+	remoteHostsFlag := flag.String("remote-hosts", "", "Comma-separated hosts remote repository URLs may use (default: github.com,gitlab.com,bitbucket.org,codeberg.org)")
+	cloneDepthFlag := flag.Int("clone-depth", defaultCloneDepth, "git clone depth for remote repository URLs (max 100)")
+	cloneTimeoutFlag := flag.Duration("clone-timeout", defaultCloneTimeout, "Timeout for cloning a remote repository URL")
+	recheckArchivedFlag := flag.Bool("recheck-archived", false, "Re-query repos the cache says have been archived for over --archived-skip-days")
+	archivedSkipDaysFlag := flag.Int("archived-skip-days", defaultArchivedSkipDays, "Answer from the cache for repos archived for over this many days")
+	cacheTTLFlag := flag.Duration("cache-ttl", defaultCacheTTL, "Answer from the cache without querying GitHub when every repo was checked within this long (0 disables)")
+	pushgatewayFlag := flag.String("pushgateway", "", "Push run metrics to this Prometheus pushgateway URL, grouped by repo and branch")
+	phaseStatsFlag := flag.Bool("phase-stats", false, "Report time and heap use after each pipeline phase on stderr")
+	maxDurationFlag := flag.Duration("max-duration", 0, "Skip optional analysis (enrichment, then --deprecated, then --files) as the run nears this wall time and cancel work still in flight once it passes (0 = unlimited)")
+	maxRequestsFlag := flag.Int("max-requests", 0, "Skip optional analysis (enrichment, then --deprecated, then --files) as the run nears this many HTTP requests (0 = unlimited)")
+	signFlag := flag.String("sign", "", "Sign the JSON report with this Ed25519 private key (PEM), writing a detached JWS")
+	signatureFlag := flag.String("signature", defaultSignatureFile, "File --sign writes the detached signature to")
 
 	// Info flags
 	versionFlag := flag.Bool("version", false, "Print version information and exit")
 
 	flag.Usage = func() {
-		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot [flags] [path/to/go.mod | path/to/dir]
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot [flags] [path/to/go.mod | path/to/dir | https://host/owner/repo]
+       modrot doctor [--token-env VAR]
+       modrot tidy-archived [--write] [path]
 
 Detect archived GitHub dependencies in a Go project.
 
@@ -98,7 +191,8 @@ dependencies as a table. Exits 1 if any are found (useful for CI).
 Flags can appear before or after the path argument.
 
 Output format:
-  --format string       Output format: table, json, markdown, mermaid, quickfix (default "table")
+  --format string       Output format: table, json, markdown, mermaid, quickfix, plain,
+                          heatmap (default "table")
   --json                Output as JSON (alias for --format=json)
   --markdown            Output as GitHub-flavored Markdown (alias for --format=markdown)
   --mermaid             Output Mermaid flowchart diagram (alias for --format=mermaid)
@@ -110,12 +204,41 @@ Filtering:
   --ignore string       Comma-separated list of module paths to ignore
   --show-ignored        Show ignored modules and their current state
   --no-ignore           Disable ignore lists (.modrotignore and --ignore)
+  --filter string       Only report modules matching comma-separated expressions:
+                          owner=NAME, module=GLOB (* matches anything), direct, indirect
+                          Different kinds must all match; repeats of a kind are alternatives
+  --include-vendored-forked
+                        Count archived modules patched in vendor/ as archived failures
+  --require-go-only     Don't note other ecosystems' manifests (package.json,
+                          requirements.txt, ...) found next to go.mod as unchecked
   --stale[=THRESHOLD]   Show dependencies not pushed in >THRESHOLD (default: 2y, e.g. 1y6m, 180d)
 
 Analysis:
   --resolve             Resolve vanity import paths to GitHub repos (recommended)
+  --allow-insecure-hosts
+                        With --resolve, fetch vanity import pages of hosts matching
+                          GOINSECURE over http when https fails (warns per host)
+  --preflight-hosts     With --resolve, probe each vanity host once (DNS + connect) and
+                          skip all its modules with one warning if it is unreachable
   --deprecated          Check for deprecated modules via the Go module proxy
-  --freshness           Show latest available version and how far behind each dependency is
+                          (direct deps, plus indirect deps that are archived or stale)
+  --deprecated-all      Check every module for deprecation (implies --deprecated)
+  --freshness           Show latest available version and how far behind each dependency is,
+                          and mark modules with a newer major version (MAJOR UPGRADE AVAILABLE)
+  --upgrade-paths       Classify archived indirect deps as actionable via a direct dep upgrade
+                          or unavoidable (uses go mod graph)
+  --remediations        Group archived findings by suggested action: upgrade a direct dep,
+                          replace with a successor, fork and maintain, remove unused require
+  --actions             Also check GitHub Actions used in .github/workflows for archived repos
+  --dockerfiles         Also check the GitHub repos behind ghcr.io images used in Dockerfiles
+  --lint-vanity         Flag dependencies whose vanity import host no longer serves go-import
+                          meta tags (they break GOPROXY=direct builds)
+  --policy string       Warn about deps whose GitHub repo or version matches comma-separated
+                          rules: topic:NAME, property:NAME[=VALUE] (custom properties),
+                          version:OPVERSION (e.g. version:<v1.0.0); append @TAG to apply a
+                          rule only to modules with that .modrot.yaml tag
+  --advisories          For archived deps, report OSV advisories published after the
+                          last GitHub release (vulnerabilities that will never be fixed)
   --age[=THRESHOLD]     Show how old each dependency's version is (today minus publish date)
                           With threshold, show OUTDATED section (e.g. --age=18m, --age=1y6m)
   --duration[=DATE]     Show how long dependencies have been archived (default: today)
@@ -123,6 +246,8 @@ Analysis:
 Display:
   --all                 Show all modules, not just archived ones
   --tree                Show ASCII dependency tree for archived modules (uses go mod graph)
+  --selected-only       Prune the module graph to the versions MVS selects, so --tree and
+                          dependency chains only show modules in the build (uses go list -m all)
   --files               Show source files that import archived modules (requires rg)
   --sort string         Sort: name[:asc|desc], duration[:asc|desc], pushed[:asc|desc]
                           name defaults to asc (A-Z), duration and pushed default to desc (oldest first)
@@ -131,16 +256,90 @@ Display:
 
 Execution:
   --workers int         Number of repos per GitHub GraphQL batch request (default 50)
+  --proxy-rps float     Maximum Go module proxy requests per second, shared by resolve,
+                          freshness, deprecation, and upgrade analysis (default 50, 0 = unlimited)
+  --host-concurrency int
+                        Maximum simultaneous requests to any one vanity import host
+                          (default 2, 0 = unlimited)
+  --host-rps float      Maximum requests per second to any one vanity import host
+                          (default 0 = unlimited)
   --go-version string   Override the Go toolchain version from go.mod
   --recursive           Scan all go.mod files in the directory tree (monorepos)
   --no-color            Disable colored output (also respects NO_COLOR env var)
+  --no-emoji            Use plain-text markers ([FAIL], [WARN], [OK]) instead of emoji in the
+                          summary badge that ends text output
   --color-threshold     Age thresholds: 2–4 comma-separated values (default: 3m,1y,2y,5y)
                           2 values → 3 levels, 3 → 4 levels, 4 → 5 levels
                           Symbols: ★ new  ◇ recent  ◆ moderate  ▲ old  ✖ critical
+  --strict              Treat degradations (rg missing, go mod graph failing, etc.)
+                          as errors and exit 3 instead of reporting partial results
+  --fail-on string      Which archived deps fail the run with exit 1: archived (any),
+                          direct (only direct deps), never (report only) (default "archived")
+  --disabled-repos string
+                        Repos GitHub disabled or blocked (e.g. DMCA takedown): fail (list
+                          them and exit 1 regardless of --fail-on), report, ignore
+                          (default "fail")
+  --unknown-repos string
+                        Repos whose status could not be checked (GitHub timeouts, rate
+                          limits, server errors): report (list them), fail (also exit 1)
+                          (default "report")
+  --fail-fast           Stop querying GitHub at the first archived direct dependency and
+                          exit 1 with a minimal report (for cheap gating checks)
+  --token-env string    Read the GitHub token from this environment variable
+                          instead of running gh auth token
+  --endpoints-from file Read GitHub, Go proxy, and OSV base URLs from a JSON file
+                          (for fixture servers and mirrors)
+  --history string      Record when archived deps first appear and disappear (fixed)
+                          in this JSON file; view with modrot history
+  --team string         Team name recorded with --history entries
+  --remote-hosts string Hosts remote repository URLs may use
+                          (default: github.com,gitlab.com,bitbucket.org,codeberg.org)
+  --clone-depth int     git clone depth for remote repository URLs (default 1, max 100)
+  --clone-timeout dur   Timeout for cloning a remote repository URL (default 2m0s)
+  --recheck-archived    Re-query GitHub for repos the cache says have been archived
+                          for over --archived-skip-days (skipped by default)
+  --archived-skip-days int
+                        Answer from the cache for repos archived for over this many
+                          days (default 30)
+  --cache-ttl dur       Skip the GitHub query when the cache checked every repo within
+                          this long (default 6h0m0s; 0 always queries)
+  --pushgateway URL     Push run metrics (counts, score, durations) to a Prometheus
+                          pushgateway, grouped by repo and branch
+  --phase-stats         Report time and heap use after each pipeline phase on stderr
+  --max-duration dur    Bound the run's wall time: past half of it, proxy enrichment is
+                          skipped; past 3/4, --deprecated checks; past all of it,
+                          --files scanning, and requests still in flight are cancelled
+                          (0 = unlimited)
+  --max-requests int    Bound the run's HTTP requests the same way (0 = unlimited)
+  --sign file           Sign the JSON report with an Ed25519 private key (PEM); check it
+                          with modrot verify-report
+  --signature file      Where --sign writes the detached JWS (default modrot-report.jws)
 
 Info:
   --version             Print version information and exit
 
+Subcommands:
+  digest                Summarize the --history file over a period (--since 7d): new
+                          archives, fixes, oldest findings; Markdown or Slack format
+  doctor                Check gh auth, rg, go, network access, and cache directory
+  fix                   Plan direct dep upgrades that drop archived indirect deps and
+                          replacements of archived direct deps (--write applies them, --pr
+                          opens a pull request)
+  history               Show the --history archive timeline and mean time to remediation
+                          per team
+  init                  Create .modrot.yaml interactively (format, fail policy, token
+                          source, ignore seeds) and optionally a GitHub Actions workflow
+  lsp-diagnostics       Print LSP diagnostics JSON for source imports of archived modules
+  matrix                Scan several targets (e.g. release branches as repo@REF) and
+                          print a targets × findings matrix (--json keys it by target)
+  serve                 Rescan on an interval and serve the latest result at
+                          /.well-known/modrot.json for fleet scanners
+  tidy-archived         List archived requires nothing imports anymore (--write removes
+                          them and verifies with go mod tidy)
+  verify-report         Check a JSON report against the detached signature from --sign
+  warm-cache            Query every dependency under the given dirs, repos, or GitHub orgs to
+                          populate the archive cache, with no report (for nightly jobs)
+
 Examples:
   modrot                                     Check current directory
   modrot /path/to/go.mod                     Check a specific go.mod
@@ -187,9 +386,53 @@ Examples:
 	case *quickfixFlag:
 		cfg.OutputFormat = "quickfix"
 	}
+	cfg.IgnoreInline = *ignoreFlag
+	cfg.FailOn = *failOnFlag
+	cfg.DisabledRepos = *disabledReposFlag
+	cfg.FailFast = *failFastFlag
+	if !slices.Contains(disabledModes, cfg.DisabledRepos) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: invalid --disabled-repos %q (want %s)\n", cfg.DisabledRepos, strings.Join(disabledModes, ", "))
+		os.Exit(2)
+	}
+	cfg.UnknownRepos = *unknownReposFlag
+	if !slices.Contains(unknownModes, cfg.UnknownRepos) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: invalid --unknown-repos %q (want %s)\n", cfg.UnknownRepos, strings.Join(unknownModes, ", "))
+		os.Exit(2)
+	}
+	cfg.TokenEnv = *tokenEnvFlag
+
+	// .modrot.yaml next to the scanned go.mod fills in settings not given on
+	// the command line. Remote repositories are untrusted, so theirs is not read.
+	if flag.NArg() == 0 || !isRemoteSpec(cfg, flag.Arg(0)) {
+		pc, err := loadProjectConfig(filepath.Dir(goModFile(resolveInputPath())))
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		set := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		applyProjectConfig(cfg, pc, set)
+	}
+	if !slices.Contains(failOnModes, cfg.FailOn) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: invalid --fail-on %q (want %s)\n", cfg.FailOn, strings.Join(failOnModes, ", "))
+		os.Exit(2)
+	}
+	if cfg.FailFast && cfg.FailOn == "never" {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --fail-fast cannot be combined with --fail-on never\n")
+		os.Exit(2)
+	}
+	tokenEnv = cfg.TokenEnv
+	if *endpointsFromFlag != "" {
+		e, err := loadEndpoints(*endpointsFromFlag)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		endpoints = e
+	}
 
 	// Auto-enable rules
-	if cfg.OutputFormat == "quickfix" {
+	if cfg.OutputFormat == "quickfix" || cfg.OutputFormat == "plain" {
 		*filesFlag = true
 	}
 	if cfg.OutputFormat == "mermaid" {
@@ -198,23 +441,104 @@ Examples:
 
 	cfg.DirectOnly = *directOnly
 	cfg.IgnoreFile = *ignoreFileFlag
-	cfg.IgnoreInline = *ignoreFlag
 	cfg.ShowIgnored = *showIgnoredFlag
 	cfg.NoIgnore = *noIgnoreFlag
+	cfg.IncludeVendoredForked = *includeVendoredForkedFlag
+	cfg.RequireGoOnly = *requireGoOnlyFlag
 	cfg.Resolve = *resolveFlag
-	cfg.Deprecated = *deprecatedFlag
+	if *allowInsecureFlag {
+		insecureHosts = goInsecure()
+		if insecureHosts == "" {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: --allow-insecure-hosts has no effect: GOINSECURE is not set.\n")
+		}
+	}
+	preflightHosts = *preflightFlag
+	cfg.Deprecated = *deprecatedFlag || *deprecatedAllFlag
+	cfg.DeprecatedAll = *deprecatedAllFlag
 	cfg.Freshness = *freshnessFlag
+	cfg.UpgradePaths = *upgradePathsFlag
+	cfg.Remediations = *remediationsFlag
+	cfg.Actions = *actionsFlag
+	cfg.Dockerfiles = *dockerfilesFlag
+	cfg.LintVanity = *lintVanityFlag
+	cfg.Advisories = *advisoriesFlag
+	if *filterFlag != "" {
+		filters, err := parseFilters(*filterFlag)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		cfg.Filters = filters
+	}
+	if *policyFlag != "" {
+		rules, err := parsePolicyRules(*policyFlag)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		cfg.Policy = rules
+	}
 	cfg.Duration = durCfg
 	cfg.Stale = staleCfg
 	cfg.Age = ageCfg
 	cfg.ShowAll = *allFlag
 	cfg.Tree = *treeFlag
+	cfg.NoEmoji = *noEmojiFlag
+	cfg.SelectedOnly = *selectedOnlyFlag
 	cfg.Files = *filesFlag
 	cfg.Stats = *statsFlag
 	cfg.Workers = *workers
+	cfg.ProxyRPS = *proxyRPSFlag
+	if cfg.ProxyRPS < 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: invalid --proxy-rps %g (must be 0 or more)\n", cfg.ProxyRPS)
+		os.Exit(2)
+	}
+	proxyRPS = cfg.ProxyRPS
+	cfg.HostConcurrency = *hostConcurrencyFlag
+	cfg.HostRPS = *hostRPSFlag
+	if cfg.HostConcurrency < 0 || cfg.HostRPS < 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: invalid --host-concurrency %d or --host-rps %g (must be 0 or more)\n", cfg.HostConcurrency, cfg.HostRPS)
+		os.Exit(2)
+	}
+	hostConcurrency, hostRPS = cfg.HostConcurrency, cfg.HostRPS
 	cfg.GoVersion = *goVersionFlag
 	cfg.GoToolchain = goToolchainVersion()
 	cfg.Recursive = *recursiveFlag
+	cfg.Strict = *strictFlag
+	cfg.History = *historyFlag
+	cfg.Team = *teamFlag
+	cfg.Pushgateway = *pushgatewayFlag
+	cfg.RecheckArchived = *recheckArchivedFlag
+	if *archivedSkipDaysFlag < 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: invalid --archived-skip-days %d (must be 1 or more; --recheck-archived always re-queries)\n", *archivedSkipDaysFlag)
+		os.Exit(2)
+	}
+	cfg.ArchivedSkipDays = *archivedSkipDaysFlag
+	cfg.CacheTTL = *cacheTTLFlag
+	cfg.PhaseStats = *phaseStatsFlag
+	if *maxDurationFlag < 0 || *maxRequestsFlag < 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: invalid --max-duration %s or --max-requests %d (must be 0 or more)\n", *maxDurationFlag, *maxRequestsFlag)
+		os.Exit(2)
+	}
+	cfg.Budget = runBudget{start: time.Now(), MaxDuration: *maxDurationFlag, MaxRequests: int64(*maxRequestsFlag)}
+	cfg.Sign = *signFlag
+	cfg.Signature = *signatureFlag
+	if *remoteHostsFlag != "" {
+		for _, h := range strings.Split(*remoteHostsFlag, ",") {
+			if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+				cfg.Remote.Hosts = append(cfg.Remote.Hosts, h)
+			}
+		}
+	}
+	if *cloneDepthFlag < 1 || *cloneDepthFlag > maxCloneDepth {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --clone-depth must be between 1 and %d\n", maxCloneDepth)
+		os.Exit(2)
+	}
+	cfg.Remote.Depth = *cloneDepthFlag
+	cfg.Remote.Timeout = *cloneTimeoutFlag
+	if cfg.GoToolchain == "go (unknown)" {
+		warnDegraded(cfg, "go", "could not determine Go toolchain version (is go installed?)")
+	}
 
 	// Set date format
 	if *timeFlag {
@@ -225,12 +549,39 @@ Examples:
 	cfg.SortMode, cfg.SortReverse = parseSortFlag(*sortFlag)
 
 	// Initialize color support (auto-detects terminal, respects NO_COLOR)
-	// Disable color for non-table formats (JSON, markdown, mermaid, quickfix)
-	noColor := *noColorFlag || cfg.OutputFormat != "table"
+	// Disable color for non-table formats (JSON, markdown, mermaid, quickfix, plain)
+	noColor := *noColorFlag || (cfg.OutputFormat != "table" && cfg.OutputFormat != "heatmap")
 	if err := initColor(cfg, noColor, *colorThresholdFlag); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(2)
 	}
+	// The heatmap buckets ages by the color thresholds even without color
+	if cfg.OutputFormat == "heatmap" && len(cfg.Color.Thresholds) == 0 {
+		threshold := *colorThresholdFlag
+		if threshold == "" {
+			threshold = defaultColorThreshold
+		}
+		thresholds, err := parseColorThreshold(threshold)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		cfg.Color.Thresholds = thresholds
+	}
+
+	// Load the signing key up front so a bad key fails before the scan
+	if cfg.Sign != "" {
+		if cfg.OutputFormat != "json" {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: --sign requires --json\n")
+			os.Exit(2)
+		}
+		key, err := loadSigningKey(cfg.Sign)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		reportSigner = &signer{key: key, path: cfg.Signature}
+	}
 
 	return cfg
 }
@@ -249,80 +600,174 @@ func resolveInputPath() string {
 	return absPath
 }
 
+// goModFile returns path itself, or path/go.mod if path is a directory.
+func goModFile(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, "go.mod")
+	}
+	return path
+}
+
+// relToCwd returns path relative to the working directory, or path
+// unchanged if it cannot be made relative.
+func relToCwd(path string) string {
+	cwd, _ := os.Getwd()
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
 // runSingleModule runs the full pipeline for a single go.mod file.
 // Returns exit code: 0 = no archived deps, 1 = archived deps found, 2 = error.
 func runSingleModule(cfg *Config, inputPath string) int {
-	gomodPath := inputPath
-	if info, err := os.Stat(gomodPath); err == nil && info.IsDir() {
-		gomodPath = filepath.Join(gomodPath, "go.mod")
-	}
+	gomodPath := goModFile(inputPath)
+
+	ps := newPhaseStats(cfg)
+	defer ps.done()
 
 	allModules, err := ParseGoMod(gomodPath)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 2
 	}
+	applyTags(cfg.Tags, allModules)
+	ps.mark("parse", len(allModules))
 
 	// Print module header
 	modName, _ := ModuleName(gomodPath)
-	cwd, _ := os.Getwd()
-	relPath, relErr := filepath.Rel(cwd, gomodPath)
-	if relErr != nil {
-		relPath = gomodPath
-	}
+	relPath := relToCwd(gomodPath)
 	_, _ = fmt.Fprintf(os.Stderr, "=== %s — %s (%s) ===\n", relPath, modName, goToolchainVersion())
 
+	// All proxy and vanity-host requests go through one resolver, so each
+	// URL is fetched once no matter how many analyses need it.
+	proxy := newResolver()
+
 	// Resolve vanity imports to GitHub repos
 	if cfg.Resolve {
-		resolved := ResolveVanityImports(allModules, 20)
+		resolved := resolveVanityImportsWithResolver(allModules, 20, proxy)
 		if resolved > 0 {
 			_, _ = fmt.Fprintf(os.Stderr, "Resolved %d non-GitHub modules to GitHub repos.\n", resolved)
 		}
+		ps.mark("resolve", len(allModules))
 	}
 
-	// Check for deprecated modules via proxy
+	// Check direct deps for deprecation up front; indirect deps are checked
+	// after the GitHub query, once we know which are archived or stale.
+	deprecatedCount := 0
+	if cfg.Deprecated && !budgetAllows(cfg, "deprecation checks", budgetShareDeprecated) {
+		cfg.Deprecated = false
+	}
 	if cfg.Deprecated {
-		count := CheckDeprecations(allModules, 20)
-		if count > 0 {
-			_, _ = fmt.Fprintf(os.Stderr, "Found %d deprecated %s.\n", count, pluralize(count, "module", "modules"))
-		}
+		deprecatedCount = checkDeprecationsSelected(allModules, 20, proxy, deprecationTier1(cfg))
 	}
 
 	// Filter to GitHub modules and deduplicate
-	githubModules, nonGitHubModules := FilterGitHub(allModules, cfg.DirectOnly)
+	checked, internalForks := skipInternalForks(cfg, allModules)
+	reportInternalForks(internalForks)
+	githubModules, nonGitHubModules := FilterGitHub(checked, cfg.DirectOnly)
+	githubModules = filterModules(cfg, githubModules)
+	nonGitHubModules = filterModules(cfg, nonGitHubModules)
+	addFailFastRepos(cfg, gomodPath, githubModules)
+
+	// Proxy enrichment is the first analysis a --max-duration or
+	// --max-requests budget gives up
+	freshness := cfg.Freshness || cfg.Age.Enabled || cfg.OutputFormat == "heatmap"
+	enrich := (len(nonGitHubModules) > 0 || freshness) && budgetAllows(cfg, "proxy enrichment", budgetShareEnrichment)
 
 	// Enrich non-GitHub modules with proxy data
-	if len(nonGitHubModules) > 0 {
-		EnrichNonGitHub(nonGitHubModules, 20)
+	if enrich && len(nonGitHubModules) > 0 {
+		enrichNonGitHubWithResolver(nonGitHubModules, 20, proxy)
 	}
 
 	// Enrich all modules with version data (skips already-enriched)
-	if cfg.Freshness || cfg.Age.Enabled {
-		EnrichFreshness(allModules, 20)
+	if enrich && freshness {
+		enrichFreshnessWithResolver(allModules, 20, proxy)
 	}
 
+	// Flag modules whose newer major version lives at a new module path
+	if enrich && cfg.Freshness {
+		if n := detectNewerMajorsWithResolver(allModules, 20, proxy); n > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "Found %d %s with a newer major version.\n", n, pluralize(n, "module", "modules"))
+			copyNewerMajors(githubModules, allModules)
+			copyNewerMajors(nonGitHubModules, allModules)
+		}
+	}
+	ps.mark("enrich", len(allModules))
+
 	if len(githubModules) == 0 {
-		_, _ = fmt.Fprintf(os.Stderr, "No GitHub modules found in %s\n", gomodPath)
+		if len(cfg.Filters) > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "No GitHub modules in %s match --filter %s\n", gomodPath, filterString(cfg.Filters))
+		} else {
+			_, _ = fmt.Fprintf(os.Stderr, "No GitHub modules found in %s\n", gomodPath)
+		}
+		cfg.Summary.add(modName, relPath, nil)
+		cfg.Summary.observe(modName, relPath, allModules, nil)
 		return 0
 	}
 
 	_, _ = fmt.Fprintf(os.Stderr, "Checking %d GitHub modules...\n", len(githubModules))
 
 	// Query GitHub
-	results, err := CheckRepos(githubModules, cfg.Workers)
+	results, err := CheckReposCached(cfg, githubModules)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", budgetError(cfg, err))
 		return 2
 	}
+	applyDisabledMode(cfg, results)
+	triageNotFound(cfg, results, proxy)
+	if found := failFastFindings(cfg, results); len(found) > 0 {
+		return failFast(cfg, found, len(results), len(githubModules))
+	}
+	cfg.Summary.observe(modName, relPath, allModules, results)
 
 	// Apply ignore list
 	results, ignoredResults, ignoreList := applyIgnoreList(cfg, results, gomodPath)
 
+	// Check the repositories of the actions the workflows use
+	var actions []actionFinding
+	if cfg.Actions {
+		actions = checkWorkflowActions(cfg, filepath.Dir(gomodPath))
+	}
+
+	// Check the source repositories of the images the Dockerfiles use
+	var images []imageFinding
+	if cfg.Dockerfiles {
+		images = checkDockerfileImages(cfg, filepath.Dir(gomodPath))
+	}
+
+	// Check that vanity import hosts still serve their go-import tags
+	var vanity []vanityFinding
+	if cfg.LintVanity {
+		vanity = lintVanityHosts(cfg, filterModules(cfg, allModules), proxy)
+	}
+
+	// Set aside archived modules the project has forked inside vendor/
+	results, vendoredForked := splitVendoredForked(cfg, results, filepath.Dir(gomodPath))
+
+	// Fetch the repository topics/properties --policy rules check
+	fetchPolicyMetadata(cfg, results)
+
+	// Fetch last releases and post-release advisories for --advisories
+	fetchAdvisories(cfg, results)
+
+	// Classify archived modules whose every version is retracted
+	if cfg.Deprecated && budgetAllows(cfg, "deprecation checks", budgetShareDeprecated) {
+		reportRetired(detectRetiredWithResolver(results, 20, proxy))
+	}
+
+	cfg.Summary.add(modName, relPath, results)
+	ps.mark("github", len(results))
+
 	// Collect archived module paths
 	hasArchived, archivedModulePaths := findArchived(results)
 
 	// Scan source files for imports of archived modules
 	var fileMatches map[string][]FileMatch
+	if cfg.Files && hasArchived && !budgetAllows(cfg, "--files source scanning", budgetShareFiles) {
+		cfg.Files = false
+	}
 	if cfg.Files && hasArchived {
 		fm, scanErr := ScanImports(filepath.Dir(gomodPath), archivedModulePaths)
 		if scanErr != nil {
@@ -332,27 +777,94 @@ func runSingleModule(cfg *Config, inputPath string) int {
 		fileMatches = fm
 	}
 
-	// Collect deprecated modules for output
-	deprecatedModules := collectDeprecated(cfg, allModules)
-
 	// Filter stale modules (non-archived repos with old push dates)
 	stale := filterStale(cfg, results)
 
-	// Handle --tree mode
-	if cfg.Tree && hasArchived {
-		graph, graphErr := parseModGraph(filepath.Dir(gomodPath), cfg.GoVersion)
+	// Second deprecation pass: indirect deps that are archived or stale
+	if cfg.Deprecated {
+		if include := deprecationTier2(cfg, results, stale); include != nil && budgetAllows(cfg, "deprecation checks", budgetShareDeprecated) {
+			deprecatedCount += checkDeprecationsSelected(allModules, 20, proxy, include)
+		}
+		if deprecatedCount > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "Found %d deprecated %s.\n", deprecatedCount, pluralize(deprecatedCount, "module", "modules"))
+		}
+	}
+
+	// Collect deprecated modules for output
+	deprecatedModules := collectDeprecated(cfg, allModules)
+	cfg.Summary.addDeprecated(deprecatedModules)
+
+	// The module graph is needed for --tree, --upgrade-paths, --remediations,
+	// and the dependency chains in --files
+	var graph map[string][]string
+	if (cfg.Tree || cfg.UpgradePaths || cfg.Remediations || cfg.Files) && hasArchived {
+		g, graphErr := loadModGraph(cfg, gomodPath)
 		if graphErr != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Warning: could not run go mod graph: %v\n", graphErr)
+			warnDegraded(cfg, "go mod graph", "could not run go mod graph: %v", graphErr)
 		} else {
-			outputTree(cfg, results, graph, allModules, fileMatches, nonGitHubModules, deprecatedModules, stale, ignoredResults, ignoreList)
-			return exitCode(hasArchived)
+			graph = g
 		}
 	}
 
+	extras := &runExtras{
+		relDir:          filepath.Dir(relPath),
+		vendoredForked:  vendoredForked,
+		policy:          evaluatePolicy(cfg.Policy, results),
+		tags:            buildTagBreakdown(cfg, results, stale, deprecatedModules),
+		otherEcosystems: detectOtherEcosystems(cfg, filepath.Dir(gomodPath)),
+		actions:         actions,
+		images:          images,
+		vanity:          vanity,
+	}
+	if cfg.Files && graph != nil {
+		extras.via = archivedVia(results, graph, allModules)
+	}
+	var upgrades []UpgradeFinding
+	if (cfg.UpgradePaths || cfg.Remediations) && graph != nil {
+		upgrades = analyzeUpgrades(results, graph, allModules, proxy, 20)
+	}
+	if cfg.UpgradePaths && graph != nil {
+		extras.upgrades = upgrades
+		if extras.upgrades == nil {
+			extras.upgrades = []UpgradeFinding{}
+		}
+	}
+	if cfg.Remediations && hasArchived {
+		unused := findUnusedArchived(cfg, gomodPath, results, allModules, fileMatches)
+		extras.remediations = planRemediations(results, allModules, upgrades, unused)
+	}
+
+	ps.mark("analysis", len(results))
+
+	// Handle --tree mode
+	if cfg.Tree && graph != nil {
+		outputTree(cfg, results, graph, allModules, fileMatches, nonGitHubModules, deprecatedModules, stale, ignoredResults, ignoreList, extras)
+		ps.mark("output", len(results))
+		return exitCode(hasArchived || len(actions) > 0 || len(images) > 0)
+	}
+
 	// Output
-	outputFlat(cfg, results, nonGitHubModules, fileMatches, deprecatedModules, stale, ignoredResults, ignoreList)
+	outputFlat(cfg, results, nonGitHubModules, fileMatches, deprecatedModules, stale, ignoredResults, ignoreList, extras)
+	ps.mark("output", len(results))
 
-	return exitCode(hasArchived)
+	return exitCode(hasArchived || len(actions) > 0 || len(images) > 0)
+}
+
+// runExtras carries run context and the results of optional analyses from
+// runSingleModule to the output layer. An analysis field is nil when the
+// analysis was not run.
+type runExtras struct {
+	relDir          string // module directory relative to the working directory
+	vendoredForked  []RepoStatus
+	upgrades        []UpgradeFinding
+	remediations    []Remediation
+	policy          []PolicyViolation
+	tags            []tagBreakdown // findings per criticality tag (tags in .modrot.yaml)
+	otherEcosystems []ecosystemManifest
+	actions         []actionFinding     // archived GitHub Actions (--actions)
+	images          []imageFinding      // archived repos behind Dockerfile images (--dockerfiles)
+	vanity          []vanityFinding     // dependencies on dead vanity hosts (--lint-vanity)
+	via             map[string][]string // archived module path → direct deps pulling it in (--files)
 }
 
 // applyIgnoreList builds and applies the ignore list, returning filtered results.
@@ -368,7 +880,7 @@ func applyIgnoreList(cfg *Config, results []RepoStatus, gomodPath string) ([]Rep
 		ignoreFilePath = filepath.Join(filepath.Dir(gomodPath), ".modrotignore")
 	}
 	if il, err := LoadIgnoreFile(ignoreFilePath); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Warning: could not read ignore file: %v\n", err)
+		warnDegraded(cfg, "ignore file", "could not read ignore file: %v", err)
 	} else {
 		for p, reason := range il.paths {
 			ignoreList.AddWithReason(p, reason)
@@ -408,7 +920,7 @@ func collectDeprecated(cfg *Config, allModules []Module) []Module {
 	var deprecated []Module
 	for _, m := range allModules {
 		if m.Deprecated != "" {
-			if cfg.DirectOnly && !m.Direct {
+			if (cfg.DirectOnly && !m.Direct) || !matchesFilters(cfg.Filters, m) {
 				continue
 			}
 			deprecated = append(deprecated, m)
@@ -420,18 +932,30 @@ func collectDeprecated(cfg *Config, allModules []Module) []Module {
 // outputTree dispatches tree-mode output to the appropriate format.
 func outputTree(cfg *Config, results []RepoStatus, graph map[string][]string, allModules []Module,
 	fileMatches map[string][]FileMatch, nonGitHubModules []Module, deprecatedModules []Module,
-	stale []RepoStatus, ignoredResults []RepoStatus, ignoreList *IgnoreList) {
+	stale []RepoStatus, ignoredResults []RepoStatus, ignoreList *IgnoreList, extras *runExtras) {
 
 	switch cfg.OutputFormat {
 	case "mermaid":
 		PrintMermaid(cfg, results, graph, allModules)
+	case "heatmap":
+		PrintHeatmap(cfg, results, nonGitHubModules)
 	case "json":
-		PrintTreeJSON(cfg, results, graph, allModules, fileMatches, nonGitHubModules, deprecatedModules)
+		out := buildTreeJSONOutput(cfg, results, graph, allModules, fileMatches, nonGitHubModules, deprecatedModules)
+		out.Errors = strictErrors(cfg)
+		out.VendoredForked = buildVendoredForkedJSON(extras.vendoredForked)
+		out.Upgrades = buildJSONUpgrades(extras.upgrades)
+		out.Remediations = buildRemediationJSON(extras.remediations)
+		out.PolicyWarnings = buildPolicyJSON(extras.policy)
+		out.ByTag = buildTagsJSON(extras.tags)
+		out.OtherEcosystems = extras.otherEcosystems
+		out.ArchivedActions = buildActionsJSON(extras.actions)
+		out.ArchivedImages = buildImagesJSON(extras.images)
+		out.DeadVanityHosts = buildVanityJSON(extras.vanity)
+		writeJSON(out)
 	case "markdown":
 		PrintMarkdownTree(cfg, results, graph, allModules, fileMatches)
-		if len(stale) > 0 {
-			PrintMarkdownStale(cfg, stale)
-		}
+		PrintMarkdownUnknown(results)
+		printMarkdownSections(cfg, results, stale, extras)
 		if len(deprecatedModules) > 0 {
 			PrintMarkdown(cfg, nil, nil, deprecatedModules)
 		}
@@ -440,9 +964,8 @@ func outputTree(cfg *Config, results []RepoStatus, graph map[string][]string, al
 		}
 	default:
 		PrintTree(cfg, results, graph, allModules, fileMatches)
-		if len(stale) > 0 {
-			PrintStaleTable(cfg, stale)
-		}
+		PrintUnknownTable(results)
+		printSections(cfg, results, stale, extras)
 		if len(deprecatedModules) > 0 {
 			PrintDeprecatedTable(deprecatedModules)
 		}
@@ -456,35 +979,91 @@ func outputTree(cfg *Config, results []RepoStatus, graph map[string][]string, al
 // outputFlat dispatches non-tree output to the appropriate format.
 func outputFlat(cfg *Config, results []RepoStatus, nonGitHubModules []Module,
 	fileMatches map[string][]FileMatch, deprecatedModules []Module,
-	stale []RepoStatus, ignoredResults []RepoStatus, ignoreList *IgnoreList) {
+	stale []RepoStatus, ignoredResults []RepoStatus, ignoreList *IgnoreList, extras *runExtras) {
 
 	switch cfg.OutputFormat {
 	case "quickfix":
 		if fileMatches != nil {
 			PrintFilesPlain(results, fileMatches)
 		}
+	case "plain":
+		if fileMatches != nil {
+			PrintProblems(cfg, extras.relDir, results, fileMatches)
+		}
+	case "heatmap":
+		PrintHeatmap(cfg, results, nonGitHubModules)
 	case "json":
-		PrintJSON(cfg, results, nonGitHubModules, fileMatches, stale, deprecatedModules)
+		out := buildJSONOutput(cfg, results, nonGitHubModules, fileMatches, stale, deprecatedModules)
+		out.Errors = strictErrors(cfg)
+		setJSONVia(out.Archived, extras.via)
+		out.VendoredForked = buildVendoredForkedJSON(extras.vendoredForked)
+		out.Upgrades = buildJSONUpgrades(extras.upgrades)
+		out.Remediations = buildRemediationJSON(extras.remediations)
+		out.PolicyWarnings = buildPolicyJSON(extras.policy)
+		out.ByTag = buildTagsJSON(extras.tags)
+		out.OtherEcosystems = extras.otherEcosystems
+		out.ArchivedActions = buildActionsJSON(extras.actions)
+		out.ArchivedImages = buildImagesJSON(extras.images)
+		out.DeadVanityHosts = buildVanityJSON(extras.vanity)
+		writeJSON(out)
 	case "markdown":
 		PrintMarkdown(cfg, results, nonGitHubModules, deprecatedModules)
 		if fileMatches != nil {
-			PrintMarkdownFiles(results, fileMatches)
-		}
-		if len(stale) > 0 {
-			PrintMarkdownStale(cfg, stale)
+			PrintMarkdownFiles(results, fileMatches, extras.via)
 		}
+		printMarkdownSections(cfg, results, stale, extras)
 	default:
 		PrintTable(cfg, results, nonGitHubModules, deprecatedModules)
 		if fileMatches != nil {
-			PrintFiles(results, fileMatches)
-		}
-		if len(stale) > 0 {
-			PrintStaleTable(cfg, stale)
+			PrintFiles(results, fileMatches, extras.via)
 		}
+		printSections(cfg, results, stale, extras)
 	}
 	outputSupplement(cfg, results, nonGitHubModules, stale, deprecatedModules, ignoredResults, ignoreList)
 }
 
+// printSections prints the text sections that follow a project's main table
+// or tree. Single-module and recursive output share it so both report the
+// same findings.
+func printSections(cfg *Config, results, stale []RepoStatus, extras *runExtras) {
+	if len(stale) > 0 {
+		PrintStaleTable(cfg, stale)
+	}
+	PrintVendoredForkedTable(cfg, extras.vendoredForked)
+	PrintPolicyTable(extras.policy)
+	PrintTagTable(extras.tags)
+	PrintAdvisoryTable(cfg, results)
+	PrintRetiredTable(results)
+	PrintOtherEcosystemsTable(extras.otherEcosystems)
+	PrintActionsTable(cfg, extras.actions)
+	PrintImagesTable(cfg, extras.images)
+	PrintVanityTable(extras.vanity)
+	if extras.upgrades != nil {
+		PrintUpgradeTable(extras.upgrades)
+	}
+	PrintRemediationTable(extras.remediations)
+}
+
+// printMarkdownSections is printSections for Markdown output.
+func printMarkdownSections(cfg *Config, results, stale []RepoStatus, extras *runExtras) {
+	if len(stale) > 0 {
+		PrintMarkdownStale(cfg, stale)
+	}
+	PrintMarkdownVendoredForked(cfg, extras.vendoredForked)
+	PrintMarkdownPolicy(extras.policy)
+	PrintMarkdownTags(extras.tags)
+	PrintMarkdownAdvisories(cfg, results)
+	PrintMarkdownRetired(results)
+	PrintMarkdownOtherEcosystems(extras.otherEcosystems)
+	PrintMarkdownActions(cfg, extras.actions)
+	PrintMarkdownImages(cfg, extras.images)
+	PrintMarkdownVanity(extras.vanity)
+	if extras.upgrades != nil {
+		PrintMarkdownUpgrades(extras.upgrades)
+	}
+	PrintMarkdownRemediations(extras.remediations)
+}
+
 // outputSupplement prints age, ignored, and stats sections common to all formats.
 func outputSupplement(cfg *Config, results []RepoStatus, nonGitHubModules []Module,
 	stale []RepoStatus, deprecatedModules []Module, ignoredResults []RepoStatus, ignoreList *IgnoreList) {
@@ -511,12 +1090,34 @@ func exitCode(hasArchived bool) int {
 // valueFlagNames lists flags that take a value argument (not boolean).
 var valueFlagNames = map[string]bool{
 	"-workers": true, "--workers": true,
+	"-proxy-rps": true, "--proxy-rps": true,
+	"-host-concurrency": true, "--host-concurrency": true,
+	"-host-rps": true, "--host-rps": true,
 	"-go-version": true, "--go-version": true,
 	"-sort": true, "--sort": true,
 	"-ignore-file": true, "--ignore-file": true,
 	"-ignore": true, "--ignore": true,
 	"-format": true, "--format": true,
 	"-color-threshold": true, "--color-threshold": true,
+	"-history": true, "--history": true,
+	"-team": true, "--team": true,
+	"-policy": true, "--policy": true,
+	"-filter": true, "--filter": true,
+	"-pushgateway": true, "--pushgateway": true,
+	"-sign": true, "--sign": true,
+	"-signature": true, "--signature": true,
+	"-remote-hosts": true, "--remote-hosts": true,
+	"-clone-depth": true, "--clone-depth": true,
+	"-clone-timeout": true, "--clone-timeout": true,
+	"-cache-ttl": true, "--cache-ttl": true,
+	"-archived-skip-days": true, "--archived-skip-days": true,
+	"-fail-on": true, "--fail-on": true,
+	"-disabled-repos": true, "--disabled-repos": true,
+	"-unknown-repos": true, "--unknown-repos": true,
+	"-token-env": true, "--token-env": true,
+	"-endpoints-from": true, "--endpoints-from": true,
+	"-max-duration": true, "--max-duration": true,
+	"-max-requests": true, "--max-requests": true,
 }
 
 // reorderArgs moves flags after positional arguments to before them,
@@ -660,7 +1261,7 @@ func extractAgeFlag() AgeConfig {
 // a map of parent → []child (both as "module@version" strings).
 // If goVersion is non-empty, GOTOOLCHAIN is set to force that Go version.
 func parseModGraph(dir string, goVersion string) (map[string][]string, error) {
-	cmd := exec.Command("go", "mod", "graph")
+	cmd := exec.CommandContext(runCtx, "go", "mod", "graph")
 	cmd.Dir = dir
 	if goVersion != "" {
 		cmd.Env = append(os.Environ(), "GOTOOLCHAIN=go"+goVersion)
@@ -678,7 +1279,7 @@ func parseModGraph(dir string, goVersion string) (map[string][]string, error) {
 		if len(parts) != 2 {
 			continue
 		}
-		parent, child := parts[0], parts[1]
+		parent, child := canonicalGraphNode(parts[0]), canonicalGraphNode(parts[1])
 		graph[parent] = append(graph[parent], child)
 	}
 	return graph, scanner.Err()
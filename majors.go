@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// maxMajorProbe bounds how many successive major versions are probed past
+// the pinned one when looking for the newest major.
+const maxMajorProbe = 10
+
+// nextMajorPath returns the module path of the major version after the one
+// modulePath names:
+//
+//	github.com/foo/bar     → github.com/foo/bar/v2
+//	github.com/foo/bar/v2  → github.com/foo/bar/v3
+//	gopkg.in/yaml.v2       → gopkg.in/yaml.v3
+//
+// Returns "" if modulePath has no recognizable major-version form.
+func nextMajorPath(modulePath string) string {
+	prefix, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return ""
+	}
+	if pathMajor == "" {
+		if strings.HasPrefix(modulePath, "gopkg.in/") {
+			return ""
+		}
+		return prefix + "/v2"
+	}
+	sep := pathMajor[:2] // "/v" or ".v"
+	n, err := strconv.Atoi(strings.TrimSuffix(pathMajor[2:], "-unstable"))
+	if err != nil {
+		return ""
+	}
+	return prefix + sep + strconv.Itoa(max(n, 1)+1)
+}
+
+// fetchNewerMajor probes the proxy for successive major versions after the
+// one modulePath names and returns the newest that exists, with its latest
+// version. Returns "", "" if no newer major is published.
+func (r *resolver) fetchNewerMajor(modulePath string) (path, version string) {
+	cur := modulePath
+	for range maxMajorProbe {
+		next := nextMajorPath(cur)
+		if next == "" {
+			break
+		}
+		latest, _, _ := r.fetchLatestInfo(next)
+		if latest == "" {
+			break
+		}
+		path, version, cur = next, latest, next
+	}
+	return path, version
+}
+
+// detectNewerMajorsWithResolver sets NewerMajorPath and NewerMajorVersion on
+// every module for which the proxy publishes a newer major version module
+// path than the one pinned (v1 pinned, /v2 exists). Returns the count found.
+func detectNewerMajorsWithResolver(modules []Module, maxWorkers int, r *resolver) int {
+	type result struct{ path, version string }
+	results, _ := mapPool(context.Background(), modules, poolOptions{Workers: maxWorkers}, func(_ context.Context, m Module) (result, error) {
+		path, version := r.fetchNewerMajor(m.Path)
+		return result{path, version}, nil
+	})
+
+	count := 0
+	for i, res := range results {
+		if res.path != "" {
+			modules[i].NewerMajorPath = res.path
+			modules[i].NewerMajorVersion = res.version
+			count++
+		}
+	}
+	return count
+}
+
+// detectNewerMajorsAcrossModulesWithResolver is the recursive-mode
+// counterpart of detectNewerMajorsWithResolver, probing each module path once.
+func detectNewerMajorsAcrossModulesWithResolver(modules []moduleInfo, r *resolver) int {
+	type location struct {
+		miIdx  int
+		modIdx int
+	}
+	pathLocations := make(map[string][]location)
+	for i := range modules {
+		for j, m := range modules[i].allModules {
+			pathLocations[m.Path] = append(pathLocations[m.Path], location{miIdx: i, modIdx: j})
+		}
+	}
+	paths := make([]string, 0, len(pathLocations))
+	for p := range pathLocations {
+		paths = append(paths, p)
+	}
+
+	type result struct{ path, version string }
+	const maxWorkers = 20
+	results, _ := mapPool(context.Background(), paths, poolOptions{Workers: maxWorkers}, func(_ context.Context, p string) (result, error) {
+		path, version := r.fetchNewerMajor(p)
+		return result{path, version}, nil
+	})
+
+	count := 0
+	for j, res := range results {
+		if res.path == "" {
+			continue
+		}
+		for _, loc := range pathLocations[paths[j]] {
+			m := &modules[loc.miIdx].allModules[loc.modIdx]
+			m.NewerMajorPath = res.path
+			m.NewerMajorVersion = res.version
+		}
+		count++
+	}
+	return count
+}
+
+// majorUpgradeMarker returns the table marker for a module with a newer
+// major version, or "" if there is none.
+func majorUpgradeMarker(m Module) string {
+	if m.NewerMajorPath == "" {
+		return ""
+	}
+	return fmt.Sprintf("[MAJOR UPGRADE AVAILABLE: %s]", m.NewerMajorVersion)
+}
+
+// JSONMajorUpgrade is the newest major version module path of a dependency
+// in JSON output.
+type JSONMajorUpgrade = report.MajorUpgrade
+
+// buildMajorUpgradeJSON returns the JSON major_upgrade field for m, or nil.
+func buildMajorUpgradeJSON(m Module) *JSONMajorUpgrade {
+	if m.NewerMajorPath == "" {
+		return nil
+	}
+	return &JSONMajorUpgrade{Module: m.NewerMajorPath, Version: m.NewerMajorVersion}
+}
+
+// copyNewerMajors copies newer-major findings from src onto the modules in
+// dst with the same path. dst holds copies made before detection ran (the
+// GitHub/non-GitHub split), so they need the findings carried over.
+func copyNewerMajors(dst, src []Module) {
+	byPath := make(map[string]Module)
+	for _, m := range src {
+		if m.NewerMajorPath != "" {
+			byPath[m.Path] = m
+		}
+	}
+	for i := range dst {
+		if m, ok := byPath[dst[i].Path]; ok {
+			dst[i].NewerMajorPath = m.NewerMajorPath
+			dst[i].NewerMajorVersion = m.NewerMajorVersion
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNextMajorPath(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"github.com/foo/bar", "github.com/foo/bar/v2"},
+		{"github.com/foo/bar/v2", "github.com/foo/bar/v3"},
+		{"github.com/foo/bar/v9", "github.com/foo/bar/v10"},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml.v3"},
+		{"gopkg.in/check.v1", "gopkg.in/check.v2"},
+		{"gopkg.in/foo.v0", "gopkg.in/foo.v2"},
+		{"gopkg.in/nomajor", ""},
+	}
+	for _, tt := range tests {
+		if got := nextMajorPath(tt.in); got != tt.want {
+			t.Errorf("nextMajorPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// newMajorProxy serves @latest for the given module paths.
+func newMajorProxy(t *testing.T, latest map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/@latest"), "/")
+		v, ok := latest[path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"Version": v})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDetectNewerMajors(t *testing.T) {
+	srv := newMajorProxy(t, map[string]string{
+		"github.com/foo/bar/v2": "v2.5.0",
+		"github.com/foo/bar/v3": "v3.1.0",
+		"gopkg.in/yaml.v3":      "v3.0.1",
+	})
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+
+	modules := []Module{
+		{Path: "github.com/foo/bar", Version: "v1.2.0"},
+		{Path: "github.com/foo/bar/v2", Version: "v2.0.0"},
+		{Path: "github.com/foo/bar/v3", Version: "v3.1.0"},
+		{Path: "gopkg.in/yaml.v2", Version: "v2.4.0"},
+		{Path: "github.com/solo/lib", Version: "v1.0.0"},
+	}
+	if n := detectNewerMajorsWithResolver(modules, 4, r); n != 3 {
+		t.Errorf("found %d newer majors, want 3", n)
+	}
+
+	want := []struct{ path, version string }{
+		{"github.com/foo/bar/v3", "v3.1.0"}, // probes past v2 to the newest
+		{"github.com/foo/bar/v3", "v3.1.0"},
+		{"", ""},
+		{"gopkg.in/yaml.v3", "v3.0.1"},
+		{"", ""},
+	}
+	for i, w := range want {
+		if modules[i].NewerMajorPath != w.path || modules[i].NewerMajorVersion != w.version {
+			t.Errorf("%s: newer major = %q %q, want %q %q", modules[i].Path,
+				modules[i].NewerMajorPath, modules[i].NewerMajorVersion, w.path, w.version)
+		}
+	}
+}
+
+func TestDetectNewerMajorsAcrossModules(t *testing.T) {
+	srv := newMajorProxy(t, map[string]string{"github.com/foo/bar/v2": "v2.0.0"})
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+
+	modules := []moduleInfo{
+		{allModules: []Module{{Path: "github.com/foo/bar", Version: "v1.0.0"}}},
+		{allModules: []Module{{Path: "github.com/foo/bar", Version: "v1.1.0"}, {Path: "github.com/x/y", Version: "v1.0.0"}}},
+	}
+	if n := detectNewerMajorsAcrossModulesWithResolver(modules, r); n != 1 {
+		t.Errorf("found %d newer majors, want 1", n)
+	}
+	for _, mi := range modules {
+		if mi.allModules[0].NewerMajorPath != "github.com/foo/bar/v2" {
+			t.Errorf("NewerMajorPath = %q, want github.com/foo/bar/v2", mi.allModules[0].NewerMajorPath)
+		}
+	}
+	if modules[1].allModules[1].NewerMajorPath != "" {
+		t.Error("github.com/x/y should have no newer major")
+	}
+}
+
+func TestLatestOrDash_MajorUpgrade(t *testing.T) {
+	tests := []struct {
+		m    Module
+		want string
+	}{
+		{Module{Version: "v1.0.0", LatestVersion: "v1.2.0"}, "v1.2.0"},
+		{Module{Version: "v1.2.0", LatestVersion: "v1.2.0"}, "-"},
+		{Module{Version: "v1.2.0", LatestVersion: "v1.2.0", NewerMajorPath: "x/v2", NewerMajorVersion: "v2.0.0"},
+			"- [MAJOR UPGRADE AVAILABLE: v2.0.0]"},
+		{Module{Version: "v1.0.0", NewerMajorPath: "x/v2", NewerMajorVersion: "v2.0.0"},
+			"[MAJOR UPGRADE AVAILABLE: v2.0.0]"},
+	}
+	for _, tt := range tests {
+		if got := latestOrDash(tt.m); got != tt.want {
+			t.Errorf("latestOrDash(%+v) = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestCopyNewerMajors(t *testing.T) {
+	src := []Module{{Path: "a", NewerMajorPath: "a/v2", NewerMajorVersion: "v2.0.0"}, {Path: "b"}}
+	dst := []Module{{Path: "b"}, {Path: "a"}}
+	copyNewerMajors(dst, src)
+	if dst[1].NewerMajorPath != "a/v2" || dst[0].NewerMajorPath != "" {
+		t.Errorf("copyNewerMajors = %+v", dst)
+	}
+}
+
+func TestBuildJSONOutput_MajorUpgrade(t *testing.T) {
+	cfg := &Config{Freshness: true}
+	m := Module{Path: "github.com/foo/bar", Version: "v1.0.0", Owner: "foo", Repo: "bar",
+		NewerMajorPath: "github.com/foo/bar/v2", NewerMajorVersion: "v2.1.0"}
+	out := buildJSONOutput(cfg, []RepoStatus{{Module: m, IsArchived: true}}, nil, nil, nil)
+	got := out.Archived[0].MajorUpgrade
+	if got == nil || got.Module != "github.com/foo/bar/v2" || got.Version != "v2.1.0" {
+		t.Errorf("major_upgrade = %+v", got)
+	}
+}
@@ -26,9 +26,13 @@ func printMarkdownTable(w io.Writer, headers []string, rows [][]string) {
 
 // PrintMarkdown outputs results in GitHub-flavored Markdown format.
 func PrintMarkdown(cfg *Config, results []RepoStatus, nonGitHubModules []Module, deprecatedModules ...[]Module) {
-	var archived, notFound, active []RepoStatus
+	var disabled, archived, notFound, active []RepoStatus
 	for _, r := range results {
 		switch {
+		case r.Disabled:
+			disabled = append(disabled, r)
+		case r.Unknown:
+			// listed by PrintUnknown*
 		case r.NotFound:
 			notFound = append(notFound, r)
 		case r.IsArchived:
@@ -52,6 +56,8 @@ func PrintMarkdown(cfg *Config, results []RepoStatus, nonGitHubModules []Module,
 
 	totalChecked := len(results)
 
+	PrintMarkdownDisabled(splitDisabled(disabled))
+
 	if len(archived) > 0 {
 		_, _ = fmt.Fprintf(os.Stdout, "## ARCHIVED DEPENDENCIES (%d of %d github.com modules)\n\n", len(archived), totalChecked)
 		headers := archivedHeaders(cfg)
@@ -74,13 +80,17 @@ func PrintMarkdown(cfg *Config, results []RepoStatus, nonGitHubModules []Module,
 	} else {
 		_, _ = fmt.Fprintf(os.Stdout, "No archived dependencies found among %d github.com modules.\n", totalChecked)
 	}
+	if totalChecked > 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "\n%s\n", computeRepoTotals(results))
+	}
 
 	if len(notFound) > 0 {
 		_, _ = fmt.Fprintf(os.Stdout, "\n## NOT FOUND (%d modules)\n\n", len(notFound))
 		for _, r := range notFound {
-			_, _ = fmt.Fprintf(os.Stdout, "- %s — %s\n", r.Module.Path, r.Error)
+			_, _ = fmt.Fprintf(os.Stdout, "- %s — %s\n", r.Module.Path, notFoundHint(r))
 		}
 	}
+	PrintMarkdownUnknown(results)
 
 	if cfg.ShowAll && len(active) > 0 {
 		_, _ = fmt.Fprintf(os.Stdout, "\n## ACTIVE DEPENDENCIES (%d modules)\n\n", len(active))
@@ -150,27 +160,24 @@ func PrintMarkdownSkipped(cfg *Config, modules []Module) {
 	printMarkdownTable(os.Stdout, headers, rows)
 }
 
-// PrintMarkdownFiles outputs source file matches in Markdown format.
-func PrintMarkdownFiles(results []RepoStatus, fileMatches map[string][]FileMatch) {
-	var archivedPaths []string
-	for _, r := range results {
-		if r.IsArchived {
-			archivedPaths = append(archivedPaths, r.Module.Path)
-		}
-	}
-	sort.Strings(archivedPaths)
-
+// PrintMarkdownFiles outputs source file matches in Markdown format. via is
+// as for PrintFiles.
+func PrintMarkdownFiles(results []RepoStatus, fileMatches map[string][]FileMatch, via map[string][]string) {
 	_, _ = fmt.Fprintf(os.Stdout, "\n## SOURCE FILES IMPORTING ARCHIVED MODULES\n")
 
-	for _, modPath := range archivedPaths {
-		matches := fileMatches[modPath]
+	for _, r := range sortedArchived(results) {
+		matches := fileMatches[r.Module.Path]
 		uniqueFiles := make(map[string]bool)
 		for _, m := range matches {
 			uniqueFiles[m.File] = true
 		}
-		_, _ = fmt.Fprintf(os.Stdout, "\n### %s (%d %s)\n\n", modPath, len(uniqueFiles), pluralize(len(uniqueFiles), "file", "files"))
+		_, _ = fmt.Fprintf(os.Stdout, "\n### %s\n\n", fileChainLabel(r, len(uniqueFiles), via))
 		for _, m := range matches {
-			_, _ = fmt.Fprintf(os.Stdout, "- `%s:%d`\n", m.File, m.Line)
+			if m.UsageKind == usageTypeOnly {
+				_, _ = fmt.Fprintf(os.Stdout, "- `%s:%d` (type-only)\n", m.File, m.Line)
+			} else {
+				_, _ = fmt.Fprintf(os.Stdout, "- `%s:%d`\n", m.File, m.Line)
+			}
 		}
 	}
 }
@@ -207,7 +214,7 @@ func PrintMarkdownTree(cfg *Config, results []RepoStatus, graph map[string][]str
 	for _, e := range entries {
 		if ctx.archivedPaths[e.directPath] {
 			if rs, ok := ctx.getStatus(e.directPath); ok {
-				_, _ = fmt.Fprintf(os.Stdout, "- **%s** `[ARCHIVED %s]`", formatTreeLabel(e.directPath, ctx.versionByPath[e.directPath]), fmtDate(cfg, rs.ArchivedAt))
+				_, _ = fmt.Fprintf(os.Stdout, "- **%s** `[ARCHIVED %s]`", formatTreeLabel(e.directPath, ctx.versionByPath[e.directPath]), fmtArchivedAt(cfg, rs))
 			} else {
 				_, _ = fmt.Fprintf(os.Stdout, "- **%s** `[ARCHIVED]`", e.directPath)
 			}
@@ -228,7 +235,7 @@ func PrintMarkdownTree(cfg *Config, results []RepoStatus, graph map[string][]str
 			}
 			seen[a] = true
 			if rs, ok := ctx.getStatus(a); ok {
-				_, _ = fmt.Fprintf(os.Stdout, "  - **%s** `[ARCHIVED %s]`\n", formatTreeLabel(a, ctx.versionByPath[a]), fmtDate(cfg, rs.ArchivedAt))
+				_, _ = fmt.Fprintf(os.Stdout, "  - **%s** `[ARCHIVED %s]`\n", formatTreeLabel(a, ctx.versionByPath[a]), fmtArchivedAt(cfg, rs))
 			} else {
 				_, _ = fmt.Fprintf(os.Stdout, "  - **%s** `[ARCHIVED]`\n", a)
 			}
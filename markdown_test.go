@@ -120,7 +120,7 @@ func TestPrintMarkdownFiles(t *testing.T) {
 	}
 
 	output := captureStdout(t, func() {
-		PrintMarkdownFiles(results, fileMatches)
+		PrintMarkdownFiles(results, fileMatches, nil)
 	})
 
 	if !strings.Contains(output, "## SOURCE FILES") {
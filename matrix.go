@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// matrixTarget is one scanned target of `modrot matrix`: its go.mod files
+// and the final status of their GitHub dependencies.
+type matrixTarget struct {
+	Target  string
+	GoMods  int
+	Results []RepoStatus
+}
+
+// matrixCounts are a target's unique-repository finding counts, the cells
+// of one matrix row.
+type matrixCounts struct {
+	GoMods         int `json:"go_mod_files"`
+	Repos          int `json:"unique_repos"`
+	Archived       int `json:"archived"`
+	ArchivedDirect int `json:"archived_direct"`
+	Disabled       int `json:"disabled"`
+	NotFound       int `json:"not_found"`
+	Unknown        int `json:"unknown"`
+	Active         int `json:"active"`
+}
+
+// countMatrixTarget counts the findings of one target. Like the summary
+// badge, it counts unique repositories, so the counts of targets with a
+// different number of go.mod files stay comparable.
+func countMatrixTarget(t matrixTarget) matrixCounts {
+	var s runSummary
+	s.add(t.Target, t.Target, t.Results)
+	b := computeBadgeCounts(&s)
+	c := matrixCounts{
+		GoMods:         t.GoMods,
+		Archived:       b.Archived,
+		ArchivedDirect: b.ArchivedDirect,
+		Disabled:       b.Disabled,
+		Unknown:        b.Unknown,
+		Active:         b.Active,
+	}
+	seen := make(map[string]bool)
+	for _, r := range t.Results {
+		key := repoKey(r.Module)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		c.Repos++
+		if r.NotFound {
+			c.NotFound++
+		}
+	}
+	return c
+}
+
+// JSONMatrixTarget is one target of the `modrot matrix --json` document.
+type JSONMatrixTarget struct {
+	matrixCounts
+	ArchivedModules []JSONModule `json:"archived_modules"`
+}
+
+// JSONMatrix is the `modrot matrix --json` document: every target's counts
+// and archived modules, keyed by the target as given on the command line.
+type JSONMatrix struct {
+	Targets map[string]JSONMatrixTarget `json:"targets"`
+}
+
+// buildMatrixJSON converts scanned targets for JSON output.
+func buildMatrixJSON(cfg *Config, targets []matrixTarget) JSONMatrix {
+	out := JSONMatrix{Targets: make(map[string]JSONMatrixTarget, len(targets))}
+	for _, t := range targets {
+		out.Targets[t.Target] = JSONMatrixTarget{
+			matrixCounts:    countMatrixTarget(t),
+			ArchivedModules: buildJSONOutput(cfg, t.Results, nil, nil, nil).Archived,
+		}
+	}
+	return out
+}
+
+var matrixHeaders = []string{"Target", "go.mod", "Repos", "Archived", "Direct", "Disabled", "Not found", "Unknown", "Active"}
+
+// matrixRows formats targets as matrix rows, in command line order.
+func matrixRows(targets []matrixTarget) [][]string {
+	rows := make([][]string, len(targets))
+	for i, t := range targets {
+		c := countMatrixTarget(t)
+		rows[i] = []string{t.Target, strconv.Itoa(c.GoMods), strconv.Itoa(c.Repos), strconv.Itoa(c.Archived),
+			strconv.Itoa(c.ArchivedDirect), strconv.Itoa(c.Disabled), strconv.Itoa(c.NotFound), strconv.Itoa(c.Unknown), strconv.Itoa(c.Active)}
+	}
+	return rows
+}
+
+// PrintMatrixTable outputs the targets × findings matrix.
+func PrintMatrixTable(targets []matrixTarget) {
+	_, _ = fmt.Fprintf(os.Stderr, "\nRELEASE MATRIX (%d %s)\n\n", len(targets), pluralize(len(targets), "target", "targets"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(matrixHeaders))
+	for _, row := range matrixRows(targets) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownMatrix outputs the targets × findings matrix in Markdown
+// format.
+func PrintMarkdownMatrix(targets []matrixTarget) {
+	_, _ = fmt.Fprintf(os.Stdout, "## RELEASE MATRIX (%d)\n\n", len(targets))
+	printMarkdownTable(os.Stdout, matrixHeaders, matrixRows(targets))
+}
+
+// scanMatrixTargets clones (concurrently) and parses every target, then
+// checks the GitHub repositories of all of them with one batched query,
+// so a repo shared by several release lines is only looked up once.
+func scanMatrixTargets(cfg *Config, targets []string, check func([]Module) ([]RepoStatus, error)) ([]matrixTarget, []*workspace, error) {
+	type parsed struct {
+		modules    []moduleInfo
+		workspaces []*workspace
+	}
+	scans, err := mapPool(context.Background(), targets, poolOptions{Workers: 4}, func(_ context.Context, target string) (parsed, error) {
+		modules, workspaces, err := warmTargetModules(cfg, []string{target})
+		if err != nil {
+			// A failed task's result is dropped, so clean up here.
+			for _, ws := range workspaces {
+				ws.Cleanup()
+			}
+			return parsed{}, err
+		}
+		return parsed{modules, workspaces}, nil
+	})
+	var workspaces []*workspace
+	for _, s := range scans {
+		workspaces = append(workspaces, s.workspaces...)
+	}
+	if err != nil {
+		return nil, workspaces, err
+	}
+
+	var all []moduleInfo
+	for _, s := range scans {
+		all = append(all, s.modules...)
+	}
+	if resolved := resolveAcrossModulesWithResolver(all, newResolver()); resolved > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Resolved %d non-GitHub modules to GitHub repos.\n", resolved)
+	}
+
+	perTarget := make([][]Module, len(targets))
+	var unique []Module
+	seen := make(map[string]bool)
+	offset := 0
+	for i, s := range scans {
+		for _, mi := range all[offset : offset+len(s.modules)] {
+			ghMods, _ := FilterGitHub(mi.allModules, cfg.DirectOnly)
+			perTarget[i] = append(perTarget[i], ghMods...)
+			for _, m := range ghMods {
+				if key := repoKey(m); !seen[key] {
+					seen[key] = true
+					unique = append(unique, m)
+				}
+			}
+		}
+		offset += len(s.modules)
+	}
+
+	statusMap := make(map[string]RepoStatus)
+	if len(unique) > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Checking %d unique GitHub repos across %d %s...\n",
+			len(unique), len(targets), pluralize(len(targets), "target", "targets"))
+		results, err := check(unique)
+		if err != nil {
+			return nil, workspaces, err
+		}
+		applyDisabledMode(cfg, results)
+		for _, r := range results {
+			statusMap[repoKey(r.Module)] = r
+		}
+	}
+
+	out := make([]matrixTarget, len(targets))
+	for i, target := range targets {
+		results := applyStatus(perTarget[i], statusMap)
+		sort.Slice(results, func(a, b int) bool { return results[a].Module.Path < results[b].Module.Path })
+		out[i] = matrixTarget{Target: target, GoMods: len(scans[i].modules), Results: results}
+	}
+	return out, workspaces, nil
+}
+
+// runMatrix implements `modrot matrix [--json | --markdown] [--direct-only]
+// [--workers N] [--token-env VAR] TARGET...`: scans several targets (local
+// directories, or repository specs pinned to a branch with @REF, such as
+// each maintained release line) in one invocation and prints a
+// targets × findings matrix, or a JSON document keyed by target.
+// Returns exit code: 0 = no archived or disabled repos in any target,
+// 1 = some target has them, 2 = error.
+func runMatrix(args []string) int {
+	fs := flag.NewFlagSet("matrix", flag.ContinueOnError)
+	jsonFlag := fs.Bool("json", false, "Output a JSON document keyed by target")
+	markdownFlag := fs.Bool("markdown", false, "Output the matrix as a Markdown table")
+	directOnly := fs.Bool("direct-only", false, "Only check direct dependencies")
+	workers := fs.Int("workers", 50, "Number of repos per GitHub GraphQL batch request")
+	tokenEnvFlag := fs.String("token-env", "", "Read the GitHub token from this environment variable instead of gh auth token")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot matrix [--json | --markdown] [--direct-only] [--workers N] [--token-env VAR] TARGET...
+
+Scan several targets in one invocation and compare their findings side by
+side: one row per target, one column per finding count (unique repos). A
+target is a directory, a go.mod file, or a repository spec, optionally
+pinned to a branch or tag with @REF, e.g.
+
+  modrot matrix github.com/acme/api@release-1.3 github.com/acme/api@release-1.4 github.com/acme/api
+
+Remote targets are cloned concurrently; all targets share one GitHub query.
+
+  --json            Output a JSON document keyed by target
+  --markdown        Output the matrix as a Markdown table
+  --direct-only     Only check direct dependencies
+  --workers int     Number of repos per GitHub GraphQL batch request (default 50)
+  --token-env VAR   Read the GitHub token from this environment variable
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	if *jsonFlag && *markdownFlag {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --json and --markdown are mutually exclusive\n")
+		return 2
+	}
+	if *workers < 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --workers must be at least 1\n")
+		return 2
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.Workers = *workers
+	cfg.DirectOnly = *directOnly
+	tokenEnv = *tokenEnvFlag
+
+	targets, workspaces, err := scanMatrixTargets(cfg, fs.Args(), func(ms []Module) ([]RepoStatus, error) {
+		return CheckReposCached(cfg, ms)
+	})
+	defer func() {
+		for _, ws := range workspaces {
+			ws.Cleanup()
+		}
+	}()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	switch {
+	case *jsonFlag:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(buildMatrixJSON(cfg, targets)); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+	case *markdownFlag:
+		PrintMarkdownMatrix(targets)
+	default:
+		PrintMatrixTable(targets)
+	}
+
+	for _, t := range targets {
+		if c := countMatrixTarget(t); c.Archived > 0 || c.Disabled > 0 {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanMatrixTargets(t *testing.T) {
+	writeGoMod := func(body string) string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.22\n\n"+body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	release := writeGoMod("require (\n\tgithub.com/dead/lib v1.0.0\n\tgithub.com/live/lib v1.0.0\n)\n")
+	mainline := writeGoMod("require github.com/live/lib v1.2.0\n")
+
+	var queried []string
+	check := func(ms []Module) ([]RepoStatus, error) {
+		out := make([]RepoStatus, len(ms))
+		for i, m := range ms {
+			queried = append(queried, m.Path)
+			out[i] = RepoStatus{Module: m, IsArchived: m.Owner == "dead"}
+		}
+		return out, nil
+	}
+
+	cfg := NewDefaultConfig()
+	targets, workspaces, err := scanMatrixTargets(cfg, []string{release, mainline}, check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workspaces) != 0 {
+		t.Errorf("workspaces = %v, want none for local targets", workspaces)
+	}
+	if len(queried) != 2 {
+		t.Errorf("queried %v, want each repo once across targets", queried)
+	}
+
+	tests := []struct {
+		target string
+		want   matrixCounts
+	}{
+		{release, matrixCounts{GoMods: 1, Repos: 2, Archived: 1, ArchivedDirect: 1, Active: 1}},
+		{mainline, matrixCounts{GoMods: 1, Repos: 1, Active: 1}},
+	}
+	for i, tt := range tests {
+		if targets[i].Target != tt.target {
+			t.Errorf("targets[%d] = %q, want %q", i, targets[i].Target, tt.target)
+		}
+		if got := countMatrixTarget(targets[i]); got != tt.want {
+			t.Errorf("counts of %s = %+v, want %+v", tt.target, got, tt.want)
+		}
+	}
+
+	out := buildMatrixJSON(cfg, targets)
+	if got := out.Targets[release].ArchivedModules; len(got) != 1 || got[0].Module != "github.com/dead/lib" {
+		t.Errorf("archived_modules of release = %+v", got)
+	}
+	if got := out.Targets[mainline].ArchivedModules; len(got) != 0 {
+		t.Errorf("archived_modules of mainline = %+v, want none", got)
+	}
+}
+
+func TestScanMatrixTargets_MissingTarget(t *testing.T) {
+	cfg := NewDefaultConfig()
+	_, _, err := scanMatrixTargets(cfg, []string{filepath.Join(t.TempDir(), "missing")}, func([]Module) ([]RepoStatus, error) {
+		t.Error("check called for a failed scan")
+		return nil, nil
+	})
+	if err == nil {
+		t.Error("expected an error for a missing target")
+	}
+}
+
+func TestPrintMatrixTable(t *testing.T) {
+	targets := []matrixTarget{
+		{Target: "github.com/acme/api@release-1.3", GoMods: 2, Results: []RepoStatus{
+			{Module: Module{Path: "github.com/dead/lib", Owner: "dead", Repo: "lib", Direct: true}, IsArchived: true},
+			{Module: Module{Path: "github.com/gone/lib", Owner: "gone", Repo: "lib"}, NotFound: true},
+			{Module: Module{Path: "github.com/slow/lib", Owner: "slow", Repo: "lib"}, Unknown: true, Error: "timeout"},
+		}},
+		{Target: "github.com/acme/api", GoMods: 1, Results: []RepoStatus{
+			{Module: Module{Path: "github.com/live/lib", Owner: "live", Repo: "lib"}},
+		}},
+	}
+
+	output := captureStdout(t, func() {
+		PrintMatrixTable(targets)
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header and 2 rows:\n%s", len(lines), output)
+	}
+	if got := strings.Fields(lines[1]); strings.Join(got, " ") != "github.com/acme/api@release-1.3 2 3 1 1 0 1 1 0" {
+		t.Errorf("release row = %q", lines[1])
+	}
+	if got := strings.Fields(lines[2]); strings.Join(got, " ") != "github.com/acme/api 1 1 0 0 0 0 0 1" {
+		t.Errorf("mainline row = %q", lines[2])
+	}
+
+	md := captureStdout(t, func() {
+		PrintMarkdownMatrix(targets)
+	})
+	if !strings.Contains(md, "| Target | go.mod |") {
+		t.Errorf("markdown output missing header:\n%s", md)
+	}
+}
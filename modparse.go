@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
 )
 
 // Module represents a parsed go.mod dependency.
@@ -21,6 +22,18 @@ type Module struct {
 	VersionTime   time.Time // publish time of current version from proxy
 	LatestTime    time.Time // publish time of latest version from proxy
 	SourceURL     string    // VCS URL from proxy Origin.URL
+	GoMod         string    // go.mod file with the require, as passed to ParseGoMod
+	Line          int       // line of the require in GoMod (0 if unknown)
+	ReplacedBy    string    // module path or directory a replace directive substitutes (empty if none)
+
+	// Newest major version module path the proxy publishes past the pinned
+	// one (e.g. github.com/foo/bar/v3 when github.com/foo/bar is pinned),
+	// and its latest version. Set with --freshness.
+	NewerMajorPath    string
+	NewerMajorVersion string
+
+	// Criticality tags from the tags setting in .modrot.yaml.
+	Tags []string
 }
 
 // ParseGoMod reads and parses a go.mod file, returning all required modules.
@@ -35,12 +48,32 @@ func ParseGoMod(path string) ([]Module, error) {
 		return nil, fmt.Errorf("parsing go.mod: %w", err)
 	}
 
-	var modules []Module
+	// A replace without a version applies to every version of the module;
+	// one with a version only to that version and takes precedence.
+	replaced := make(map[string]string, len(f.Replace))
+	for _, r := range f.Replace {
+		key := r.Old.Path
+		if r.Old.Version != "" {
+			key += "@" + r.Old.Version
+		}
+		replaced[key] = r.New.Path
+	}
+
+	modules := make([]Module, 0, len(f.Require))
 	for _, req := range f.Require {
 		m := Module{
 			Path:    req.Mod.Path,
 			Version: req.Mod.Version,
 			Direct:  !req.Indirect,
+			GoMod:   path,
+		}
+		if req.Syntax != nil {
+			m.Line = req.Syntax.Start.Line
+		}
+		if r, ok := replaced[req.Mod.Path+"@"+req.Mod.Version]; ok {
+			m.ReplacedBy = r
+		} else {
+			m.ReplacedBy = replaced[req.Mod.Path]
 		}
 		m.Owner, m.Repo = extractGitHub(req.Mod.Path)
 		modules = append(modules, m)
@@ -48,6 +81,15 @@ func ParseGoMod(path string) ([]Module, error) {
 	return modules, nil
 }
 
+// requiredAt returns where m is required as "go.mod:LINE", with the go.mod
+// path relative to the working directory, or "" if unknown.
+func requiredAt(m Module) string {
+	if m.GoMod == "" || m.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", relToCwd(m.GoMod), m.Line)
+}
+
 // extractGitHub extracts the GitHub owner and repo from a module path.
 // Returns ("", "") for non-GitHub modules.
 // Handles paths like:
@@ -55,6 +97,7 @@ func ParseGoMod(path string) ([]Module, error) {
 //   - github.com/foo/bar/v2        → (foo, bar)
 //   - github.com/foo/bar/sdk/v2    → (foo, bar)
 func extractGitHub(path string) (owner, repo string) {
+	path = canonicalModulePath(path)
 	if !strings.HasPrefix(path, "github.com/") {
 		return "", ""
 	}
@@ -65,6 +108,38 @@ func extractGitHub(path string) (owner, repo string) {
 	return parts[1], parts[2]
 }
 
+// canonicalModulePath undoes the proxy's bang-encoding of upper-case
+// letters ("github.com/!azure/x" for "github.com/Azure/x"), so paths copied
+// from proxy URLs or the module cache match the ones go.mod uses. Paths
+// that are not valid escapes are returned unchanged.
+func canonicalModulePath(path string) string {
+	if !strings.Contains(path, "!") {
+		return path
+	}
+	if p, err := module.UnescapePath(path); err == nil {
+		return p
+	}
+	return path
+}
+
+// canonicalGraphNode applies canonicalModulePath to the path of a
+// "path@version" node from go mod graph.
+func canonicalGraphNode(node string) string {
+	path, version, ok := strings.Cut(node, "@")
+	if !ok {
+		return canonicalModulePath(node)
+	}
+	return canonicalModulePath(path) + "@" + version
+}
+
+// repoKey returns the lower-cased "owner/repo" of a module's GitHub
+// repository. GitHub names are case-insensitive, so github.com/Azure/x and
+// github.com/azure/x are one repository and must not become two findings;
+// every dedup and lookup by repository uses this key.
+func repoKey(m Module) string {
+	return strings.ToLower(m.Owner + "/" + m.Repo)
+}
+
 // ModuleName reads the module path (the "module" directive) from a go.mod file.
 func ModuleName(path string) (string, error) {
 	data, err := os.ReadFile(path)
@@ -101,8 +176,28 @@ func GoModInfo(path string) (moduleName, goVersion string, err error) {
 }
 
 // FilterGitHub separates modules into GitHub and non-GitHub.
-// GitHub modules are deduplicated by owner/repo.
+// GitHub modules are deduplicated by owner/repo. Both results share one
+// allocation sized up front, which keeps the copies of very large go.mod
+// files compact.
 func FilterGitHub(modules []Module, directOnly bool) (github []Module, nonGitHub []Module) {
+	nGH, nOther := 0, 0
+	for _, m := range modules {
+		switch {
+		case directOnly && !m.Direct:
+		case m.Owner == "":
+			nOther++
+		default:
+			nGH++
+		}
+	}
+	buf := make([]Module, 0, nGH+nOther)
+	if nGH > 0 {
+		github = buf[:0:nGH]
+	}
+	if nOther > 0 {
+		nonGitHub = buf[nGH:nGH:cap(buf)]
+	}
+
 	seen := make(map[string]bool)
 	for _, m := range modules {
 		if directOnly && !m.Direct {
@@ -112,7 +207,7 @@ func FilterGitHub(modules []Module, directOnly bool) (github []Module, nonGitHub
 			nonGitHub = append(nonGitHub, m)
 			continue
 		}
-		key := m.Owner + "/" + m.Repo
+		key := repoKey(m)
 		if seen[key] {
 			continue
 		}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -134,6 +136,30 @@ require (
 	if modules[2].Owner != "" || modules[2].Repo != "" {
 		t.Error("modules[2] should have empty owner/repo")
 	}
+
+	// Check provenance: the go.mod file and line of each require
+	for i, wantLine := range []int{6, 7, 8} {
+		if modules[i].GoMod != path || modules[i].Line != wantLine {
+			t.Errorf("modules[%d] required at %s:%d, want %s:%d", i, modules[i].GoMod, modules[i].Line, path, wantLine)
+		}
+	}
+}
+
+func TestRequiredAt(t *testing.T) {
+	cwd, _ := os.Getwd()
+	tests := []struct {
+		m    Module
+		want string
+	}{
+		{Module{GoMod: filepath.Join(cwd, "tools", "go.mod"), Line: 12}, filepath.Join("tools", "go.mod") + ":12"},
+		{Module{GoMod: "go.mod"}, ""},
+		{Module{Line: 3}, ""},
+	}
+	for _, tt := range tests {
+		if got := requiredAt(tt.m); got != tt.want {
+			t.Errorf("requiredAt(%+v) = %q, want %q", tt.m, got, tt.want)
+		}
+	}
 }
 
 func TestParseGoMod_FileNotFound(t *testing.T) {
@@ -298,3 +324,133 @@ func TestFilterGitHub_DeduplicatesMultiPathRepos(t *testing.T) {
 		t.Errorf("expected first occurrence to be kept, got %q", gh[0].Path)
 	}
 }
+
+func TestParseGoMod_LargeFile(t *testing.T) {
+	const n = 5000
+	var b strings.Builder
+	b.WriteString("module example.com/generated\n\ngo 1.22\n\nrequire (\n")
+	for i := range n {
+		if i%2 == 0 {
+			fmt.Fprintf(&b, "\tgithub.com/org%d/repo v1.0.%d // indirect\n", i, i)
+		} else {
+			fmt.Fprintf(&b, "\texample.com/mod%d v0.%d.0\n", i, i)
+		}
+	}
+	b.WriteString(")\n")
+	path := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mods, err := ParseGoMod(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mods) != n || cap(mods) != n {
+		t.Errorf("len/cap = %d/%d, want %d/%d", len(mods), cap(mods), n, n)
+	}
+	if mods[n-1].Line != n+5 {
+		t.Errorf("last require line = %d, want %d", mods[n-1].Line, n+5)
+	}
+
+	gh, nonGH := FilterGitHub(mods, false)
+	if len(gh) != n/2 || len(nonGH) != n/2 {
+		t.Fatalf("got %d GitHub, %d non-GitHub, want %d each", len(gh), len(nonGH), n/2)
+	}
+	// The two results share one allocation without overlapping: growing
+	// one must not overwrite the other.
+	gh = append(gh, Module{Path: "github.com/extra/repo"})
+	if nonGH[0].Path != "example.com/mod1" {
+		t.Errorf("nonGH[0] = %q after appending to the GitHub slice", nonGH[0].Path)
+	}
+	if gh[0].Path != "github.com/org0/repo" || gh[len(gh)-1].Path != "github.com/extra/repo" {
+		t.Errorf("GitHub slice corrupted: first %q, last %q", gh[0].Path, gh[len(gh)-1].Path)
+	}
+}
+
+func TestCanonicalModulePath(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"github.com/Azure/azure-sdk-for-go", "github.com/Azure/azure-sdk-for-go"},
+		{"github.com/!azure/azure-sdk-for-go", "github.com/Azure/azure-sdk-for-go"},
+		{"github.com/!burnt!sushi/toml", "github.com/BurntSushi/toml"},
+		{"github.com/bad!/escape", "github.com/bad!/escape"}, // invalid escape kept as is
+	}
+	for _, tt := range tests {
+		if got := canonicalModulePath(tt.in); got != tt.want {
+			t.Errorf("canonicalModulePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if got := canonicalGraphNode("github.com/!burnt!sushi/toml@v1.3.2"); got != "github.com/BurntSushi/toml@v1.3.2" {
+		t.Errorf("canonicalGraphNode = %q", got)
+	}
+}
+
+func TestExtractGitHub_BangEncoded(t *testing.T) {
+	owner, repo := extractGitHub("github.com/!azure/go-autorest/autorest")
+	if owner != "Azure" || repo != "go-autorest" {
+		t.Errorf("extractGitHub = %q/%q, want Azure/go-autorest", owner, repo)
+	}
+}
+
+func TestFilterGitHub_DeduplicatesCaseVariants(t *testing.T) {
+	modules := []Module{
+		{Path: "github.com/Azure/go-autorest/autorest", Version: "v0.11.29", Direct: true},
+		{Path: "github.com/azure/go-autorest/tracing", Version: "v0.6.0"},
+		{Path: "github.com/!azure/go-autorest/logger", Version: "v0.2.1"},
+	}
+	for i := range modules {
+		modules[i].Owner, modules[i].Repo = extractGitHub(modules[i].Path)
+	}
+	gh, _ := FilterGitHub(modules, false)
+	if len(gh) != 1 {
+		t.Fatalf("expected 1 module for Azure/go-autorest, got %d: %+v", len(gh), gh)
+	}
+	if repoKey(gh[0]) != "azure/go-autorest" {
+		t.Errorf("repoKey = %q, want azure/go-autorest", repoKey(gh[0]))
+	}
+}
+
+func TestParseGoMod_ReplacedBy(t *testing.T) {
+	dir := t.TempDir()
+	gomod := filepath.Join(dir, "go.mod")
+	content := `module example.com/app
+
+go 1.21
+
+require (
+	github.com/upstream/lib v1.2.0
+	github.com/upstream/pinned v0.3.0
+	github.com/upstream/other v0.4.0
+	github.com/plain/dep v1.0.0
+)
+
+replace github.com/upstream/lib => github.com/acme/lib v1.2.1-acme.1
+
+replace (
+	github.com/upstream/pinned v0.2.0 => github.com/acme/pinned v0.2.0
+	github.com/upstream/pinned v0.3.0 => ../forks/pinned
+	github.com/upstream/other => github.com/acme/other v0.4.1
+	github.com/upstream/other v0.4.0 => github.com/acme/other-exact v0.4.0
+)
+`
+	if err := os.WriteFile(gomod, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modules, err := ParseGoMod(gomod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"github.com/upstream/lib":    "github.com/acme/lib",
+		"github.com/upstream/pinned": "../forks/pinned",
+		"github.com/upstream/other":  "github.com/acme/other-exact",
+		"github.com/plain/dep":       "",
+	}
+	for _, m := range modules {
+		if m.ReplacedBy != want[m.Path] {
+			t.Errorf("%s ReplacedBy = %q, want %q", m.Path, m.ReplacedBy, want[m.Path])
+		}
+	}
+}
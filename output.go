@@ -1,13 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
+
+	"github.com/norman-abramovitz/modrot/report"
 )
 
 // fmtDate formats a time using the current dateFmt setting.
@@ -79,12 +83,18 @@ func directLabel(m Module) string {
 	return "indirect"
 }
 
-// latestOrDash returns the latest version, or "-" if it matches the current version.
+// latestOrDash returns the latest version, or "-" if it matches the current
+// version, followed by a MAJOR UPGRADE AVAILABLE marker when a newer major
+// version module path exists.
 func latestOrDash(m Module) string {
-	if m.LatestVersion != "" && m.LatestVersion == m.Version {
-		return "-"
+	latest := m.LatestVersion
+	if latest != "" && latest == m.Version {
+		latest = "-"
+	}
+	if marker := majorUpgradeMarker(m); marker != "" {
+		return strings.TrimSpace(latest + " " + marker)
 	}
-	return m.LatestVersion
+	return latest
 }
 
 // archivedHeaders returns column headers for archived tables based on cfg flags.
@@ -102,7 +112,11 @@ func archivedHeaders(cfg *Config) []string {
 
 // archivedRow returns column values for one archived result.
 func archivedRow(cfg *Config, r RepoStatus) []string {
-	row := []string{r.Module.Path, r.Module.Version, directLabel(r.Module), fmtDate(cfg, r.ArchivedAt)}
+	path := r.Module.Path
+	if r.Retired {
+		path += " " + retiredMarker
+	}
+	row := []string{path, r.Module.Version, directLabel(r.Module), fmtArchivedAt(cfg, r)}
 	if cfg.Duration.Enabled {
 		row = append(row, formatDuration(cfg, r.ArchivedAt))
 	}
@@ -207,14 +221,14 @@ func exceedsThreshold(pushedAt time.Time, y, m, d int, now time.Time) bool {
 }
 
 // filterStale returns repos whose PushedAt exceeds the stale threshold
-// and are not archived or not-found.
+// and are not archived, disabled, or not-found.
 func filterStale(cfg *Config, results []RepoStatus) []RepoStatus {
 	if !cfg.Stale.Enabled {
 		return nil
 	}
 	var stale []RepoStatus
 	for _, r := range results {
-		if r.IsArchived || r.NotFound {
+		if r.IsArchived || r.Disabled || r.NotFound {
 			continue
 		}
 		if exceedsThreshold(r.PushedAt, cfg.Stale.Years, cfg.Stale.Months, cfg.Stale.Days, cfg.Now) {
@@ -299,10 +313,7 @@ func PrintOutdatedTable(cfg *Config, results []RepoStatus, nonGHModules []Module
 		if m.Direct {
 			direct = "direct"
 		}
-		latest := m.LatestVersion
-		if latest == m.Version {
-			latest = "-"
-		}
+		latest := latestOrDash(m)
 		behind := formatBehind(m)
 		age := formatAge(cfg, m)
 		published := ""
@@ -470,10 +481,7 @@ func PrintSkippedTable(cfg *Config, modules []Module) {
 		if m.Direct {
 			direct = "direct"
 		}
-		latest := m.LatestVersion
-		if latest != "" && latest == m.Version {
-			latest = "-"
-		}
+		latest := latestOrDash(m)
 		published := fmtDate(cfg, m.VersionTime)
 		if cfg.Freshness {
 			behind := formatBehind(m)
@@ -490,7 +498,12 @@ func printArchivedRows(cfg *Config, w *tabwriter.Writer, archived []RepoStatus)
 	for _, r := range archived {
 		row := archivedRow(cfg, r)
 		// Apply color to Archived At (index 3) and Last Pushed (after Duration if present)
-		row[3] = colorize(cfg, row[3], r.ArchivedAt)
+		// Formally retired modules are always shown at the most severe level
+		if r.Retired {
+			row[3] = colorizeSevere(cfg, row[3])
+		} else {
+			row[3] = colorize(cfg, row[3], r.ArchivedAt)
+		}
 		pushedIdx := 4
 		if cfg.Duration.Enabled {
 			pushedIdx = 5
@@ -503,10 +516,14 @@ func printArchivedRows(cfg *Config, w *tabwriter.Writer, archived []RepoStatus)
 // PrintTable outputs archived (or all) results in a human-readable table.
 // If deprecatedModules is non-nil, a DEPRECATED MODULES section is appended.
 func PrintTable(cfg *Config, results []RepoStatus, nonGitHubModules []Module, deprecatedModules ...[]Module) {
-	// Separate archived, not-found, and active
-	var archived, notFound, active []RepoStatus
+	// Separate disabled, archived, not-found, and active
+	var disabled, archived, notFound, active []RepoStatus
 	for _, r := range results {
 		switch {
+		case r.Disabled:
+			disabled = append(disabled, r)
+		case r.Unknown:
+			// listed by PrintUnknown*
 		case r.NotFound:
 			notFound = append(notFound, r)
 		case r.IsArchived:
@@ -530,6 +547,8 @@ func PrintTable(cfg *Config, results []RepoStatus, nonGitHubModules []Module, de
 
 	totalChecked := len(results)
 
+	PrintDisabledTable(cfg, splitDisabled(disabled))
+
 	if len(archived) > 0 {
 		_, _ = fmt.Fprintf(os.Stderr, "\nARCHIVED DEPENDENCIES (%d of %d github.com modules)\n\n", len(archived), totalChecked)
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
@@ -552,13 +571,17 @@ func PrintTable(cfg *Config, results []RepoStatus, nonGitHubModules []Module, de
 	} else {
 		_, _ = fmt.Fprintf(os.Stderr, "\nNo archived dependencies found among %d github.com modules.\n", totalChecked)
 	}
+	if totalChecked > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "\n%s%s\n", computeRepoTotals(results), cacheNote(cfg))
+	}
 
 	if len(notFound) > 0 {
 		_, _ = fmt.Fprintf(os.Stderr, "\nNOT FOUND (%d modules):\n", len(notFound))
 		for _, r := range notFound {
-			_, _ = fmt.Fprintf(os.Stderr, "  %s — %s\n", r.Module.Path, r.Error)
+			_, _ = fmt.Fprintf(os.Stderr, "  %s — %s\n", r.Module.Path, notFoundHint(r))
 		}
 	}
+	PrintUnknownTable(results)
 
 	if cfg.ShowAll && len(active) > 0 {
 		_, _ = fmt.Fprintf(os.Stderr, "\nACTIVE DEPENDENCIES (%d modules)\n\n", len(active))
@@ -591,34 +614,45 @@ func PrintTable(cfg *Config, results []RepoStatus, nonGitHubModules []Module, de
 	}
 }
 
-// PrintFiles outputs a section showing source files that import archived modules.
-func PrintFiles(results []RepoStatus, fileMatches map[string][]FileMatch) {
-	// Collect archived modules in sorted order
-	var archivedPaths []string
-	for _, r := range results {
-		if r.IsArchived {
-			archivedPaths = append(archivedPaths, r.Module.Path)
-		}
-	}
-	sort.Strings(archivedPaths)
-
+// PrintFiles outputs a section showing source files that import archived
+// modules. via (from archivedVia) names the direct dependencies that pull in
+// each archived indirect module; it may be nil if the graph is unavailable.
+func PrintFiles(results []RepoStatus, fileMatches map[string][]FileMatch, via map[string][]string) {
 	_, _ = fmt.Fprintf(os.Stderr, "\nSOURCE FILES IMPORTING ARCHIVED MODULES\n")
 
-	for _, modPath := range archivedPaths {
-		matches := fileMatches[modPath]
+	for _, r := range sortedArchived(results) {
+		matches := fileMatches[r.Module.Path]
 		// Deduplicate by file (show each file only once per module)
 		uniqueFiles := make(map[string]bool)
 		for _, m := range matches {
 			uniqueFiles[m.File] = true
 		}
 
-		_, _ = fmt.Fprintf(os.Stdout, "\n%s (%d %s)\n", modPath, len(uniqueFiles), pluralize(len(uniqueFiles), "file", "files"))
+		_, _ = fmt.Fprintf(os.Stdout, "\n%s\n", fileChainLabel(r, len(uniqueFiles), via))
 		for _, m := range matches {
-			_, _ = fmt.Fprintf(os.Stdout, "  %s:%d\n", m.File, m.Line)
+			if m.UsageKind == usageTypeOnly {
+				_, _ = fmt.Fprintf(os.Stdout, "  %s:%d (type-only)\n", m.File, m.Line)
+			} else {
+				_, _ = fmt.Fprintf(os.Stdout, "  %s:%d\n", m.File, m.Line)
+			}
 		}
 	}
 }
 
+// sortedArchived returns the archived results ordered by module path.
+func sortedArchived(results []RepoStatus) []RepoStatus {
+	var archived []RepoStatus
+	for _, r := range results {
+		if r.IsArchived {
+			archived = append(archived, r)
+		}
+	}
+	sort.Slice(archived, func(i, j int) bool {
+		return archived[i].Module.Path < archived[j].Module.Path
+	})
+	return archived
+}
+
 // PrintFilesPlain outputs quickfix-format lines: file:line:module_path
 // This format is compatible with vim's quickfix list and similar editor integrations.
 func PrintFilesPlain(results []RepoStatus, fileMatches map[string][]FileMatch) {
@@ -637,6 +671,30 @@ func PrintFilesPlain(results []RepoStatus, fileMatches map[string][]FileMatch) {
 	}
 }
 
+// PrintProblems outputs one line per import of an archived module in the
+// path:line:col: warning: message form understood by common CI problem
+// matchers (--format plain). dir is joined to each file path so paths are
+// relative to the working directory.
+func PrintProblems(cfg *Config, dir string, results []RepoStatus, fileMatches map[string][]FileMatch) {
+	var archived []RepoStatus
+	for _, r := range results {
+		if r.IsArchived {
+			archived = append(archived, r)
+		}
+	}
+	sort.Slice(archived, func(i, j int) bool {
+		return archived[i].Module.Path < archived[j].Module.Path
+	})
+
+	for _, r := range archived {
+		msg := archivedImportMessage(cfg, r)
+		for _, m := range fileMatches[r.Module.Path] {
+			_, _ = fmt.Fprintf(os.Stdout, "%s:%d:%d: warning: %s\n",
+				filepath.Join(dir, m.File), m.Line, max(m.Col, 1), msg)
+		}
+	}
+}
+
 // PrintDeprecatedTable outputs a standalone deprecated modules table.
 // Used when --tree mode needs to append a deprecated section separately.
 func PrintDeprecatedTable(modules []Module) {
@@ -665,44 +723,13 @@ func pluralize(n int, singular, plural string) string {
 }
 
 // JSONSkippedModule represents a non-GitHub module in JSON output.
-type JSONSkippedModule struct {
-	Module        string `json:"module"`
-	Version       string `json:"version"`
-	Direct        bool   `json:"direct"`
-	LatestVersion string `json:"latest_version,omitempty"`
-	Behind        string `json:"behind,omitempty"`
-	Published     string `json:"published,omitempty"`
-	Host          string `json:"host,omitempty"`
-	SourceURL     string `json:"source_url,omitempty"`
-}
+type JSONSkippedModule = report.SkippedModule
 
-// JSONOutput is the structure for JSON output mode.
-type JSONOutput struct {
-	Archived         []JSONModule        `json:"archived"`
-	Stale            []JSONModule        `json:"stale,omitempty"`
-	Deprecated       []JSONModule        `json:"deprecated,omitempty"`
-	NotFound         []JSONModule        `json:"not_found,omitempty"`
-	Active           []JSONModule        `json:"active,omitempty"`
-	NonGitHubCount   int                 `json:"non_github_count"`
-	NonGitHubModules []JSONSkippedModule `json:"non_github_modules,omitempty"`
-	TotalChecked     int                 `json:"total_checked"`
-}
+// JSONOutput is the structure for JSON output mode. It is the report
+// package's Result, so custom renderers use the model modrot encodes.
+type JSONOutput = report.Result
 
-type JSONModule struct {
-	Module            string           `json:"module"`
-	Version           string           `json:"version"`
-	Direct            bool             `json:"direct"`
-	Owner             string           `json:"owner"`
-	Repo              string           `json:"repo"`
-	ArchivedAt        string           `json:"archived_at,omitempty"`
-	ArchivedDuration  string           `json:"archived_duration,omitempty"`
-	PushedAt          string           `json:"pushed_at,omitempty"`
-	Error             string           `json:"error,omitempty"`
-	DeprecatedMessage string           `json:"deprecated_message,omitempty"`
-	LatestVersion     string           `json:"latest_version,omitempty"`
-	Behind            string           `json:"behind,omitempty"`
-	SourceFiles       []JSONSourceFile `json:"source_files,omitempty"`
-}
+type JSONModule = report.Module
 
 // setJSONFreshness populates LatestVersion and Behind on a JSONModule from a Module.
 func setJSONFreshness(jm *JSONModule, m Module) {
@@ -712,14 +739,11 @@ func setJSONFreshness(jm *JSONModule, m Module) {
 	if va := formatBehind(m); va != "" && va != "-" {
 		jm.Behind = va
 	}
+	jm.MajorUpgrade = buildMajorUpgradeJSON(m)
 }
 
 // JSONSourceFile represents a source file match in JSON output.
-type JSONSourceFile struct {
-	File   string `json:"file"`
-	Line   int    `json:"line"`
-	Import string `json:"import"`
-}
+type JSONSourceFile = report.SourceFile
 
 // buildJSONOutput creates the JSONOutput data structure without writing it.
 // staleResults and deprecatedModules are optional; pass nil if not applicable.
@@ -727,15 +751,17 @@ func buildJSONOutput(cfg *Config, results []RepoStatus, nonGitHubModules []Modul
 	out := JSONOutput{
 		NonGitHubCount: len(nonGitHubModules),
 		TotalChecked:   len(results),
+		Meta:           computeRepoTotals(results),
 		Archived:       []JSONModule{},
 	}
 
 	for _, m := range nonGitHubModules {
 		jsm := JSONSkippedModule{
-			Module:  m.Path,
-			Version: m.Version,
-			Direct:  m.Direct,
-			Host:    hostDomain(m.Path),
+			Module:     m.Path,
+			Version:    m.Version,
+			Direct:     m.Direct,
+			Host:       hostDomain(m.Path),
+			RequiredAt: requiredAt(m),
 		}
 		if m.LatestVersion != "" {
 			jsm.LatestVersion = m.LatestVersion
@@ -750,17 +776,20 @@ func buildJSONOutput(cfg *Config, results []RepoStatus, nonGitHubModules []Modul
 			if va := formatBehind(m); va != "" && va != "-" {
 				jsm.Behind = va
 			}
+			jsm.MajorUpgrade = buildMajorUpgradeJSON(m)
 		}
 		out.NonGitHubModules = append(out.NonGitHubModules, jsm)
 	}
 
 	for _, r := range results {
 		jm := JSONModule{
-			Module:  r.Module.Path,
-			Version: r.Module.Version,
-			Direct:  r.Module.Direct,
-			Owner:   r.Module.Owner,
-			Repo:    r.Module.Repo,
+			Module:     r.Module.Path,
+			Version:    r.Module.Version,
+			Direct:     r.Module.Direct,
+			Owner:      r.Module.Owner,
+			Repo:       r.Module.Repo,
+			RequiredAt: requiredAt(r.Module),
+			Tags:       r.Module.Tags,
 		}
 		if !r.PushedAt.IsZero() {
 			jm.PushedAt = r.PushedAt.Format("2006-01-02T15:04:05Z")
@@ -770,22 +799,38 @@ func buildJSONOutput(cfg *Config, results []RepoStatus, nonGitHubModules []Modul
 		}
 
 		switch {
+		case r.Disabled:
+			jm.FindingID = findingID(findingDisabled, r.Module.Path)
+			jm.DisabledReason = r.DisabledReason
+			jm.Error = r.Error
+			out.Disabled = append(out.Disabled, jm)
+		case r.Unknown:
+			// collected by buildUnknownJSON below
 		case r.NotFound:
+			jm.FindingID = findingID(findingNotFound, r.Module.Path)
 			jm.Error = r.Error
+			jm.Triage = r.Triage
+			jm.RenamedTo = r.RenamedTo
 			out.NotFound = append(out.NotFound, jm)
 		case r.IsArchived:
+			jm.FindingID = findingID(findingArchived, r.Module.Path)
 			if !r.ArchivedAt.IsZero() {
 				jm.ArchivedAt = r.ArchivedAt.Format("2006-01-02T15:04:05Z")
 			}
+			jm.ArchivedAtSource, jm.ArchivedAtPrecision = archivedAtProvenance(r)
 			if dur := formatDuration(cfg, r.ArchivedAt); dur != "" {
 				jm.ArchivedDuration = dur
 			}
+			setJSONAdvisories(&jm, r)
+			jm.Retired = r.Retired
+			jm.RetractionRationale = r.RetiredRationale
 			if fileMatches != nil {
 				for _, fm := range fileMatches[r.Module.Path] {
 					jm.SourceFiles = append(jm.SourceFiles, JSONSourceFile{
-						File:   fm.File,
-						Line:   fm.Line,
-						Import: fm.ImportPath,
+						File:      fm.File,
+						Line:      fm.Line,
+						Import:    fm.ImportPath,
+						UsageKind: fm.UsageKind,
 					})
 				}
 			}
@@ -797,14 +842,19 @@ func buildJSONOutput(cfg *Config, results []RepoStatus, nonGitHubModules []Modul
 		}
 	}
 
+	out.Unknown = buildUnknownJSON(results)
+
 	// Add stale modules if provided.
 	for _, r := range staleResults {
 		jm := JSONModule{
-			Module:  r.Module.Path,
-			Version: r.Module.Version,
-			Direct:  r.Module.Direct,
-			Owner:   r.Module.Owner,
-			Repo:    r.Module.Repo,
+			FindingID:  findingID(findingStale, r.Module.Path),
+			Module:     r.Module.Path,
+			Version:    r.Module.Version,
+			Direct:     r.Module.Direct,
+			Owner:      r.Module.Owner,
+			Repo:       r.Module.Repo,
+			RequiredAt: requiredAt(r.Module),
+			Tags:       r.Module.Tags,
 		}
 		if !r.PushedAt.IsZero() {
 			jm.PushedAt = r.PushedAt.Format("2006-01-02T15:04:05Z")
@@ -819,12 +869,15 @@ func buildJSONOutput(cfg *Config, results []RepoStatus, nonGitHubModules []Modul
 	if len(deprecatedModules) > 0 && len(deprecatedModules[0]) > 0 {
 		for _, m := range deprecatedModules[0] {
 			out.Deprecated = append(out.Deprecated, JSONModule{
+				FindingID:         findingID(findingDeprecated, m.Path),
 				Module:            m.Path,
 				Version:           m.Version,
 				Direct:            m.Direct,
 				Owner:             m.Owner,
 				Repo:              m.Repo,
 				DeprecatedMessage: m.Deprecated,
+				RequiredAt:        requiredAt(m),
+				Tags:              m.Tags,
 			})
 		}
 	}
@@ -837,9 +890,25 @@ func buildJSONOutput(cfg *Config, results []RepoStatus, nonGitHubModules []Modul
 // staleResults and deprecatedModules are optional; pass nil if not applicable.
 func PrintJSON(cfg *Config, results []RepoStatus, nonGitHubModules []Module, fileMatches map[string][]FileMatch, staleResults []RepoStatus, deprecatedModules ...[]Module) {
 	out := buildJSONOutput(cfg, results, nonGitHubModules, fileMatches, staleResults, deprecatedModules...)
-	enc := json.NewEncoder(os.Stdout)
+	out.Errors = strictErrors(cfg)
+	writeJSON(out)
+}
+
+// writeJSON encodes v to stdout with two-space indentation. With --sign,
+// the exact bytes written are also signed; a report that cannot be signed
+// fails the run rather than leaving it unverifiable.
+func writeJSON(v any) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
 	enc.SetIndent("", "  ")
-	_ = enc.Encode(out)
+	_ = enc.Encode(v)
+	_, _ = os.Stdout.Write(buf.Bytes())
+	if reportSigner != nil {
+		if err := reportSigner.sign(buf.Bytes()); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: could not sign report: %v\n", err)
+			os.Exit(2)
+		}
+	}
 }
 
 // formatArchivedLine returns a formatted string with version, archived date, and last pushed date.
@@ -854,7 +923,7 @@ func formatArchivedLine(cfg *Config, modPath, version string, rs RepoStatus) str
 	b.WriteString(" [ARCHIVED")
 	if !rs.ArchivedAt.IsZero() {
 		b.WriteString(" ")
-		b.WriteString(fmtDate(cfg, rs.ArchivedAt))
+		b.WriteString(fmtArchivedAt(cfg, rs))
 	}
 	if dur := formatDurationShort(cfg, rs.ArchivedAt); dur != "" {
 		b.WriteString(", ")
@@ -879,6 +948,7 @@ type treeContext struct {
 	archivedPaths    map[string]bool
 	deprecatedByPath map[string]string // module path → deprecation message
 	versionByPath    map[string]string
+	requiredAtByPath map[string]string // module path → go.mod:LINE of its require
 	getStatus        func(string) (RepoStatus, bool)
 }
 
@@ -890,7 +960,7 @@ func buildTree(results []RepoStatus, graph map[string][]string, allModules []Mod
 	archivedPaths := make(map[string]bool)
 	for _, r := range results {
 		if r.IsArchived {
-			statusByRepo[r.Module.Owner+"/"+r.Module.Repo] = r
+			statusByRepo[repoKey(r.Module)] = r
 			archivedPaths[r.Module.Path] = true
 		}
 	}
@@ -898,13 +968,13 @@ func buildTree(results []RepoStatus, graph map[string][]string, allModules []Mod
 	repoToModules := make(map[string][]string)
 	for _, m := range allModules {
 		if m.Owner != "" {
-			key := m.Owner + "/" + m.Repo
+			key := repoKey(m)
 			repoToModules[key] = append(repoToModules[key], m.Path)
 		}
 	}
 	for _, r := range results {
 		if r.IsArchived {
-			for _, p := range repoToModules[r.Module.Owner+"/"+r.Module.Repo] {
+			for _, p := range repoToModules[repoKey(r.Module)] {
 				archivedPaths[p] = true
 			}
 		}
@@ -914,10 +984,12 @@ func buildTree(results []RepoStatus, graph map[string][]string, allModules []Mod
 	versionByPath := make(map[string]string)
 	repoByPath := make(map[string]string)       // module path → "owner/repo"
 	deprecatedByPath := make(map[string]string) // module path → deprecation message
+	requiredAtByPath := make(map[string]string)
 	for _, m := range allModules {
 		versionByPath[m.Path] = m.Version
+		requiredAtByPath[m.Path] = requiredAt(m)
 		if m.Owner != "" {
-			repoByPath[m.Path] = m.Owner + "/" + m.Repo
+			repoByPath[m.Path] = repoKey(m)
 		}
 		if m.Deprecated != "" {
 			deprecatedByPath[m.Path] = m.Deprecated
@@ -941,6 +1013,7 @@ func buildTree(results []RepoStatus, graph map[string][]string, allModules []Mod
 		archivedPaths:    archivedPaths,
 		deprecatedByPath: deprecatedByPath,
 		versionByPath:    versionByPath,
+		requiredAtByPath: requiredAtByPath,
 		getStatus:        getStatus,
 	}
 
@@ -978,12 +1051,15 @@ func buildTree(results []RepoStatus, graph map[string][]string, allModules []Mod
 		return entries, ctx
 	}
 
-	// For each direct dependency (child of root), find archived transitive deps
+	// For each direct dependency (child of root), find archived transitive
+	// deps. Direct deps share most of their subtrees, so the walk is memoized
+	// across entries.
+	reach := newArchivedReach(graph, archivedPaths)
 	var entries []treeEntry
 	for _, child := range graph[rootKey] {
 		childMod := stripVersion(child)
 		selfArchived := archivedPaths[childMod]
-		archivedTransitive := findArchivedTransitive(child, graph, archivedPaths, make(map[string]bool))
+		archivedTransitive := reach.from(child)
 
 		if selfArchived || len(archivedTransitive) > 0 {
 			entry := treeEntry{directPath: childMod}
@@ -1072,38 +1148,15 @@ func PrintTree(cfg *Config, results []RepoStatus, graph map[string][]string, all
 	}
 }
 
-// JSONTreeOutput is the structure for --tree --json output mode.
-type JSONTreeOutput struct {
-	Tree             []JSONTreeEntry     `json:"tree"`
-	Deprecated       []JSONModule        `json:"deprecated,omitempty"`
-	NonGitHubCount   int                 `json:"non_github_count"`
-	NonGitHubModules []JSONSkippedModule `json:"non_github_modules,omitempty"`
-	TotalChecked     int                 `json:"total_checked"`
-}
+// JSONTreeOutput is the structure for --tree --json output mode: a Result
+// whose Tree is set.
+type JSONTreeOutput = report.Result
 
 // JSONTreeEntry represents a direct dependency in the JSON tree.
-type JSONTreeEntry struct {
-	Module               string                `json:"module"`
-	Version              string                `json:"version"`
-	Archived             bool                  `json:"archived"`
-	ArchivedAt           string                `json:"archived_at,omitempty"`
-	ArchivedDuration     string                `json:"archived_duration,omitempty"`
-	PushedAt             string                `json:"pushed_at,omitempty"`
-	DeprecatedMessage    string                `json:"deprecated_message,omitempty"`
-	SourceFiles          []JSONSourceFile      `json:"source_files,omitempty"`
-	ArchivedDependencies []JSONTreeArchivedDep `json:"archived_dependencies"`
-}
+type JSONTreeEntry = report.TreeEntry
 
 // JSONTreeArchivedDep represents an archived transitive dependency.
-type JSONTreeArchivedDep struct {
-	Module            string           `json:"module"`
-	Version           string           `json:"version"`
-	ArchivedAt        string           `json:"archived_at,omitempty"`
-	ArchivedDuration  string           `json:"archived_duration,omitempty"`
-	PushedAt          string           `json:"pushed_at,omitempty"`
-	DeprecatedMessage string           `json:"deprecated_message,omitempty"`
-	SourceFiles       []JSONSourceFile `json:"source_files,omitempty"`
-}
+type JSONTreeArchivedDep = report.TreeArchivedDep
 
 // buildTreeJSONOutput creates the JSONTreeOutput data structure without writing it.
 // deprecatedModules is optional; if provided, the first element is used.
@@ -1114,14 +1167,17 @@ func buildTreeJSONOutput(cfg *Config, results []RepoStatus, graph map[string][]s
 		Tree:           []JSONTreeEntry{},
 		NonGitHubCount: len(nonGitHubModules),
 		TotalChecked:   len(results),
+		Meta:           computeRepoTotals(results),
+		Unknown:        buildUnknownJSON(results),
 	}
 
 	for _, m := range nonGitHubModules {
 		jsm := JSONSkippedModule{
-			Module:  m.Path,
-			Version: m.Version,
-			Direct:  m.Direct,
-			Host:    hostDomain(m.Path),
+			Module:     m.Path,
+			Version:    m.Version,
+			Direct:     m.Direct,
+			Host:       hostDomain(m.Path),
+			RequiredAt: requiredAt(m),
 		}
 		if m.LatestVersion != "" {
 			jsm.LatestVersion = m.LatestVersion
@@ -1136,6 +1192,7 @@ func buildTreeJSONOutput(cfg *Config, results []RepoStatus, graph map[string][]s
 			if va := formatBehind(m); va != "" && va != "-" {
 				jsm.Behind = va
 			}
+			jsm.MajorUpgrade = buildMajorUpgradeJSON(m)
 		}
 		out.NonGitHubModules = append(out.NonGitHubModules, jsm)
 	}
@@ -1144,12 +1201,15 @@ func buildTreeJSONOutput(cfg *Config, results []RepoStatus, graph map[string][]s
 	if len(deprecatedModules) > 0 && len(deprecatedModules[0]) > 0 {
 		for _, m := range deprecatedModules[0] {
 			out.Deprecated = append(out.Deprecated, JSONModule{
+				FindingID:         findingID(findingDeprecated, m.Path),
 				Module:            m.Path,
 				Version:           m.Version,
 				Direct:            m.Direct,
 				Owner:             m.Owner,
 				Repo:              m.Repo,
 				DeprecatedMessage: m.Deprecated,
+				RequiredAt:        requiredAt(m),
+				Tags:              m.Tags,
 			})
 		}
 	}
@@ -1165,9 +1225,10 @@ func buildTreeJSONOutput(cfg *Config, results []RepoStatus, graph map[string][]s
 		var sf []JSONSourceFile
 		for _, fm := range fileMatches[modPath] {
 			sf = append(sf, JSONSourceFile{
-				File:   fm.File,
-				Line:   fm.Line,
-				Import: fm.ImportPath,
+				File:      fm.File,
+				Line:      fm.Line,
+				Import:    fm.ImportPath,
+				UsageKind: fm.UsageKind,
 			})
 		}
 		return sf
@@ -1179,14 +1240,22 @@ func buildTreeJSONOutput(cfg *Config, results []RepoStatus, graph map[string][]s
 			Version:              ctx.versionByPath[e.directPath],
 			Archived:             ctx.archivedPaths[e.directPath],
 			DeprecatedMessage:    ctx.deprecatedByPath[e.directPath],
+			RequiredAt:           ctx.requiredAtByPath[e.directPath],
 			ArchivedDependencies: []JSONTreeArchivedDep{},
 		}
+		switch {
+		case entry.Archived:
+			entry.FindingID = findingID(findingArchived, e.directPath)
+		case entry.DeprecatedMessage != "":
+			entry.FindingID = findingID(findingDeprecated, e.directPath)
+		}
 
 		if entry.Archived {
 			if rs, ok := ctx.getStatus(e.directPath); ok {
 				if !rs.ArchivedAt.IsZero() {
 					entry.ArchivedAt = rs.ArchivedAt.Format("2006-01-02T15:04:05Z")
 				}
+				entry.ArchivedAtSource, entry.ArchivedAtPrecision = archivedAtProvenance(rs)
 				if dur := formatDuration(cfg, rs.ArchivedAt); dur != "" {
 					entry.ArchivedDuration = dur
 				}
@@ -1205,14 +1274,17 @@ func buildTreeJSONOutput(cfg *Config, results []RepoStatus, graph map[string][]s
 			seen[a] = true
 
 			dep := JSONTreeArchivedDep{
+				FindingID:         findingID(findingArchived, a),
 				Module:            a,
 				Version:           ctx.versionByPath[a],
 				DeprecatedMessage: ctx.deprecatedByPath[a],
+				RequiredAt:        ctx.requiredAtByPath[a],
 			}
 			if rs, ok := ctx.getStatus(a); ok {
 				if !rs.ArchivedAt.IsZero() {
 					dep.ArchivedAt = rs.ArchivedAt.Format("2006-01-02T15:04:05Z")
 				}
+				dep.ArchivedAtSource, dep.ArchivedAtPrecision = archivedAtProvenance(rs)
 				if dur := formatDuration(cfg, rs.ArchivedAt); dur != "" {
 					dep.ArchivedDuration = dur
 				}
@@ -1234,36 +1306,16 @@ func buildTreeJSONOutput(cfg *Config, results []RepoStatus, graph map[string][]s
 // deprecatedModules is optional; if provided, the first element is used.
 func PrintTreeJSON(cfg *Config, results []RepoStatus, graph map[string][]string, allModules []Module, fileMatches map[string][]FileMatch, nonGitHubModules []Module, deprecatedModules ...[]Module) {
 	out := buildTreeJSONOutput(cfg, results, graph, allModules, fileMatches, nonGitHubModules, deprecatedModules...)
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(out)
+	out.Errors = strictErrors(cfg)
+	writeJSON(out)
 }
 
-// RecursiveJSONOutput wraps per-module results for --recursive --json.
-type RecursiveJSONOutput struct {
-	Modules []RecursiveJSONEntry `json:"modules"`
-}
+// RecursiveJSONOutput wraps per-module results for --recursive --json,
+// with or without --tree.
+type RecursiveJSONOutput = report.Document
 
 // RecursiveJSONEntry holds results for a single go.mod in recursive mode.
-type RecursiveJSONEntry struct {
-	GoMod      string `json:"go_mod"`
-	ModulePath string `json:"module_path"`
-	GoVersion  string `json:"go_version,omitempty"`
-	JSONOutput
-}
-
-// RecursiveJSONTreeOutput wraps per-module tree results for --recursive --tree --json.
-type RecursiveJSONTreeOutput struct {
-	Modules []RecursiveJSONTreeEntry `json:"modules"`
-}
-
-// RecursiveJSONTreeEntry holds tree results for a single go.mod in recursive mode.
-type RecursiveJSONTreeEntry struct {
-	GoMod      string `json:"go_mod"`
-	ModulePath string `json:"module_path"`
-	GoVersion  string `json:"go_version,omitempty"`
-	JSONTreeOutput
-}
+type RecursiveJSONEntry = report.Project
 
 // allSeen returns true if all items in slice are already in the seen set.
 func allSeen(items []string, seen map[string]bool) bool {
@@ -1283,19 +1335,102 @@ func stripVersion(s string) string {
 	return s
 }
 
-func findArchivedTransitive(node string, graph map[string][]string, archivedPaths map[string]bool, visited map[string]bool) []string {
-	if visited[node] {
-		return nil
+// archivedReach memoizes, per graph node, the archived module paths
+// reachable from it, so shared subtrees are walked once however many
+// direct dependencies lead into them. Nodes on a cycle form one strongly
+// connected component (Tarjan's algorithm) and share one result, which
+// keeps the memoization correct when the graph has cycles.
+type archivedReach struct {
+	graph    map[string][]string
+	archived map[string]bool     // archived module paths
+	memo     map[string][]string // node → sorted archived paths reachable from it
+
+	// Tarjan state
+	index   map[string]int
+	low     map[string]int
+	onStack map[string]bool
+	stack   []string
+	next    int
+}
+
+func newArchivedReach(graph map[string][]string, archivedPaths map[string]bool) *archivedReach {
+	return &archivedReach{
+		graph:    graph,
+		archived: archivedPaths,
+		memo:     make(map[string][]string),
+		index:    make(map[string]int),
+		low:      make(map[string]int),
+		onStack:  make(map[string]bool),
 	}
-	visited[node] = true
+}
 
-	var result []string
-	for _, child := range graph[node] {
-		childMod := stripVersion(child)
-		if archivedPaths[childMod] {
-			result = append(result, childMod)
+// from returns the archived module paths reachable from node (excluding
+// node itself unless a cycle leads back to it), sorted and deduplicated.
+// The returned slice is shared; callers must not modify it.
+func (a *archivedReach) from(node string) []string {
+	if _, done := a.index[node]; !done {
+		a.visit(node)
+	}
+	return a.memo[node]
+}
+
+// visit runs one step of Tarjan's algorithm from node. When node is the
+// root of a component, the component's archived set is computed from its
+// members' edges and the (already final) sets of the components they lead to.
+func (a *archivedReach) visit(node string) {
+	a.index[node] = a.next
+	a.low[node] = a.next
+	a.next++
+	a.stack = append(a.stack, node)
+	a.onStack[node] = true
+
+	for _, child := range a.graph[node] {
+		if _, seen := a.index[child]; !seen {
+			a.visit(child)
+			a.low[node] = min(a.low[node], a.low[child])
+		} else if a.onStack[child] {
+			a.low[node] = min(a.low[node], a.index[child])
+		}
+	}
+	if a.low[node] != a.index[node] {
+		return
+	}
+
+	var members []string
+	inComponent := make(map[string]bool)
+	for {
+		top := a.stack[len(a.stack)-1]
+		a.stack = a.stack[:len(a.stack)-1]
+		a.onStack[top] = false
+		members = append(members, top)
+		inComponent[top] = true
+		if top == node {
+			break
+		}
+	}
+
+	set := make(map[string]bool)
+	for _, m := range members {
+		for _, child := range a.graph[m] {
+			if mod := stripVersion(child); a.archived[mod] {
+				set[mod] = true
+			}
+			if !inComponent[child] {
+				for _, p := range a.memo[child] {
+					set[p] = true
+				}
+			}
 		}
-		result = append(result, findArchivedTransitive(child, graph, archivedPaths, visited)...)
 	}
-	return result
+	var reach []string
+	if len(set) > 0 {
+		reach = make([]string, 0, len(set))
+		for p := range set {
+			reach = append(reach, p)
+		}
+		sort.Strings(reach)
+	}
+	for _, m := range members {
+		a.memo[m] = reach
+	}
 }
@@ -3,8 +3,10 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -47,7 +49,7 @@ func TestAllSeen(t *testing.T) {
 	}
 }
 
-func TestFindArchivedTransitive(t *testing.T) {
+func TestArchivedReach(t *testing.T) {
 	graph := map[string][]string{
 		"root":                  {"github.com/a/b@v1.0.0", "github.com/c/d@v1.0.0"},
 		"github.com/a/b@v1.0.0": {"github.com/x/y@v1.0.0"},
@@ -60,13 +62,13 @@ func TestFindArchivedTransitive(t *testing.T) {
 		"github.com/x/y": true,
 	}
 
-	result := findArchivedTransitive("github.com/a/b@v1.0.0", graph, archivedPaths, make(map[string]bool))
+	result := newArchivedReach(graph, archivedPaths).from("github.com/a/b@v1.0.0")
 	if len(result) != 1 || result[0] != "github.com/x/y" {
 		t.Errorf("expected [github.com/x/y], got %v", result)
 	}
 }
 
-func TestFindArchivedTransitive_Cycle(t *testing.T) {
+func TestArchivedReach_Cycle(t *testing.T) {
 	// Ensure cycles don't cause infinite loops
 	graph := map[string][]string{
 		"a@v1": {"b@v1"},
@@ -75,13 +77,13 @@ func TestFindArchivedTransitive_Cycle(t *testing.T) {
 
 	archivedPaths := map[string]bool{"b": true}
 
-	result := findArchivedTransitive("a@v1", graph, archivedPaths, make(map[string]bool))
+	result := newArchivedReach(graph, archivedPaths).from("a@v1")
 	if len(result) != 1 || result[0] != "b" {
 		t.Errorf("expected [b], got %v", result)
 	}
 }
 
-func TestFindArchivedTransitive_Deep(t *testing.T) {
+func TestArchivedReach_Deep(t *testing.T) {
 	graph := map[string][]string{
 		"a@v1": {"b@v1"},
 		"b@v1": {"c@v1"},
@@ -91,13 +93,13 @@ func TestFindArchivedTransitive_Deep(t *testing.T) {
 
 	archivedPaths := map[string]bool{"d": true}
 
-	result := findArchivedTransitive("a@v1", graph, archivedPaths, make(map[string]bool))
+	result := newArchivedReach(graph, archivedPaths).from("a@v1")
 	if len(result) != 1 || result[0] != "d" {
 		t.Errorf("expected [d], got %v", result)
 	}
 }
 
-func TestFindArchivedTransitive_NoArchived(t *testing.T) {
+func TestArchivedReach_NoArchived(t *testing.T) {
 	graph := map[string][]string{
 		"a@v1": {"b@v1"},
 		"b@v1": {},
@@ -105,12 +107,111 @@ func TestFindArchivedTransitive_NoArchived(t *testing.T) {
 
 	archivedPaths := map[string]bool{}
 
-	result := findArchivedTransitive("a@v1", graph, archivedPaths, make(map[string]bool))
+	result := newArchivedReach(graph, archivedPaths).from("a@v1")
 	if len(result) != 0 {
 		t.Errorf("expected empty, got %v", result)
 	}
 }
 
+func TestArchivedReach_SharedSubtree(t *testing.T) {
+	// Both direct deps reach the same archived leaf through a shared node;
+	// the second lookup reuses the first's result.
+	graph := map[string][]string{
+		"a@v1":      {"shared@v1"},
+		"b@v1":      {"shared@v1", "x@v1"},
+		"shared@v1": {"y@v1"},
+		"x@v1":      {},
+		"y@v1":      {},
+	}
+	reach := newArchivedReach(graph, map[string]bool{"y": true, "x": true})
+
+	if got := reach.from("a@v1"); !reflect.DeepEqual(got, []string{"y"}) {
+		t.Errorf("from(a) = %v, want [y]", got)
+	}
+	visited := reach.next
+	if got := reach.from("b@v1"); !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Errorf("from(b) = %v, want [x y]", got)
+	}
+	// Only b and x are new; shared and y come from the memo.
+	if walked := reach.next - visited; walked != 2 {
+		t.Errorf("second lookup visited %d nodes, want 2", walked)
+	}
+}
+
+func TestArchivedReach_CycleWithExit(t *testing.T) {
+	// a → b → c → a is a cycle; c also leads out to archived d. Every
+	// member of the cycle reaches d, and b and c are archived themselves.
+	graph := map[string][]string{
+		"a@v1": {"b@v1"},
+		"b@v1": {"c@v1"},
+		"c@v1": {"a@v1", "d@v1"},
+		"d@v1": {},
+	}
+	reach := newArchivedReach(graph, map[string]bool{"b": true, "d": true})
+	for _, node := range []string{"c@v1", "a@v1", "b@v1"} {
+		if got := reach.from(node); !reflect.DeepEqual(got, []string{"b", "d"}) {
+			t.Errorf("from(%s) = %v, want [b d]", node, got)
+		}
+	}
+	if got := reach.from("d@v1"); got != nil {
+		t.Errorf("from(d) = %v, want nil", got)
+	}
+}
+
+func TestArchivedReach_MatchesNaiveWalk(t *testing.T) {
+	// Pseudo-random dense graphs with cycles: the memoized result must
+	// equal a plain reachability walk from every node.
+	seed := uint32(1)
+	rnd := func(n int) int {
+		seed = seed*1664525 + 1013904223
+		return int(seed>>16) % n
+	}
+	for trial := 0; trial < 20; trial++ {
+		const nodes = 30
+		graph := make(map[string][]string)
+		archived := make(map[string]bool)
+		for i := 0; i < nodes; i++ {
+			node := fmt.Sprintf("m%d@v1", i)
+			for e := rnd(4); e > 0; e-- {
+				graph[node] = append(graph[node], fmt.Sprintf("m%d@v1", rnd(nodes)))
+			}
+			if rnd(4) == 0 {
+				archived[fmt.Sprintf("m%d", i)] = true
+			}
+		}
+
+		reach := newArchivedReach(graph, archived)
+		for i := 0; i < nodes; i++ {
+			node := fmt.Sprintf("m%d@v1", i)
+			seen := map[string]bool{}
+			want := map[string]bool{}
+			queue := []string{node}
+			for len(queue) > 0 {
+				n := queue[0]
+				queue = queue[1:]
+				for _, c := range graph[n] {
+					if archived[stripVersion(c)] {
+						want[stripVersion(c)] = true
+					}
+					if !seen[c] {
+						seen[c] = true
+						queue = append(queue, c)
+					}
+				}
+			}
+			got := reach.from(node)
+			if len(got) != len(want) {
+				t.Fatalf("trial %d: from(%s) = %v, want %d paths %v", trial, node, got, len(want), want)
+			}
+			for _, p := range got {
+				if !want[p] {
+					t.Fatalf("trial %d: from(%s) has unexpected %s", trial, node, p)
+				}
+			}
+		}
+	}
+}
+
 func TestFmtDate(t *testing.T) {
 	ts := time.Date(2024, 7, 22, 14, 30, 45, 0, time.UTC)
 
@@ -212,6 +313,26 @@ func captureStdout(t *testing.T, fn func()) string {
 	return buf.String()
 }
 
+// captureStderr captures stderr output during fn execution.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	old := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = w
+
+	fn()
+
+	_ = w.Close()
+	os.Stderr = old
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	return buf.String()
+}
+
 // defaultTestConfig returns a Config with defaults suitable for most tests.
 func defaultTestConfig() *Config {
 	return &Config{
@@ -636,7 +757,7 @@ func TestPrintFiles_BasicOutput(t *testing.T) {
 	}
 
 	output := captureStdout(t, func() {
-		PrintFiles(results, fileMatches)
+		PrintFiles(results, fileMatches, nil)
 	})
 
 	if !strings.Contains(output, "github.com/baz/qux (1 file)") {
@@ -664,7 +785,7 @@ func TestPrintFiles_ZeroFiles(t *testing.T) {
 	fileMatches := map[string][]FileMatch{}
 
 	output := captureStdout(t, func() {
-		PrintFiles(results, fileMatches)
+		PrintFiles(results, fileMatches, nil)
 	})
 
 	if !strings.Contains(output, "github.com/foo/bar (0 files)") {
@@ -1495,6 +1616,40 @@ func TestPrintFilesPlain(t *testing.T) {
 	}
 }
 
+func TestPrintProblems(t *testing.T) {
+	cfg := defaultTestConfig()
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/foo/bar", Owner: "foo", Repo: "bar"}, IsArchived: true,
+			ArchivedAt: time.Date(2024, 7, 22, 0, 0, 0, 0, time.UTC)},
+		{Module: Module{Path: "github.com/baz/qux", Owner: "baz", Repo: "qux"}, IsArchived: true},
+		{Module: Module{Path: "github.com/ok/lib", Owner: "ok", Repo: "lib"}},
+	}
+
+	fileMatches := map[string][]FileMatch{
+		"github.com/foo/bar": {{File: "audit/hash.go", Line: 14, Col: 2, ImportPath: "github.com/foo/bar"}},
+		"github.com/baz/qux": {{File: "cmd/main.go", Line: 5, ImportPath: "github.com/baz/qux"}},
+		"github.com/ok/lib":  {{File: "cmd/main.go", Line: 6, Col: 2, ImportPath: "github.com/ok/lib"}},
+	}
+
+	output := captureStdout(t, func() {
+		PrintProblems(cfg, "svc", results, fileMatches)
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	want := []string{
+		"svc/cmd/main.go:5:1: warning: import of archived module github.com/baz/qux",
+		"svc/audit/hash.go:14:2: warning: import of archived module github.com/foo/bar (archived 2024-07-22)",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(want), len(lines), output)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
 func TestParseThreshold(t *testing.T) {
 	tests := []struct {
 		input   string
@@ -1845,3 +2000,74 @@ func TestPrintIgnoredTable_Empty(t *testing.T) {
 		t.Errorf("expected no output for empty ignored list, got %q", output)
 	}
 }
+
+func TestBuildJSONOutput_ArchivedAtProvenance(t *testing.T) {
+	cfg := defaultTestConfig()
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/a/exact", Owner: "a", Repo: "exact"}, IsArchived: true,
+			ArchivedAt: time.Date(2024, 7, 22, 0, 0, 0, 0, time.UTC), ArchivedAtSource: archivedAtGitHub},
+		{Module: Module{Path: "github.com/b/undated", Owner: "b", Repo: "undated"}, IsArchived: true,
+			PushedAt: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), ArchivedAtSource: archivedAtUnknown},
+		{Module: Module{Path: "github.com/c/active", Owner: "c", Repo: "active"}},
+	}
+	cfg.ShowAll = true
+	out := buildJSONOutput(cfg, results, nil, nil, nil, nil)
+
+	want := map[string][2]string{
+		"github.com/a/exact":   {"github", "second"},
+		"github.com/b/undated": {"unknown", ""},
+	}
+	for _, m := range out.Archived {
+		if got := [2]string{m.ArchivedAtSource, m.ArchivedAtPrecision}; got != want[m.Module] {
+			t.Errorf("%s: source/precision = %v, want %v", m.Module, got, want[m.Module])
+		}
+		if m.ArchivedAtSource == archivedAtUnknown && (m.ArchivedAt != "" || m.PushedAt == "") {
+			t.Errorf("%s: archived_at = %q, pushed_at = %q; want no archived_at and the push date", m.Module, m.ArchivedAt, m.PushedAt)
+		}
+	}
+	for _, m := range out.Active {
+		if m.ArchivedAtSource != "" || m.ArchivedAtPrecision != "" {
+			t.Errorf("%s: active module should have no archived_at provenance", m.Module)
+		}
+	}
+}
+
+func TestBuildJSONOutput_FindingIDs(t *testing.T) {
+	cfg := defaultTestConfig()
+	cfg.ShowAll = true
+	archived := RepoStatus{Module: Module{Path: "github.com/a/archived", Version: "v1.0.0", Owner: "a", Repo: "archived"}, IsArchived: true}
+	upgraded := archived
+	upgraded.Module.Version = "v1.1.0"
+	results := []RepoStatus{
+		archived,
+		{Module: Module{Path: "github.com/b/gone", Owner: "b", Repo: "gone"}, NotFound: true},
+		{Module: Module{Path: "github.com/c/active", Owner: "c", Repo: "active"}},
+	}
+	stale := []RepoStatus{{Module: Module{Path: "github.com/d/stale", Owner: "d", Repo: "stale"}}}
+	deprecated := []Module{{Path: "github.com/e/old", Deprecated: "use new"}}
+
+	out := buildJSONOutput(cfg, results, nil, nil, stale, deprecated)
+
+	checks := []struct {
+		got, kind, path string
+	}{
+		{out.Archived[0].FindingID, findingArchived, "github.com/a/archived"},
+		{out.NotFound[0].FindingID, findingNotFound, "github.com/b/gone"},
+		{out.Stale[0].FindingID, findingStale, "github.com/d/stale"},
+		{out.Deprecated[0].FindingID, findingDeprecated, "github.com/e/old"},
+	}
+	for _, c := range checks {
+		if want := findingID(c.kind, c.path); c.got != want {
+			t.Errorf("%s finding_id = %q, want %q", c.path, c.got, want)
+		}
+	}
+	if id := out.Active[0].FindingID; id != "" {
+		t.Errorf("active module has finding_id %q, want none", id)
+	}
+
+	// The ID survives a version bump that leaves the module archived.
+	again := buildJSONOutput(cfg, []RepoStatus{upgraded}, nil, nil, nil)
+	if again.Archived[0].FindingID != out.Archived[0].FindingID {
+		t.Errorf("finding_id changed across versions: %q → %q", out.Archived[0].FindingID, again.Archived[0].FindingID)
+	}
+}
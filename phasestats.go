@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+)
+
+// phaseStats reports wall time and heap use after each pipeline phase for
+// --phase-stats, so memory growth on very large go.mod files can be traced
+// to the phase responsible, and records phase durations in the run summary
+// for --pushgateway. A nil *phaseStats reports nothing.
+type phaseStats struct {
+	w       io.Writer   // nil when only recording
+	summary *runSummary // receives phase durations; may be nil
+	start   time.Time
+	last    time.Time
+	peak    uint64 // highest heap in use seen at any mark
+}
+
+// newPhaseStats returns a tracker writing to stderr with --phase-stats and
+// recording phase durations with --pushgateway, or nil with neither.
+func newPhaseStats(cfg *Config) *phaseStats {
+	if !cfg.PhaseStats && cfg.Pushgateway == "" {
+		return nil
+	}
+	now := time.Now()
+	p := &phaseStats{summary: &cfg.Summary, start: now, last: now}
+	if cfg.PhaseStats {
+		p.w = os.Stderr
+	}
+	return p
+}
+
+// mark reports the phase that just finished and how many modules it held.
+func (p *phaseStats) mark(phase string, modules int) {
+	if p == nil {
+		return
+	}
+	now := time.Now()
+	if p.summary != nil {
+		p.summary.phases = append(p.summary.phases, phaseTiming{Phase: phase, Duration: now.Sub(p.last)})
+	}
+	if p.w == nil {
+		p.last = now
+		return
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	p.peak = max(p.peak, ms.HeapInuse)
+	_, _ = fmt.Fprintf(p.w, "phase %-12s %7.2fs  %6d modules  heap %7.1f MiB  peak %7.1f MiB\n",
+		phase, now.Sub(p.last).Seconds(), modules, mib(ms.HeapInuse), mib(p.peak))
+	p.last = now
+}
+
+// done reports the total run time and peak heap.
+func (p *phaseStats) done() {
+	if p == nil || p.w == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(p.w, "phase %-12s %7.2fs  peak heap %.1f MiB\n", "total", time.Since(p.start).Seconds(), mib(p.peak))
+}
+
+// mib converts a byte count to mebibytes.
+func mib(n uint64) float64 {
+	return float64(n) / (1 << 20)
+}
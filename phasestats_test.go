@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPhaseStats(t *testing.T) {
+	var buf bytes.Buffer
+	p := &phaseStats{w: &buf, start: time.Now(), last: time.Now()}
+	p.mark("parse", 4200)
+	p.mark("github", 1800)
+	p.done()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{"phase parse", "phase github", "phase total"} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[0], "4200 modules") || !strings.Contains(lines[0], "MiB") {
+		t.Errorf("parse line = %q, want module count and heap", lines[0])
+	}
+	if p.peak == 0 {
+		t.Error("peak heap not recorded")
+	}
+}
+
+func TestPhaseStats_Disabled(t *testing.T) {
+	p := newPhaseStats(NewDefaultConfig())
+	if p != nil {
+		t.Fatal("newPhaseStats() should return nil without --phase-stats or --pushgateway")
+	}
+	// A nil tracker is safe to use.
+	p.mark("parse", 1)
+	p.done()
+}
+
+func TestPhaseStats_RecordOnly(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Pushgateway = "http://pg:9091"
+	p := newPhaseStats(cfg)
+	if p == nil || p.w != nil {
+		t.Fatalf("newPhaseStats() = %+v, want a recording tracker that writes nothing", p)
+	}
+	p.mark("parse", 10)
+	p.mark("github", 10)
+	p.done()
+	if len(cfg.Summary.phases) != 2 || cfg.Summary.phases[0].Phase != "parse" || cfg.Summary.phases[1].Phase != "github" {
+		t.Errorf("phases = %+v, want parse then github", cfg.Summary.phases)
+	}
+}
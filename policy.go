@@ -0,0 +1,388 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/norman-abramovitz/modrot/report"
+	"golang.org/x/mod/semver"
+)
+
+// PolicyRule is one --policy rule. A dependency whose GitHub repository
+// or required version matches the rule is reported as a policy warning.
+//
+//	topic:experimental          repo is tagged with the "experimental" topic
+//	property:lifecycle=sunset   repo custom property "lifecycle" is "sunset"
+//	property:lifecycle          repo has any value for custom property "lifecycle"
+//	version:<v1.0.0             required version is below v1.0.0 (pre-1.0)
+//	topic:pre-1.0@critical      as topic:pre-1.0, for modules tagged critical only
+//
+// Version rules compare with <, <=, >, >=, or = by semantic version order,
+// so pseudo-versions count as the release they precede.
+// A rule ending in @TAG applies only to modules carrying that criticality
+// tag (tags in .modrot.yaml), so critical dependencies can be held to
+// stricter rules than the rest.
+type PolicyRule struct {
+	Kind  string // "topic", "property", or "version"
+	Name  string // topic name, custom property name, or version comparison operator
+	Value string // required property value ("" matches any value) or version bound
+	Tag   string // criticality tag the rule is limited to; "" applies to all modules
+}
+
+// String returns the rule in --policy syntax.
+func (r PolicyRule) String() string {
+	s := r.Kind + ":" + r.Name
+	switch {
+	case r.Kind == "version":
+		s += r.Value
+	case r.Kind == "property" && r.Value != "":
+		s += "=" + r.Value
+	}
+	if r.Tag != "" {
+		s += "@" + r.Tag
+	}
+	return s
+}
+
+// parsePolicyRules parses a comma-separated --policy value.
+func parsePolicyRules(s string) ([]PolicyRule, error) {
+	var rules []PolicyRule
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kind, spec, ok := strings.Cut(part, ":")
+		if !ok || spec == "" {
+			return nil, fmt.Errorf("invalid policy rule %q (want topic:NAME, property:NAME[=VALUE], or version:OPVERSION, optionally @TAG)", part)
+		}
+		var tag string
+		if i := strings.LastIndex(spec, "@"); i >= 0 {
+			spec, tag = spec[:i], strings.ToLower(spec[i+1:])
+			if spec == "" || tag == "" {
+				return nil, fmt.Errorf("invalid policy rule %q (want RULE@TAG)", part)
+			}
+		}
+		switch kind {
+		case "topic":
+			rules = append(rules, PolicyRule{Kind: kind, Name: strings.ToLower(spec), Tag: tag})
+		case "property":
+			name, value, _ := strings.Cut(spec, "=")
+			rules = append(rules, PolicyRule{Kind: kind, Name: name, Value: value, Tag: tag})
+		case "version":
+			op, version := cutVersionOp(spec)
+			if op == "" || !semver.IsValid(version) {
+				return nil, fmt.Errorf("invalid policy rule %q (want version:OPVERSION, e.g. version:<v1.0.0)", part)
+			}
+			rules = append(rules, PolicyRule{Kind: kind, Name: op, Value: version, Tag: tag})
+		default:
+			return nil, fmt.Errorf("invalid policy rule %q: unknown kind %q (want topic, property, or version)", part, kind)
+		}
+	}
+	return rules, nil
+}
+
+// cutVersionOp splits a version rule such as "<v1.0.0" into its comparison
+// operator and version. The operator is "" if spec starts with none.
+func cutVersionOp(spec string) (op, version string) {
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if v, ok := strings.CutPrefix(spec, op); ok {
+			return op, strings.TrimSpace(v)
+		}
+	}
+	return "", spec
+}
+
+// versionMatches reports whether version satisfies the rule's comparison.
+// Invalid versions, such as those of local replacements, never match.
+func versionMatches(rule PolicyRule, version string) bool {
+	if !semver.IsValid(version) {
+		return false
+	}
+	c := semver.Compare(version, rule.Value)
+	switch rule.Name {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	default:
+		return c == 0
+	}
+}
+
+// PolicyViolation is a dependency repository that matched a policy rule.
+type PolicyViolation struct {
+	Status  RepoStatus
+	Rule    PolicyRule
+	Matched string // the topic, "name=value" property, or version that matched
+}
+
+// evaluatePolicy checks every found repository against the rules. A rule
+// with a Tag only checks the modules carrying that tag.
+func evaluatePolicy(rules []PolicyRule, results []RepoStatus) []PolicyViolation {
+	var out []PolicyViolation
+	for _, r := range results {
+		if r.NotFound {
+			continue
+		}
+		for _, rule := range rules {
+			if rule.Tag != "" && !slices.Contains(r.Module.Tags, rule.Tag) {
+				continue
+			}
+			switch rule.Kind {
+			case "topic":
+				for _, t := range r.Topics {
+					if strings.EqualFold(t, rule.Name) {
+						out = append(out, PolicyViolation{Status: r, Rule: rule, Matched: t})
+						break
+					}
+				}
+			case "property":
+				if v, ok := r.Properties[rule.Name]; ok && (rule.Value == "" || v == rule.Value) {
+					out = append(out, PolicyViolation{Status: r, Rule: rule, Matched: rule.Name + "=" + v})
+				}
+			case "version":
+				if versionMatches(rule, r.Module.Version) {
+					out = append(out, PolicyViolation{Status: r, Rule: rule, Matched: r.Module.Version})
+				}
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Status.Module.Path < out[j].Status.Module.Path
+	})
+	return out
+}
+
+// fetchPolicyMetadata fills Topics and Properties on results with the
+// repository metadata the policy rules need: topics via GraphQL, custom
+// properties via the REST API (they are not exposed over GraphQL).
+// Failures are reported as degradations.
+func fetchPolicyMetadata(cfg *Config, results []RepoStatus) {
+	if len(cfg.Policy) == 0 || len(results) == 0 {
+		return
+	}
+	token, err := getGHToken()
+	if err != nil {
+		warnDegraded(cfg, "policy", "could not fetch repository metadata: %v", err)
+		return
+	}
+	if err := fetchPolicyMetadataWithClient(cfg.Policy, results, cfg.Workers, token, newGHClient()); err != nil {
+		warnDegraded(cfg, "policy", "could not fetch repository metadata: %v", err)
+	}
+}
+
+// fetchPolicyMetadataWithClient is the internal implementation that accepts
+// a ghClient, allowing tests to inject mock HTTP servers.
+func fetchPolicyMetadataWithClient(rules []PolicyRule, results []RepoStatus, batchSize int, token string, gc *ghClient) error {
+	var wantTopics, wantProps bool
+	for _, r := range rules {
+		wantTopics = wantTopics || r.Kind == "topic"
+		wantProps = wantProps || r.Kind == "property"
+	}
+
+	var idx []int
+	for i, r := range results {
+		if !r.NotFound {
+			idx = append(idx, i)
+		}
+	}
+
+	if wantTopics {
+		for start := 0; start < len(idx); start += batchSize {
+			end := min(start+batchSize, len(idx))
+			batch := idx[start:end]
+			topics, err := gc.queryTopics(token, results, batch)
+			if err != nil {
+				return err
+			}
+			for j, i := range batch {
+				results[i].Topics = topics[j]
+			}
+		}
+	}
+
+	if wantProps {
+		// Custom properties are one REST call per repository; retry
+		// transient failures before giving up on the metadata.
+		opts := poolOptions{Workers: 10, Retries: 2, Backoff: 500 * time.Millisecond}
+		props, err := mapPool(context.Background(), idx, opts, func(_ context.Context, i int) (map[string]string, error) {
+			return gc.fetchCustomProperties(token, results[i].Module.Owner, results[i].Module.Repo)
+		})
+		if err != nil {
+			return err
+		}
+		for j, i := range idx {
+			results[i].Properties = props[j]
+		}
+	}
+	return nil
+}
+
+// queryTopics fetches repository topics for results[batch...] in one
+// GraphQL request. Returns topics parallel to batch.
+func (g *ghClient) queryTopics(token string, results []RepoStatus, batch []int) ([][]string, error) {
+	var qb strings.Builder
+	qb.WriteString("{\n")
+	for j, i := range batch {
+		m := results[i].Module
+		fmt.Fprintf(&qb, "  r%d: repository(owner: %q, name: %q) {\n", j, m.Owner, m.Repo)
+		qb.WriteString("    repositoryTopics(first: 50) { nodes { topic { name } } }\n")
+		qb.WriteString("  }\n")
+	}
+	qb.WriteString("}\n")
+
+	body, err := g.post(token, qb.String())
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data map[string]*struct {
+			RepositoryTopics struct {
+				Nodes []struct {
+					Topic struct {
+						Name string `json:"name"`
+					} `json:"topic"`
+				} `json:"nodes"`
+			} `json:"repositoryTopics"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	out := make([][]string, len(batch))
+	for j := range batch {
+		rd := resp.Data[fmt.Sprintf("r%d", j)]
+		if rd == nil {
+			continue
+		}
+		for _, n := range rd.RepositoryTopics.Nodes {
+			out[j] = append(out[j], n.Topic.Name)
+		}
+	}
+	return out, nil
+}
+
+// fetchCustomProperties returns a repository's custom property values via
+// GET /repos/{owner}/{repo}/properties/values. Repositories without access
+// to custom properties (404/403) yield no properties rather than an error.
+func (g *ghClient) fetchCustomProperties(token, owner, repo string) (map[string]string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/properties/values", g.restURL, owner, repo)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitHub API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var values []struct {
+		PropertyName string `json:"property_name"`
+		Value        any    `json:"value"` // string, or []string for multi-select
+	}
+	if err := json.Unmarshal(body, &values); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	props := make(map[string]string)
+	for _, v := range values {
+		switch val := v.Value.(type) {
+		case string:
+			props[v.PropertyName] = val
+		case []any:
+			var parts []string
+			for _, p := range val {
+				if s, ok := p.(string); ok {
+					parts = append(parts, s)
+				}
+			}
+			props[v.PropertyName] = strings.Join(parts, ",")
+		}
+	}
+	return props, nil
+}
+
+var policyHeaders = []string{"Module", "Version", "Direct", "Rule", "Matched"}
+
+// policyRows formats policy violations as table rows.
+func policyRows(violations []PolicyViolation) [][]string {
+	rows := make([][]string, len(violations))
+	for i, v := range violations {
+		m := v.Status.Module
+		rows[i] = []string{m.Path, m.Version, directLabel(m), v.Rule.String(), v.Matched}
+	}
+	return rows
+}
+
+// PrintPolicyTable outputs dependencies that matched a --policy rule.
+func PrintPolicyTable(violations []PolicyViolation) {
+	if len(violations) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nPOLICY WARNINGS (%d)\n\n", len(violations))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(policyHeaders))
+	for _, row := range policyRows(violations) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownPolicy outputs policy violations in Markdown format.
+func PrintMarkdownPolicy(violations []PolicyViolation) {
+	if len(violations) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## POLICY WARNINGS (%d)\n\n", len(violations))
+	printMarkdownTable(os.Stdout, policyHeaders, policyRows(violations))
+}
+
+// JSONPolicyWarning is a policy violation in JSON output.
+type JSONPolicyWarning = report.PolicyWarning
+
+// buildPolicyJSON converts policy violations for JSON output.
+func buildPolicyJSON(violations []PolicyViolation) []JSONPolicyWarning {
+	var out []JSONPolicyWarning
+	for _, v := range violations {
+		m := v.Status.Module
+		rule := v.Rule.String()
+		out = append(out, JSONPolicyWarning{
+			FindingID: findingID(findingPolicy+":"+rule, m.Path),
+			Module:    m.Path,
+			Version:   m.Version,
+			Direct:    m.Direct,
+			Rule:      rule,
+			Matched:   v.Matched,
+		})
+	}
+	return out
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParsePolicyRules(t *testing.T) {
+	rules, err := parsePolicyRules("topic:Experimental, property:lifecycle=sunset,property:owner,topic:pre-1.0@Critical,version:<v1.0.0,version:>= v2.1.0@critical")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"topic:experimental", "property:lifecycle=sunset", "property:owner", "topic:pre-1.0@critical", "version:<v1.0.0", "version:>=v2.1.0@critical"}
+	if len(rules) != len(want) {
+		t.Fatalf("len(rules) = %d, want %d", len(rules), len(want))
+	}
+	for i, r := range rules {
+		if r.String() != want[i] {
+			t.Errorf("rules[%d] = %q, want %q", i, r.String(), want[i])
+		}
+	}
+
+	for _, bad := range []string{"experimental", "topic:", "label:x", "topic:x@", "topic:@critical", "version:v1.0.0", "version:<1.0", "version:<"} {
+		if _, err := parsePolicyRules(bad); err == nil {
+			t.Errorf("parsePolicyRules(%q) should fail", bad)
+		}
+	}
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	rules, _ := parsePolicyRules("topic:experimental,topic:pre-1.0,property:lifecycle=sunset,property:owner")
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/z/beta"}, Topics: []string{"go", "Experimental"}},
+		{Module: Module{Path: "github.com/a/old"}, Properties: map[string]string{"lifecycle": "sunset"}},
+		{Module: Module{Path: "github.com/b/active"}, Properties: map[string]string{"lifecycle": "active", "owner": "infra"}},
+		{Module: Module{Path: "github.com/c/gone"}, NotFound: true, Topics: []string{"experimental"}},
+	}
+
+	got := evaluatePolicy(rules, results)
+	var lines []string
+	for _, v := range got {
+		lines = append(lines, v.Status.Module.Path+" "+v.Rule.String()+" "+v.Matched)
+	}
+	want := []string{
+		"github.com/a/old property:lifecycle=sunset lifecycle=sunset",
+		"github.com/b/active property:owner owner=infra",
+		"github.com/z/beta topic:experimental Experimental",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("evaluatePolicy() =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := evaluatePolicy(nil, results); got != nil {
+		t.Errorf("no rules should yield nil, got %+v", got)
+	}
+}
+
+func TestEvaluatePolicy_Version(t *testing.T) {
+	rules, _ := parsePolicyRules("version:<v1.0.0,version:=v2.3.0")
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/a/young", Version: "v0.9.2"}},
+		{Module: Module{Path: "github.com/b/pseudo", Version: "v0.0.0-20200101000000-abcdef123456"}},
+		{Module: Module{Path: "github.com/c/stable", Version: "v1.0.0"}},
+		{Module: Module{Path: "github.com/d/pinned", Version: "v2.3.0+incompatible"}},
+		{Module: Module{Path: "github.com/e/local"}},
+	}
+
+	got := evaluatePolicy(rules, results)
+	var lines []string
+	for _, v := range got {
+		lines = append(lines, v.Status.Module.Path+" "+v.Rule.String()+" "+v.Matched)
+	}
+	want := []string{
+		"github.com/a/young version:<v1.0.0 v0.9.2",
+		"github.com/b/pseudo version:<v1.0.0 v0.0.0-20200101000000-abcdef123456",
+		"github.com/d/pinned version:=v2.3.0 v2.3.0+incompatible",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("evaluatePolicy() =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestEvaluatePolicy_TagScoped(t *testing.T) {
+	rules, _ := parsePolicyRules("topic:pre-1.0@critical")
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/a/vault", Tags: []string{"critical"}}, Topics: []string{"pre-1.0"}},
+		{Module: Module{Path: "github.com/b/widgets", Tags: []string{"ui"}}, Topics: []string{"pre-1.0"}},
+		{Module: Module{Path: "github.com/c/untagged"}, Topics: []string{"pre-1.0"}},
+	}
+	got := evaluatePolicy(rules, results)
+	if len(got) != 1 || got[0].Status.Module.Path != "github.com/a/vault" {
+		t.Errorf("evaluatePolicy() = %+v, want only the critical module", got)
+	}
+}
+
+func TestFetchPolicyMetadataWithClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("missing auth header on %s", r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/graphql":
+			_, _ = w.Write([]byte(`{"data":{
+				"r0":{"repositoryTopics":{"nodes":[{"topic":{"name":"experimental"}}]}},
+				"r1":{"repositoryTopics":{"nodes":[]}}}}`))
+		case "/repos/a/one/properties/values":
+			_, _ = w.Write([]byte(`[{"property_name":"lifecycle","value":"sunset"},{"property_name":"teams","value":["x","y"]}]`))
+		case "/repos/b/two/properties/values":
+			http.NotFound(w, r)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/a/one", Owner: "a", Repo: "one"}},
+		{Module: Module{Path: "github.com/b/two", Owner: "b", Repo: "two"}},
+		{Module: Module{Path: "github.com/c/gone", Owner: "c", Repo: "gone"}, NotFound: true},
+	}
+	rules, _ := parsePolicyRules("topic:experimental,property:lifecycle")
+	gc := &ghClient{client: srv.Client(), graphqlURL: srv.URL + "/graphql", restURL: srv.URL}
+	if err := fetchPolicyMetadataWithClient(rules, results, 50, "tok", gc); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results[0].Topics) != 1 || results[0].Topics[0] != "experimental" {
+		t.Errorf("results[0].Topics = %v", results[0].Topics)
+	}
+	if results[0].Properties["lifecycle"] != "sunset" || results[0].Properties["teams"] != "x,y" {
+		t.Errorf("results[0].Properties = %v", results[0].Properties)
+	}
+	if results[1].Topics != nil || results[1].Properties != nil {
+		t.Errorf("results[1] = %+v, want no metadata", results[1])
+	}
+}
+
+func TestFetchPolicyMetadataWithClient_TopicsOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			t.Errorf("topic-only rules should not call %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data":{"r0":{"repositoryTopics":{"nodes":[]}}}}`))
+	}))
+	defer srv.Close()
+
+	results := []RepoStatus{{Module: Module{Path: "github.com/a/one", Owner: "a", Repo: "one"}}}
+	rules, _ := parsePolicyRules("topic:experimental")
+	gc := &ghClient{client: srv.Client(), graphqlURL: srv.URL + "/graphql", restURL: srv.URL}
+	if err := fetchPolicyMetadataWithClient(rules, results, 50, "tok", gc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildPolicyJSON(t *testing.T) {
+	rules, _ := parsePolicyRules("topic:experimental")
+	v := evaluatePolicy(rules, []RepoStatus{
+		{Module: Module{Path: "github.com/a/one", Version: "v0.3.0", Direct: true}, Topics: []string{"experimental"}},
+	})
+	got := buildPolicyJSON(v)
+	if len(got) != 1 || got[0].Rule != "topic:experimental" || got[0].Version != "v0.3.0" || !got[0].Direct {
+		t.Errorf("buildPolicyJSON() = %+v", got)
+	}
+	if buildPolicyJSON(nil) != nil {
+		t.Error("buildPolicyJSON(nil) should be nil")
+	}
+}
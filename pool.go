@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// poolOptions configures a worker pool run.
+type poolOptions struct {
+	Workers int           // maximum concurrent tasks; values < 1 mean 1
+	Retries int           // extra attempts for a task that returns an error
+	Backoff time.Duration // delay before the first retry; doubled for each further retry
+}
+
+// mapPool calls fn for every item on at most opts.Workers goroutines and
+// returns the results in input order. A failing task is retried up to
+// opts.Retries times; its last error is collected and its result left as
+// the zero value. Once ctx is cancelled no further tasks or retries start,
+// and ctx.Err() is included in the returned error.
+//
+// The returned error is the errors.Join of every task's final error, so a
+// caller that treats failures as partial results can simply ignore it.
+func mapPool[T, R any](ctx context.Context, items []T, opts poolOptions, fn func(context.Context, T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+	workers := min(max(opts.Workers, 1), len(items))
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				r, err := runWithRetry(ctx, opts, func() (R, error) { return fn(ctx, items[i]) })
+				if err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+					continue
+				}
+				results[i] = r
+			}
+		}()
+	}
+
+feed:
+	for i := range items {
+		select {
+		case <-ctx.Done():
+			break feed
+		case next <- i:
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return results, errors.Join(errs...)
+}
+
+// runPool is mapPool for tasks that only have side effects.
+func runPool[T any](ctx context.Context, items []T, opts poolOptions, fn func(context.Context, T) error) error {
+	_, err := mapPool(ctx, items, opts, func(ctx context.Context, item T) (struct{}, error) {
+		return struct{}{}, fn(ctx, item)
+	})
+	return err
+}
+
+// runWithRetry calls fn, retrying on error as opts allows.
+func runWithRetry[R any](ctx context.Context, opts poolOptions, fn func() (R, error)) (R, error) {
+	backoff := opts.Backoff
+	for attempt := 0; ; attempt++ {
+		r, err := fn()
+		if err == nil || attempt >= opts.Retries || ctx.Err() != nil {
+			return r, err
+		}
+		select {
+		case <-ctx.Done():
+			return r, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
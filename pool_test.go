@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapPool_OrderAndConcurrency(t *testing.T) {
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+
+	var running, peak atomic.Int32
+	got, err := mapPool(context.Background(), items, poolOptions{Workers: 5}, func(_ context.Context, n int) (int, error) {
+		cur := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if cur <= p || peak.CompareAndSwap(p, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return n * n, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range got {
+		if v != i*i {
+			t.Fatalf("got[%d] = %d, want %d", i, v, i*i)
+		}
+	}
+	if p := peak.Load(); p > 5 {
+		t.Errorf("peak concurrency %d, want <= 5", p)
+	}
+}
+
+func TestMapPool_Empty(t *testing.T) {
+	got, err := mapPool(context.Background(), []string(nil), poolOptions{Workers: 3}, func(context.Context, string) (int, error) {
+		t.Fatal("task called for empty input")
+		return 0, nil
+	})
+	if err != nil || len(got) != 0 {
+		t.Errorf("mapPool(nil) = %v, %v", got, err)
+	}
+}
+
+func TestMapPool_CollectsErrors(t *testing.T) {
+	errOdd := errors.New("odd")
+	got, err := mapPool(context.Background(), []int{1, 2, 3, 4}, poolOptions{Workers: 2}, func(_ context.Context, n int) (int, error) {
+		if n%2 == 1 {
+			return n, errOdd
+		}
+		return n, nil
+	})
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("err = %v, want errOdd", err)
+	}
+	want := []int{0, 2, 0, 4} // failed tasks leave the zero value
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMapPool_Retry(t *testing.T) {
+	var calls atomic.Int32
+	got, err := mapPool(context.Background(), []string{"x"}, poolOptions{Workers: 1, Retries: 2, Backoff: time.Millisecond}, func(context.Context, string) (string, error) {
+		if calls.Add(1) < 3 {
+			return "", errors.New("transient")
+		}
+		return "ok", nil
+	})
+	if err != nil || got[0] != "ok" {
+		t.Fatalf("mapPool = %v, %v; want ok after retries", got, err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("task called %d times, want 3", n)
+	}
+
+	calls.Store(0)
+	_, err = mapPool(context.Background(), []string{"x"}, poolOptions{Workers: 1, Retries: 1, Backoff: time.Millisecond}, func(context.Context, string) (string, error) {
+		calls.Add(1)
+		return "", errors.New("permanent")
+	})
+	if err == nil {
+		t.Error("want error after retries are exhausted")
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("task called %d times, want 2 (1 + 1 retry)", n)
+	}
+}
+
+func TestMapPool_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	items := make([]int, 50)
+
+	var started atomic.Int32
+	_, err := mapPool(ctx, items, poolOptions{Workers: 2}, func(context.Context, int) (int, error) {
+		if started.Add(1) == 2 {
+			cancel()
+		}
+		time.Sleep(time.Millisecond)
+		return 0, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if n := started.Load(); n >= int32(len(items)) {
+		t.Errorf("%d tasks started after cancellation, want fewer than %d", n, len(items))
+	}
+}
+
+func TestRunPool(t *testing.T) {
+	var sum atomic.Int64
+	err := runPool(context.Background(), []int{1, 2, 3}, poolOptions{Workers: 3}, func(_ context.Context, n int) error {
+		sum.Add(int64(n))
+		return nil
+	})
+	if err != nil || sum.Load() != 6 {
+		t.Errorf("runPool: sum = %d, err = %v", sum.Load(), err)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// preflightTimeout bounds the DNS lookup and TCP connect of one host probe.
+const preflightTimeout = 3 * time.Second
+
+// preflightHosts enables the per-host reachability probe newResolver
+// applies before vanity page fetches; set by --preflight-hosts.
+var preflightHosts bool
+
+// hostProbe is the outcome of one host's reachability check, shared by
+// every module served from that host.
+type hostProbe struct {
+	once sync.Once
+	err  error
+}
+
+// hostReachable reports whether the vanity host serving modulePath accepts
+// connections. Each host is probed once per resolver with a DNS lookup and
+// a TCP connect to its https port (and its http port when the module may
+// fall back to http); a host that fails is reported once on stderr and
+// every later module on it is skipped without a request, instead of each
+// waiting out its own fetch timeout. Always true unless --preflight-hosts.
+func (r *resolver) hostReachable(modulePath string) bool {
+	if !r.preflight {
+		return true
+	}
+	host, _, _ := strings.Cut(modulePath, "/")
+	v, _ := r.probes.LoadOrStore(strings.ToLower(host), &hostProbe{})
+	p := v.(*hostProbe)
+	p.once.Do(func() {
+		p.err = r.probeHost(host, r.insecure != "" && module.MatchPrefixPatterns(r.insecure, modulePath))
+		if p.err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: vanity host %s is unreachable (%v); skipping its modules.\n", host, p.err)
+		}
+	})
+	return p.err == nil
+}
+
+// probeHost dials host, which may carry an explicit port, succeeding if any
+// candidate port accepts a connection.
+func (r *resolver) probeHost(host string, allowHTTP bool) error {
+	addrs := []string{host}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		addrs = []string{net.JoinHostPort(host, "443")}
+		if allowHTTP {
+			addrs = append(addrs, net.JoinHostPort(host, "80"))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+	defer cancel()
+	var d net.Dialer
+	var err error
+	for _, addr := range addrs {
+		var conn net.Conn
+		if conn, err = d.DialContext(ctx, "tcp", addr); err == nil {
+			_ = conn.Close()
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// projectConfigFile is the per-project settings file written by modrot init
+// and read from the scanned directory. Command-line flags override it.
+const projectConfigFile = ".modrot.yaml"
+
+// outputFormats are the values --format and the format setting accept.
+var outputFormats = []string{"table", "json", "markdown", "mermaid", "quickfix", "plain", "heatmap"}
+
+// failOnModes are the values --fail-on and the fail_on setting accept.
+var failOnModes = []string{"archived", "direct", "never"}
+
+// projectConfig holds the settings in .modrot.yaml.
+type projectConfig struct {
+	Format   string      // format: output format
+	FailOn   string      // fail_on: archived, direct, or never
+	TokenEnv string      // token_env: environment variable holding the GitHub token
+	Ignore   []string    // ignore: module paths to ignore, as with --ignore
+	Tags     []moduleTag // tags: criticality tags by module pattern
+
+	InternalOrgs []string // internal_orgs: GitHub orgs whose replace targets are internal forks
+}
+
+// parseProjectConfig parses the small YAML subset .modrot.yaml uses:
+// "key: value" lines, an "ignore:" key followed by "- path" items, a
+// "tags:" key followed by "- pattern: tag[, tag]" items, an
+// "internal_orgs:" key followed by "- org" items, and "#" comments. Unknown
+// keys and invalid values are errors so typos do not silently change CI
+// behavior.
+func parseProjectConfig(data string) (projectConfig, error) {
+	var pc projectConfig
+	list := "" // the list key whose items follow
+	for i, line := range strings.Split(data, "\n") {
+		if j := strings.Index(line, " #"); j >= 0 {
+			line = line[:j]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok {
+			switch list {
+			case "ignore":
+				pc.Ignore = append(pc.Ignore, unquoteYAML(item))
+			case "tags":
+				tags, err := parseModuleTag(item)
+				if err != nil {
+					return pc, fmt.Errorf("line %d: %w", i+1, err)
+				}
+				pc.Tags = append(pc.Tags, tags...)
+			case "internal_orgs":
+				pc.InternalOrgs = append(pc.InternalOrgs, unquoteYAML(item))
+			default:
+				return pc, fmt.Errorf("line %d: list item outside ignore, tags, or internal_orgs", i+1)
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return pc, fmt.Errorf("line %d: want key: value", i+1)
+		}
+		key, value = strings.TrimSpace(key), unquoteYAML(value)
+		list = ""
+		switch key {
+		case "format":
+			if !slices.Contains(outputFormats, value) {
+				return pc, fmt.Errorf("line %d: invalid format %q (want %s)", i+1, value, strings.Join(outputFormats, ", "))
+			}
+			pc.Format = value
+		case "fail_on":
+			if !slices.Contains(failOnModes, value) {
+				return pc, fmt.Errorf("line %d: invalid fail_on %q (want %s)", i+1, value, strings.Join(failOnModes, ", "))
+			}
+			pc.FailOn = value
+		case "token_env":
+			pc.TokenEnv = value
+		case "ignore":
+			if value != "" && value != "[]" {
+				return pc, fmt.Errorf("line %d: ignore takes a list of \"- module/path\" items", i+1)
+			}
+			list = key
+		case "tags":
+			if value != "" && value != "[]" {
+				return pc, fmt.Errorf("line %d: tags takes a list of \"- module/pattern: tag\" items", i+1)
+			}
+			list = key
+		case "internal_orgs":
+			if value != "" && value != "[]" {
+				return pc, fmt.Errorf("line %d: internal_orgs takes a list of \"- org\" items", i+1)
+			}
+			list = key
+		default:
+			return pc, fmt.Errorf("line %d: unknown setting %q", i+1, key)
+		}
+	}
+	return pc, nil
+}
+
+// unquoteYAML trims whitespace and one pair of surrounding quotes.
+func unquoteYAML(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// formatProjectConfig renders pc as .modrot.yaml content.
+func formatProjectConfig(pc projectConfig) string {
+	var b strings.Builder
+	b.WriteString("# modrot settings; command-line flags override these.\n")
+	fmt.Fprintf(&b, "format: %s\n", pc.Format)
+	fmt.Fprintf(&b, "fail_on: %s\n", pc.FailOn)
+	if pc.TokenEnv != "" {
+		fmt.Fprintf(&b, "token_env: %s\n", pc.TokenEnv)
+	}
+	if len(pc.Ignore) > 0 {
+		b.WriteString("ignore:\n")
+		for _, p := range pc.Ignore {
+			fmt.Fprintf(&b, "  - %s\n", p)
+		}
+	}
+	if len(pc.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, t := range pc.Tags {
+			fmt.Fprintf(&b, "  - %s: %s\n", t.Pattern, t.Tag)
+		}
+	}
+	if len(pc.InternalOrgs) > 0 {
+		b.WriteString("internal_orgs:\n")
+		for _, org := range pc.InternalOrgs {
+			fmt.Fprintf(&b, "  - %s\n", org)
+		}
+	}
+	return b.String()
+}
+
+// loadProjectConfig reads .modrot.yaml from dir. A missing file yields the
+// zero projectConfig.
+func loadProjectConfig(dir string) (projectConfig, error) {
+	path := filepath.Join(dir, projectConfigFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return projectConfig{}, nil
+	}
+	if err != nil {
+		return projectConfig{}, err
+	}
+	pc, err := parseProjectConfig(string(data))
+	if err != nil {
+		return pc, fmt.Errorf("%s: %w", path, err)
+	}
+	return pc, nil
+}
+
+// applyProjectConfig fills in the settings from pc that were not given on
+// the command line; set holds the names of flags that were.
+func applyProjectConfig(cfg *Config, pc projectConfig, set map[string]bool) {
+	if pc.Format != "" && !set["format"] && !set["json"] && !set["markdown"] && !set["mermaid"] && !set["quickfix"] {
+		cfg.OutputFormat = pc.Format
+	}
+	if pc.FailOn != "" && !set["fail-on"] {
+		cfg.FailOn = pc.FailOn
+	}
+	if pc.TokenEnv != "" && !set["token-env"] {
+		cfg.TokenEnv = pc.TokenEnv
+	}
+	if len(pc.Ignore) > 0 {
+		ignore := pc.Ignore
+		if cfg.IgnoreInline != "" {
+			ignore = append([]string{cfg.IgnoreInline}, ignore...)
+		}
+		cfg.IgnoreInline = strings.Join(ignore, ",")
+	}
+	cfg.Tags = append(cfg.Tags, pc.Tags...)
+	cfg.InternalOrgs = append(cfg.InternalOrgs, pc.InternalOrgs...)
+}
+
+// failOnExitCode applies --fail-on to a run's exit code: "never" turns
+// archived findings (1) into success, and "direct" does so unless an
+// archived dependency is direct. Other codes, and runs --fail-fast ended
+// at an archived direct dependency, pass through.
+func failOnExitCode(cfg *Config, code int) int {
+	if code != 1 || cfg.FailedFast {
+		return code
+	}
+	switch cfg.FailOn {
+	case "never":
+		return 0
+	case "direct":
+		for _, results := range cfg.Summary.results {
+			for _, r := range results {
+				if r.IsArchived && r.Module.Direct {
+					return 1
+				}
+			}
+		}
+		return 0
+	}
+	return code
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseProjectConfig(t *testing.T) {
+	data := `# modrot settings
+format: markdown
+fail_on: "direct"  # only direct deps fail CI
+token_env: MODROT_TOKEN
+ignore:
+  - github.com/pkg/errors
+  - 'github.com/golang/mock'
+tags:
+  - github.com/hashicorp/vault: critical
+  - "golang.org/x/*": infrastructure, Critical
+internal_orgs:
+  - acme
+  - "acme-forks"
+`
+	pc, err := parseProjectConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.Format != "markdown" || pc.FailOn != "direct" || pc.TokenEnv != "MODROT_TOKEN" {
+		t.Errorf("parseProjectConfig = %+v", pc)
+	}
+	if strings.Join(pc.Ignore, ",") != "github.com/pkg/errors,github.com/golang/mock" {
+		t.Errorf("Ignore = %v", pc.Ignore)
+	}
+	wantTags := []moduleTag{
+		{"github.com/hashicorp/vault", "critical"},
+		{"golang.org/x/*", "infrastructure"},
+		{"golang.org/x/*", "critical"},
+	}
+	if !slices.Equal(pc.Tags, wantTags) {
+		t.Errorf("Tags = %v, want %v", pc.Tags, wantTags)
+	}
+	if !slices.Equal(pc.InternalOrgs, []string{"acme", "acme-forks"}) {
+		t.Errorf("InternalOrgs = %v", pc.InternalOrgs)
+	}
+}
+
+func TestParseProjectConfig_Errors(t *testing.T) {
+	tests := []struct{ data, wantErr string }{
+		{"format: xml", "invalid format"},
+		{"fail_on: sometimes", "invalid fail_on"},
+		{"colour: red", "unknown setting"},
+		{"- github.com/pkg/errors", "outside ignore"},
+		{"ignore: github.com/pkg/errors", "list of"},
+		{"tags: critical", "list of"},
+		{"internal_orgs: acme", "list of"},
+		{"tags:\n  - github.com/a/b", "want"},
+		{"tags:\n  - github.com/a/b:", "no tag"},
+		{"just words", "want key: value"},
+	}
+	for _, tt := range tests {
+		_, err := parseProjectConfig(tt.data)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseProjectConfig(%q) error = %v, want containing %q", tt.data, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFormatProjectConfig_RoundTrip(t *testing.T) {
+	want := projectConfig{Format: "json", FailOn: "never", TokenEnv: "GITHUB_TOKEN", Ignore: []string{"github.com/a/b"},
+		Tags: []moduleTag{{"github.com/c/*", "ui"}}, InternalOrgs: []string{"acme"}}
+	got, err := parseProjectConfig(formatProjectConfig(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Format != want.Format || got.FailOn != want.FailOn || got.TokenEnv != want.TokenEnv ||
+		len(got.Ignore) != 1 || got.Ignore[0] != "github.com/a/b" || !slices.Equal(got.Tags, want.Tags) ||
+		!slices.Equal(got.InternalOrgs, want.InternalOrgs) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	if pc, err := loadProjectConfig(dir); err != nil || pc.Format != "" {
+		t.Errorf("missing file = %+v, %v; want zero config", pc, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, projectConfigFile), []byte("format: nope\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadProjectConfig(dir); err == nil || !strings.Contains(err.Error(), projectConfigFile) {
+		t.Errorf("invalid file error = %v, want it to name %s", err, projectConfigFile)
+	}
+}
+
+func TestApplyProjectConfig(t *testing.T) {
+	pc := projectConfig{Format: "json", FailOn: "never", TokenEnv: "TOK", Ignore: []string{"github.com/a/b"}}
+
+	cfg := NewDefaultConfig()
+	cfg.IgnoreInline = "github.com/x/y"
+	applyProjectConfig(cfg, pc, map[string]bool{})
+	if cfg.OutputFormat != "json" || cfg.FailOn != "never" || cfg.TokenEnv != "TOK" {
+		t.Errorf("applied config = format %q, fail-on %q, token-env %q", cfg.OutputFormat, cfg.FailOn, cfg.TokenEnv)
+	}
+	if cfg.IgnoreInline != "github.com/x/y,github.com/a/b" {
+		t.Errorf("IgnoreInline = %q", cfg.IgnoreInline)
+	}
+
+	// Flags given on the command line win.
+	cfg = NewDefaultConfig()
+	cfg.OutputFormat = "markdown"
+	applyProjectConfig(cfg, pc, map[string]bool{"markdown": true, "fail-on": true})
+	if cfg.OutputFormat != "markdown" || cfg.FailOn != "archived" {
+		t.Errorf("flags should override settings: format %q, fail-on %q", cfg.OutputFormat, cfg.FailOn)
+	}
+}
+
+func TestFailOnExitCode(t *testing.T) {
+	indirect := RepoStatus{Module: Module{Path: "github.com/a/b"}, IsArchived: true}
+	direct := RepoStatus{Module: Module{Path: "github.com/c/d", Direct: true}, IsArchived: true}
+
+	tests := []struct {
+		failOn  string
+		results []RepoStatus
+		code    int
+		want    int
+	}{
+		{"archived", []RepoStatus{indirect}, 1, 1},
+		{"never", []RepoStatus{direct}, 1, 0},
+		{"direct", []RepoStatus{indirect}, 1, 0},
+		{"direct", []RepoStatus{indirect, direct}, 1, 1},
+		{"never", nil, 2, 2},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig()
+		cfg.FailOn = tt.failOn
+		cfg.Summary.add("example.com/m", "go.mod", tt.results)
+		if got := failOnExitCode(cfg, tt.code); got != tt.want {
+			t.Errorf("failOnExitCode(%s, %d) = %d, want %d", tt.failOn, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestGetGHToken_Env(t *testing.T) {
+	defer func(prev string) { tokenEnv = prev }(tokenEnv)
+	tokenEnv = "MODROT_TEST_TOKEN"
+
+	t.Setenv("MODROT_TEST_TOKEN", " secret\n")
+	if tok, err := getGHToken(); err != nil || tok != "secret" {
+		t.Errorf("getGHToken() = %q, %v; want secret", tok, err)
+	}
+	t.Setenv("MODROT_TEST_TOKEN", "")
+	if _, err := getGHToken(); err == nil {
+		t.Error("want error for an unset token variable")
+	}
+}
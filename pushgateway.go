@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pushgatewayJob is the job label metrics are pushed under.
+const pushgatewayJob = "modrot"
+
+// pushMetrics pushes run metrics to the --pushgateway URL, grouped by the
+// repo and branch being scanned, so dashboards get data from one-shot CI
+// runs. dir is the scanned directory, used to detect repo and branch.
+// Failures are reported as degradations.
+func pushMetrics(cfg *Config, dir string, elapsed time.Duration, code int) {
+	if cfg.Pushgateway == "" {
+		return
+	}
+	repo, branch := detectRepoBranch(dir)
+	url := pushgatewayURL(cfg.Pushgateway, repo, branch)
+	body := formatMetrics(cfg, elapsed, code)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	if err := pushTo(client, url, body); err != nil {
+		warnDegraded(cfg, "pushgateway", "could not push metrics: %v", err)
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Pushed metrics to %s (repo=%s, branch=%s).\n", cfg.Pushgateway, repo, branch)
+}
+
+// pushTo replaces the metrics of a pushgateway grouping with body.
+func pushTo(client *http.Client, url, body string) error {
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		var msg bytes.Buffer
+		_, _ = msg.ReadFrom(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(msg.String()))
+	}
+	return nil
+}
+
+// pushgatewayURL builds the push URL for the modrot job grouped by repo and
+// branch. Label values containing "/" (or empty ones) use the pushgateway's
+// base64 encoding, since they cannot appear as plain path segments.
+//
+//	http://pg:9091/metrics/job/modrot/repo@base64/b3JnL2FwcA/branch/main
+func pushgatewayURL(base, repo, branch string) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(base, "/"))
+	b.WriteString("/metrics/job/" + pushgatewayJob)
+	for _, kv := range [][2]string{{"repo", repo}, {"branch", branch}} {
+		name, value := kv[0], kv[1]
+		switch {
+		case value == "":
+			b.WriteString("/" + name + "@base64/=")
+		case strings.ContainsAny(value, "/%"):
+			b.WriteString("/" + name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value)))
+		default:
+			b.WriteString("/" + name + "/" + value)
+		}
+	}
+	return b.String()
+}
+
+// detectRepoBranch returns the repository and branch being scanned,
+// preferring CI environment variables and falling back to git in dir.
+func detectRepoBranch(dir string) (repo, branch string) {
+	repo = firstEnv("GITHUB_REPOSITORY", "CI_PROJECT_PATH")
+	if repo == "" {
+		if out, err := gitOutput(dir, "remote", "get-url", "origin"); err == nil {
+			repo = repoFromRemote(out)
+		}
+	}
+	if repo == "" {
+		repo = filepath.Base(dir)
+	}
+
+	branch = firstEnv("GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME")
+	if branch == "" {
+		if out, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && out != "HEAD" {
+			branch = out
+		}
+	}
+	return repo, branch
+}
+
+// firstEnv returns the first non-empty environment variable among names.
+func firstEnv(names ...string) string {
+	for _, n := range names {
+		if v := os.Getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// gitOutput runs git in dir and returns its trimmed stdout.
+func gitOutput(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// repoFromRemote reduces a git remote URL to owner/repo for GitHub remotes,
+// or to host/path for others.
+//
+//	git@github.com:org/app.git → org/app
+//	https://gitlab.example.com/team/app.git → gitlab.example.com/team/app
+func repoFromRemote(remote string) string {
+	if owner, repo := extractGitHubFromURL(strings.Replace(remote, "git@github.com:", "github.com/", 1)); owner != "" {
+		return owner + "/" + repo
+	}
+	r := remote
+	if i := strings.Index(r, "://"); i >= 0 {
+		r = r[i+3:]
+	}
+	if at := strings.Index(r, "@"); at >= 0 {
+		r = r[at+1:]
+	}
+	r = strings.Replace(r, ":", "/", 1)
+	return strings.TrimSuffix(strings.TrimSuffix(r, "/"), ".git")
+}
+
+// formatMetrics renders the run summary in the Prometheus text exposition
+// format.
+func formatMetrics(cfg *Config, elapsed time.Duration, code int) string {
+	var modules, archived, archivedDirect, stale int
+	perProject := make(map[string]int)
+	for i, project := range cfg.Summary.projects {
+		results := cfg.Summary.results[i]
+		modules += len(results)
+		for _, r := range results {
+			if r.IsArchived {
+				archived++
+				perProject[project]++
+				if r.Module.Direct {
+					archivedDirect++
+				}
+			}
+		}
+		stale += len(filterStale(cfg, results))
+	}
+
+	var b strings.Builder
+	gauge := func(name, help string, value string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, value)
+	}
+	gauge("modrot_projects", "go.mod files scanned.", strconv.Itoa(len(cfg.Summary.projects)))
+	gauge("modrot_modules_checked", "GitHub modules checked for archive status.", strconv.Itoa(modules))
+	gauge("modrot_archived_modules", "Archived modules found (after ignore lists).", strconv.Itoa(archived))
+	gauge("modrot_archived_direct_modules", "Archived direct dependencies found.", strconv.Itoa(archivedDirect))
+	gauge("modrot_score", "Percent of unique GitHub repositories depended on that are not archived (100 is clean).", strconv.FormatFloat(runScore(cfg.Summary.totals()), 'f', 1, 64))
+	if cfg.Stale.Enabled {
+		gauge("modrot_stale_modules", "Non-archived modules not pushed within the --stale threshold.", strconv.Itoa(stale))
+	}
+	gauge("modrot_degradations", "Tool-environment problems that degraded the analysis.", strconv.Itoa(len(cfg.Degradations)))
+	gauge("modrot_run_duration_seconds", "Wall-clock duration of the run.", strconv.FormatFloat(elapsed.Seconds(), 'f', 3, 64))
+	gauge("modrot_exit_code", "Exit code of the run (0 clean, 1 archived found, 2 error, 3 strict-degraded).", strconv.Itoa(code))
+	gauge("modrot_last_run_timestamp_seconds", "Unix time the run started.", strconv.FormatInt(cfg.Now.Unix(), 10))
+
+	if len(cfg.Summary.phases) > 0 {
+		b.WriteString("# HELP modrot_phase_duration_seconds Wall-clock duration of each pipeline phase.\n")
+		b.WriteString("# TYPE modrot_phase_duration_seconds gauge\n")
+		for _, ph := range cfg.Summary.phases {
+			fmt.Fprintf(&b, "modrot_phase_duration_seconds{phase=\"%s\"} %.3f\n", escapeLabelValue(ph.Phase), ph.Duration.Seconds())
+		}
+	}
+
+	if len(perProject) > 0 {
+		projects := make([]string, 0, len(perProject))
+		for p := range perProject {
+			projects = append(projects, p)
+		}
+		sort.Strings(projects)
+		b.WriteString("# HELP modrot_project_archived_modules Archived modules found per go.mod.\n")
+		b.WriteString("# TYPE modrot_project_archived_modules gauge\n")
+		for _, p := range projects {
+			fmt.Fprintf(&b, "modrot_project_archived_modules{project=\"%s\"} %d\n", escapeLabelValue(p), perProject[p])
+		}
+	}
+	return b.String()
+}
+
+// runScore is the percentage of unique repositories that are not archived;
+// a run without GitHub dependencies scores 100.
+func runScore(t repoTotals) float64 {
+	if t.Repos == 0 {
+		return 100
+	}
+	return math.Round((100-t.ArchivedPct)*10) / 10
+}
+
+// escapeLabelValue escapes a Prometheus label value.
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPushgatewayURL(t *testing.T) {
+	tests := []struct {
+		repo, branch string
+		want         string
+	}{
+		{"app", "main", "http://pg:9091/metrics/job/modrot/repo/app/branch/main"},
+		{"org/app", "main", "http://pg:9091/metrics/job/modrot/repo@base64/b3JnL2FwcA/branch/main"},
+		{"app", "feature/x", "http://pg:9091/metrics/job/modrot/repo/app/branch@base64/ZmVhdHVyZS94"},
+		{"app", "", "http://pg:9091/metrics/job/modrot/repo/app/branch@base64/="},
+	}
+	for _, tt := range tests {
+		if got := pushgatewayURL("http://pg:9091/", tt.repo, tt.branch); got != tt.want {
+			t.Errorf("pushgatewayURL(%q, %q) = %q, want %q", tt.repo, tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestRepoFromRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"https://github.com/org/app.git", "org/app"},
+		{"git@github.com:org/app.git", "org/app"},
+		{"https://gitlab.example.com/team/app.git", "gitlab.example.com/team/app"},
+		{"ssh://git@gitlab.example.com/team/app", "gitlab.example.com/team/app"},
+		{"git@gitlab.example.com:team/app.git", "gitlab.example.com/team/app"},
+	}
+	for _, tt := range tests {
+		if got := repoFromRemote(tt.remote); got != tt.want {
+			t.Errorf("repoFromRemote(%q) = %q, want %q", tt.remote, got, tt.want)
+		}
+	}
+}
+
+func TestDetectRepoBranch_CIEnv(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY", "org/app")
+	t.Setenv("GITHUB_HEAD_REF", "")
+	t.Setenv("GITHUB_REF_NAME", "release-1.2")
+	repo, branch := detectRepoBranch(t.TempDir())
+	if repo != "org/app" || branch != "release-1.2" {
+		t.Errorf("detectRepoBranch() = %q, %q", repo, branch)
+	}
+}
+
+func TestFormatMetrics(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Now = time.Unix(1700000000, 0)
+	cfg.Summary.add("example.com/app", "go.mod", []RepoStatus{
+		{Module: Module{Path: "github.com/a/old", Owner: "a", Repo: "old", Direct: true}, IsArchived: true},
+		{Module: Module{Path: "github.com/b/old", Owner: "b", Repo: "old"}, IsArchived: true},
+		{Module: Module{Path: "github.com/c/fine", Owner: "c", Repo: "fine"}},
+	})
+	cfg.Summary.add(`example.com/"quoted"`, "go.mod", []RepoStatus{
+		{Module: Module{Path: "github.com/a/old", Owner: "a", Repo: "old"}, IsArchived: true},
+	})
+
+	cfg.Summary.phases = []phaseTiming{{Phase: "parse", Duration: 20 * time.Millisecond}, {Phase: "github", Duration: 1200 * time.Millisecond}}
+
+	got := formatMetrics(cfg, 1500*time.Millisecond, 1)
+	for _, want := range []string{
+		"modrot_score 33.3\n",
+		"# TYPE modrot_phase_duration_seconds gauge\n",
+		`modrot_phase_duration_seconds{phase="parse"} 0.020` + "\n",
+		`modrot_phase_duration_seconds{phase="github"} 1.200` + "\n",
+		"# TYPE modrot_archived_modules gauge\nmodrot_archived_modules 3\n",
+		"modrot_projects 2\n",
+		"modrot_modules_checked 4\n",
+		"modrot_archived_direct_modules 1\n",
+		"modrot_run_duration_seconds 1.500\n",
+		"modrot_exit_code 1\n",
+		"modrot_last_run_timestamp_seconds 1700000000\n",
+		`modrot_project_archived_modules{project="example.com/\"quoted\""} 1` + "\n",
+		`modrot_project_archived_modules{project="example.com/app"} 2` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "modrot_stale_modules") {
+		t.Error("stale gauge should be omitted without --stale")
+	}
+}
+
+func TestPushTo(t *testing.T) {
+	var method, path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	url := pushgatewayURL(srv.URL, "app", "main")
+	if err := pushTo(srv.Client(), url, "modrot_projects 1\n"); err != nil {
+		t.Fatalf("pushTo: %v", err)
+	}
+	if method != http.MethodPut || path != "/metrics/job/modrot/repo/app/branch/main" || body != "modrot_projects 1\n" {
+		t.Errorf("got %s %s %q", method, path, body)
+	}
+}
+
+func TestPushTo_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad metric", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	err := pushTo(srv.Client(), srv.URL+"/metrics/job/modrot", "x")
+	if err == nil || !strings.Contains(err.Error(), "bad metric") {
+		t.Errorf("pushTo() error = %v, want status and body", err)
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -38,13 +37,27 @@ func applyStatus(modules []Module, statusMap map[string]RepoStatus) []RepoStatus
 	results := make([]RepoStatus, len(modules))
 	for i, m := range modules {
 		rs := RepoStatus{Module: m}
-		key := m.Owner + "/" + m.Repo
-		if global, ok := statusMap[key]; ok {
+		if global, ok := statusMap[repoKey(m)]; ok {
 			rs.IsArchived = global.IsArchived
 			rs.ArchivedAt = global.ArchivedAt
+			rs.ArchivedAtSource = global.ArchivedAtSource
 			rs.PushedAt = global.PushedAt
 			rs.NotFound = global.NotFound
 			rs.Error = global.Error
+			rs.Disabled = global.Disabled
+			rs.DisabledReason = global.DisabledReason
+			rs.Topics = global.Topics
+			rs.Properties = global.Properties
+			rs.LastRelease = global.LastRelease
+			rs.LastReleaseTag = global.LastReleaseTag
+			rs.Advisories = global.Advisories
+			rs.Retired = global.Retired
+			rs.RetiredRationale = global.RetiredRationale
+			rs.Triage = global.Triage
+			rs.RenamedTo = global.RenamedTo
+			rs.Unknown = global.Unknown
+		} else {
+			rs.Unknown, rs.Error = true, notChecked
 		}
 		results[i] = rs
 	}
@@ -105,14 +118,18 @@ func runRecursive(rootDir string, cfg *Config) int {
 		return 2
 	}
 
+	ps := newPhaseStats(cfg)
+	defer ps.done()
+
 	// Phase 1: Parse all go.mod files
 	var modules []moduleInfo
 	for _, gp := range gomodPaths {
 		allMods, err := ParseGoMod(gp)
 		if err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", gp, err)
+			warnDegraded(cfg, "go.mod", "skipping %s: %v", gp, err)
 			continue
 		}
+		applyTags(cfg.Tags, allMods)
 		modName, _ := ModuleName(gp)
 		rel, _ := filepath.Rel(rootDir, gp)
 		modules = append(modules, moduleInfo{
@@ -122,33 +139,45 @@ func runRecursive(rootDir string, cfg *Config) int {
 			allModules: allMods,
 		})
 	}
+	ps.mark("parse", countModules(modules))
+
+	// One resolver serves every proxy phase, so shared modules are fetched
+	// once across all go.mod files and phases.
+	depResolver := newResolver()
 
 	// Phase 2: Resolve vanity imports (before filtering)
 	if cfg.Resolve {
-		resolved := resolveAcrossModules(modules)
+		resolved := resolveAcrossModulesWithResolver(modules, depResolver)
 		if resolved > 0 {
 			_, _ = fmt.Fprintf(os.Stderr, "Resolved %d non-GitHub modules to GitHub repos.\n", resolved)
 		}
+		ps.mark("resolve", countModules(modules))
 	}
 
-	// Phase 2.5: Check deprecations (before filtering)
+	// Phase 2.5: Check direct deps for deprecation (indirect deps follow
+	// in phase 4.5, once archived/stale status is known)
+	deprecatedCount := 0
+	if cfg.Deprecated && !budgetAllows(cfg, "deprecation checks", budgetShareDeprecated) {
+		cfg.Deprecated = false
+	}
 	if cfg.Deprecated {
-		count := checkDeprecationsAcrossModules(modules)
-		if count > 0 {
-			_, _ = fmt.Fprintf(os.Stderr, "Found %d deprecated %s.\n", count, pluralize(count, "module", "modules"))
-		}
+		deprecatedCount = checkDeprecationsAcrossModulesSelected(modules, depResolver, deprecationTier1(cfg))
 	}
 
 	// Phase 3: Filter to GitHub modules and collect globally unique repos
 	var allGitHub []Module
 	globalSeen := make(map[string]bool)
+	var internalForks []Module
 	for i := range modules {
-		ghMods, nonGH := FilterGitHub(modules[i].allModules, cfg.DirectOnly)
-		modules[i].githubModules = ghMods
-		modules[i].nonGHModules = nonGH
-
-		for _, m := range ghMods {
-			key := m.Owner + "/" + m.Repo
+		checked, forks := skipInternalForks(cfg, modules[i].allModules)
+		internalForks = append(internalForks, forks...)
+		ghMods, nonGH := FilterGitHub(checked, cfg.DirectOnly)
+		modules[i].githubModules = filterModules(cfg, ghMods)
+		modules[i].nonGHModules = filterModules(cfg, nonGH)
+		addFailFastRepos(cfg, modules[i].gomodPath, modules[i].githubModules)
+
+		for _, m := range modules[i].githubModules {
+			key := repoKey(m)
 			if !globalSeen[key] {
 				globalSeen[key] = true
 				allGitHub = append(allGitHub, m)
@@ -156,14 +185,33 @@ func runRecursive(rootDir string, cfg *Config) int {
 		}
 	}
 
-	// Phase 3.5: Enrich non-GitHub modules with proxy data
-	enrichAcrossModules(modules)
+	reportInternalForks(internalForks)
+
+	// Phase 3.5: Enrich non-GitHub modules with proxy data; like the next
+	// two phases, skipped first under a --max-duration or --max-requests budget
+	enrich := budgetAllows(cfg, "proxy enrichment", budgetShareEnrichment)
+	if enrich {
+		enrichAcrossModulesWithResolver(modules, depResolver)
+	}
 
 	// Phase 3.6: Enrich all modules with freshness data (skips already-enriched)
-	if cfg.Freshness {
-		enrichFreshnessAcrossModules(modules)
+	if enrich && (cfg.Freshness || cfg.OutputFormat == "heatmap") {
+		enrichFreshnessAcrossModulesWithResolver(modules, depResolver)
 	}
 
+	// Phase 3.7: Flag modules whose newer major version lives at a new module path
+	if enrich && cfg.Freshness {
+		if n := detectNewerMajorsAcrossModulesWithResolver(modules, depResolver); n > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "Found %d %s with a newer major version.\n", n, pluralize(n, "module", "modules"))
+			for i := range modules {
+				copyNewerMajors(modules[i].githubModules, modules[i].allModules)
+				copyNewerMajors(modules[i].nonGHModules, modules[i].allModules)
+			}
+		}
+	}
+
+	ps.mark("enrich", countModules(modules))
+
 	if len(modules) == 0 {
 		_, _ = fmt.Fprintf(os.Stderr, "No valid go.mod files found.\n")
 		return 2
@@ -177,38 +225,172 @@ func runRecursive(rootDir string, cfg *Config) int {
 	_, _ = fmt.Fprintf(os.Stderr, "Found %d go.mod files, checking %d unique GitHub repos...\n", len(modules), len(allGitHub))
 
 	// Query GitHub once for all unique repos
-	globalResults, err := CheckRepos(allGitHub, cfg.Workers)
+	globalResults, err := CheckReposCached(cfg, allGitHub)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", budgetError(cfg, err))
 		return 2
 	}
+	applyDisabledMode(cfg, globalResults)
+	triageNotFound(cfg, globalResults, depResolver)
+	if found := failFastFindings(cfg, globalResults); len(found) > 0 {
+		return failFast(cfg, found, len(globalResults), len(allGitHub))
+	}
+
+	// Fetch the repository topics/properties --policy rules check
+	fetchPolicyMetadata(cfg, globalResults)
+
+	// Fetch last releases and post-release advisories for --advisories
+	fetchAdvisories(cfg, globalResults)
+
+	// Classify archived modules whose every version is retracted
+	if cfg.Deprecated && budgetAllows(cfg, "deprecation checks", budgetShareDeprecated) {
+		reportRetired(detectRetiredWithResolver(globalResults, 20, depResolver))
+	}
+
+	ps.mark("github", len(globalResults))
 
 	// Build status map: owner/repo → RepoStatus
 	statusMap := make(map[string]RepoStatus)
 	for _, r := range globalResults {
-		statusMap[r.Module.Owner+"/"+r.Module.Repo] = r
+		statusMap[repoKey(r.Module)] = r
+	}
+
+	// Phase 4.5: Check indirect deps that are archived or stale for deprecation
+	if cfg.Deprecated {
+		if include := deprecationTier2(cfg, globalResults, filterStale(cfg, globalResults)); include != nil && budgetAllows(cfg, "deprecation checks", budgetShareDeprecated) {
+			deprecatedCount += checkDeprecationsAcrossModulesSelected(modules, depResolver, include)
+		}
+		if deprecatedCount > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "Found %d deprecated %s.\n", deprecatedCount, pluralize(deprecatedCount, "module", "modules"))
+		}
+	}
+
+	// Source scanning for --files is the last analysis a budget gives up
+	if cfg.Files && !budgetAllows(cfg, "--files source scanning", budgetShareFiles) {
+		cfg.Files = false
 	}
 
 	hasAnyArchived := false
+	rx := &recursiveExtras{resolver: depResolver}
+
+	// Workflows and Dockerfiles belong to the repository, not to one go.mod,
+	// so their actions and images are checked once for the scanned root
+	if cfg.Actions {
+		rx.actions = checkWorkflowActions(cfg, rootDir)
+	}
+	if cfg.Dockerfiles {
+		rx.images = checkDockerfileImages(cfg, rootDir)
+	}
+
+	recordDeprecated(cfg, modules)
+	recordScans(cfg, modules, statusMap)
 
 	switch cfg.OutputFormat {
-	case "quickfix":
+	case "quickfix", "plain":
 		hasAnyArchived = runRecursiveQuickfix(modules, statusMap, cfg)
 	case "json":
-		hasAnyArchived = runRecursiveJSON(modules, statusMap, cfg)
+		hasAnyArchived = runRecursiveJSON(modules, statusMap, cfg, rx)
 	case "markdown":
-		hasAnyArchived = runRecursiveMarkdown(modules, statusMap, cfg)
+		hasAnyArchived = runRecursiveMarkdown(modules, statusMap, cfg, rx)
+	case "heatmap":
+		hasAnyArchived = runRecursiveHeatmap(modules, statusMap, cfg)
 	default:
-		hasAnyArchived = runRecursiveText(modules, statusMap, cfg)
+		hasAnyArchived = runRecursiveText(modules, statusMap, cfg, rx)
 	}
 
-	if hasAnyArchived {
+	ps.mark("output", len(globalResults))
+
+	if hasAnyArchived || len(rx.actions) > 0 || len(rx.images) > 0 {
 		return 1
 	}
 	return 0
 }
 
-// runRecursiveQuickfix outputs quickfix-format lines across all modules.
+// recordDeprecated adds every go.mod's reported deprecated modules to the
+// run summary, which --history, --pushgateway, and the badge read whatever
+// the output format.
+func recordDeprecated(cfg *Config, modules []moduleInfo) {
+	for _, mi := range modules {
+		cfg.Summary.addDeprecated(filterModules(cfg, getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated)))
+	}
+}
+
+// recordScans adds what the GitHub query saw for every go.mod to the run
+// summary, before per-module ignore lists or the vendored-forked split, so
+// --history only closes episodes for modules that were actually checked.
+func recordScans(cfg *Config, modules []moduleInfo, statusMap map[string]RepoStatus) {
+	for _, mi := range modules {
+		cfg.Summary.observe(mi.moduleName, mi.relPath, mi.allModules, applyStatus(mi.githubModules, statusMap))
+	}
+}
+
+// countModules returns the number of requires across all parsed go.mod files.
+func countModules(modules []moduleInfo) int {
+	n := 0
+	for _, mi := range modules {
+		n += len(mi.allModules)
+	}
+	return n
+}
+
+// recursiveExtras carries what the optional analyses of a recursive scan
+// share across go.mod files.
+type recursiveExtras struct {
+	resolver *resolver       // the scan's proxy resolver, for --upgrade-paths, --remediations, and --lint-vanity
+	actions  []actionFinding // archived GitHub Actions of the scanned repository (--actions)
+	images   []imageFinding  // archived repos behind the scanned tree's Dockerfile images (--dockerfiles)
+}
+
+// hasRepoFindings reports whether there are findings for the scanned
+// repository as a whole, which recursive output lists after the go.mod files.
+func (rx *recursiveExtras) hasRepoFindings() bool {
+	return len(rx.actions) > 0 || len(rx.images) > 0
+}
+
+// moduleExtras runs the optional per-go.mod analyses for one module of a
+// recursive scan. graph is the module graph if the caller already loaded
+// it; otherwise it is loaded when an analysis needs it.
+func moduleExtras(cfg *Config, mi moduleInfo, results, vendoredForked []RepoStatus, fileMatches map[string][]FileMatch, graph map[string][]string, rx *recursiveExtras) *runExtras {
+	deprecatedModules := filterModules(cfg, getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated))
+	extras := &runExtras{
+		vendoredForked:  vendoredForked,
+		policy:          evaluatePolicy(cfg.Policy, results),
+		tags:            buildTagBreakdown(cfg, results, filterStale(cfg, results), deprecatedModules),
+		otherEcosystems: detectOtherEcosystems(cfg, filepath.Dir(mi.gomodPath)),
+	}
+	if cfg.LintVanity {
+		extras.vanity = lintVanityHosts(cfg, filterModules(cfg, mi.allModules), rx.resolver)
+	}
+	if (!cfg.UpgradePaths && !cfg.Remediations) || len(getArchivedPaths(results)) == 0 {
+		return extras
+	}
+	if graph == nil {
+		g, err := loadModGraph(cfg, mi.gomodPath)
+		if err != nil {
+			warnDegraded(cfg, "go mod graph", "could not run go mod graph for %s: %v", mi.relPath, err)
+		}
+		graph = g
+	}
+
+	var upgrades []UpgradeFinding
+	if graph != nil {
+		upgrades = analyzeUpgrades(results, graph, mi.allModules, rx.resolver, 20)
+	}
+	if cfg.UpgradePaths && graph != nil {
+		extras.upgrades = upgrades
+		if extras.upgrades == nil {
+			extras.upgrades = []UpgradeFinding{}
+		}
+	}
+	if cfg.Remediations {
+		unused := findUnusedArchived(cfg, mi.gomodPath, results, mi.allModules, fileMatches)
+		extras.remediations = planRemediations(results, mi.allModules, upgrades, unused)
+	}
+	return extras
+}
+
+// runRecursiveQuickfix outputs quickfix- or plain-format lines across all
+// modules. Plain output prefixes each file with its module's directory.
 func runRecursiveQuickfix(modules []moduleInfo, statusMap map[string]RepoStatus, cfg *Config) bool {
 	hasAnyArchived := false
 
@@ -220,15 +402,22 @@ func runRecursiveQuickfix(modules []moduleInfo, statusMap map[string]RepoStatus,
 			results, _ = il.FilterResults(results)
 		}
 
+		results, _ = splitVendoredForked(cfg, results, filepath.Dir(mi.gomodPath))
+		cfg.Summary.add(mi.moduleName, mi.relPath, results)
+
 		archivedPaths := getArchivedPaths(results)
 		if len(archivedPaths) > 0 {
 			hasAnyArchived = true
 			fm, err := ScanImports(filepath.Dir(mi.gomodPath), archivedPaths)
 			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Warning: could not scan imports for %s: %v\n", mi.relPath, err)
+				warnDegraded(cfg, "rg", "could not scan imports for %s: %v", mi.relPath, err)
 				continue
 			}
-			PrintFilesPlain(results, fm)
+			if cfg.OutputFormat == "plain" {
+				PrintProblems(cfg, relToCwd(filepath.Dir(mi.gomodPath)), results, fm)
+			} else {
+				PrintFilesPlain(results, fm)
+			}
 		}
 	}
 
@@ -236,11 +425,11 @@ func runRecursiveQuickfix(modules []moduleInfo, statusMap map[string]RepoStatus,
 }
 
 // runRecursiveJSON outputs recursive results as a single JSON document.
-func runRecursiveJSON(modules []moduleInfo, statusMap map[string]RepoStatus, cfg *Config) bool {
+func runRecursiveJSON(modules []moduleInfo, statusMap map[string]RepoStatus, cfg *Config, rx *recursiveExtras) bool {
 	hasAnyArchived := false
 
 	if cfg.Tree {
-		out := RecursiveJSONTreeOutput{Modules: []RecursiveJSONTreeEntry{}}
+		out := RecursiveJSONOutput{Projects: []RecursiveJSONEntry{}}
 
 		for _, mi := range modules {
 			results := applyStatus(mi.githubModules, statusMap)
@@ -251,6 +440,9 @@ func runRecursiveJSON(modules []moduleInfo, statusMap map[string]RepoStatus, cfg
 				results, _ = il.FilterResults(results)
 			}
 
+			results, vendoredForked := splitVendoredForked(cfg, results, filepath.Dir(mi.gomodPath))
+			cfg.Summary.add(mi.moduleName, mi.relPath, results)
+
 			archivedPaths := getArchivedPaths(results)
 			if len(archivedPaths) > 0 {
 				hasAnyArchived = true
@@ -260,33 +452,45 @@ func runRecursiveJSON(modules []moduleInfo, statusMap map[string]RepoStatus, cfg
 			if cfg.Files && len(archivedPaths) > 0 {
 				fm, err := ScanImports(filepath.Dir(mi.gomodPath), archivedPaths)
 				if err != nil {
-					_, _ = fmt.Fprintf(os.Stderr, "Warning: could not scan imports for %s: %v\n", mi.relPath, err)
+					warnDegraded(cfg, "rg", "could not scan imports for %s: %v", mi.relPath, err)
 				} else {
 					fileMatches = fm
 				}
 			}
 
-			graph, err := parseModGraph(filepath.Dir(mi.gomodPath), cfg.GoVersion)
+			graph, err := loadModGraph(cfg, mi.gomodPath)
 			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Warning: could not run go mod graph for %s: %v\n", mi.relPath, err)
+				warnDegraded(cfg, "go mod graph", "could not run go mod graph for %s: %v", mi.relPath, err)
+			}
+			extras := moduleExtras(cfg, mi, results, vendoredForked, fileMatches, graph, rx)
+			if graph == nil {
 				graph = map[string][]string{}
 			}
 
-			deprecatedModules := getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated)
+			deprecatedModules := filterModules(cfg, getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated))
 			treeOut := buildTreeJSONOutput(cfg, results, graph, mi.allModules, fileMatches, mi.nonGHModules, deprecatedModules)
-			out.Modules = append(out.Modules, RecursiveJSONTreeEntry{
-				GoMod:          mi.relPath,
-				ModulePath:     mi.moduleName,
-				GoVersion:      cfg.GoToolchain,
-				JSONTreeOutput: treeOut,
+			treeOut.VendoredForked = buildVendoredForkedJSON(extras.vendoredForked)
+			treeOut.DeadVanityHosts = buildVanityJSON(extras.vanity)
+			treeOut.Upgrades = buildJSONUpgrades(extras.upgrades)
+			treeOut.Remediations = buildRemediationJSON(extras.remediations)
+			treeOut.PolicyWarnings = buildPolicyJSON(extras.policy)
+			treeOut.ByTag = buildTagsJSON(extras.tags)
+			treeOut.OtherEcosystems = extras.otherEcosystems
+			out.Projects = append(out.Projects, RecursiveJSONEntry{
+				GoMod:      mi.relPath,
+				ModulePath: mi.moduleName,
+				GoVersion:  cfg.GoToolchain,
+				Result:     treeOut,
 			})
 		}
 
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		_ = enc.Encode(out)
+		out.ArchivedActions = buildActionsJSON(rx.actions)
+		out.ArchivedImages = buildImagesJSON(rx.images)
+		out.Meta = cfg.Summary.totals()
+		out.Errors = strictErrors(cfg)
+		writeJSON(out)
 	} else {
-		out := RecursiveJSONOutput{Modules: []RecursiveJSONEntry{}}
+		out := RecursiveJSONOutput{Projects: []RecursiveJSONEntry{}}
 
 		for _, mi := range modules {
 			results := applyStatus(mi.githubModules, statusMap)
@@ -297,6 +501,9 @@ func runRecursiveJSON(modules []moduleInfo, statusMap map[string]RepoStatus, cfg
 				results, _ = il.FilterResults(results)
 			}
 
+			results, vendoredForked := splitVendoredForked(cfg, results, filepath.Dir(mi.gomodPath))
+			cfg.Summary.add(mi.moduleName, mi.relPath, results)
+
 			archivedPaths := getArchivedPaths(results)
 			if len(archivedPaths) > 0 {
 				hasAnyArchived = true
@@ -306,33 +513,46 @@ func runRecursiveJSON(modules []moduleInfo, statusMap map[string]RepoStatus, cfg
 			if cfg.Files && len(archivedPaths) > 0 {
 				fm, err := ScanImports(filepath.Dir(mi.gomodPath), archivedPaths)
 				if err != nil {
-					_, _ = fmt.Fprintf(os.Stderr, "Warning: could not scan imports for %s: %v\n", mi.relPath, err)
+					warnDegraded(cfg, "rg", "could not scan imports for %s: %v", mi.relPath, err)
 				} else {
 					fileMatches = fm
 				}
 			}
 
-			deprecatedModules := getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated)
+			deprecatedModules := filterModules(cfg, getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated))
 			stale := filterStale(cfg, results)
 			jsonOut := buildJSONOutput(cfg, results, mi.nonGHModules, fileMatches, stale, deprecatedModules)
-			out.Modules = append(out.Modules, RecursiveJSONEntry{
+			if fileMatches != nil {
+				setJSONVia(jsonOut.Archived, filesViaForModule(cfg, mi.gomodPath, results, mi.allModules))
+			}
+			extras := moduleExtras(cfg, mi, results, vendoredForked, fileMatches, nil, rx)
+			jsonOut.VendoredForked = buildVendoredForkedJSON(extras.vendoredForked)
+			jsonOut.DeadVanityHosts = buildVanityJSON(extras.vanity)
+			jsonOut.Upgrades = buildJSONUpgrades(extras.upgrades)
+			jsonOut.Remediations = buildRemediationJSON(extras.remediations)
+			jsonOut.PolicyWarnings = buildPolicyJSON(extras.policy)
+			jsonOut.ByTag = buildTagsJSON(extras.tags)
+			jsonOut.OtherEcosystems = extras.otherEcosystems
+			out.Projects = append(out.Projects, RecursiveJSONEntry{
 				GoMod:      mi.relPath,
 				ModulePath: mi.moduleName,
 				GoVersion:  cfg.GoToolchain,
-				JSONOutput: jsonOut,
+				Result:     jsonOut,
 			})
 		}
 
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		_ = enc.Encode(out)
+		out.ArchivedActions = buildActionsJSON(rx.actions)
+		out.ArchivedImages = buildImagesJSON(rx.images)
+		out.Meta = cfg.Summary.totals()
+		out.Errors = strictErrors(cfg)
+		writeJSON(out)
 	}
 
 	return hasAnyArchived
 }
 
 // runRecursiveMarkdown outputs recursive results as Markdown with per-module headers.
-func runRecursiveMarkdown(modules []moduleInfo, statusMap map[string]RepoStatus, cfg *Config) bool {
+func runRecursiveMarkdown(modules []moduleInfo, statusMap map[string]RepoStatus, cfg *Config, rx *recursiveExtras) bool {
 	hasAnyArchived := false
 
 	for i, mi := range modules {
@@ -348,6 +568,9 @@ func runRecursiveMarkdown(modules []moduleInfo, statusMap map[string]RepoStatus,
 			}
 		}
 
+		results, vendoredForked := splitVendoredForked(cfg, results, filepath.Dir(mi.gomodPath))
+		cfg.Summary.add(mi.moduleName, mi.relPath, results)
+
 		archivedPaths := getArchivedPaths(results)
 		hasArchived := len(archivedPaths) > 0
 		if hasArchived {
@@ -368,48 +591,97 @@ func runRecursiveMarkdown(modules []moduleInfo, statusMap map[string]RepoStatus,
 		if cfg.Files && hasArchived {
 			fm, err := ScanImports(filepath.Dir(mi.gomodPath), archivedPaths)
 			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Warning: could not scan imports: %v\n", err)
+				warnDegraded(cfg, "rg", "could not scan imports: %v", err)
 			} else {
 				fileMatches = fm
 			}
 		}
 
-		deprecatedModules := getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated)
+		deprecatedModules := filterModules(cfg, getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated))
 		stale := filterStale(cfg, results)
 
+		var graph map[string][]string
 		if cfg.Tree && hasArchived {
-			graph, err := parseModGraph(filepath.Dir(mi.gomodPath), cfg.GoVersion)
+			g, err := loadModGraph(cfg, mi.gomodPath)
 			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Warning: could not run go mod graph: %v\n", err)
+				warnDegraded(cfg, "go mod graph", "could not run go mod graph: %v", err)
 			} else {
-				PrintMarkdownTree(cfg, results, graph, mi.allModules, fileMatches)
-				if len(stale) > 0 {
-					PrintMarkdownStale(cfg, stale)
-				}
-				if len(deprecatedModules) > 0 {
-					PrintMarkdown(cfg, nil, nil, deprecatedModules)
-				}
-				if len(mi.nonGHModules) > 0 {
-					PrintMarkdownSkipped(cfg, mi.nonGHModules)
-				}
-				continue
+				graph = g
 			}
 		}
+		extras := moduleExtras(cfg, mi, results, vendoredForked, fileMatches, graph, rx)
+
+		if graph != nil {
+			PrintMarkdownTree(cfg, results, graph, mi.allModules, fileMatches)
+			PrintMarkdownUnknown(results)
+			printMarkdownSections(cfg, results, stale, extras)
+			if len(deprecatedModules) > 0 {
+				PrintMarkdown(cfg, nil, nil, deprecatedModules)
+			}
+			if len(mi.nonGHModules) > 0 {
+				PrintMarkdownSkipped(cfg, mi.nonGHModules)
+			}
+			continue
+		}
 
 		PrintMarkdown(cfg, results, mi.nonGHModules, deprecatedModules)
 		if fileMatches != nil {
-			PrintMarkdownFiles(results, fileMatches)
-		}
-		if len(stale) > 0 {
-			PrintMarkdownStale(cfg, stale)
+			PrintMarkdownFiles(results, fileMatches, filesViaForModule(cfg, mi.gomodPath, results, mi.allModules))
 		}
+		printMarkdownSections(cfg, results, stale, extras)
+	}
+
+	if rx.hasRepoFindings() {
+		_, _ = fmt.Fprintf(os.Stdout, "\n# Repository\n")
+		PrintMarkdownActions(cfg, rx.actions)
+		PrintMarkdownImages(cfg, rx.images)
+	}
+
+	if len(modules) > 1 {
+		_, _ = fmt.Fprintf(os.Stdout, "\n# Total across %d go.mod files\n\n%s\n", len(modules), cfg.Summary.totals())
 	}
 
 	return hasAnyArchived
 }
 
+// runRecursiveHeatmap outputs one age heatmap covering the dependencies of
+// every go.mod, counting a module required by several of them once.
+func runRecursiveHeatmap(modules []moduleInfo, statusMap map[string]RepoStatus, cfg *Config) bool {
+	var allResults []RepoStatus
+	var allNonGH []Module
+	seen := make(map[string]bool)
+
+	for _, mi := range modules {
+		results := applyStatus(mi.githubModules, statusMap)
+
+		il := BuildIgnoreList(filepath.Dir(mi.gomodPath), cfg.IgnoreFile, cfg.IgnoreInline)
+		if il.Len() > 0 {
+			results, _ = il.FilterResults(results)
+		}
+
+		results, _ = splitVendoredForked(cfg, results, filepath.Dir(mi.gomodPath))
+		cfg.Summary.add(mi.moduleName, mi.relPath, results)
+
+		for _, r := range results {
+			if !seen[r.Module.Path] {
+				seen[r.Module.Path] = true
+				allResults = append(allResults, r)
+			}
+		}
+		for _, m := range mi.nonGHModules {
+			if !seen[m.Path] {
+				seen[m.Path] = true
+				allNonGH = append(allNonGH, m)
+			}
+		}
+	}
+
+	PrintHeatmap(cfg, allResults, allNonGH)
+	return len(getArchivedPaths(allResults)) > 0
+}
+
 // runRecursiveText outputs recursive results as text with per-module headers.
-func runRecursiveText(modules []moduleInfo, statusMap map[string]RepoStatus, cfg *Config) bool {
+func runRecursiveText(modules []moduleInfo, statusMap map[string]RepoStatus, cfg *Config, rx *recursiveExtras) bool {
 	hasAnyArchived := false
 
 	for i, mi := range modules {
@@ -425,6 +697,9 @@ func runRecursiveText(modules []moduleInfo, statusMap map[string]RepoStatus, cfg
 			}
 		}
 
+		results, vendoredForked := splitVendoredForked(cfg, results, filepath.Dir(mi.gomodPath))
+		cfg.Summary.add(mi.moduleName, mi.relPath, results)
+
 		archivedPaths := getArchivedPaths(results)
 		hasArchived := len(archivedPaths) > 0
 		if hasArchived {
@@ -445,45 +720,59 @@ func runRecursiveText(modules []moduleInfo, statusMap map[string]RepoStatus, cfg
 		if cfg.Files && hasArchived {
 			fm, err := ScanImports(filepath.Dir(mi.gomodPath), archivedPaths)
 			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Warning: could not scan imports: %v\n", err)
+				warnDegraded(cfg, "rg", "could not scan imports: %v", err)
 			} else {
 				fileMatches = fm
 			}
 		}
 
-		deprecatedModules := getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated)
+		deprecatedModules := filterModules(cfg, getDeprecatedModules(mi.allModules, cfg.DirectOnly, cfg.Deprecated))
 		stale := filterStale(cfg, results)
 
+		var graph map[string][]string
 		if cfg.Tree && hasArchived {
-			graph, err := parseModGraph(filepath.Dir(mi.gomodPath), cfg.GoVersion)
+			g, err := loadModGraph(cfg, mi.gomodPath)
 			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Warning: could not run go mod graph: %v\n", err)
+				warnDegraded(cfg, "go mod graph", "could not run go mod graph: %v", err)
 			} else {
-				if cfg.OutputFormat == "mermaid" {
-					PrintMermaid(cfg, results, graph, mi.allModules)
-				} else {
-					PrintTree(cfg, results, graph, mi.allModules, fileMatches)
-					if len(stale) > 0 {
-						PrintStaleTable(cfg, stale)
-					}
-					if len(deprecatedModules) > 0 {
-						PrintDeprecatedTable(deprecatedModules)
-					}
-					if len(mi.nonGHModules) > 0 {
-						PrintSkippedTable(cfg, mi.nonGHModules)
-					}
-				}
-				continue
+				graph = g
+			}
+		}
+
+		if graph != nil && cfg.OutputFormat == "mermaid" {
+			PrintMermaid(cfg, results, graph, mi.allModules)
+			continue
+		}
+		extras := moduleExtras(cfg, mi, results, vendoredForked, fileMatches, graph, rx)
+
+		if graph != nil {
+			PrintTree(cfg, results, graph, mi.allModules, fileMatches)
+			PrintUnknownTable(results)
+			printSections(cfg, results, stale, extras)
+			if len(deprecatedModules) > 0 {
+				PrintDeprecatedTable(deprecatedModules)
 			}
+			if len(mi.nonGHModules) > 0 {
+				PrintSkippedTable(cfg, mi.nonGHModules)
+			}
+			continue
 		}
 
 		PrintTable(cfg, results, mi.nonGHModules, deprecatedModules)
 		if fileMatches != nil {
-			PrintFiles(results, fileMatches)
-		}
-		if len(stale) > 0 {
-			PrintStaleTable(cfg, stale)
+			PrintFiles(results, fileMatches, filesViaForModule(cfg, mi.gomodPath, results, mi.allModules))
 		}
+		printSections(cfg, results, stale, extras)
+	}
+
+	if rx.hasRepoFindings() {
+		_, _ = fmt.Fprintf(os.Stderr, "\n=== Repository ===\n")
+		PrintActionsTable(cfg, rx.actions)
+		PrintImagesTable(cfg, rx.images)
+	}
+
+	if len(modules) > 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "\n=== Total across %d go.mod files ===\n%s%s\n", len(modules), cfg.Summary.totals(), cacheNote(cfg))
 	}
 
 	return hasAnyArchived
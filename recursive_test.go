@@ -1,8 +1,12 @@
 package main
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -130,6 +134,19 @@ func TestApplyStatus(t *testing.T) {
 	}
 }
 
+func TestApplyStatus_CaseInsensitiveRepo(t *testing.T) {
+	statusMap := map[string]RepoStatus{"azure/go-autorest": {IsArchived: true}}
+	modules := []Module{
+		{Path: "github.com/Azure/go-autorest/autorest", Owner: "Azure", Repo: "go-autorest"},
+		{Path: "github.com/azure/go-autorest/tracing", Owner: "azure", Repo: "go-autorest"},
+	}
+	for _, r := range applyStatus(modules, statusMap) {
+		if !r.IsArchived {
+			t.Errorf("expected %s to pick up the archived status", r.Module.Path)
+		}
+	}
+}
+
 func TestGetArchivedPaths(t *testing.T) {
 	results := []RepoStatus{
 		{Module: Module{Path: "github.com/foo/bar"}, IsArchived: true},
@@ -214,3 +231,143 @@ func TestGetDeprecatedModules_NoneDeprecated(t *testing.T) {
 		t.Errorf("expected 0 deprecated modules, got %d", len(result))
 	}
 }
+
+func TestModuleExtras_UpgradePaths(t *testing.T) {
+	srv := upgradeTestProxy(t)
+	defer srv.Close()
+
+	results, graph, allModules := upgradeTestInputs()
+	mi := moduleInfo{gomodPath: "go.mod", relPath: "go.mod", allModules: allModules}
+	rx := &recursiveExtras{resolver: &resolver{client: srv.Client(), proxyBaseURL: srv.URL}}
+
+	cfg := defaultTestConfig()
+	if extras := moduleExtras(cfg, mi, results, nil, nil, graph, rx); extras.upgrades != nil {
+		t.Errorf("upgrades without --upgrade-paths = %+v, want nil", extras.upgrades)
+	}
+
+	cfg.UpgradePaths = true
+	if extras := moduleExtras(cfg, mi, results, nil, nil, graph, rx); len(extras.upgrades) != 2 {
+		t.Errorf("upgrades = %+v, want the 2 archived indirect modules", extras.upgrades)
+	}
+}
+
+func TestModuleExtras_Remediations(t *testing.T) {
+	srv := upgradeTestProxy(t)
+	defer srv.Close()
+
+	results, graph, allModules := upgradeTestInputs()
+	mi := moduleInfo{gomodPath: filepath.Join(t.TempDir(), "go.mod"), relPath: "go.mod", allModules: allModules}
+	rx := &recursiveExtras{resolver: &resolver{client: srv.Client(), proxyBaseURL: srv.URL}}
+
+	cfg := defaultTestConfig()
+	cfg.Remediations = true
+	var extras *runExtras
+	captureStderr(t, func() { extras = moduleExtras(cfg, mi, results, nil, nil, graph, rx) })
+	if extras.upgrades != nil {
+		t.Errorf("upgrades without --upgrade-paths = %+v, want nil", extras.upgrades)
+	}
+	var upgrade bool
+	for _, r := range extras.remediations {
+		upgrade = upgrade || r.Kind == remedyUpgrade
+	}
+	if !upgrade {
+		t.Errorf("remediations = %+v, want the upgrade that drops x/old", extras.remediations)
+	}
+}
+
+func TestRecursiveOutputs_RepoFindings(t *testing.T) {
+	cfg := defaultTestConfig()
+	rx := &recursiveExtras{actions: []actionFinding{{
+		Status: RepoStatus{Module: Module{Path: "github.com/old/action", Owner: "old", Repo: "action"}, IsArchived: true},
+		Uses:   []workflowUse{{Owner: "old", Repo: "action", Ref: "v1", File: ".github/workflows/ci.yml", Line: 7}},
+	}}, images: []imageFinding{{
+		Status: RepoStatus{Module: Module{Path: "github.com/old/image", Owner: "old", Repo: "image"}, IsArchived: true},
+		Uses:   []imageUse{{Owner: "old", Repo: "image", Image: "ghcr.io/old/image:1.0", File: "Dockerfile", Line: 1}},
+	}}}
+
+	out := captureStdout(t, func() { runRecursiveJSON(nil, nil, cfg, rx) })
+	var doc RecursiveJSONOutput
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.ArchivedActions) != 1 || doc.ArchivedActions[0].Action != "old/action" {
+		t.Errorf("archived_actions = %+v", doc.ArchivedActions)
+	}
+	if len(doc.ArchivedImages) != 1 || doc.ArchivedImages[0].Repository != "old/image" {
+		t.Errorf("archived_images = %+v", doc.ArchivedImages)
+	}
+
+	md := captureStdout(t, func() { runRecursiveMarkdown(nil, nil, cfg, rx) })
+	if !strings.Contains(md, "# Repository") || !strings.Contains(md, "## ARCHIVED GITHUB ACTIONS (1)") || !strings.Contains(md, "ghcr.io/old/image:1.0") {
+		t.Errorf("markdown output missing repository findings:\n%s", md)
+	}
+
+	var table string
+	stderr := captureStderr(t, func() {
+		table = captureStdout(t, func() { runRecursiveText(nil, nil, cfg, rx) })
+	})
+	if !strings.Contains(stderr, "=== Repository ===") || !strings.Contains(table, "old/action") || !strings.Contains(table, "old/image") {
+		t.Errorf("text output missing repository findings:\n%s\n%s", stderr, table)
+	}
+}
+
+func TestModuleExtras_LintVanity(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	mi := moduleInfo{gomodPath: "go.mod", relPath: "go.mod", allModules: []Module{
+		{Path: host + "/gone", Version: "v0.1.0", Direct: true},
+		{Path: "github.com/foo/bar", Version: "v1.0.0", Direct: true, Owner: "foo", Repo: "bar"},
+	}}
+	rx := &recursiveExtras{resolver: &resolver{client: srv.Client(), proxyBaseURL: "http://unused"}}
+
+	cfg := defaultTestConfig()
+	cfg.LintVanity = true
+	var extras *runExtras
+	captureStderr(t, func() { extras = moduleExtras(cfg, mi, nil, nil, nil, nil, rx) })
+	if len(extras.vanity) != 1 || extras.vanity[0].Module.Path != host+"/gone" {
+		t.Errorf("vanity = %+v, want the dead vanity module", extras.vanity)
+	}
+}
+
+func TestRecordDeprecated(t *testing.T) {
+	modules := []moduleInfo{
+		{gomodPath: "a/go.mod", allModules: []Module{
+			{Path: "github.com/old/x", Direct: true, Deprecated: "use y"},
+			{Path: "github.com/ok/z", Direct: true},
+		}},
+		{gomodPath: "b/go.mod", allModules: []Module{
+			{Path: "github.com/old/x", Direct: true, Deprecated: "use y"},
+			{Path: "github.com/old/w", Direct: true, Deprecated: "gone"},
+		}},
+	}
+	cfg := defaultTestConfig()
+	cfg.Deprecated = true
+	recordDeprecated(cfg, modules)
+	if len(cfg.Summary.deprecated) != 2 || !cfg.Summary.deprecated["github.com/old/x"] || !cfg.Summary.deprecated["github.com/old/w"] {
+		t.Errorf("deprecated = %v, want github.com/old/x and github.com/old/w", cfg.Summary.deprecated)
+	}
+}
+
+func TestModuleExtras_Sections(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	forked := []RepoStatus{{Module: Module{Path: "github.com/old/patched", Owner: "old", Repo: "patched"}, IsArchived: true}}
+	mi := moduleInfo{gomodPath: filepath.Join(dir, "go.mod"), relPath: "go.mod"}
+
+	extras := moduleExtras(defaultTestConfig(), mi, nil, forked, nil, nil, &recursiveExtras{})
+	if len(extras.vendoredForked) != 1 || len(extras.otherEcosystems) != 1 {
+		t.Fatalf("extras = %+v, want the vendored-forked module and package.json", extras)
+	}
+
+	// The recursive tree branch prints the same sections as single-module output
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() { printSections(defaultTestConfig(), nil, nil, extras) })
+	})
+	if !strings.Contains(stderr, "VENDORED-FORKED") {
+		t.Errorf("printSections stderr = %q, want the vendored-forked section", stderr)
+	}
+}
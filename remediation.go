@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// Remediation kinds, in the order planRemediations prefers them.
+const (
+	remedyRemove  = "remove"  // archived require no package imports
+	remedyUpgrade = "upgrade" // a direct dependency upgrade drops it
+	remedyReplace = "replace" // a known successor exists
+	remedyFork    = "fork"    // nothing else applies
+)
+
+// Remediation is one action that resolves one or more archived findings.
+type Remediation struct {
+	Kind     string
+	Target   string   // upgrade: direct dep path; replace: successor; fork: owner/repo
+	From     string   // upgrade: current version
+	To       string   // upgrade: version that drops the archived modules
+	Source   string   // replace: where the successor came from
+	Resolves []string // archived module paths, sorted
+}
+
+// Action describes the remediation as an imperative phrase.
+func (r Remediation) Action() string {
+	switch r.Kind {
+	case remedyRemove:
+		return "remove unused requires (modrot tidy-archived --write)"
+	case remedyUpgrade:
+		return fmt.Sprintf("upgrade direct dep %s %s → %s", r.Target, r.From, r.To)
+	case remedyReplace:
+		return fmt.Sprintf("replace with %s (%s)", r.Target, r.Source)
+	default:
+		return "fork and maintain github.com/" + r.Target
+	}
+}
+
+// knownReplacements maps widely used archived modules to their accepted
+// successors, for modules whose authors never published a deprecation.
+var knownReplacements = map[string]string{
+	"github.com/boltdb/bolt":                "go.etcd.io/bbolt",
+	"github.com/dgrijalva/jwt-go":           "github.com/golang-jwt/jwt/v5",
+	"github.com/ghodss/yaml":                "sigs.k8s.io/yaml",
+	"github.com/gogo/protobuf":              "google.golang.org/protobuf",
+	"github.com/golang/mock":                "go.uber.org/mock",
+	"github.com/howeyc/gopass":              "golang.org/x/term",
+	"github.com/jteeuwen/go-bindata":        "embed (standard library)",
+	"github.com/mitchellh/go-homedir":       "os.UserHomeDir (standard library)",
+	"github.com/mitchellh/mapstructure":     "github.com/go-viper/mapstructure/v2",
+	"github.com/opentracing/opentracing-go": "go.opentelemetry.io/otel",
+	"github.com/pkg/errors":                 "errors and fmt.Errorf with %w (standard library)",
+	"github.com/satori/go.uuid":             "github.com/gofrs/uuid",
+}
+
+// successorRe finds a module path in free text such as a deprecation
+// message: a dotted host followed by at least one path element.
+var successorRe = regexp.MustCompile(`[a-z0-9][-a-z0-9]*(?:\.[-a-z0-9]+)*\.[a-z]{2,}(?:/[A-Za-z0-9._~-]+)+`)
+
+// successorIn returns the first module path mentioned in msg other than
+// self, or "".
+func successorIn(msg, self string) string {
+	for _, m := range successorRe.FindAllString(msg, -1) {
+		m = strings.TrimRight(m, ".")
+		if m != self {
+			return m
+		}
+	}
+	return ""
+}
+
+// replacementFor returns the successor of an archived module and where it
+// came from: the module's deprecation notice, its retraction rationale,
+// or the known-replacements table.
+func replacementFor(r RepoStatus, deprecated string) (successor, source string) {
+	if s := successorIn(deprecated, r.Module.Path); s != "" {
+		return s, "deprecation notice"
+	}
+	if s := successorIn(r.RetiredRationale, r.Module.Path); s != "" {
+		return s, "retraction"
+	}
+	if s, ok := knownReplacements[r.Module.Path]; ok {
+		return s, "known successor"
+	}
+	return "", ""
+}
+
+// planRemediations assigns every archived finding one action, preferring
+// removal of unused requires, then direct dependency upgrades that drop it,
+// then a known replacement, and falling back to forking. Upgrades and
+// replacements are grouped so each action lists every finding it resolves;
+// upgrades are picked greedily, largest first. upgrades and unused may be
+// nil when those analyses were not run.
+func planRemediations(results []RepoStatus, allModules []Module, upgrades []UpgradeFinding, unused map[string]bool) []Remediation {
+	deprecated := make(map[string]string)
+	for _, m := range allModules {
+		if m.Deprecated != "" {
+			deprecated[m.Path] = m.Deprecated
+		}
+	}
+
+	pending := make(map[string]RepoStatus)
+	for _, r := range results {
+		if r.IsArchived {
+			pending[r.Module.Path] = r
+		}
+	}
+
+	var plan []Remediation
+	remove := Remediation{Kind: remedyRemove}
+	for path := range pending {
+		if unused[path] {
+			remove.Resolves = append(remove.Resolves, path)
+			delete(pending, path)
+		}
+	}
+	if len(remove.Resolves) > 0 {
+		plan = append(plan, remove)
+	}
+
+	planned := planUpgrades(upgrades, nil)
+	sort.SliceStable(planned, func(i, j int) bool {
+		return len(planned[i].Drops) > len(planned[j].Drops)
+	})
+	for _, pu := range planned {
+		rm := Remediation{Kind: remedyUpgrade, Target: pu.Path, From: pu.From, To: pu.To}
+		for _, path := range pu.Drops {
+			if _, ok := pending[path]; ok {
+				rm.Resolves = append(rm.Resolves, path)
+				delete(pending, path)
+			}
+		}
+		if len(rm.Resolves) > 0 {
+			plan = append(plan, rm)
+		}
+	}
+
+	replace := make(map[string]*Remediation)
+	fork := make(map[string]*Remediation)
+	for path, r := range pending {
+		if s, src := replacementFor(r, deprecated[path]); s != "" {
+			if replace[s] == nil {
+				replace[s] = &Remediation{Kind: remedyReplace, Target: s, Source: src}
+			}
+			replace[s].Resolves = append(replace[s].Resolves, path)
+			continue
+		}
+		repo := r.Module.Owner + "/" + r.Module.Repo
+		if fork[repo] == nil {
+			fork[repo] = &Remediation{Kind: remedyFork, Target: repo}
+		}
+		fork[repo].Resolves = append(fork[repo].Resolves, path)
+	}
+	for _, group := range []map[string]*Remediation{replace, fork} {
+		keys := make([]string, 0, len(group))
+		for k := range group {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			plan = append(plan, *group[k])
+		}
+	}
+
+	for i := range plan {
+		sort.Strings(plan[i].Resolves)
+	}
+	return plan
+}
+
+// findUnusedArchived returns the archived requires the main module no
+// longer needs, per go mod why and the source import scan. fileMatches is
+// reused when --files already scanned imports. Failures degrade to nil.
+func findUnusedArchived(cfg *Config, gomodPath string, results []RepoStatus, allModules []Module, fileMatches map[string][]FileMatch) map[string]bool {
+	archived := archivedRequires(results, allModules)
+	if len(archived) == 0 {
+		return nil
+	}
+	paths := make([]string, len(archived))
+	for i, m := range archived {
+		paths[i] = m.Path
+	}
+	dir := filepath.Dir(gomodPath)
+	unused, err := findUnusedModules(dir, paths)
+	if err != nil {
+		warnDegraded(cfg, "go mod why", "could not find unused archived requires: %v", err)
+		return nil
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	if fileMatches == nil {
+		fm, scanErr := ScanImports(dir, paths)
+		if scanErr != nil {
+			warnDegraded(cfg, "rg", "could not confirm unused requires against source imports: %v", scanErr)
+			return nil
+		}
+		fileMatches = fm
+	}
+	for p := range fileMatches {
+		delete(unused, p)
+	}
+	return unused
+}
+
+var remediationHeaders = []string{"Action", "Resolves", "Modules"}
+
+// remediationRows formats the plan as table rows.
+func remediationRows(plan []Remediation) [][]string {
+	rows := make([][]string, len(plan))
+	for i, rm := range plan {
+		rows[i] = []string{rm.Action(), fmt.Sprint(len(rm.Resolves)), strings.Join(rm.Resolves, ", ")}
+	}
+	return rows
+}
+
+// remediationTitle summarizes the plan for section headers.
+func remediationTitle(plan []Remediation) string {
+	findings := 0
+	for _, rm := range plan {
+		findings += len(rm.Resolves)
+	}
+	return fmt.Sprintf("REMEDIATIONS (%d %s for %d archived %s)",
+		len(plan), pluralize(len(plan), "action", "actions"),
+		findings, pluralize(findings, "module", "modules"))
+}
+
+// PrintRemediationTable outputs archived findings grouped by suggested action.
+func PrintRemediationTable(plan []Remediation) {
+	if len(plan) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\n%s\n\n", remediationTitle(plan))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(remediationHeaders))
+	for _, row := range remediationRows(plan) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownRemediations outputs the remediation plan in Markdown format.
+func PrintMarkdownRemediations(plan []Remediation) {
+	if len(plan) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## %s\n\n", remediationTitle(plan))
+	printMarkdownTable(os.Stdout, remediationHeaders, remediationRows(plan))
+}
+
+// JSONRemediation is one entry of the remediations section of JSON output.
+type JSONRemediation = report.Remediation
+
+// buildRemediationJSON converts the plan to its JSON section.
+func buildRemediationJSON(plan []Remediation) []JSONRemediation {
+	var out []JSONRemediation
+	for _, rm := range plan {
+		out = append(out, JSONRemediation{
+			Kind:     rm.Kind,
+			Action:   rm.Action(),
+			Target:   rm.Target,
+			From:     rm.From,
+			To:       rm.To,
+			Source:   rm.Source,
+			Resolves: rm.Resolves,
+		})
+	}
+	return out
+}
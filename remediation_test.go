@@ -0,0 +1,168 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func archivedStatus(path, owner, repo string, direct bool) RepoStatus {
+	return RepoStatus{Module: Module{Path: path, Version: "v1.0.0", Direct: direct, Owner: owner, Repo: repo}, IsArchived: true}
+}
+
+func TestPlanRemediations(t *testing.T) {
+	results := []RepoStatus{
+		archivedStatus("github.com/old/unused", "old", "unused", true),
+		archivedStatus("github.com/a/one", "a", "one", false),
+		archivedStatus("github.com/a/two", "a", "two", false),
+		archivedStatus("github.com/b/three", "b", "three", false),
+		archivedStatus("github.com/ghodss/yaml", "ghodss", "yaml", true),
+		archivedStatus("github.com/dep/noted", "dep", "noted", true),
+		archivedStatus("github.com/lone/lib", "lone", "lib", true),
+		archivedStatus("github.com/lone/lib/v2", "lone", "lib", true),
+		{Module: Module{Path: "github.com/active/lib", Owner: "active", Repo: "lib"}},
+	}
+	allModules := []Module{
+		{Path: "github.com/dep/noted", Deprecated: "Use example.org/noted/v2 instead."},
+	}
+	upgrades := []UpgradeFinding{
+		{Module: "github.com/a/one", Via: []UpgradeVia{
+			{Path: "github.com/big/dep", Version: "v1.0.0", Latest: "v1.5.0", Drops: true},
+			{Path: "github.com/small/dep", Version: "v0.1.0", Latest: "v0.2.0", Drops: true},
+		}},
+		{Module: "github.com/a/two", Via: []UpgradeVia{
+			{Path: "github.com/big/dep", Version: "v1.0.0", Latest: "v1.5.0", Drops: true},
+		}},
+		{Module: "github.com/b/three", Via: []UpgradeVia{
+			{Path: "github.com/big/dep", Version: "v1.0.0", Latest: "v1.5.0"},
+		}},
+	}
+	unused := map[string]bool{"github.com/old/unused": true}
+
+	got := planRemediations(results, allModules, upgrades, unused)
+	want := []Remediation{
+		{Kind: remedyRemove, Resolves: []string{"github.com/old/unused"}},
+		{Kind: remedyUpgrade, Target: "github.com/big/dep", From: "v1.0.0", To: "v1.5.0", Resolves: []string{"github.com/a/one", "github.com/a/two"}},
+		{Kind: remedyReplace, Target: "example.org/noted/v2", Source: "deprecation notice", Resolves: []string{"github.com/dep/noted"}},
+		{Kind: remedyReplace, Target: "sigs.k8s.io/yaml", Source: "known successor", Resolves: []string{"github.com/ghodss/yaml"}},
+		{Kind: remedyFork, Target: "b/three", Resolves: []string{"github.com/b/three"}},
+		{Kind: remedyFork, Target: "lone/lib", Resolves: []string{"github.com/lone/lib", "github.com/lone/lib/v2"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("planRemediations() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestPlanRemediations_NoAnalyses(t *testing.T) {
+	results := []RepoStatus{archivedStatus("github.com/a/one", "a", "one", false)}
+	got := planRemediations(results, nil, nil, nil)
+	if len(got) != 1 || got[0].Kind != remedyFork {
+		t.Errorf("planRemediations() = %+v, want a single fork", got)
+	}
+	if got := planRemediations(nil, nil, nil, nil); got != nil {
+		t.Errorf("planRemediations(nil) = %+v, want nil", got)
+	}
+}
+
+func TestReplacementFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		r          RepoStatus
+		deprecated string
+		want       string
+		wantSource string
+	}{
+		{
+			name:       "deprecation notice wins",
+			r:          archivedStatus("github.com/pkg/errors", "pkg", "errors", true),
+			deprecated: "moved to github.com/pkg/errors/v2.",
+			want:       "github.com/pkg/errors/v2",
+			wantSource: "deprecation notice",
+		},
+		{
+			name:       "deprecation without a path falls back to table",
+			r:          archivedStatus("github.com/pkg/errors", "pkg", "errors", true),
+			deprecated: "no longer maintained",
+			want:       knownReplacements["github.com/pkg/errors"],
+			wantSource: "known successor",
+		},
+		{
+			name:       "retraction rationale",
+			r:          RepoStatus{Module: Module{Path: "github.com/x/y"}, IsArchived: true, Retired: true, RetiredRationale: "Moved to gitlab.com/x/y."},
+			want:       "gitlab.com/x/y",
+			wantSource: "retraction",
+		},
+		{
+			name:       "self reference is skipped",
+			r:          archivedStatus("github.com/x/y", "x", "y", true),
+			deprecated: "github.com/x/y is unmaintained",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, src := replacementFor(tt.r, tt.deprecated)
+			if got != tt.want || src != tt.wantSource {
+				t.Errorf("replacementFor() = (%q, %q), want (%q, %q)", got, src, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestRemediationAction(t *testing.T) {
+	tests := []struct {
+		rm   Remediation
+		want string
+	}{
+		{Remediation{Kind: remedyRemove}, "remove unused requires (modrot tidy-archived --write)"},
+		{Remediation{Kind: remedyUpgrade, Target: "github.com/big/dep", From: "v1.0.0", To: "v1.5.0"}, "upgrade direct dep github.com/big/dep v1.0.0 → v1.5.0"},
+		{Remediation{Kind: remedyReplace, Target: "sigs.k8s.io/yaml", Source: "known successor"}, "replace with sigs.k8s.io/yaml (known successor)"},
+		{Remediation{Kind: remedyFork, Target: "lone/lib"}, "fork and maintain github.com/lone/lib"},
+	}
+	for _, tt := range tests {
+		if got := tt.rm.Action(); got != tt.want {
+			t.Errorf("Action() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestPrintRemediationTable(t *testing.T) {
+	plan := []Remediation{
+		{Kind: remedyUpgrade, Target: "github.com/big/dep", From: "v1.0.0", To: "v1.5.0", Resolves: []string{"github.com/a/one", "github.com/a/two"}},
+		{Kind: remedyFork, Target: "lone/lib", Resolves: []string{"github.com/lone/lib"}},
+	}
+	stdout := captureStdout(t, func() { PrintRemediationTable(plan) })
+	for _, want := range []string{"ACTION", "RESOLVES", "github.com/a/one, github.com/a/two", "fork and maintain github.com/lone/lib"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %q:\n%s", want, stdout)
+		}
+	}
+	if got := remediationTitle(plan); got != "REMEDIATIONS (2 actions for 3 archived modules)" {
+		t.Errorf("remediationTitle() = %q", got)
+	}
+
+	md := captureStdout(t, func() { PrintMarkdownRemediations(plan) })
+	if !strings.Contains(md, "## REMEDIATIONS (2 actions for 3 archived modules)") || !strings.Contains(md, "| Action |") {
+		t.Errorf("markdown output:\n%s", md)
+	}
+	if out := captureStdout(t, func() { PrintRemediationTable(nil) }); out != "" {
+		t.Errorf("empty plan should print nothing, got %q", out)
+	}
+}
+
+func TestBuildRemediationJSON(t *testing.T) {
+	plan := []Remediation{{Kind: remedyReplace, Target: "go.uber.org/mock", Source: "known successor", Resolves: []string{"github.com/golang/mock"}}}
+	got := buildRemediationJSON(plan)
+	want := []JSONRemediation{{
+		Kind:     "replace",
+		Action:   "replace with go.uber.org/mock (known successor)",
+		Target:   "go.uber.org/mock",
+		Source:   "known successor",
+		Resolves: []string{"github.com/golang/mock"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildRemediationJSON() = %+v, want %+v", got, want)
+	}
+	if buildRemediationJSON(nil) != nil {
+		t.Error("nil plan should produce nil JSON")
+	}
+}
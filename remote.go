@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Remote scanning defaults and hard limits. The limits bound what a
+// caller (including serve mode) can make modrot clone.
+const (
+	defaultCloneDepth   = 1
+	maxCloneDepth       = 100
+	defaultCloneTimeout = 2 * time.Minute
+	maxCloneBytes       = 1 << 30 // 1 GiB on disk after checkout
+	maxRemoteURLLen     = 256
+
+	// workspacePrefix names the temp directories remote scans clone into.
+	workspacePrefix = "modrot-remote-"
+	// staleWorkspaceAge is how old a leftover workspace (from a killed run)
+	// must be before cleanupStaleWorkspaces removes it.
+	staleWorkspaceAge = 6 * time.Hour
+)
+
+// defaultRemoteHosts are the hosts remote repository URLs may point at
+// unless --remote-hosts says otherwise.
+var defaultRemoteHosts = []string{"github.com", "gitlab.com", "bitbucket.org", "codeberg.org"}
+
+// remotePathSegmentRe matches one owner/group/repo path segment.
+var remotePathSegmentRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// remoteRefRe matches a branch or tag name a remote spec may pin with @REF.
+var remoteRefRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]{0,99}$`)
+
+// splitRemoteRef splits a trailing @REF (branch or tag) off a remote spec,
+// as in github.com/org/repo@release-1.4. An @ before the path, as in
+// user@host credentials, is left for validateRemoteURL to reject.
+func splitRemoteRef(spec string) (string, string) {
+	_, rest, ok := strings.Cut(spec, "://")
+	if !ok {
+		rest = spec
+	}
+	slash := strings.Index(rest, "/")
+	at := strings.LastIndex(rest, "@")
+	if slash < 0 || at < slash {
+		return spec, ""
+	}
+	return spec[:len(spec)-len(rest)+at], rest[at+1:]
+}
+
+// isRemoteSpec reports whether a path argument names a remote repository
+// rather than a local path: an https:// URL, or host/owner/repo for a
+// known host with no local file of that name.
+func isRemoteSpec(cfg *Config, arg string) bool {
+	if strings.HasPrefix(arg, "https://") || strings.HasPrefix(arg, "http://") {
+		return true
+	}
+	host, _, ok := strings.Cut(arg, "/")
+	if !ok || !hostAllowed(cfg, host) {
+		return false
+	}
+	_, err := os.Stat(arg)
+	return os.IsNotExist(err)
+}
+
+// validateRemoteURL strictly validates a remote repository spec and returns
+// the canonical https clone URL. Only https URLs on allowed hosts with plain
+// owner/repo paths are accepted: no credentials, ports, IP literals,
+// queries, fragments, or path tricks.
+func validateRemoteURL(cfg *Config, spec string) (string, error) {
+	if len(spec) > maxRemoteURLLen {
+		return "", fmt.Errorf("remote URL longer than %d characters", maxRemoteURLLen)
+	}
+	raw := spec
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid remote URL %q: %w", spec, err)
+	}
+	switch {
+	case u.Scheme != "https":
+		return "", fmt.Errorf("remote URL %q: only https is allowed", spec)
+	case u.User != nil:
+		return "", fmt.Errorf("remote URL %q: credentials in URLs are not allowed", spec)
+	case u.Port() != "":
+		return "", fmt.Errorf("remote URL %q: explicit ports are not allowed", spec)
+	case u.RawQuery != "" || u.Fragment != "" || u.ForceQuery:
+		return "", fmt.Errorf("remote URL %q: query strings and fragments are not allowed", spec)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if net.ParseIP(host) != nil {
+		return "", fmt.Errorf("remote URL %q: IP addresses are not allowed", spec)
+	}
+	if !hostAllowed(cfg, host) {
+		return "", fmt.Errorf("remote URL %q: host %s is not allowed (allowed: %s; see --remote-hosts)",
+			spec, host, strings.Join(remoteHosts(cfg), ", "))
+	}
+
+	path := strings.TrimSuffix(strings.Trim(u.EscapedPath(), "/"), ".git")
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 || len(segments) > 5 {
+		return "", fmt.Errorf("remote URL %q: want %s/OWNER/REPO", spec, host)
+	}
+	for _, seg := range segments {
+		if !remotePathSegmentRe.MatchString(seg) || strings.Contains(seg, "..") {
+			return "", fmt.Errorf("remote URL %q: invalid path segment %q", spec, seg)
+		}
+	}
+	return "https://" + host + "/" + strings.Join(segments, "/") + ".git", nil
+}
+
+// remoteHosts returns the allowed remote hosts.
+func remoteHosts(cfg *Config) []string {
+	if len(cfg.Remote.Hosts) > 0 {
+		return cfg.Remote.Hosts
+	}
+	return defaultRemoteHosts
+}
+
+// hostAllowed reports whether host is in the remote host allowlist.
+func hostAllowed(cfg *Config, host string) bool {
+	host = strings.ToLower(host)
+	for _, h := range remoteHosts(cfg) {
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// workspace is a temporary directory a remote repository is cloned into.
+// Call Cleanup when done; cleanupStaleWorkspaces removes workspaces left
+// behind by runs that were killed before cleaning up.
+type workspace struct {
+	dir string
+}
+
+// newWorkspace creates an empty workspace under the system temp directory.
+func newWorkspace() (*workspace, error) {
+	dir, err := os.MkdirTemp("", workspacePrefix)
+	if err != nil {
+		return nil, err
+	}
+	return &workspace{dir: dir}, nil
+}
+
+// Cleanup removes the workspace and everything in it. Safe on nil.
+func (w *workspace) Cleanup() {
+	if w == nil || w.dir == "" {
+		return
+	}
+	_ = os.RemoveAll(w.dir)
+	w.dir = ""
+}
+
+// cleanupStaleWorkspaces removes workspaces in tmpDir older than maxAge.
+// Returns the number removed.
+func cleanupStaleWorkspaces(tmpDir string, maxAge time.Duration, now time.Time) int {
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return 0
+	}
+	removed := 0
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), workspacePrefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || now.Sub(info.ModTime()) < maxAge {
+			continue
+		}
+		if os.RemoveAll(filepath.Join(tmpDir, e.Name())) == nil {
+			removed++
+		}
+	}
+	return removed
+}
+
+// fetchRemote validates spec, clones it shallowly into a fresh workspace,
+// and returns the checkout directory. A trailing @REF clones that branch or
+// tag instead of the default branch. The caller must Cleanup the
+// workspace; on error it has already been removed.
+func fetchRemote(cfg *Config, spec string) (string, *workspace, error) {
+	spec, ref := splitRemoteRef(spec)
+	if ref != "" && (!remoteRefRe.MatchString(ref) || strings.Contains(ref, "..")) {
+		return "", nil, fmt.Errorf("remote URL %q: invalid ref %q", spec, ref)
+	}
+	cloneURL, err := validateRemoteURL(cfg, spec)
+	if err != nil {
+		return "", nil, err
+	}
+	cleanupStaleWorkspaces(os.TempDir(), staleWorkspaceAge, time.Now())
+
+	ws, err := newWorkspace()
+	if err != nil {
+		return "", nil, err
+	}
+	dir := filepath.Join(ws.dir, "repo")
+
+	timeout := cfg.Remote.Timeout
+	if timeout <= 0 {
+		timeout = defaultCloneTimeout
+	}
+	ctx, cancel := context.WithTimeout(runCtx, timeout)
+	defer cancel()
+
+	label := cloneURL
+	if ref != "" {
+		label += "@" + ref
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Cloning %s (depth %d)...\n", label, cloneDepth(cfg))
+	if err := cloneShallow(ctx, cloneURL, ref, dir, cloneDepth(cfg)); err != nil {
+		ws.Cleanup()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", nil, fmt.Errorf("cloning %s: timed out after %s", cloneURL, timeout)
+		}
+		return "", nil, fmt.Errorf("cloning %s: %w", cloneURL, err)
+	}
+	if size, err := dirSize(dir); err != nil || size > maxCloneBytes {
+		ws.Cleanup()
+		if err != nil {
+			return "", nil, err
+		}
+		return "", nil, fmt.Errorf("cloning %s: checkout is %d MiB, over the %d MiB limit", cloneURL, size>>20, maxCloneBytes>>20)
+	}
+	return dir, ws, nil
+}
+
+// cloneDepth returns the clone depth, clamped to [1, maxCloneDepth].
+func cloneDepth(cfg *Config) int {
+	return min(max(cfg.Remote.Depth, defaultCloneDepth), maxCloneDepth)
+}
+
+// cloneShallow runs a shallow, single-branch clone of ref (the default
+// branch if empty) with hooks, submodules, credential prompts, and
+// non-https transports disabled.
+func cloneShallow(ctx context.Context, cloneURL, ref, dir string, depth int) error {
+	args := []string{
+		"-c", "protocol.allow=never",
+		"-c", "protocol.https.allow=always",
+		"-c", "core.hooksPath=/dev/null",
+		"-c", "credential.helper=",
+		"clone", "--quiet", "--depth", fmt.Sprint(depth), "--single-branch", "--no-tags",
+	}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", cloneURL, dir)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "GIT_LFS_SKIP_SMUDGE=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// dirSize returns the total size of regular files under dir.
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
@@ -0,0 +1,38 @@
+package report_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// asciiDoc renders archived dependencies as an AsciiDoc table.
+type asciiDoc struct{}
+
+func (asciiDoc) Render(w io.Writer, doc *report.Document) error {
+	for _, p := range doc.Projects {
+		_, _ = fmt.Fprintln(w, "|===\n|Module |Version |Archived")
+		for _, m := range p.Archived {
+			_, _ = fmt.Fprintf(w, "|%s |%s |%s\n", m.Module, m.Version, m.ArchivedAt[:10])
+		}
+		_, _ = fmt.Fprintln(w, "|===")
+	}
+	return nil
+}
+
+func Example() {
+	// In a real renderer the input is os.Stdin, piped from modrot --json.
+	in := strings.NewReader(`{"archived": [{"module": "github.com/pkg/errors", "version": "v0.9.1",
+		"archived_at": "2021-12-01T00:00:00Z"}], "non_github_count": 0, "total_checked": 1, "meta": {}}`)
+	if err := report.Run(asciiDoc{}, in, os.Stdout); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// |===
+	// |Module |Version |Archived
+	// |github.com/pkg/errors |v0.9.1 |2021-12-01
+	// |===
+}
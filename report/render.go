@@ -0,0 +1,60 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Renderer writes a Document in some output format.
+type Renderer interface {
+	Render(w io.Writer, doc *Document) error
+}
+
+// RendererFunc adapts a function to the Renderer interface.
+type RendererFunc func(w io.Writer, doc *Document) error
+
+// Render calls f(w, doc).
+func (f RendererFunc) Render(w io.Writer, doc *Document) error {
+	return f(w, doc)
+}
+
+// Decode reads one modrot JSON document: single-module or --recursive
+// output, with or without --tree. It decodes into the same types modrot
+// encoded the document from.
+func Decode(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var probe struct {
+		Modules json.RawMessage `json:"modules"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("parsing modrot JSON: %w", err)
+	}
+
+	if probe.Modules != nil {
+		var doc Document
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parsing modrot JSON: %w", err)
+		}
+		return &doc, nil
+	}
+
+	var res Result
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("parsing modrot JSON: %w", err)
+	}
+	return &Document{Projects: []Project{{Result: res}}, Meta: res.Meta, Errors: res.Errors}, nil
+}
+
+// Run decodes a modrot JSON document from in and renders it to out; a
+// custom renderer's main function can be just this call.
+func Run(r Renderer, in io.Reader, out io.Writer) error {
+	doc, err := Decode(in)
+	if err != nil {
+		return err
+	}
+	return r.Render(out, doc)
+}
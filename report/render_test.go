@@ -0,0 +1,84 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+const singleJSON = `{
+  "archived": [{"module": "github.com/pkg/errors", "version": "v0.9.1", "direct": true,
+    "owner": "pkg", "repo": "errors", "archived_at": "2021-12-01T00:00:00Z",
+    "source_files": [{"file": "main.go", "line": 5, "import": "github.com/pkg/errors", "usage_kind": "call"}]}],
+  "non_github_count": 2,
+  "total_checked": 10,
+  "meta": {"unique_repos": 10, "archived_repos": 1, "archived_direct_repos": 1, "archived_pct": 10, "archived_direct_pct": 10},
+  "errors": [{"component": "rg", "message": "rg not found"}]
+}`
+
+const recursiveJSON = `{
+  "modules": [
+    {"go_mod": "go.mod", "module_path": "example.com/a", "archived": [], "non_github_count": 0, "total_checked": 3, "meta": {}},
+    {"go_mod": "tools/go.mod", "module_path": "example.com/a/tools",
+     "tree": [{"module": "github.com/x/y", "version": "v1.0.0", "archived": false,
+       "archived_dependencies": [{"module": "github.com/old/lib", "version": "v0.1.0"}]}],
+     "non_github_count": 0, "total_checked": 4, "meta": {}}
+  ],
+  "meta": {"unique_repos": 7, "archived_repos": 1}
+}`
+
+func TestDecode_Single(t *testing.T) {
+	doc, err := Decode(strings.NewReader(singleJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Projects) != 1 || doc.Projects[0].GoMod != "" {
+		t.Fatalf("Projects = %+v, want one unnamed project", doc.Projects)
+	}
+	res := doc.Projects[0].Result
+	if len(res.Archived) != 1 || res.Archived[0].SourceFiles[0].Line != 5 {
+		t.Errorf("Archived = %+v", res.Archived)
+	}
+	if doc.Meta.Archived != 1 || len(doc.Errors) != 1 || doc.Errors[0].Component != "rg" {
+		t.Errorf("document meta/errors = %+v %+v", doc.Meta, doc.Errors)
+	}
+}
+
+func TestDecode_Recursive(t *testing.T) {
+	doc, err := Decode(strings.NewReader(recursiveJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Projects) != 2 || doc.Projects[1].GoMod != "tools/go.mod" {
+		t.Fatalf("Projects = %+v", doc.Projects)
+	}
+	tree := doc.Projects[1].Tree
+	if len(tree) != 1 || tree[0].ArchivedDependencies[0].Module != "github.com/old/lib" {
+		t.Errorf("Tree = %+v", tree)
+	}
+	if doc.Meta.Repos != 7 {
+		t.Errorf("Meta = %+v", doc.Meta)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	if _, err := Decode(strings.NewReader("not json")); err == nil {
+		t.Error("want error for invalid JSON")
+	}
+}
+
+func TestRun(t *testing.T) {
+	var buf bytes.Buffer
+	count := RendererFunc(func(w io.Writer, doc *Document) error {
+		_, err := fmt.Fprintf(w, "%d archived\n", len(doc.Projects[0].Archived))
+		return err
+	})
+	if err := Run(count, strings.NewReader(singleJSON), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1 archived\n" {
+		t.Errorf("Run output = %q", buf.String())
+	}
+}
@@ -0,0 +1,357 @@
+// Package report is the typed model of modrot's JSON output, for writing
+// custom renderers (Confluence, AsciiDoc, ...) without hand-parsing JSON.
+//
+// modrot builds its --json output from these types, so the model is the
+// JSON schema. Run modrot with --json (optionally with --recursive or
+// --tree), read its output with Decode, and render the Document with a
+// Renderer:
+//
+//	modrot --json | my-renderer
+//
+// Fields are only ever added, never renamed or removed, so renderers keep
+// compiling across modrot releases.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Document is one modrot JSON document: the --recursive output. Single-module
+// output decodes to a Document with one Project whose GoMod is empty.
+type Document struct {
+	Projects        []Project        `json:"modules"`
+	ArchivedActions []ArchivedAction `json:"archived_actions,omitempty"` // of the scanned repository (--actions)
+	ArchivedImages  []ArchivedImage  `json:"archived_images,omitempty"`  // of the scanned tree's Dockerfiles (--dockerfiles)
+	Meta            Totals           `json:"meta"`                       // unique-repo totals across all projects
+	Errors          []Problem        `json:"errors,omitempty"`           // degradations seen during the run
+}
+
+// Project is the result for one go.mod file.
+type Project struct {
+	GoMod      string `json:"go_mod"`
+	ModulePath string `json:"module_path"`
+	GoVersion  string `json:"go_version,omitempty"`
+	Result
+}
+
+// MarshalJSON encodes p's go.mod fields followed by its Result. Project
+// would otherwise inherit Result's MarshalJSON and lose the go.mod fields.
+func (p Project) MarshalJSON() ([]byte, error) {
+	type header struct {
+		GoMod      string `json:"go_mod"`
+		ModulePath string `json:"module_path"`
+		GoVersion  string `json:"go_version,omitempty"`
+	}
+	h := header{p.GoMod, p.ModulePath, p.GoVersion}
+	if p.Tree == nil {
+		return json.Marshal(struct {
+			header
+			result
+		}{h, result(p.Result)})
+	}
+	return json.Marshal(struct {
+		header
+		treeResult
+	}{h, newTreeResult(p.Result)})
+}
+
+// Result is the report for one go.mod: the equivalent of modrot --json,
+// or of --tree --json when Tree is set (Archived, Stale, NotFound, and
+// Active are then empty).
+type Result struct {
+	Archived         []Module         `json:"archived"`
+	Disabled         []Module         `json:"disabled,omitempty"`
+	Tree             []TreeEntry      `json:"tree,omitempty"`
+	VendoredForked   []Module         `json:"vendored_forked,omitempty"`
+	PolicyWarnings   []PolicyWarning  `json:"policy_warnings,omitempty"`
+	OtherEcosystems  []Manifest       `json:"other_ecosystems,omitempty"`
+	ArchivedActions  []ArchivedAction `json:"archived_actions,omitempty"`
+	ArchivedImages   []ArchivedImage  `json:"archived_images,omitempty"`
+	DeadVanityHosts  []DeadVanity     `json:"dead_vanity_hosts,omitempty"`
+	Stale            []Module         `json:"stale,omitempty"`
+	Deprecated       []Module         `json:"deprecated,omitempty"`
+	NotFound         []Module         `json:"not_found,omitempty"`
+	Unknown          []Module         `json:"unknown,omitempty"` // status could not be checked; Error says why
+	Active           []Module         `json:"active,omitempty"`
+	NonGitHubCount   int              `json:"non_github_count"`
+	NonGitHubModules []SkippedModule  `json:"non_github_modules,omitempty"`
+	TotalChecked     int              `json:"total_checked"`
+	Meta             Totals           `json:"meta"`
+	Upgrades         *Upgrades        `json:"upgrade_analysis,omitempty"`
+	Remediations     []Remediation    `json:"remediations,omitempty"`
+	ByTag            []TagBreakdown   `json:"by_tag,omitempty"`
+	Errors           []Problem        `json:"errors,omitempty"`
+}
+
+// result is Result without its MarshalJSON method.
+type result Result
+
+// treeResult is the encoding of a --tree Result: it always has a tree, even
+// an empty one, and never the flat archived list.
+type treeResult struct {
+	Tree     []TreeEntry `json:"tree"`
+	Archived []Module    `json:"archived,omitempty"`
+	result
+}
+
+func newTreeResult(r Result) treeResult {
+	return treeResult{Tree: r.Tree, result: result(r)}
+}
+
+// MarshalJSON encodes r as modrot writes it: a Result whose Tree is set
+// (even to an empty slice) is --tree output.
+func (r Result) MarshalJSON() ([]byte, error) {
+	if r.Tree == nil {
+		return json.Marshal(result(r))
+	}
+	return json.Marshal(newTreeResult(r))
+}
+
+// Module is a GitHub-hosted dependency. Timestamps are RFC 3339 strings as
+// in the JSON; empty means unknown or not applicable.
+type Module struct {
+	FindingID           string        `json:"finding_id,omitempty"` // stable across runs; absent for active modules
+	Module              string        `json:"module"`
+	Version             string        `json:"version"`
+	Direct              bool          `json:"direct"`
+	Owner               string        `json:"owner"`
+	Repo                string        `json:"repo"`
+	ArchivedAt          string        `json:"archived_at,omitempty"`
+	ArchivedAtSource    string        `json:"archived_at_source,omitempty"`    // github, or unknown when GitHub has no date
+	ArchivedAtPrecision string        `json:"archived_at_precision,omitempty"` // second; empty when the date is unknown
+	ArchivedDuration    string        `json:"archived_duration,omitempty"`
+	PushedAt            string        `json:"pushed_at,omitempty"`
+	Error               string        `json:"error,omitempty"`
+	Triage              string        `json:"triage,omitempty"`          // not found: renamed, deleted_cached, or not_found_anywhere
+	RenamedTo           string        `json:"renamed_to,omitempty"`      // not found, renamed: the new module path
+	DisabledReason      string        `json:"disabled_reason,omitempty"` // disabled (suspended by GitHub) or takedown
+	DeprecatedMessage   string        `json:"deprecated_message,omitempty"`
+	LatestVersion       string        `json:"latest_version,omitempty"`
+	Behind              string        `json:"behind,omitempty"`
+	MajorUpgrade        *MajorUpgrade `json:"major_upgrade,omitempty"`
+	SourceFiles         []SourceFile  `json:"source_files,omitempty"`
+	Via                 []string      `json:"via,omitempty"`         // direct deps pulling it in, as path@version
+	RequiredAt          string        `json:"required_at,omitempty"` // go.mod:LINE of the require
+	Tags                []string      `json:"tags,omitempty"`        // criticality tags from .modrot.yaml
+
+	LastRelease            *Release   `json:"last_release,omitempty"`
+	AdvisoriesAfterRelease []Advisory `json:"advisories_after_release,omitempty"`
+
+	Retired             bool   `json:"retired,omitempty"`              // every published version is retracted
+	RetractionRationale string `json:"retraction_rationale,omitempty"` // rationale of the covering retraction
+}
+
+// TagBreakdown counts the findings among the modules carrying one
+// criticality tag; Tag is "(untagged)" for modules no tag matches.
+type TagBreakdown struct {
+	Tag             string   `json:"tag"`
+	Modules         int      `json:"modules"`
+	Archived        int      `json:"archived"`
+	Disabled        int      `json:"disabled"`
+	Stale           int      `json:"stale"`
+	Deprecated      int      `json:"deprecated"`
+	ArchivedModules []string `json:"archived_modules,omitempty"`
+}
+
+// SkippedModule is a dependency not hosted on GitHub.
+type SkippedModule struct {
+	Module        string        `json:"module"`
+	Version       string        `json:"version"`
+	Direct        bool          `json:"direct"`
+	LatestVersion string        `json:"latest_version,omitempty"`
+	Behind        string        `json:"behind,omitempty"`
+	Published     string        `json:"published,omitempty"`
+	Host          string        `json:"host,omitempty"`
+	SourceURL     string        `json:"source_url,omitempty"`
+	MajorUpgrade  *MajorUpgrade `json:"major_upgrade,omitempty"`
+	RequiredAt    string        `json:"required_at,omitempty"`
+}
+
+// SourceFile is an import of an archived module (--files).
+type SourceFile struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Import    string `json:"import"`
+	UsageKind string `json:"usage_kind,omitempty"` // call, type-only, side-effect, unknown
+}
+
+// MajorUpgrade is the newest major version module path of a dependency.
+type MajorUpgrade struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+}
+
+// Release is a dependency's latest GitHub release (--advisories).
+type Release struct {
+	Tag         string `json:"tag"`
+	PublishedAt string `json:"published_at"`
+}
+
+// Advisory is an OSV advisory published after a dependency's last release.
+type Advisory struct {
+	ID        string `json:"id"`
+	Summary   string `json:"summary,omitempty"`
+	Published string `json:"published"`
+}
+
+// PolicyWarning is a dependency whose repository matched a --policy rule.
+type PolicyWarning struct {
+	FindingID string `json:"finding_id"`
+	Module    string `json:"module"`
+	Version   string `json:"version"`
+	Direct    bool   `json:"direct"`
+	Rule      string `json:"rule"`
+	Matched   string `json:"matched"`
+}
+
+// Manifest is a non-Go dependency manifest found next to go.mod.
+type Manifest struct {
+	Ecosystem string `json:"ecosystem"`
+	Manifest  string `json:"manifest"`
+}
+
+// ArchivedAction is an archived GitHub Actions repository referenced from
+// the scanned repo's workflows (--actions).
+type ArchivedAction struct {
+	FindingID           string        `json:"finding_id"`
+	Action              string        `json:"action"` // owner/repo
+	ArchivedAt          string        `json:"archived_at,omitempty"`
+	ArchivedAtSource    string        `json:"archived_at_source,omitempty"`
+	ArchivedAtPrecision string        `json:"archived_at_precision,omitempty"`
+	PushedAt            string        `json:"pushed_at,omitempty"`
+	Uses                []WorkflowUse `json:"uses"`
+}
+
+// WorkflowUse is one `uses:` reference to an archived action.
+type WorkflowUse struct {
+	Workflow string `json:"workflow"` // workflow file path
+	Line     int    `json:"line"`
+	Ref      string `json:"ref"` // tag, branch, or commit after the @
+}
+
+// ArchivedImage is an archived GitHub repository whose ghcr.io image the
+// scanned repo's Dockerfiles use (--dockerfiles).
+type ArchivedImage struct {
+	FindingID           string     `json:"finding_id"`
+	Repository          string     `json:"repository"` // owner/repo
+	ArchivedAt          string     `json:"archived_at,omitempty"`
+	ArchivedAtSource    string     `json:"archived_at_source,omitempty"`
+	ArchivedAtPrecision string     `json:"archived_at_precision,omitempty"`
+	PushedAt            string     `json:"pushed_at,omitempty"`
+	Uses                []ImageUse `json:"uses"`
+}
+
+// ImageUse is one Dockerfile reference (FROM or COPY --from) to an image.
+type ImageUse struct {
+	Dockerfile string `json:"dockerfile"`
+	Line       int    `json:"line"`
+	Image      string `json:"image"` // full reference, e.g. ghcr.io/owner/repo:1.2
+}
+
+// DeadVanity is a dependency whose vanity import host no longer serves a
+// go-import meta tag for it (--lint-vanity).
+type DeadVanity struct {
+	FindingID string `json:"finding_id"`
+	Module    string `json:"module"`
+	Version   string `json:"version"`
+	Direct    bool   `json:"direct"`
+	Problem   string `json:"problem"` // "unreachable" or "no-go-import"
+}
+
+// Totals are archive counts normalized to unique GitHub repositories.
+type Totals struct {
+	Repos             int     `json:"unique_repos"`
+	Archived          int     `json:"archived_repos"`
+	ArchivedDirect    int     `json:"archived_direct_repos"`
+	ArchivedPct       float64 `json:"archived_pct"`
+	ArchivedDirectPct float64 `json:"archived_direct_pct"`
+	Unknown           int     `json:"unknown_repos,omitempty"` // repos whose status could not be checked
+}
+
+// String formats the totals for the summary line:
+//
+//	Unique repos: 40 checked, 3 archived (7.5%), 2 archived direct (5.0%)
+func (t Totals) String() string {
+	s := fmt.Sprintf("Unique repos: %d checked, %d archived (%.1f%%), %d archived direct (%.1f%%)",
+		t.Repos, t.Archived, t.ArchivedPct, t.ArchivedDirect, t.ArchivedDirectPct)
+	if t.Unknown > 0 {
+		s += fmt.Sprintf(", %d unknown", t.Unknown)
+	}
+	return s
+}
+
+// Problem is a tool-environment degradation (missing rg, failing go mod
+// graph, ...) that reduced what the report covers.
+type Problem struct {
+	Component string `json:"component"`
+	Message   string `json:"message"`
+}
+
+// Remediation is one suggested action (--remediations) and the archived
+// modules it resolves.
+type Remediation struct {
+	Kind     string   `json:"kind"` // remove, upgrade, replace, or fork
+	Action   string   `json:"action"`
+	Target   string   `json:"target,omitempty"` // direct dep, successor, or owner/repo
+	From     string   `json:"from,omitempty"`
+	To       string   `json:"to,omitempty"`
+	Source   string   `json:"source,omitempty"` // where a replacement came from
+	Resolves []string `json:"resolves"`
+}
+
+// Upgrades is the --upgrade-paths analysis.
+type Upgrades struct {
+	Actionable  []UpgradeFinding `json:"actionable"`
+	Unavoidable []UpgradeFinding `json:"unavoidable"`
+	Commands    []string         `json:"commands"` // go get ... && go mod tidy per actionable upgrade
+}
+
+// UpgradeFinding is one archived indirect module and the direct
+// dependencies that pull it in.
+type UpgradeFinding struct {
+	Module  string       `json:"module"`
+	Version string       `json:"version"`
+	Via     []UpgradeVia `json:"via"`
+}
+
+// UpgradeVia is one direct dependency path in an UpgradeFinding.
+type UpgradeVia struct {
+	Module        string `json:"module"`
+	Version       string `json:"version"`
+	LatestVersion string `json:"latest_version,omitempty"`
+	DropsArchived bool   `json:"drops_archived"`
+}
+
+// TreeEntry is a direct dependency in --tree output and the archived
+// modules it pulls in.
+type TreeEntry struct {
+	FindingID            string            `json:"finding_id,omitempty"` // set when archived or deprecated
+	Module               string            `json:"module"`
+	Version              string            `json:"version"`
+	Archived             bool              `json:"archived"`
+	ArchivedAt           string            `json:"archived_at,omitempty"`
+	ArchivedAtSource     string            `json:"archived_at_source,omitempty"`    // github, or unknown when GitHub has no date
+	ArchivedAtPrecision  string            `json:"archived_at_precision,omitempty"` // second; empty when the date is unknown
+	ArchivedDuration     string            `json:"archived_duration,omitempty"`
+	PushedAt             string            `json:"pushed_at,omitempty"`
+	DeprecatedMessage    string            `json:"deprecated_message,omitempty"`
+	RequiredAt           string            `json:"required_at,omitempty"` // go.mod:LINE of the require
+	SourceFiles          []SourceFile      `json:"source_files,omitempty"`
+	ArchivedDependencies []TreeArchivedDep `json:"archived_dependencies"`
+}
+
+// TreeArchivedDep is an archived transitive dependency in a TreeEntry.
+type TreeArchivedDep struct {
+	FindingID           string       `json:"finding_id,omitempty"`
+	Module              string       `json:"module"`
+	Version             string       `json:"version"`
+	ArchivedAt          string       `json:"archived_at,omitempty"`
+	ArchivedAtSource    string       `json:"archived_at_source,omitempty"`    // github, or unknown when GitHub has no date
+	ArchivedAtPrecision string       `json:"archived_at_precision,omitempty"` // second; empty when the date is unknown
+	ArchivedDuration    string       `json:"archived_duration,omitempty"`
+	PushedAt            string       `json:"pushed_at,omitempty"`
+	DeprecatedMessage   string       `json:"deprecated_message,omitempty"`
+	RequiredAt          string       `json:"required_at,omitempty"` // go.mod:LINE of the require
+	SourceFiles         []SourceFile `json:"source_files,omitempty"`
+}
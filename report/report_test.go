@@ -0,0 +1,68 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestResultMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       any
+		want     []string
+		unwanted []string
+	}{
+		{"flat", Result{}, []string{`"archived":null`}, []string{`"tree"`}},
+		{"empty tree", Result{Tree: []TreeEntry{}}, []string{`"tree":[]`}, []string{`"archived"`}},
+		{"project", Project{GoMod: "tools/go.mod", Result: Result{Tree: []TreeEntry{}}},
+			[]string{`{"go_mod":"tools/go.mod","module_path":"","tree":[]`}, []string{`"archived"`}},
+		{"document", Document{Projects: []Project{{GoMod: "go.mod", Result: Result{Archived: []Module{}}}}},
+			[]string{`{"modules":[{"go_mod":"go.mod","module_path":"","archived":[]`, `"meta":{`}, []string{`"tree"`}},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(string(data), s) {
+				t.Errorf("%s: %s\nmissing %s", tt.name, data, s)
+			}
+		}
+		for _, s := range tt.unwanted {
+			if strings.Contains(string(data), s) {
+				t.Errorf("%s: %s\nshould not contain %s", tt.name, data, s)
+			}
+		}
+	}
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	in := Project{GoMod: "go.mod", ModulePath: "example.com/a", Result: Result{
+		Tree: []TreeEntry{{Module: "github.com/x/y", ArchivedDependencies: []TreeArchivedDep{{Module: "github.com/old/lib"}}}},
+		Meta: Totals{Repos: 2, Archived: 1},
+	}}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Project
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.ModulePath != in.ModulePath || len(out.Tree) != 1 || out.Tree[0].ArchivedDependencies[0].Module != "github.com/old/lib" || out.Meta != in.Meta {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestTotalsString(t *testing.T) {
+	tot := Totals{Repos: 40, Archived: 3, ArchivedDirect: 2, ArchivedPct: 7.5, ArchivedDirectPct: 5}
+	if got, want := tot.String(), "Unique repos: 40 checked, 3 archived (7.5%), 2 archived direct (5.0%)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	tot.Unknown = 1
+	if got := tot.String(); !strings.HasSuffix(got, ", 1 unknown") {
+		t.Errorf("String() = %q, want the unknown count", got)
+	}
+}
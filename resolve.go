@@ -4,8 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"os"
+	"os/exec"
 	"regexp"
 	"strings"
 	"sync"
@@ -14,10 +15,31 @@ import (
 	"golang.org/x/mod/module"
 )
 
-// resolver holds HTTP client and configurable URLs for resolving vanity imports.
+// resolver is the shared fetch layer for the Go module proxy and vanity
+// import hosts. One resolver is created per run and passed to every
+// subsystem, so responses are cached and deduplicated across resolve,
+// enrichment, deprecation, and upgrade analysis (see get in fetch.go).
 type resolver struct {
 	client       *http.Client
-	proxyBaseURL string // "https://proxy.golang.org" in production
+	proxyBaseURL string // "https://proxy.golang.org" unless --endpoints-from overrides it
+
+	slots chan struct{}         // global request limit; nil means unlimited
+	limit *rateLimiter          // proxy requests per second; nil means unpaced
+	mu    sync.Mutex            // guards calls and cached
+	calls map[string]*fetchCall // URL → in-flight or completed request
+
+	cached int // bytes of response bodies held in calls
+	budget int // cap on cached; 0 means unbounded
+
+	hostSlots int                   // concurrent requests per vanity host; 0 means unlimited
+	hostRPS   float64               // requests per second per vanity host; 0 means unpaced
+	hosts     map[string]*hostLimit // host → its limits, guarded by mu
+
+	insecure string   // GOINSECURE patterns whose vanity pages may fall back to http
+	warned   sync.Map // host → struct{}: insecure fetch or hint already reported
+
+	preflight bool     // probe each vanity host once before fetching (--preflight-hosts)
+	probes    sync.Map // host → *hostProbe
 }
 
 // proxyInfo represents the JSON response from proxy.golang.org/{module}/@latest.
@@ -36,22 +58,40 @@ var metaRe = regexp.MustCompile(`(?i)<meta\s+([^>]*)>`)
 // attrRe extracts name="..." and content="..." from a meta tag's attributes.
 var attrRe = regexp.MustCompile(`(?i)(name|content)\s*=\s*"([^"]*)"`)
 
-// newResolver creates a resolver with production defaults.
+// newResolver creates a resolver for the endpoints in effect.
 func newResolver() *resolver {
 	return &resolver{
-		client:       &http.Client{Timeout: 10 * time.Second},
-		proxyBaseURL: "https://proxy.golang.org",
+		client:       &http.Client{Timeout: 10 * time.Second, Transport: countingTransport{}},
+		proxyBaseURL: endpoints.GoProxy,
+		slots:        make(chan struct{}, defaultFetchConcurrency),
+		limit:        newRateLimiter(proxyRPS),
+		budget:       defaultCacheBudget,
+		hostSlots:    hostConcurrency,
+		hostRPS:      hostRPS,
+		insecure:     insecureHosts,
+		preflight:    preflightHosts,
 	}
 }
 
-// ResolveVanityImports resolves non-GitHub modules to GitHub repos.
-// It updates Owner/Repo in-place on each Module. Returns the count resolved.
-func ResolveVanityImports(modules []Module, maxWorkers int) int {
-	return resolveVanityImportsWithResolver(modules, maxWorkers, newResolver())
+// insecureHosts holds the GOINSECURE patterns newResolver lets vanity
+// import pages fall back to plain http for; set by --allow-insecure-hosts.
+var insecureHosts string
+
+// goInsecure returns the GOINSECURE patterns from the environment, or
+// from `go env` when the variable is only set in the Go env file.
+func goInsecure() string {
+	if v := os.Getenv("GOINSECURE"); v != "" {
+		return v
+	}
+	out, err := exec.Command("go", "env", "GOINSECURE").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
 }
 
-// resolveVanityImportsWithResolver is the internal implementation that accepts
-// a resolver, allowing tests to inject mock HTTP servers.
+// resolveVanityImportsWithResolver resolves non-GitHub modules to GitHub repos.
+// It updates Owner/Repo in-place on each Module. Returns the count resolved.
 func resolveVanityImportsWithResolver(modules []Module, maxWorkers int, r *resolver) int {
 	// Collect indices of non-GitHub modules.
 	var indices []int
@@ -64,37 +104,19 @@ func resolveVanityImportsWithResolver(modules []Module, maxWorkers int, r *resol
 		return 0
 	}
 
-	// Bounded worker pool.
-	type result struct {
-		idx         int
-		owner, repo string
-	}
-	results := make(chan result, len(indices))
-
-	sem := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
-
-	for _, idx := range indices {
-		wg.Add(1)
-		go func(i int) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			owner, repo := r.resolveOne(modules[i].Path)
-			if owner != "" {
-				results <- result{idx: i, owner: owner, repo: repo}
-			}
-		}(idx)
-	}
-
-	wg.Wait()
-	close(results)
+	type result struct{ owner, repo string }
+	results, _ := mapPool(context.Background(), indices, poolOptions{Workers: maxWorkers}, func(_ context.Context, i int) (result, error) {
+		owner, repo := r.resolveOne(modules[i].Path)
+		return result{owner, repo}, nil
+	})
 
 	resolved := 0
-	for res := range results {
-		modules[res.idx].Owner = res.owner
-		modules[res.idx].Repo = res.repo
+	for j, res := range results {
+		if res.owner == "" {
+			continue
+		}
+		modules[indices[j]].Owner = res.owner
+		modules[indices[j]].Repo = res.repo
 		resolved++
 	}
 	return resolved
@@ -116,27 +138,8 @@ func (r *resolver) resolveViaProxy(modulePath string) (owner, repo string) {
 		return "", ""
 	}
 
-	url := fmt.Sprintf("%s/%s/@latest", r.proxyBaseURL, escaped)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", ""
-	}
-
-	resp, err := r.client.Do(req)
-	if err != nil {
-		return "", ""
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != 200 {
-		return "", ""
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	body, ok := r.get(fmt.Sprintf("%s/%s/@latest", r.proxyBaseURL, escaped))
+	if !ok {
 		return "", ""
 	}
 
@@ -152,29 +155,19 @@ func (r *resolver) resolveViaProxy(modulePath string) (owner, repo string) {
 }
 
 // resolveViaMeta fetches the module's vanity import page (?go-get=1)
-// and parses go-import/go-source meta tags for GitHub URLs.
+// and parses go-import/go-source meta tags for GitHub URLs. Like the go
+// command, it falls back to http for hosts matching GOINSECURE, but only
+// when --allow-insecure-hosts opted in. With --preflight-hosts, modules on
+// a host that failed its reachability probe are skipped.
 func (r *resolver) resolveViaMeta(modulePath string) (owner, repo string) {
-	url := "https://" + modulePath + "?go-get=1"
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", ""
-	}
-
-	resp, err := r.client.Do(req)
-	if err != nil {
+	if !r.hostReachable(modulePath) {
 		return "", ""
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != 200 {
-		return "", ""
+	body, ok := r.get("https://" + modulePath + "?go-get=1")
+	if !ok {
+		body, ok = r.getInsecure(modulePath)
 	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	if !ok {
 		return "", ""
 	}
 
@@ -203,6 +196,28 @@ func (r *resolver) resolveViaMeta(modulePath string) (owner, repo string) {
 	return "", ""
 }
 
+// getInsecure retries a failed vanity page fetch over http when the module
+// matches the resolver's GOINSECURE patterns, warning once per host. For a
+// GOINSECURE host without --allow-insecure-hosts it only prints a hint.
+func (r *resolver) getInsecure(modulePath string) ([]byte, bool) {
+	host, _, _ := strings.Cut(modulePath, "/")
+	if r.insecure == "" {
+		if patterns := os.Getenv("GOINSECURE"); patterns != "" && module.MatchPrefixPatterns(patterns, modulePath) {
+			if _, dup := r.warned.LoadOrStore(host, struct{}{}); !dup {
+				_, _ = fmt.Fprintf(os.Stderr, "Note: %s matches GOINSECURE but https failed; pass --allow-insecure-hosts to try http.\n", host)
+			}
+		}
+		return nil, false
+	}
+	if !module.MatchPrefixPatterns(r.insecure, modulePath) {
+		return nil, false
+	}
+	if _, dup := r.warned.LoadOrStore(host, struct{}{}); !dup {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: fetching vanity import pages from %s over insecure http (GOINSECURE).\n", host)
+	}
+	return r.get("http://" + modulePath + "?go-get=1")
+}
+
 // extractGitHubFromURL parses a URL for github.com/owner/repo.
 // Handles https://github.com/owner/repo, .git suffix, no scheme, etc.
 func extractGitHubFromURL(rawURL string) (owner, repo string) {
@@ -260,15 +275,9 @@ func parseMetaTags(body string) (goImport, goSource string) {
 	return goImport, goSource
 }
 
-// resolveAcrossModules resolves non-GitHub modules across multiple
+// resolveAcrossModulesWithResolver resolves non-GitHub modules across multiple
 // moduleInfo entries (for --recursive), deduplicating by module path.
 // It updates Owner/Repo in-place on each Module. Returns the total count resolved.
-func resolveAcrossModules(modules []moduleInfo) int {
-	return resolveAcrossModulesWithResolver(modules, newResolver())
-}
-
-// resolveAcrossModulesWithResolver is the internal implementation that accepts
-// a resolver, allowing tests to inject mock HTTP servers.
 func resolveAcrossModulesWithResolver(modules []moduleInfo, r *resolver) int {
 	// Collect unique non-GitHub module paths and their locations.
 	type location struct {
@@ -297,36 +306,19 @@ func resolveAcrossModulesWithResolver(modules []moduleInfo, r *resolver) int {
 	}
 
 	// Resolve concurrently with bounded workers.
-	type result struct {
-		path        string
-		owner, repo string
-	}
-	results := make(chan result, len(uniquePaths))
-
+	type result struct{ owner, repo string }
 	const maxWorkers = 20
-	sem := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
-
-	for _, path := range uniquePaths {
-		wg.Add(1)
-		go func(p string) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			owner, repo := r.resolveOne(p)
-			if owner != "" {
-				results <- result{path: p, owner: owner, repo: repo}
-			}
-		}(path)
-	}
-
-	wg.Wait()
-	close(results)
+	results, _ := mapPool(context.Background(), uniquePaths, poolOptions{Workers: maxWorkers}, func(_ context.Context, p string) (result, error) {
+		owner, repo := r.resolveOne(p)
+		return result{owner, repo}, nil
+	})
 
 	resolved := 0
-	for res := range results {
-		for _, loc := range pathLocations[res.path] {
+	for j, res := range results {
+		if res.owner == "" {
+			continue
+		}
+		for _, loc := range pathLocations[uniquePaths[j]] {
 			modules[loc.miIdx].allModules[loc.modIdx].Owner = res.owner
 			modules[loc.miIdx].allModules[loc.modIdx].Repo = res.repo
 		}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -253,6 +256,43 @@ func splitAllFields(s string) []string {
 	return fields
 }
 
+func TestResolveViaMeta_InsecureFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><head><meta name="go-import" content="internal.example/lib git https://github.com/acme/lib"></head></html>`)
+	}))
+	defer srv.Close()
+
+	// The test server speaks plain http, so the https attempt fails and
+	// only the GOINSECURE fallback can reach it.
+	host := strings.TrimPrefix(srv.URL, "http://")
+	modulePath := host + "/lib"
+	t.Setenv("GOINSECURE", "")
+
+	tests := []struct {
+		name      string
+		insecure  string
+		wantOwner string
+	}{
+		{name: "host matches pattern", insecure: host, wantOwner: "acme"},
+		{name: "pattern list with match", insecure: "corp.example," + host, wantOwner: "acme"},
+		{name: "not opted in", insecure: ""},
+		{name: "host does not match", insecure: "corp.example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &resolver{client: srv.Client(), insecure: tt.insecure}
+			owner, repo := r.resolveViaMeta(modulePath)
+			if owner != tt.wantOwner {
+				t.Errorf("owner = %q, want %q", owner, tt.wantOwner)
+			}
+			if tt.wantOwner != "" && repo != "lib" {
+				t.Errorf("repo = %q, want %q", repo, "lib")
+			}
+		})
+	}
+}
+
 func TestResolveOne(t *testing.T) {
 	t.Run("proxy hit", func(t *testing.T) {
 		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -477,3 +517,53 @@ func TestResolveAcrossModules_WorkerPool_Empty(t *testing.T) {
 		t.Errorf("resolved = %d, want 0 when no non-GitHub modules", resolved)
 	}
 }
+
+func TestResolveViaMeta_PreflightSkipsDeadHost(t *testing.T) {
+	// A port nothing listens on: connections are refused immediately.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := ln.Addr().String()
+	_ = ln.Close()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(404)
+	}))
+	defer srv.Close()
+	live := strings.TrimPrefix(srv.URL, "http://")
+
+	// The test server speaks plain http, reached through the GOINSECURE fallback.
+	t.Setenv("GOINSECURE", "")
+	r := &resolver{client: srv.Client(), preflight: true, insecure: live}
+	for _, mod := range []string{"/a", "/b", "/c"} {
+		if owner, _ := r.resolveViaMeta(dead + mod); owner != "" {
+			t.Errorf("resolveViaMeta(%s) owner = %q, want empty", dead+mod, owner)
+		}
+	}
+	v, ok := r.probes.Load(dead)
+	if !ok || v.(*hostProbe).err == nil {
+		t.Errorf("dead host probe = %v, want a recorded failure", v)
+	}
+
+	// A reachable host is probed, then fetched as usual.
+	r.resolveViaMeta(live + "/a")
+	if v, ok := r.probes.Load(live); !ok || v.(*hostProbe).err != nil {
+		t.Errorf("live host probe = %v, want success", v)
+	}
+	if hits.Load() == 0 {
+		t.Error("live host got no request after a successful probe")
+	}
+}
+
+func TestHostReachable_Disabled(t *testing.T) {
+	r := &resolver{}
+	if !r.hostReachable("nonexistent.invalid/mod") {
+		t.Error("hostReachable without preflight = false, want true")
+	}
+	if _, ok := r.probes.Load("nonexistent.invalid"); ok {
+		t.Error("host probed without preflight")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// retiredMarker labels archived modules whose author retracted every
+// published version: the module is not just unmaintained but formally
+// withdrawn, so no version is safe to pin.
+const retiredMarker = "[FORMALLY RETIRED]"
+
+// fetchRetirement reports whether the latest go.mod of modulePath retracts
+// every published version, and the retraction rationale for the latest one.
+func (r *resolver) fetchRetirement(modulePath string) (retired bool, rationale string) {
+	escaped, err := module.EscapePath(modulePath)
+	if err != nil {
+		return false, ""
+	}
+	var versions []string
+	if body, ok := r.get(fmt.Sprintf("%s/%s/@v/list", r.proxyBaseURL, escaped)); ok {
+		for _, v := range strings.Fields(string(body)) {
+			if semver.IsValid(v) {
+				versions = append(versions, v)
+			}
+		}
+	}
+	if len(versions) == 0 {
+		latest, _, _ := r.fetchLatestInfo(modulePath)
+		if latest == "" {
+			return false, ""
+		}
+		versions = []string{latest}
+	}
+	semver.Sort(versions)
+	latest := versions[len(versions)-1]
+
+	body := r.fetchGoMod(modulePath, latest)
+	if body == "" {
+		return false, ""
+	}
+	f, err := modfile.ParseLax("go.mod", []byte(body), nil)
+	if err != nil {
+		return false, ""
+	}
+	return allRetracted(versions, f.Retract)
+}
+
+// allRetracted reports whether every version falls in a retract interval,
+// with the rationale of the interval covering the last (newest) version.
+func allRetracted(versions []string, retracts []*modfile.Retract) (bool, string) {
+	if len(versions) == 0 || len(retracts) == 0 {
+		return false, ""
+	}
+	var rationale string
+	for _, v := range versions {
+		covered := false
+		for _, rt := range retracts {
+			if semver.Compare(rt.Low, v) <= 0 && semver.Compare(v, rt.High) <= 0 {
+				covered = true
+				rationale = rt.Rationale
+				break
+			}
+		}
+		if !covered {
+			return false, ""
+		}
+	}
+	return true, rationale
+}
+
+// detectRetiredWithResolver marks the archived results whose every version
+// is retracted. Returns the count found.
+func detectRetiredWithResolver(results []RepoStatus, maxWorkers int, r *resolver) int {
+	var idx []int
+	for i, rs := range results {
+		if rs.IsArchived {
+			idx = append(idx, i)
+		}
+	}
+	type retirement struct {
+		retired   bool
+		rationale string
+	}
+	found, _ := mapPool(context.Background(), idx, poolOptions{Workers: maxWorkers}, func(_ context.Context, i int) (retirement, error) {
+		retired, rationale := r.fetchRetirement(results[i].Module.Path)
+		return retirement{retired, rationale}, nil
+	})
+
+	count := 0
+	for j, i := range idx {
+		if found[j].retired {
+			results[i].Retired = true
+			results[i].RetiredRationale = found[j].rationale
+			count++
+		}
+	}
+	return count
+}
+
+// retiredResults returns the formally retired results.
+func retiredResults(results []RepoStatus) []RepoStatus {
+	var retired []RepoStatus
+	for _, r := range sortedArchived(results) {
+		if r.Retired {
+			retired = append(retired, r)
+		}
+	}
+	return retired
+}
+
+var retiredHeaders = []string{"Module", "Version", "Direct", "Retraction Rationale"}
+
+// retiredRows formats formally retired modules as table rows.
+func retiredRows(retired []RepoStatus) [][]string {
+	rows := make([][]string, len(retired))
+	for i, r := range retired {
+		rationale := r.RetiredRationale
+		if rationale == "" {
+			rationale = "-"
+		}
+		rows[i] = []string{r.Module.Path, r.Module.Version, directLabel(r.Module), rationale}
+	}
+	return rows
+}
+
+// PrintRetiredTable outputs archived modules whose every version is
+// retracted, with the suggestion that fits them: replace, don't pin.
+func PrintRetiredTable(results []RepoStatus) {
+	retired := retiredResults(results)
+	if len(retired) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nFORMALLY RETIRED (%d): every published version is retracted; replace these rather than pinning an older version\n\n", len(retired))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(retiredHeaders))
+	for _, row := range retiredRows(retired) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownRetired outputs formally retired modules in Markdown format.
+func PrintMarkdownRetired(results []RepoStatus) {
+	retired := retiredResults(results)
+	if len(retired) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## FORMALLY RETIRED (%d)\n\nEvery published version is retracted; replace these rather than pinning an older version.\n\n", len(retired))
+	printMarkdownTable(os.Stdout, retiredHeaders, retiredRows(retired))
+}
+
+// reportRetired prints the formally retired count to stderr, if any.
+func reportRetired(count int) {
+	if count > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Found %d formally retired %s.\n", count, pluralize(count, "module", "modules"))
+	}
+}
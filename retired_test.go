@@ -0,0 +1,145 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+)
+
+func TestAllRetracted(t *testing.T) {
+	tests := []struct {
+		name          string
+		versions      []string
+		gomod         string
+		wantRetired   bool
+		wantRationale string
+	}{
+		{
+			name:     "no retractions",
+			versions: []string{"v1.0.0", "v1.1.0"},
+			gomod:    "module example.com/m\n",
+		},
+		{
+			name:     "single version retracted",
+			versions: []string{"v1.0.0", "v1.1.0"},
+			gomod:    "module example.com/m\n\nretract v1.0.0\n",
+		},
+		{
+			name:          "range covers all",
+			versions:      []string{"v1.0.0", "v1.1.0", "v1.2.0"},
+			gomod:         "module example.com/m\n\n// Project is dead; use example.com/n.\nretract [v0.0.0, v1.2.0]\n",
+			wantRetired:   true,
+			wantRationale: "Project is dead; use example.com/n.",
+		},
+		{
+			name:          "multiple retracts together cover all",
+			versions:      []string{"v1.0.0", "v1.1.0"},
+			gomod:         "module example.com/m\n\nretract v1.0.0 // broken\nretract v1.1.0 // abandoned\n",
+			wantRetired:   true,
+			wantRationale: "abandoned",
+		},
+		{
+			name:     "gap in coverage",
+			versions: []string{"v1.0.0", "v1.1.0", "v1.2.0"},
+			gomod:    "module example.com/m\n\nretract v1.0.0\nretract v1.2.0\n",
+		},
+		{
+			name:  "no versions",
+			gomod: "module example.com/m\n\nretract v1.0.0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := modfile.ParseLax("go.mod", []byte(tt.gomod), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			retired, rationale := allRetracted(tt.versions, f.Retract)
+			if retired != tt.wantRetired || rationale != tt.wantRationale {
+				t.Errorf("allRetracted() = (%v, %q), want (%v, %q)", retired, rationale, tt.wantRetired, tt.wantRationale)
+			}
+		})
+	}
+}
+
+func TestDetectRetiredWithResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/dead/lib/@v/list":
+			_, _ = w.Write([]byte("v1.0.0\nv1.1.0\n"))
+		case "/github.com/dead/lib/@v/v1.1.0.mod":
+			_, _ = w.Write([]byte("module github.com/dead/lib\n\nretract [v1.0.0, v1.1.0] // do not use\n"))
+		case "/github.com/old/lib/@v/list":
+			_, _ = w.Write([]byte("v0.1.0\nv0.2.0\n"))
+		case "/github.com/old/lib/@v/v0.2.0.mod":
+			_, _ = w.Write([]byte("module github.com/old/lib\n\nretract v0.1.0\n"))
+		case "/github.com/nolist/lib/@v/list":
+			_, _ = w.Write(nil)
+		case "/github.com/nolist/lib/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v2.0.0"}`))
+		case "/github.com/nolist/lib/@v/v2.0.0.mod":
+			_, _ = w.Write([]byte("module github.com/nolist/lib\n\nretract v2.0.0 // gone\n"))
+		default:
+			if strings.Contains(r.URL.Path, "/active/") {
+				t.Errorf("unexpected fetch for non-archived module: %s", r.URL.Path)
+			}
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/dead/lib"}, IsArchived: true},
+		{Module: Module{Path: "github.com/old/lib"}, IsArchived: true},
+		{Module: Module{Path: "github.com/nolist/lib"}, IsArchived: true},
+		{Module: Module{Path: "github.com/active/lib"}},
+	}
+
+	if got := detectRetiredWithResolver(results, 4, r); got != 2 {
+		t.Fatalf("detectRetiredWithResolver() = %d, want 2", got)
+	}
+	if !results[0].Retired || results[0].RetiredRationale != "do not use" {
+		t.Errorf("dead/lib: Retired=%v rationale=%q", results[0].Retired, results[0].RetiredRationale)
+	}
+	if results[1].Retired {
+		t.Error("old/lib should not be retired: v0.2.0 is not retracted")
+	}
+	if !results[2].Retired || results[2].RetiredRationale != "gone" {
+		t.Errorf("nolist/lib: Retired=%v rationale=%q", results[2].Retired, results[2].RetiredRationale)
+	}
+	if results[3].Retired {
+		t.Error("active/lib should not be checked")
+	}
+}
+
+func TestArchivedRow_Retired(t *testing.T) {
+	cfg := NewDefaultConfig()
+	r := RepoStatus{Module: Module{Path: "github.com/dead/lib", Version: "v1.1.0"}, IsArchived: true, Retired: true}
+	row := archivedRow(cfg, r)
+	if want := "github.com/dead/lib " + retiredMarker; row[0] != want {
+		t.Errorf("module cell = %q, want %q", row[0], want)
+	}
+}
+
+func TestRetiredRows(t *testing.T) {
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/b/lib", Version: "v1.0.0", Direct: true}, IsArchived: true, Retired: true},
+		{Module: Module{Path: "github.com/a/lib", Version: "v2.0.0"}, IsArchived: true, Retired: true, RetiredRationale: "moved"},
+		{Module: Module{Path: "github.com/c/lib", Version: "v1.0.0"}, IsArchived: true},
+	}
+	rows := retiredRows(retiredResults(results))
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if rows[0][0] != "github.com/a/lib" || rows[0][3] != "moved" {
+		t.Errorf("row 0 = %v", rows[0])
+	}
+	if rows[1][0] != "github.com/b/lib" || rows[1][3] != "-" {
+		t.Errorf("row 1 = %v", rows[1])
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// loadModGraph returns the module graph for the go.mod at gomodPath. With
+// --selected-only it is pruned to the versions MVS selects, so trees and
+// dependency chains describe the real build.
+func loadModGraph(cfg *Config, gomodPath string) (map[string][]string, error) {
+	dir := filepath.Dir(gomodPath)
+	graph, err := parseModGraph(dir, cfg.GoVersion)
+	if err != nil || !cfg.SelectedOnly {
+		return graph, err
+	}
+	selected, err := listSelected(dir, cfg.GoVersion)
+	if err != nil {
+		return nil, err
+	}
+	return pruneToSelected(graph, selected), nil
+}
+
+// listSelected runs `go list -m all` in dir and returns the build list as
+// module path → selected version. The main module maps to "".
+func listSelected(dir string, goVersion string) (map[string]string, error) {
+	cmd := exec.CommandContext(runCtx, "go", "list", "-m", "all")
+	cmd.Dir = dir
+	if goVersion != "" {
+		cmd.Env = append(os.Environ(), "GOTOOLCHAIN=go"+goVersion)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseBuildList(string(out)), nil
+}
+
+// parseBuildList parses `go list -m all` output: "path [version] [=> replacement]".
+func parseBuildList(out string) map[string]string {
+	selected := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case len(fields) == 1 || fields[1] == "=>":
+			selected[canonicalModulePath(fields[0])] = ""
+		default:
+			selected[canonicalModulePath(fields[0])] = fields[1]
+		}
+	}
+	return selected
+}
+
+// pruneToSelected drops the graph's nodes for versions MVS did not select
+// and points every edge at the selected version of its target, dropping
+// edges to modules outside the build list. `go mod graph` lists the
+// requirements of every version it visits, so without this an archived
+// module required only by a superseded version shows up in the tree.
+func pruneToSelected(graph map[string][]string, selected map[string]string) map[string][]string {
+	inBuild := func(node string) (string, bool) {
+		path, version, _ := strings.Cut(node, "@")
+		v, ok := selected[path]
+		if !ok {
+			return "", false
+		}
+		if version == "" || v == "" {
+			return path, true
+		}
+		return path + "@" + v, true
+	}
+
+	pruned := make(map[string][]string, len(graph))
+	for parent, children := range graph {
+		if node, ok := inBuild(parent); !ok || node != parent {
+			continue
+		}
+		seen := make(map[string]bool, len(children))
+		for _, child := range children {
+			node, ok := inBuild(child)
+			if !ok || node == parent || seen[node] {
+				continue
+			}
+			seen[node] = true
+			pruned[parent] = append(pruned[parent], node)
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBuildList(t *testing.T) {
+	out := `example.com/app
+github.com/foo/bar v1.2.0
+github.com/!burnt!sushi/toml v1.3.2
+github.com/old/lib v0.1.0 => github.com/new/lib v0.2.0
+example.com/local => ../local
+`
+	want := map[string]string{
+		"example.com/app":            "",
+		"github.com/foo/bar":         "v1.2.0",
+		"github.com/BurntSushi/toml": "v1.3.2",
+		"github.com/old/lib":         "v0.1.0",
+		"example.com/local":          "",
+	}
+	if got := parseBuildList(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBuildList() = %v, want %v", got, want)
+	}
+}
+
+func TestPruneToSelected(t *testing.T) {
+	graph := map[string][]string{
+		"example.com/app": {"github.com/a/a@v1.1.0", "github.com/b/b@v1.0.0"},
+		// a@v1.0.0 is superseded: its archived requirement is not in the build.
+		"github.com/a/a@v1.0.0": {"github.com/dead/x@v0.1.0"},
+		"github.com/a/a@v1.1.0": {"github.com/c/c@v1.0.0"},
+		// b requires an older c than the one selected.
+		"github.com/b/b@v1.0.0": {"github.com/c/c@v0.9.0", "github.com/c/c@v1.0.0"},
+	}
+	selected := map[string]string{
+		"example.com/app": "",
+		"github.com/a/a":  "v1.1.0",
+		"github.com/b/b":  "v1.0.0",
+		"github.com/c/c":  "v1.0.0",
+	}
+	want := map[string][]string{
+		"example.com/app":       {"github.com/a/a@v1.1.0", "github.com/b/b@v1.0.0"},
+		"github.com/a/a@v1.1.0": {"github.com/c/c@v1.0.0"},
+		"github.com/b/b@v1.0.0": {"github.com/c/c@v1.0.0"},
+	}
+	if got := pruneToSelected(graph, selected); !reflect.DeepEqual(got, want) {
+		t.Errorf("pruneToSelected() = %v, want %v", got, want)
+	}
+}
+
+func TestPruneToSelected_DropsArchivedFromTree(t *testing.T) {
+	graph := map[string][]string{
+		"example.com/app":       {"github.com/a/a@v1.1.0"},
+		"github.com/a/a@v1.1.0": {"github.com/a/a@v1.0.0"},
+		"github.com/a/a@v1.0.0": {"github.com/dead/x@v0.1.0"},
+	}
+	selected := map[string]string{"example.com/app": "", "github.com/a/a": "v1.1.0"}
+	results := []RepoStatus{{Module: Module{Path: "github.com/dead/x", Owner: "dead", Repo: "x"}, IsArchived: true}}
+	allModules := []Module{
+		{Path: "github.com/a/a", Version: "v1.1.0", Direct: true, Owner: "a", Repo: "a"},
+		{Path: "github.com/dead/x", Version: "v0.1.0", Owner: "dead", Repo: "x"},
+	}
+
+	if entries, _ := buildTree(results, graph, allModules); len(entries) != 1 {
+		t.Fatalf("full graph: expected github.com/a/a to pull in the archived module, got %+v", entries)
+	}
+	pruned := pruneToSelected(graph, selected)
+	if len(pruned["github.com/a/a@v1.1.0"]) != 0 {
+		t.Errorf("expected the edge to the superseded version to be dropped, got %v", pruned["github.com/a/a@v1.1.0"])
+	}
+	if entries, _ := buildTree(results, pruned, allModules); len(entries) != 0 {
+		t.Errorf("selected graph: expected no tree entries, got %+v", entries)
+	}
+}
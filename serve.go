@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// wellKnownPath is where serve mode publishes the latest scan summary.
+const wellKnownPath = "/.well-known/modrot.json"
+
+// wellKnownSchemaVersion is the version of the wellKnownReport contract.
+// Increment it on any incompatible change; additive fields keep the version.
+const wellKnownSchemaVersion = 1
+
+// wellKnownReport is the schema-versioned scan summary served at
+// /.well-known/modrot.json for org-wide crawlers.
+type wellKnownReport struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Generator     string              `json:"generator"`
+	Status        string              `json:"status"` // "pending", "ok", "archived", "error"
+	Module        string              `json:"module,omitempty"`
+	ScannedAt     string              `json:"scanned_at,omitempty"`
+	Counts        *wellKnownCounts    `json:"counts,omitempty"`
+	Archived      []wellKnownArchived `json:"archived,omitempty"`
+	Error         string              `json:"error,omitempty"`
+}
+
+// wellKnownCounts summarizes a scan.
+type wellKnownCounts struct {
+	ModulesChecked int `json:"modules_checked"`
+	Archived       int `json:"archived"`
+	ArchivedDirect int `json:"archived_direct"`
+	NotFound       int `json:"not_found"`
+	Unknown        int `json:"unknown"`
+}
+
+// wellKnownArchived is one archived dependency in the well-known report.
+type wellKnownArchived struct {
+	FindingID           string `json:"finding_id"`
+	Module              string `json:"module"`
+	Version             string `json:"version"`
+	Direct              bool   `json:"direct"`
+	ArchivedAt          string `json:"archived_at,omitempty"`
+	ArchivedAtSource    string `json:"archived_at_source,omitempty"`
+	ArchivedAtPrecision string `json:"archived_at_precision,omitempty"`
+}
+
+// buildWellKnownReport summarizes scan results for the well-known endpoint.
+func buildWellKnownReport(modName string, results []RepoStatus, scannedAt time.Time) wellKnownReport {
+	rep := wellKnownReport{
+		SchemaVersion: wellKnownSchemaVersion,
+		Generator:     "modrot " + version,
+		Status:        "ok",
+		Module:        modName,
+		ScannedAt:     scannedAt.UTC().Format(time.RFC3339),
+		Counts:        &wellKnownCounts{ModulesChecked: len(results)},
+	}
+	for _, r := range results {
+		if r.NotFound {
+			rep.Counts.NotFound++
+		}
+		if r.Unknown {
+			rep.Counts.Unknown++
+		}
+		if !r.IsArchived {
+			continue
+		}
+		rep.Counts.Archived++
+		if r.Module.Direct {
+			rep.Counts.ArchivedDirect++
+		}
+		a := wellKnownArchived{
+			FindingID: findingID(findingArchived, r.Module.Path),
+			Module:    r.Module.Path,
+			Version:   r.Module.Version,
+			Direct:    r.Module.Direct,
+		}
+		if !r.ArchivedAt.IsZero() {
+			a.ArchivedAt = r.ArchivedAt.UTC().Format(time.RFC3339)
+		}
+		a.ArchivedAtSource, a.ArchivedAtPrecision = archivedAtProvenance(r)
+		rep.Archived = append(rep.Archived, a)
+	}
+	sort.Slice(rep.Archived, func(i, j int) bool {
+		return rep.Archived[i].Module < rep.Archived[j].Module
+	})
+	if rep.Counts.Archived > 0 {
+		rep.Status = "archived"
+	}
+	return rep
+}
+
+// serveState holds the latest report served by serve mode.
+type serveState struct {
+	mu     sync.RWMutex
+	report wellKnownReport
+}
+
+// set replaces the served report.
+func (s *serveState) set(rep wellKnownReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report = rep
+}
+
+// ServeHTTP serves the latest report. Until the first scan finishes the
+// status is "pending" with 503, so crawlers can retry.
+func (s *serveState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.RLock()
+	rep := s.report
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if rep.Status == "pending" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(rep)
+}
+
+// runServe implements `modrot serve`: scans a module on an interval and
+// publishes the latest result at /.well-known/modrot.json.
+// Returns exit code: 0 = clean shutdown, 2 = error.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "Listen address")
+	interval := fs.Duration("interval", time.Hour, "Time between scans")
+	workers := fs.Int("workers", 50, "Number of repos per GitHub GraphQL batch request")
+	ignoreFile := fs.String("ignore-file", "", "Path to ignore file (default: .modrotignore next to go.mod)")
+	directOnly := fs.Bool("direct-only", false, "Only check direct dependencies")
+	remoteHosts := fs.String("remote-hosts", "", "Comma-separated hosts remote repository URLs may use")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot serve [flags] [path/to/go.mod | path/to/dir | https://host/owner/repo]
+
+Scan a module on an interval and serve the latest result as
+schema-versioned JSON at %s, for fleet-wide crawlers.
+A remote repository is cloned shallowly into a temporary workspace for each
+scan and removed afterwards.
+
+  --addr string         Listen address (default ":8080")
+  --interval duration   Time between scans (default 1h)
+  --workers int         Number of repos per GitHub GraphQL batch request (default 50)
+  --ignore-file string  Path to ignore file (default: .modrotignore next to go.mod)
+  --direct-only         Only check direct dependencies
+  --remote-hosts string Hosts remote repository URLs may use
+                          (default: github.com,gitlab.com,bitbucket.org,codeberg.org)
+`, wellKnownPath)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *interval <= 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --interval must be positive\n")
+		return 2
+	}
+
+	newConfig := func() *Config {
+		cfg := NewDefaultConfig()
+		cfg.IgnoreFile = *ignoreFile
+		cfg.DirectOnly = *directOnly
+		cfg.Workers = *workers
+		for _, h := range strings.Split(*remoteHosts, ",") {
+			if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+				cfg.Remote.Hosts = append(cfg.Remote.Hosts, h)
+			}
+		}
+		return cfg
+	}
+
+	inputPath := "."
+	if fs.NArg() > 0 {
+		inputPath = fs.Arg(0)
+	}
+	remote := isRemoteSpec(newConfig(), inputPath)
+	var gomodPath string
+	if remote {
+		if _, err := validateRemoteURL(newConfig(), inputPath); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		if n := cleanupStaleWorkspaces(os.TempDir(), staleWorkspaceAge, time.Now()); n > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "Removed %d stale remote %s.\n", n, pluralize(n, "workspace", "workspaces"))
+		}
+	} else {
+		absPath, err := filepath.Abs(inputPath)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		gomodPath = goModFile(absPath)
+		if _, err := ParseGoMod(gomodPath); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+	}
+
+	state := &serveState{report: wellKnownReport{
+		SchemaVersion: wellKnownSchemaVersion,
+		Generator:     "modrot " + version,
+		Status:        "pending",
+	}}
+	scan := func() {
+		cfg := newConfig()
+		var rep wellKnownReport
+		if remote {
+			dir, ws, err := fetchRemote(cfg, inputPath)
+			if err != nil {
+				rep = serveErrorReport("", err)
+			} else {
+				rep = serveScan(cfg, goModFile(dir))
+				ws.Cleanup()
+			}
+		} else {
+			rep = serveScan(cfg, gomodPath)
+		}
+		state.set(rep)
+		_, _ = fmt.Fprintf(os.Stderr, "Scan finished: %s.\n", rep.Status)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(wellKnownPath, state)
+	srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		scan()
+		ticker := time.NewTicker(*interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				scan()
+			}
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	target := inputPath
+	if !remote {
+		target = relToCwd(gomodPath)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Serving %s on %s (scanning %s every %s)\n", wellKnownPath, *addr, target, *interval)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// serveScan runs one archive scan of gomodPath for serve mode. Errors are
+// reported in the returned report rather than stopping the server.
+func serveScan(cfg *Config, gomodPath string) wellKnownReport {
+	modName, _ := ModuleName(gomodPath)
+	allModules, err := ParseGoMod(gomodPath)
+	if err != nil {
+		return serveErrorReport(modName, err)
+	}
+	githubModules, _ := FilterGitHub(allModules, cfg.DirectOnly)
+	var results []RepoStatus
+	if len(githubModules) > 0 {
+		results, err = CheckReposCached(cfg, githubModules)
+		if err != nil {
+			return serveErrorReport(modName, err)
+		}
+		results, _, _ = applyIgnoreList(cfg, results, gomodPath)
+		results, _ = splitVendoredForked(cfg, results, filepath.Dir(gomodPath))
+	}
+	return buildWellKnownReport(modName, results, time.Now())
+}
+
+// serveErrorReport is the report served when a scan fails.
+func serveErrorReport(modName string, err error) wellKnownReport {
+	return wellKnownReport{
+		SchemaVersion: wellKnownSchemaVersion,
+		Generator:     "modrot " + version,
+		Status:        "error",
+		Module:        modName,
+		ScannedAt:     time.Now().UTC().Format(time.RFC3339),
+		Error:         err.Error(),
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBuildWellKnownReport(t *testing.T) {
+	scannedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	archivedAt := time.Date(2021, 12, 1, 18, 32, 45, 0, time.UTC)
+	rep := buildWellKnownReport("example.com/app", []RepoStatus{
+		{Module: Module{Path: "github.com/z/old", Version: "v1.0.0"}, IsArchived: true},
+		{Module: Module{Path: "github.com/pkg/errors", Version: "v0.9.1", Direct: true}, IsArchived: true, ArchivedAt: archivedAt},
+		{Module: Module{Path: "github.com/a/fine", Version: "v1.2.0"}},
+		{Module: Module{Path: "github.com/a/gone", Version: "v0.1.0"}, NotFound: true},
+	}, scannedAt)
+
+	if rep.SchemaVersion != wellKnownSchemaVersion || rep.Status != "archived" || rep.Module != "example.com/app" {
+		t.Errorf("report header = %+v", rep)
+	}
+	if rep.ScannedAt != "2025-03-01T12:00:00Z" {
+		t.Errorf("ScannedAt = %q", rep.ScannedAt)
+	}
+	want := wellKnownCounts{ModulesChecked: 4, Archived: 2, ArchivedDirect: 1, NotFound: 1}
+	if *rep.Counts != want {
+		t.Errorf("Counts = %+v, want %+v", *rep.Counts, want)
+	}
+	if len(rep.Archived) != 2 || rep.Archived[0].Module != "github.com/pkg/errors" || rep.Archived[0].ArchivedAt != "2021-12-01T18:32:45Z" {
+		t.Errorf("Archived = %+v, want sorted with dates", rep.Archived)
+	}
+
+	if clean := buildWellKnownReport("example.com/app", nil, scannedAt); clean.Status != "ok" || clean.Counts.ModulesChecked != 0 {
+		t.Errorf("clean report = %+v", clean)
+	}
+}
+
+func TestServeState(t *testing.T) {
+	state := &serveState{report: wellKnownReport{SchemaVersion: wellKnownSchemaVersion, Status: "pending"}}
+	mux := http.NewServeMux()
+	mux.Handle(wellKnownPath, state)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	get := func() (int, wellKnownReport) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + wellKnownPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		var rep wellKnownReport
+		if err := json.NewDecoder(resp.Body).Decode(&rep); err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, rep
+	}
+
+	if code, rep := get(); code != http.StatusServiceUnavailable || rep.Status != "pending" {
+		t.Errorf("before first scan: %d %+v", code, rep)
+	}
+
+	state.set(buildWellKnownReport("example.com/app", nil, time.Now()))
+	if code, rep := get(); code != http.StatusOK || rep.Status != "ok" || rep.Module != "example.com/app" {
+		t.Errorf("after scan: %d %+v", code, rep)
+	}
+
+	resp, err := http.Post(srv.URL+wellKnownPath, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestServeScan_ParseError(t *testing.T) {
+	rep := serveScan(NewDefaultConfig(), "/nonexistent/go.mod")
+	if rep.Status != "error" || rep.Error == "" || rep.SchemaVersion != wellKnownSchemaVersion {
+		t.Errorf("serveScan(missing) = %+v, want error report", rep)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestKeys writes a fresh Ed25519 key pair as PEM files and returns
+// their paths.
+func writeTestKeys(t *testing.T, dir string) (privPath, pubPath string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPath = filepath.Join(dir, "key.pem")
+	pubPath = filepath.Join(dir, "key.pub.pem")
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return privPath, pubPath
+}
+
+func TestSignDetached_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	privPath, pubPath := writeTestKeys(t, dir)
+	priv, err := loadSigningKey(privPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := loadVerifyKey(pubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := []byte("{\n  \"archived\": []\n}\n")
+	jws := signDetached(priv, report)
+	if parts := strings.Split(jws, "."); len(parts) != 3 || parts[1] != "" {
+		t.Fatalf("signature %q is not a detached compact JWS", jws)
+	}
+	if err := verifyDetached(pub, jws, report); err != nil {
+		t.Errorf("verify unchanged report: %v", err)
+	}
+	if err := verifyDetached(pub, jws, []byte("{\n  \"archived\": [1]\n}\n")); err == nil {
+		t.Error("verify altered report succeeded, want failure")
+	}
+
+	// The signing key also works for verification.
+	fromPriv, err := loadVerifyKey(privPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyDetached(fromPriv, jws, report); err != nil {
+		t.Errorf("verify with private key file: %v", err)
+	}
+
+	// A different key is named in the error.
+	_, otherPub := writeTestKeys(t, t.TempDir())
+	other, err := loadVerifyKey(otherPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyDetached(other, jws, report); err == nil || !strings.Contains(err.Error(), "different key") {
+		t.Errorf("verify with other key = %v, want a different-key error", err)
+	}
+}
+
+func TestVerifyDetached_Malformed(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	pub := priv.Public().(ed25519.PublicKey)
+	for _, jws := range []string{
+		"",
+		"abc",
+		"a.b.c",                      // attached payload
+		"!!..AAAA",                   // bad header encoding
+		"eyJhbGciOiJIUzI1NiJ9..AAAA", // {"alg":"HS256"}
+	} {
+		if err := verifyDetached(pub, jws, []byte("{}")); err == nil {
+			t.Errorf("verifyDetached(%q) succeeded, want error", jws)
+		}
+	}
+}
+
+func TestLoadSigningKey_Errors(t *testing.T) {
+	dir := t.TempDir()
+	_, pubPath := writeTestKeys(t, dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "missing.pem"), notPEM, pubPath} {
+		if _, err := loadSigningKey(path); err == nil {
+			t.Errorf("loadSigningKey(%s) succeeded, want error", filepath.Base(path))
+		}
+	}
+}
+
+func TestRunVerifyReport(t *testing.T) {
+	dir := t.TempDir()
+	privPath, pubPath := writeTestKeys(t, dir)
+	priv, err := loadSigningKey(privPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := filepath.Join(dir, "report.json")
+	sig := filepath.Join(dir, "report.jws")
+	if err := os.WriteFile(report, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := &signer{key: priv, path: sig}
+	if err := s.sign([]byte("{}\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runVerifyReport([]string{"--key", pubPath, "--signature", sig, report}); code != 0 {
+		t.Errorf("valid report: exit %d, want 0", code)
+	}
+	if err := os.WriteFile(report, []byte("{\"tampered\":true}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code := runVerifyReport([]string{"--key", pubPath, "--signature", sig, report}); code != 1 {
+		t.Errorf("altered report: exit %d, want 1", code)
+	}
+	if code := runVerifyReport([]string{"--signature", sig, report}); code != 2 {
+		t.Errorf("missing --key: exit %d, want 2", code)
+	}
+}
@@ -14,10 +14,12 @@ func PrintStats(cfg *Config, results []RepoStatus, nonGHModules []Module, stale
 	}
 
 	// Count categories
-	var archived, active, notFound int
+	var archived, active, notFound, unknown int
 	var archivedDirect, archivedIndirect int
 	for _, r := range results {
 		switch {
+		case r.Unknown:
+			unknown++
 		case r.NotFound:
 			notFound++
 		case r.IsArchived:
@@ -63,6 +65,10 @@ func PrintStats(cfg *Config, results []RepoStatus, nonGHModules []Module, stale
 		_, _ = fmt.Fprintf(os.Stdout, "Not found:                 %d\n", notFound)
 	}
 
+	if unknown > 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "Unknown (not checked):     %d\n", unknown)
+	}
+
 	// Age distribution of archived modules
 	if archived > 0 {
 		printAgeDistribution(cfg, results)
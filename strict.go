@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// exitDegraded is the exit code used in --strict mode when a missing tool or
+// environment problem reduced the scope of the analysis.
+const exitDegraded = 3
+
+// Degradation records a tool-environment problem that silently reduced
+// functionality (e.g. rg missing, go mod graph failing).
+type Degradation = report.Problem
+
+// warnDegraded prints a warning for a degraded component and records it on cfg.
+// In --strict mode the message is reported as an error instead.
+func warnDegraded(cfg *Config, component, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	cfg.Degradations = append(cfg.Degradations, Degradation{Component: component, Message: msg})
+	prefix := "Warning"
+	if cfg.Strict {
+		prefix = "Error"
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "%s: %s\n", prefix, msg)
+}
+
+// strictErrors returns the recorded degradations when --strict is set, for
+// inclusion in structured output. Returns nil otherwise.
+func strictErrors(cfg *Config) []Degradation {
+	if !cfg.Strict {
+		return nil
+	}
+	return cfg.Degradations
+}
+
+// strictExitCode converts a run's exit code into exitDegraded when --strict is
+// set and any degradation was recorded. Hard errors (exit 2) are preserved.
+func strictExitCode(cfg *Config, code int) int {
+	if !cfg.Strict || len(cfg.Degradations) == 0 || code == 2 {
+		return code
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nSTRICT MODE: analysis degraded (%d %s)\n",
+		len(cfg.Degradations), pluralize(len(cfg.Degradations), "problem", "problems"))
+	for _, d := range cfg.Degradations {
+		_, _ = fmt.Fprintf(os.Stderr, "  [%s] %s\n", d.Component, d.Message)
+	}
+	return exitDegraded
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWarnDegraded_RecordsDegradation(t *testing.T) {
+	cfg := defaultTestConfig()
+	warnDegraded(cfg, "rg", "could not scan imports: %s", "not found")
+
+	if len(cfg.Degradations) != 1 {
+		t.Fatalf("len(Degradations) = %d, want 1", len(cfg.Degradations))
+	}
+	d := cfg.Degradations[0]
+	if d.Component != "rg" {
+		t.Errorf("Component = %q, want %q", d.Component, "rg")
+	}
+	if d.Message != "could not scan imports: not found" {
+		t.Errorf("Message = %q", d.Message)
+	}
+}
+
+func TestStrictExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		strict   bool
+		degraded bool
+		code     int
+		want     int
+	}{
+		{"not strict, degraded", false, true, 0, 0},
+		{"not strict, archived", false, true, 1, 1},
+		{"strict, clean run", true, false, 0, 0},
+		{"strict, clean run with archived", true, false, 1, 1},
+		{"strict, degraded", true, true, 0, exitDegraded},
+		{"strict, degraded with archived", true, true, 1, exitDegraded},
+		{"strict, hard error preserved", true, true, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultTestConfig()
+			cfg.Strict = tt.strict
+			if tt.degraded {
+				cfg.Degradations = []Degradation{{Component: "go mod graph", Message: "exit status 1"}}
+			}
+			if got := strictExitCode(cfg, tt.code); got != tt.want {
+				t.Errorf("strictExitCode(%d) = %d, want %d", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictErrors_JSON(t *testing.T) {
+	cfg := defaultTestConfig()
+	cfg.Degradations = []Degradation{{Component: "rg", Message: "rg not installed"}}
+
+	// Not strict: errors omitted
+	output := captureStdout(t, func() {
+		PrintJSON(cfg, nil, nil, nil, nil)
+	})
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &data); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := data["errors"]; ok {
+		t.Error("errors should be omitted when not in strict mode")
+	}
+
+	// Strict: errors included
+	cfg.Strict = true
+	output = captureStdout(t, func() {
+		PrintJSON(cfg, nil, nil, nil, nil)
+	})
+	var out JSONOutput
+	if err := json.Unmarshal([]byte(output), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].Component != "rg" {
+		t.Errorf("Errors = %+v, want one rg error", out.Errors)
+	}
+}
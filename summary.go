@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// runSummary collects each scanned project's final results (after ignore
+// lists and the vendored-forked split) for consumers that run once the
+// report is complete: --history, --pushgateway, and the summary badge.
+type runSummary struct {
+	projects   []string
+	results    [][]RepoStatus  // parallel to projects
+	deprecated map[string]bool // deprecated module paths across projects
+	phases     []phaseTiming   // pipeline phases in run order, for --pushgateway
+	scans      []projectScan   // what each project's query saw, for --history
+}
+
+// phaseTiming is the wall time one pipeline phase took.
+type phaseTiming struct {
+	Phase    string
+	Duration time.Duration
+}
+
+// add records the final results of one project. The project is identified
+// by its module path, falling back to fallback (the go.mod path) for a
+// go.mod without a module directive.
+func (s *runSummary) add(project, fallback string, results []RepoStatus) {
+	if project == "" {
+		project = fallback
+	}
+	s.projects = append(s.projects, project)
+	s.results = append(s.results, results)
+}
+
+// addDeprecated records a project's deprecated modules.
+func (s *runSummary) addDeprecated(modules []Module) {
+	for _, m := range modules {
+		if s.deprecated == nil {
+			s.deprecated = make(map[string]bool)
+		}
+		s.deprecated[m.Path] = true
+	}
+}
+
+// repoTotals are archive counts normalized to unique GitHub repositories.
+// Module-path counts overstate multi-module repos (github.com/foo/bar and
+// github.com/foo/bar/v2 are one repo), so the summary line, JSON meta, and
+// recursive totals report these alongside them.
+type repoTotals = report.Totals
+
+// computeRepoTotals counts unique repositories across results. A repository
+// counts as direct if any of its module paths is a direct dependency.
+func computeRepoTotals(results ...[]RepoStatus) repoTotals {
+	type repo struct{ archived, direct, unknown bool }
+	repos := make(map[string]*repo)
+	for _, rs := range results {
+		for _, r := range rs {
+			key := repoKey(r.Module)
+			st, ok := repos[key]
+			if !ok {
+				st = &repo{}
+				repos[key] = st
+			}
+			st.archived = st.archived || r.IsArchived
+			st.direct = st.direct || r.Module.Direct
+			st.unknown = st.unknown || r.Unknown
+		}
+	}
+
+	t := repoTotals{Repos: len(repos)}
+	for _, st := range repos {
+		if st.unknown {
+			t.Unknown++
+		}
+		if st.archived {
+			t.Archived++
+			if st.direct {
+				t.ArchivedDirect++
+			}
+		}
+	}
+	if t.Repos > 0 {
+		t.ArchivedPct = percent(t.Archived, t.Repos)
+		t.ArchivedDirectPct = percent(t.ArchivedDirect, t.Repos)
+	}
+	return t
+}
+
+// percent returns n as a percentage of total, rounded to one decimal.
+func percent(n, total int) float64 {
+	return math.Round(float64(n)*1000/float64(total)) / 10
+}
+
+// totals returns the unique-repo totals across every project in the run.
+func (s *runSummary) totals() repoTotals {
+	return computeRepoTotals(s.results...)
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestComputeRepoTotals(t *testing.T) {
+	mod := func(owner, repo, path string, direct, archived bool) RepoStatus {
+		return RepoStatus{Module: Module{Path: path, Owner: owner, Repo: repo, Direct: direct}, IsArchived: archived}
+	}
+	results := []RepoStatus{
+		// One multi-module repo, archived, used directly through one path.
+		mod("foo", "bar", "github.com/foo/bar", false, true),
+		mod("foo", "bar", "github.com/foo/bar/v2", true, true),
+		// Archived, indirect only.
+		mod("old", "lib", "github.com/old/lib", false, true),
+		// Active repos.
+		mod("a", "one", "github.com/a/one", true, false),
+		mod("A", "One", "github.com/A/One/sub", false, false), // same repo, different case
+	}
+
+	got := computeRepoTotals(results)
+	want := repoTotals{Repos: 3, Archived: 2, ArchivedDirect: 1, ArchivedPct: 66.7, ArchivedDirectPct: 33.3}
+	if got != want {
+		t.Errorf("computeRepoTotals = %+v, want %+v", got, want)
+	}
+	if s := got.String(); s != "Unique repos: 3 checked, 2 archived (66.7%), 1 archived direct (33.3%)" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestComputeRepoTotals_AcrossProjects(t *testing.T) {
+	a := []RepoStatus{{Module: Module{Owner: "foo", Repo: "bar"}, IsArchived: true}}
+	b := []RepoStatus{
+		{Module: Module{Owner: "foo", Repo: "bar", Direct: true}, IsArchived: true},
+		{Module: Module{Owner: "x", Repo: "y"}},
+	}
+	var s runSummary
+	s.add("a", "", a)
+	s.add("b", "", b)
+
+	got := s.totals()
+	want := repoTotals{Repos: 2, Archived: 1, ArchivedDirect: 1, ArchivedPct: 50, ArchivedDirectPct: 50}
+	if got != want {
+		t.Errorf("totals = %+v, want %+v", got, want)
+	}
+}
+
+func TestComputeRepoTotals_Empty(t *testing.T) {
+	if got := computeRepoTotals(nil); got != (repoTotals{}) {
+		t.Errorf("computeRepoTotals(nil) = %+v, want zero", got)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/mod/module"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// untaggedLabel names the breakdown row for modules no tag matches.
+const untaggedLabel = "(untagged)"
+
+// moduleTag assigns a criticality tag (critical, infrastructure, ui, ...)
+// to the modules matching Pattern, a GOPRIVATE-style glob prefix such as
+// github.com/hashicorp/vault or github.com/aws/*.
+type moduleTag struct {
+	Pattern string
+	Tag     string
+}
+
+// parseModuleTag parses a "pattern: tag[, tag...]" item of the tags
+// setting into one moduleTag per tag.
+func parseModuleTag(item string) ([]moduleTag, error) {
+	pattern, list, ok := strings.Cut(item, ":")
+	pattern = canonicalModulePath(unquoteYAML(pattern))
+	if !ok || pattern == "" {
+		return nil, fmt.Errorf("want \"- module/pattern: tag\", got %q", item)
+	}
+	var tags []moduleTag
+	for _, t := range strings.Split(unquoteYAML(list), ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			tags = append(tags, moduleTag{Pattern: pattern, Tag: t})
+		}
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tag given for %s", pattern)
+	}
+	return tags, nil
+}
+
+// tagNames returns the distinct tags in config order.
+func tagNames(tags []moduleTag) []string {
+	var names []string
+	for _, t := range tags {
+		if !slices.Contains(names, t.Tag) {
+			names = append(names, t.Tag)
+		}
+	}
+	return names
+}
+
+// applyTags sets Tags on every module a configured pattern matches.
+func applyTags(tags []moduleTag, modules []Module) {
+	if len(tags) == 0 {
+		return
+	}
+	for i := range modules {
+		m := &modules[i]
+		m.Tags = nil
+		for _, t := range tags {
+			if module.MatchPrefixPatterns(t.Pattern, m.Path) && !slices.Contains(m.Tags, t.Tag) {
+				m.Tags = append(m.Tags, t.Tag)
+			}
+		}
+	}
+}
+
+// tagBreakdown counts the findings among the modules carrying one tag.
+type tagBreakdown struct {
+	Tag        string
+	Modules    int
+	Archived   []string // archived module paths
+	Disabled   int
+	Stale      int
+	Deprecated int
+}
+
+// buildTagBreakdown groups the run's findings by criticality tag: one row
+// per configured tag, in config order, then one for untagged modules. A
+// module with several tags counts toward each. Returns nil when no tags
+// are configured.
+func buildTagBreakdown(cfg *Config, results, stale []RepoStatus, deprecated []Module) []tagBreakdown {
+	names := tagNames(cfg.Tags)
+	if len(names) == 0 {
+		return nil
+	}
+	rows := make([]tagBreakdown, len(names)+1)
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		rows[i].Tag = name
+		index[name] = i
+	}
+	rows[len(names)].Tag = untaggedLabel
+
+	each := func(m Module, f func(*tagBreakdown)) {
+		if len(m.Tags) == 0 {
+			f(&rows[len(names)])
+			return
+		}
+		for _, t := range m.Tags {
+			f(&rows[index[t]])
+		}
+	}
+	for _, r := range results {
+		each(r.Module, func(b *tagBreakdown) {
+			b.Modules++
+			switch {
+			case r.Disabled:
+				b.Disabled++
+			case r.IsArchived:
+				b.Archived = append(b.Archived, r.Module.Path)
+			}
+		})
+	}
+	for _, r := range stale {
+		each(r.Module, func(b *tagBreakdown) { b.Stale++ })
+	}
+	for _, m := range deprecated {
+		each(m, func(b *tagBreakdown) { b.Deprecated++ })
+	}
+	for i := range rows {
+		slices.Sort(rows[i].Archived)
+	}
+	return rows
+}
+
+var tagHeaders = []string{"Tag", "Modules", "Archived", "Disabled", "Stale", "Deprecated", "Archived Modules"}
+
+// tagRows formats the breakdown as table rows.
+func tagRows(rows []tagBreakdown) [][]string {
+	out := make([][]string, len(rows))
+	for i, b := range rows {
+		out[i] = []string{
+			b.Tag, strconv.Itoa(b.Modules), strconv.Itoa(len(b.Archived)), strconv.Itoa(b.Disabled),
+			strconv.Itoa(b.Stale), strconv.Itoa(b.Deprecated), strings.Join(b.Archived, ", "),
+		}
+	}
+	return out
+}
+
+// PrintTagTable outputs the findings per criticality tag.
+func PrintTagTable(rows []tagBreakdown) {
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nFINDINGS BY TAG\n\n")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(tagHeaders))
+	for _, row := range tagRows(rows) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownTags outputs the findings per criticality tag in Markdown
+// format.
+func PrintMarkdownTags(rows []tagBreakdown) {
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## FINDINGS BY TAG\n\n")
+	printMarkdownTable(os.Stdout, tagHeaders, tagRows(rows))
+}
+
+// JSONTagBreakdown is one criticality tag's findings in JSON output.
+type JSONTagBreakdown = report.TagBreakdown
+
+// buildTagsJSON converts the breakdown for JSON output.
+func buildTagsJSON(rows []tagBreakdown) []JSONTagBreakdown {
+	var out []JSONTagBreakdown
+	for _, b := range rows {
+		out = append(out, JSONTagBreakdown{
+			Tag:             b.Tag,
+			Modules:         b.Modules,
+			Archived:        len(b.Archived),
+			Disabled:        b.Disabled,
+			Stale:           b.Stale,
+			Deprecated:      b.Deprecated,
+			ArchivedModules: b.Archived,
+		})
+	}
+	return out
+}
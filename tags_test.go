@@ -0,0 +1,85 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestApplyTags(t *testing.T) {
+	tags := []moduleTag{
+		{"github.com/hashicorp/vault", "critical"},
+		{"golang.org/x/*", "infrastructure"},
+		{"github.com/hashicorp", "critical"},
+		{"github.com/hashicorp/vault", "infrastructure"},
+	}
+	modules := []Module{
+		{Path: "github.com/hashicorp/vault/api"},
+		{Path: "golang.org/x/mod"},
+		{Path: "golang.org/x"},
+		{Path: "github.com/pkg/errors"},
+	}
+	applyTags(tags, modules)
+	want := [][]string{{"critical", "infrastructure"}, {"infrastructure"}, nil, nil}
+	for i, m := range modules {
+		if !slices.Equal(m.Tags, want[i]) {
+			t.Errorf("%s: Tags = %v, want %v", m.Path, m.Tags, want[i])
+		}
+	}
+}
+
+func TestBuildTagBreakdown(t *testing.T) {
+	cfg := NewDefaultConfig()
+	if rows := buildTagBreakdown(cfg, []RepoStatus{{Module: Module{Path: "github.com/a/b"}}}, nil, nil); rows != nil {
+		t.Errorf("no tags configured: got %+v, want nil", rows)
+	}
+
+	cfg.Tags = []moduleTag{{"github.com/crit/*", "critical"}, {"github.com/ui/*", "ui"}, {"github.com/crit/both", "ui"}}
+	mods := []Module{
+		{Path: "github.com/crit/both", Tags: []string{"critical", "ui"}},
+		{Path: "github.com/crit/gone", Tags: []string{"critical"}},
+		{Path: "github.com/ui/slow", Tags: []string{"ui"}},
+		{Path: "github.com/other/x"},
+	}
+	results := []RepoStatus{
+		{Module: mods[0], IsArchived: true},
+		{Module: mods[1], Disabled: true, DisabledReason: disabledTakedown},
+		{Module: mods[2]},
+		{Module: mods[3], IsArchived: true},
+	}
+	stale := []RepoStatus{results[2]}
+	deprecated := []Module{mods[3]}
+
+	rows := buildTagBreakdown(cfg, results, stale, deprecated)
+	var got []string
+	for _, b := range tagRows(rows) {
+		got = append(got, strings.Join(b, " "))
+	}
+	want := []string{
+		"critical 2 1 1 0 0 github.com/crit/both",
+		"ui 2 1 0 1 0 github.com/crit/both",
+		"(untagged) 1 1 0 0 1 github.com/other/x",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("tag rows =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	js := buildTagsJSON(rows)
+	if len(js) != 3 || js[0].Tag != "critical" || js[0].Archived != 1 || js[0].Disabled != 1 {
+		t.Errorf("buildTagsJSON = %+v", js)
+	}
+}
+
+func TestPrintTagTable(t *testing.T) {
+	rows := []tagBreakdown{
+		{Tag: "critical", Modules: 1, Archived: []string{"github.com/a/b"}},
+		{Tag: untaggedLabel, Modules: 3},
+	}
+	output := captureStdout(t, func() { PrintTagTable(rows) })
+	if !strings.Contains(output, "TAG") || !strings.Contains(output, "github.com/a/b") || !strings.Contains(output, untaggedLabel) {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+	if output := captureStdout(t, func() { PrintTagTable(nil) }); output != "" {
+		t.Errorf("no tags should print nothing, got:\n%s", output)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// runTidyArchived implements `modrot tidy-archived [--write] [path]`.
+// It finds archived modules still required in go.mod that nothing imports
+// anymore and, with --write, removes them and verifies with go mod tidy.
+// Returns exit code: 0 = nothing to remove (or removed), 1 = unused archived
+// requires found (without --write), 2 = error.
+func runTidyArchived(args []string) int {
+	fs := flag.NewFlagSet("tidy-archived", flag.ContinueOnError)
+	write := fs.Bool("write", false, "Remove unused archived requires from go.mod and verify with go mod tidy")
+	workers := fs.Int("workers", 50, "Number of repos per GitHub GraphQL batch request")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot tidy-archived [--write] [path/to/go.mod | path/to/dir]
+
+Find archived modules that are still required in go.mod but no longer imported
+by any package (per go mod why and a source import scan).
+
+  --write        Remove them from go.mod and verify with go mod tidy
+  --workers int  Number of repos per GitHub GraphQL batch request (default 50)
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	gomodPath := "go.mod"
+	if fs.NArg() > 0 {
+		gomodPath = goModFile(fs.Arg(0))
+	}
+	dir := filepath.Dir(gomodPath)
+
+	allModules, err := ParseGoMod(gomodPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	githubModules, _ := FilterGitHub(allModules, false)
+	if len(githubModules) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "No GitHub modules found in %s\n", gomodPath)
+		return 0
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Checking %d GitHub modules...\n", len(githubModules))
+	results, err := CheckRepos(githubModules, *workers)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	archivedModules := archivedRequires(results, allModules)
+	if len(archivedModules) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "No archived dependencies found.\n")
+		return 0
+	}
+	archivedPaths := make([]string, len(archivedModules))
+	for i, m := range archivedModules {
+		archivedPaths[i] = m.Path
+	}
+
+	unused, err := findUnusedModules(dir, archivedPaths)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	// A source import (e.g. behind a build tag go mod why didn't consider)
+	// means the require is still needed.
+	if fm, scanErr := ScanImports(dir, archivedPaths); scanErr != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: source import scan skipped: %v\n", scanErr)
+	} else {
+		for p := range fm {
+			delete(unused, p)
+		}
+	}
+
+	var removable []Module
+	for _, m := range archivedModules {
+		if unused[m.Path] {
+			removable = append(removable, m)
+		}
+	}
+	if len(removable) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "All %d archived %s still in use.\n",
+			len(archivedModules), pluralize(len(archivedModules), "module is", "modules are"))
+		return 0
+	}
+
+	printUnusedArchived(removable)
+
+	if !*write {
+		_, _ = fmt.Fprintf(os.Stderr, "\nRun with --write to remove %s.\n", pluralize(len(removable), "it", "them"))
+		return 1
+	}
+
+	paths := make([]string, len(removable))
+	for i, m := range removable {
+		paths[i] = m.Path
+	}
+	if err := removeRequiresVerified(gomodPath, paths); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nRemoved %d archived %s from %s; go mod tidy verified.\n",
+		len(paths), pluralize(len(paths), "require", "requires"), gomodPath)
+	return 0
+}
+
+// archivedRequires returns the go.mod requires whose repo is archived,
+// covering every module path of a multi-module repo, sorted by path.
+func archivedRequires(results []RepoStatus, allModules []Module) []Module {
+	archivedRepos := make(map[string]bool)
+	for _, r := range results {
+		if r.IsArchived {
+			archivedRepos[repoKey(r.Module)] = true
+		}
+	}
+	var archived []Module
+	for _, m := range allModules {
+		if m.Owner != "" && archivedRepos[repoKey(m)] {
+			archived = append(archived, m)
+		}
+	}
+	sort.Slice(archived, func(i, j int) bool {
+		return archived[i].Path < archived[j].Path
+	})
+	return archived
+}
+
+// printUnusedArchived outputs the table of removable requires.
+func printUnusedArchived(modules []Module) {
+	_, _ = fmt.Fprintf(os.Stderr, "\nUNUSED ARCHIVED REQUIRES (%d %s not imported by any package)\n\n",
+		len(modules), pluralize(len(modules), "module", "modules"))
+	for _, m := range modules {
+		_, _ = fmt.Fprintf(os.Stdout, "%s\t%s\t%s\n", m.Path, m.Version, directLabel(m))
+	}
+}
+
+// findUnusedModules runs `go mod why -m` for the given module paths in dir
+// and returns the set the main module does not need.
+func findUnusedModules(dir string, modulePaths []string) (map[string]bool, error) {
+	args := append([]string{"mod", "why", "-m"}, modulePaths...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("running go mod why: %w", err)
+	}
+	return parseModWhy(string(out)), nil
+}
+
+// parseModWhy parses `go mod why -m` output and returns the module paths
+// reported as "(main module does not need module ...)".
+//
+//	# github.com/pkg/errors
+//	(main module does not need module github.com/pkg/errors)
+func parseModWhy(output string) map[string]bool {
+	unused := make(map[string]bool)
+	var current string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "# "):
+			current = strings.TrimPrefix(line, "# ")
+		case current != "" && strings.HasPrefix(line, "(main module does not need"):
+			unused[current] = true
+		}
+	}
+	return unused
+}
+
+// removeRequiresVerified drops the given requires from go.mod, runs
+// go mod tidy, and checks tidy did not add any of them back. On failure
+// the original go.mod and go.sum are restored.
+func removeRequiresVerified(gomodPath string, paths []string) error {
+	restore, err := snapshotGoMod(gomodPath)
+	if err != nil {
+		return err
+	}
+
+	if err := dropRequires(gomodPath, paths); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = filepath.Dir(gomodPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		restore()
+		return fmt.Errorf("go mod tidy failed, go.mod restored: %v\n%s", err, out)
+	}
+
+	after, err := ParseGoMod(gomodPath)
+	if err != nil {
+		restore()
+		return err
+	}
+	removed := make(map[string]bool)
+	for _, p := range paths {
+		removed[p] = true
+	}
+	for _, m := range after {
+		if removed[m.Path] {
+			restore()
+			return fmt.Errorf("go mod tidy re-added %s (still needed), go.mod restored", m.Path)
+		}
+	}
+	return nil
+}
+
+// snapshotGoMod saves go.mod and go.sum (if present) and returns a function
+// that writes them back.
+func snapshotGoMod(gomodPath string) (restore func(), err error) {
+	gosumPath := filepath.Join(filepath.Dir(gomodPath), "go.sum")
+	origMod, err := os.ReadFile(gomodPath)
+	if err != nil {
+		return nil, err
+	}
+	origSum, sumErr := os.ReadFile(gosumPath)
+
+	return func() {
+		_ = os.WriteFile(gomodPath, origMod, 0o644)
+		if sumErr == nil {
+			_ = os.WriteFile(gosumPath, origSum, 0o644)
+		}
+	}, nil
+}
+
+// dropRequires removes require directives for the given module paths from
+// a go.mod file, preserving comments and formatting of the rest.
+func dropRequires(gomodPath string, paths []string) error {
+	data, err := os.ReadFile(gomodPath)
+	if err != nil {
+		return fmt.Errorf("reading go.mod: %w", err)
+	}
+	f, err := modfile.Parse(gomodPath, data, nil)
+	if err != nil {
+		return fmt.Errorf("parsing go.mod: %w", err)
+	}
+	for _, p := range paths {
+		if err := f.DropRequire(p); err != nil {
+			return err
+		}
+	}
+	f.Cleanup()
+	out, err := f.Format()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(gomodPath, out, 0o644)
+}
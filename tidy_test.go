@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseModWhy(t *testing.T) {
+	output := `# github.com/pkg/errors
+(main module does not need module github.com/pkg/errors)
+
+# github.com/mitchellh/mapstructure
+example.com/app
+github.com/mitchellh/mapstructure
+
+# github.com/unused/thing
+(main module does not need module github.com/unused/thing)
+`
+	unused := parseModWhy(output)
+	if len(unused) != 2 {
+		t.Fatalf("len(unused) = %d, want 2: %v", len(unused), unused)
+	}
+	if !unused["github.com/pkg/errors"] {
+		t.Error("pkg/errors should be unused")
+	}
+	if !unused["github.com/unused/thing"] {
+		t.Error("unused/thing should be unused")
+	}
+	if unused["github.com/mitchellh/mapstructure"] {
+		t.Error("mapstructure is imported and should not be unused")
+	}
+}
+
+func TestParseModWhy_Empty(t *testing.T) {
+	if got := parseModWhy(""); len(got) != 0 {
+		t.Errorf("parseModWhy(\"\") = %v, want empty", got)
+	}
+}
+
+func TestArchivedRequires(t *testing.T) {
+	allModules := []Module{
+		{Path: "github.com/foo/bar", Version: "v1.0.0", Owner: "foo", Repo: "bar", Direct: true},
+		{Path: "github.com/foo/bar/v2", Version: "v2.1.0", Owner: "foo", Repo: "bar"},
+		{Path: "github.com/ok/lib", Version: "v1.0.0", Owner: "ok", Repo: "lib"},
+		{Path: "golang.org/x/text", Version: "v0.14.0"},
+	}
+	results := []RepoStatus{
+		{Module: allModules[0], IsArchived: true},
+		{Module: allModules[2]},
+	}
+
+	got := archivedRequires(results, allModules)
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2 (both paths of archived repo)", len(got))
+	}
+	if got[0].Path != "github.com/foo/bar" || got[1].Path != "github.com/foo/bar/v2" {
+		t.Errorf("got %q, %q", got[0].Path, got[1].Path)
+	}
+}
+
+func TestDropRequires(t *testing.T) {
+	dir := t.TempDir()
+	gomodPath := filepath.Join(dir, "go.mod")
+	content := `module example.com/app
+
+go 1.21
+
+require (
+	github.com/keep/me v1.0.0 // keep this comment
+	github.com/pkg/errors v0.9.1 // indirect
+)
+`
+	if err := os.WriteFile(gomodPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dropRequires(gomodPath, []string{"github.com/pkg/errors"}); err != nil {
+		t.Fatalf("dropRequires: %v", err)
+	}
+
+	data, _ := os.ReadFile(gomodPath)
+	got := string(data)
+	if strings.Contains(got, "github.com/pkg/errors") {
+		t.Errorf("pkg/errors should be removed:\n%s", got)
+	}
+	if !strings.Contains(got, "github.com/keep/me v1.0.0 // keep this comment") {
+		t.Errorf("other requires and comments should be preserved:\n%s", got)
+	}
+}
+
+func TestRemoveRequiresVerified(t *testing.T) {
+	dir := t.TempDir()
+	gomodPath := filepath.Join(dir, "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n\nrequire github.com/pkg/errors v0.9.1 // indirect\n"
+	if err := os.WriteFile(gomodPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := removeRequiresVerified(gomodPath, []string{"github.com/pkg/errors"}); err != nil {
+		t.Fatalf("removeRequiresVerified: %v", err)
+	}
+
+	mods, err := ParseGoMod(gomodPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mods) != 0 {
+		t.Errorf("expected no requires after removal, got %v", mods)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// Triage outcomes for repositories GitHub reports as not found, reported
+// as triage in JSON.
+const (
+	triageRenamed     = "renamed"            // GitHub redirects the path to another repository
+	triageProxyCached = "deleted_cached"     // gone from GitHub, but the proxy still serves the version
+	triageNeverFound  = "not_found_anywhere" // neither GitHub nor the proxy knows it: likely a typo
+)
+
+// triageNotFound looks into each not-found repository in results, checking
+// whether GitHub redirects its path (a rename or transfer) and whether the
+// module proxy still serves the required version, and records the outcome
+// in Triage (and RenamedTo), so reports can say what happened instead of
+// repeating GitHub's error.
+func triageNotFound(cfg *Config, results []RepoStatus, r *resolver) {
+	var idx []int
+	for i, rs := range results {
+		if rs.NotFound {
+			idx = append(idx, i)
+		}
+	}
+	if len(idx) == 0 {
+		return
+	}
+	token, err := getGHToken()
+	if err != nil {
+		warnDegraded(cfg, "triage", "could not check not-found repos for renames: %v", err)
+		token = ""
+	}
+	triageNotFoundWith(results, idx, token, newGHClient(), r)
+}
+
+// triageNotFoundWith is the internal implementation that accepts the
+// GitHub client and resolver, allowing tests to inject mock servers. An
+// empty token skips the rename check.
+func triageNotFoundWith(results []RepoStatus, idx []int, token string, gc *ghClient, r *resolver) {
+	type outcome struct{ triage, renamedTo string }
+	outcomes, _ := mapPool(context.Background(), idx, poolOptions{Workers: 10}, func(_ context.Context, i int) (outcome, error) {
+		m := results[i].Module
+		if token != "" {
+			if name, err := gc.fetchRepoFullName(token, m.Owner, m.Repo); err == nil && name != "" &&
+				!strings.EqualFold(name, m.Owner+"/"+m.Repo) {
+				return outcome{triageRenamed, "github.com/" + name}, nil
+			}
+		}
+		if r.proxyHasVersion(m.Path, m.Version) {
+			return outcome{triage: triageProxyCached}, nil
+		}
+		return outcome{triage: triageNeverFound}, nil
+	})
+	for j, i := range idx {
+		results[i].Triage = outcomes[j].triage
+		results[i].RenamedTo = outcomes[j].renamedTo
+	}
+}
+
+// fetchRepoFullName returns the "owner/name" GitHub serves for
+// GET /repos/{owner}/{repo}, following the redirect GitHub answers with
+// for renamed and transferred repositories. Returns "" if there is no
+// such repository.
+func (g *ghClient) fetchRepoFullName(token, owner, repo string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s", g.restURL, owner, repo)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("GitHub API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("GitHub API returned %d: %s", resp.StatusCode, string(body))
+	}
+	var info struct {
+		FullName string `json:"full_name"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+	return info.FullName, nil
+}
+
+// proxyHasVersion reports whether the module proxy serves version of
+// modulePath (or, without a version, any version at all).
+func (r *resolver) proxyHasVersion(modulePath, version string) bool {
+	escaped, err := module.EscapePath(modulePath)
+	if err != nil {
+		return false
+	}
+	url := fmt.Sprintf("%s/%s/@latest", r.proxyBaseURL, escaped)
+	if version != "" {
+		ev, err := module.EscapeVersion(version)
+		if err != nil {
+			return false
+		}
+		url = fmt.Sprintf("%s/%s/@v/%s.info", r.proxyBaseURL, escaped, ev)
+	}
+	_, ok := r.get(url)
+	return ok
+}
+
+// notFoundHint describes a not-found repository for reports: the triage
+// outcome when there is one, GitHub's error otherwise.
+func notFoundHint(r RepoStatus) string {
+	switch r.Triage {
+	case triageRenamed:
+		return "renamed to " + r.RenamedTo
+	case triageProxyCached:
+		return "deleted from GitHub but still cached by the module proxy"
+	case triageNeverFound:
+		return "not on GitHub or the module proxy; typo in the module path?"
+	}
+	return r.Error
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTriageNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/old/moved":
+			http.Redirect(w, r, "/repositories/42", http.StatusMovedPermanently)
+		case "/repositories/42":
+			_, _ = w.Write([]byte(`{"full_name":"new/moved"}`))
+		case "/proxy/github.com/gone/cached/@v/v1.2.0.info":
+			_, _ = w.Write([]byte(`{"Version":"v1.2.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/old/moved", Version: "v1.0.0", Owner: "old", Repo: "moved"}, NotFound: true, Error: "Could not resolve"},
+		{Module: Module{Path: "github.com/gone/cached", Version: "v1.2.0", Owner: "gone", Repo: "cached"}, NotFound: true, Error: "Could not resolve"},
+		{Module: Module{Path: "github.com/typo/lib", Version: "v0.1.0", Owner: "typo", Repo: "lib"}, NotFound: true, Error: "Could not resolve"},
+		{Module: Module{Path: "github.com/live/lib", Version: "v1.0.0", Owner: "live", Repo: "lib"}},
+	}
+	gc := &ghClient{client: srv.Client(), restURL: srv.URL}
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL + "/proxy"}
+	triageNotFoundWith(results, []int{0, 1, 2}, "tok", gc, r)
+
+	tests := []struct {
+		triage, renamedTo, hint string
+	}{
+		{triageRenamed, "github.com/new/moved", "renamed to github.com/new/moved"},
+		{triageProxyCached, "", "deleted from GitHub but still cached by the module proxy"},
+		{triageNeverFound, "", "typo in the module path?"},
+		{"", "", ""},
+	}
+	for i, tt := range tests {
+		if results[i].Triage != tt.triage || results[i].RenamedTo != tt.renamedTo {
+			t.Errorf("results[%d] triage = %q %q, want %q %q", i, results[i].Triage, results[i].RenamedTo, tt.triage, tt.renamedTo)
+		}
+		if got := notFoundHint(results[i]); !strings.Contains(got, tt.hint) {
+			t.Errorf("notFoundHint(results[%d]) = %q, want %q", i, got, tt.hint)
+		}
+	}
+}
+
+func TestTriageNotFound_NoToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/repos/") {
+			t.Errorf("unexpected GitHub request without a token: %s", r.URL.Path)
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	results := []RepoStatus{{Module: Module{Path: "github.com/old/moved", Version: "v1.0.0", Owner: "old", Repo: "moved"}, NotFound: true}}
+	gc := &ghClient{client: srv.Client(), restURL: srv.URL}
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+	triageNotFoundWith(results, []int{0}, "", gc, r)
+	if results[0].Triage != triageNeverFound {
+		t.Errorf("Triage = %q, want %q", results[0].Triage, triageNeverFound)
+	}
+}
+
+func TestNotFoundHintInOutput(t *testing.T) {
+	cfg := defaultTestConfig()
+	results := []RepoStatus{{
+		Module:    Module{Path: "github.com/old/moved", Version: "v1.0.0", Owner: "old", Repo: "moved"},
+		NotFound:  true,
+		Error:     "Could not resolve to a Repository",
+		Triage:    triageRenamed,
+		RenamedTo: "github.com/new/moved",
+	}}
+
+	out := buildJSONOutput(cfg, results, nil, nil, nil)
+	if len(out.NotFound) != 1 || out.NotFound[0].Triage != "renamed" || out.NotFound[0].RenamedTo != "github.com/new/moved" {
+		t.Errorf("not_found = %+v", out.NotFound)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// unknownModes are the values --unknown-repos accepts: "report" lists
+// repositories whose status could not be checked without affecting the
+// exit code, and "fail" fails the run when there are any, so a gate never
+// passes on dependencies it did not actually check.
+var unknownModes = []string{"report", "fail"}
+
+// notChecked is the Error of a result no GitHub response covered.
+const notChecked = "status not checked"
+
+// isNotFoundError reports whether a GraphQL error for a repository means it
+// does not exist, rather than that the check itself failed (a timeout,
+// rate limit, or server error), which leaves the status unknown.
+func isNotFoundError(errType, msg string) bool {
+	return errType == "NOT_FOUND" || strings.Contains(strings.ToLower(msg), "could not resolve to a repository")
+}
+
+// unknownExitCode fails a run with unknown repositories under
+// --unknown-repos fail. Like disabledExitCode, it runs after --fail-on.
+func unknownExitCode(cfg *Config, code int) int {
+	if code != 0 || cfg.UnknownRepos != "fail" {
+		return code
+	}
+	for _, results := range cfg.Summary.results {
+		for _, r := range results {
+			if r.Unknown {
+				return 1
+			}
+		}
+	}
+	return code
+}
+
+// splitUnknown returns the results whose status is unknown, sorted by
+// module path.
+func splitUnknown(results []RepoStatus) []RepoStatus {
+	var unknown []RepoStatus
+	for _, r := range results {
+		if r.Unknown {
+			unknown = append(unknown, r)
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i].Module.Path < unknown[j].Module.Path
+	})
+	return unknown
+}
+
+// PrintUnknownTable lists the modules whose repository status could not be
+// checked, with the reason. They are neither archived nor active.
+func PrintUnknownTable(results []RepoStatus) {
+	unknown := splitUnknown(results)
+	if len(unknown) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nUNKNOWN (%d %s, status could not be checked):\n", len(unknown), pluralize(len(unknown), "module", "modules"))
+	for _, r := range unknown {
+		_, _ = fmt.Fprintf(os.Stderr, "  %s — %s\n", r.Module.Path, r.Error)
+	}
+}
+
+// PrintMarkdownUnknown lists unknown-status modules in Markdown format.
+func PrintMarkdownUnknown(results []RepoStatus) {
+	unknown := splitUnknown(results)
+	if len(unknown) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## UNKNOWN (%d %s)\n\n", len(unknown), pluralize(len(unknown), "module", "modules"))
+	for _, r := range unknown {
+		_, _ = fmt.Fprintf(os.Stdout, "- %s — %s\n", r.Module.Path, r.Error)
+	}
+}
+
+// buildUnknownJSON converts unknown-status modules for JSON output.
+func buildUnknownJSON(results []RepoStatus) []JSONModule {
+	var out []JSONModule
+	for _, r := range splitUnknown(results) {
+		out = append(out, JSONModule{
+			FindingID:  findingID(findingUnknown, r.Module.Path),
+			Module:     r.Module.Path,
+			Version:    r.Module.Version,
+			Direct:     r.Module.Direct,
+			Owner:      r.Module.Owner,
+			Repo:       r.Module.Repo,
+			Error:      r.Error,
+			RequiredAt: requiredAt(r.Module),
+			Tags:       r.Module.Tags,
+		})
+	}
+	return out
+}
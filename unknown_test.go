@@ -0,0 +1,129 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGraphQLResponse_Unknown(t *testing.T) {
+	modules := []Module{
+		{Path: "github.com/a/gone", Owner: "a", Repo: "gone"},
+		{Path: "github.com/b/slow", Owner: "b", Repo: "slow"},
+		{Path: "github.com/c/typed", Owner: "c", Repo: "typed"},
+	}
+	resp := gqlResponse{
+		Errors: []gqlError{
+			{Message: "Could not resolve to a Repository with the name 'a/gone'.", Path: []string{"r0"}},
+			{Message: "Something went wrong while executing your query. This may be the result of a timeout.", Path: []string{"r1"}},
+			{Type: "NOT_FOUND", Message: "not here", Path: []string{"r2"}},
+		},
+	}
+	results := parseGraphQLResponse(resp, modules)
+
+	if !results[0].NotFound || results[0].Unknown {
+		t.Errorf("results[0] = %+v, want NotFound", results[0])
+	}
+	if !results[1].Unknown || results[1].NotFound || !strings.Contains(results[1].Error, "timeout") {
+		t.Errorf("results[1] = %+v, want Unknown with the error", results[1])
+	}
+	if !results[2].NotFound || results[2].Unknown {
+		t.Errorf("results[2] = %+v, want NotFound from the error type", results[2])
+	}
+}
+
+func TestApplyStatus_UncheckedIsUnknown(t *testing.T) {
+	modules := []Module{
+		{Path: "github.com/a/lib", Owner: "a", Repo: "lib"},
+		{Path: "github.com/b/lib", Owner: "b", Repo: "lib"},
+	}
+	statusMap := map[string]RepoStatus{"a/lib": {Module: modules[0], IsArchived: true}}
+	results := applyStatus(modules, statusMap)
+	if !results[0].IsArchived || results[0].Unknown {
+		t.Errorf("results[0] = %+v, want archived", results[0])
+	}
+	if !results[1].Unknown || results[1].Error != notChecked {
+		t.Errorf("results[1] = %+v, want Unknown (%s)", results[1], notChecked)
+	}
+}
+
+func TestCheckReposCached_UnknownNotCached(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "archived.json")
+	modules := []Module{{Path: "github.com/b/slow", Owner: "b", Repo: "slow"}}
+	check := func(ms []Module) ([]RepoStatus, error) {
+		return []RepoStatus{{Module: ms[0], Unknown: true, Error: "timeout"}}, nil
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.Now = now
+	if _, err := checkReposCachedWith(cfg, path, modules, check); err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := loadArchiveCache(path).Repos["b/slow"]; ok {
+		t.Errorf("unknown result cached as %+v", e)
+	}
+}
+
+func TestUnknownExitCode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		unknown bool
+		code    int
+		want    int
+	}{
+		{"report", true, 0, 0},
+		{"fail", true, 0, 1},
+		{"fail", false, 0, 0},
+		{"fail", true, 2, 2},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig()
+		cfg.UnknownRepos = tt.mode
+		cfg.Summary.add("example.com/app", "go.mod", []RepoStatus{
+			{Module: Module{Path: "github.com/b/slow", Owner: "b", Repo: "slow"}, Unknown: tt.unknown},
+		})
+		if got := unknownExitCode(cfg, tt.code); got != tt.want {
+			t.Errorf("unknownExitCode(%s, unknown=%v, %d) = %d, want %d", tt.mode, tt.unknown, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownInOutputs(t *testing.T) {
+	cfg := defaultTestConfig()
+	cfg.ShowAll = true
+	results := []RepoStatus{
+		{Module: Module{Path: "github.com/b/slow", Version: "v1.0.0", Owner: "b", Repo: "slow"}, Unknown: true, Error: "timeout"},
+		{Module: Module{Path: "github.com/c/live", Version: "v1.0.0", Owner: "c", Repo: "live"}},
+	}
+
+	out := buildJSONOutput(cfg, results, nil, nil, nil)
+	if len(out.Unknown) != 1 || out.Unknown[0].Module != "github.com/b/slow" || out.Unknown[0].Error != "timeout" {
+		t.Errorf("unknown = %+v", out.Unknown)
+	}
+	if len(out.Active) != 1 || out.Active[0].Module != "github.com/c/live" {
+		t.Errorf("active = %+v, want only the checked repo", out.Active)
+	}
+	if out.Meta.Unknown != 1 || !strings.HasSuffix(out.Meta.String(), ", 1 unknown") {
+		t.Errorf("meta = %+v (%s)", out.Meta, out.Meta)
+	}
+
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() { PrintTable(cfg, results, nil) })
+	})
+	if !strings.Contains(stderr, "UNKNOWN (1 module,") || !strings.Contains(stderr, "github.com/b/slow — timeout") {
+		t.Errorf("table output missing unknown section:\n%s", stderr)
+	}
+
+	md := captureStdout(t, func() { PrintMarkdown(cfg, results, nil) })
+	if !strings.Contains(md, "## UNKNOWN (1 module)") {
+		t.Errorf("markdown output missing unknown section:\n%s", md)
+	}
+
+	var s runSummary
+	s.add("example.com/app", "go.mod", results)
+	if got := summaryBadge(computeBadgeCounts(&s), false); !strings.Contains(got, "[WARN] 1 unknown") || !strings.Contains(got, "[OK] 1 active") {
+		t.Errorf("badge = %q", got)
+	}
+}
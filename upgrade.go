@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// UpgradeVia is a direct dependency through which an archived indirect
+// module is reached.
+type UpgradeVia struct {
+	Path    string
+	Version string
+	Latest  string // latest release; "" if unknown or not newer than Version
+	Drops   bool   // Latest no longer requires the archived module
+}
+
+// UpgradeFinding classifies one archived indirect module by whether
+// upgrading any direct dependency would drop it.
+type UpgradeFinding struct {
+	Module  string
+	Version string
+	Via     []UpgradeVia // sorted by path
+}
+
+// Actionable reports whether at least one direct dependency has a newer
+// release that no longer requires the archived module.
+func (f UpgradeFinding) Actionable() bool {
+	for _, v := range f.Via {
+		if v.Drops {
+			return true
+		}
+	}
+	return false
+}
+
+// directUpgrade holds the proxy data fetched for one direct dependency.
+type directUpgrade struct {
+	latest     string
+	currentReq map[string]bool
+	latestReq  map[string]bool
+}
+
+// analyzeUpgrades finds archived indirect modules in the dependency graph,
+// the direct dependencies each is reached through, and whether the latest
+// release of any of those direct dependencies drops it.
+//
+// A direct dependency only counts as dropping a module when its current
+// go.mod requires it and its latest go.mod does not. Modules reached deeper
+// than the direct dependency's own go.mod (pre-1.17 modules without graph
+// pruning) cannot be judged from go.mod alone and are left as unavoidable.
+func analyzeUpgrades(results []RepoStatus, graph map[string][]string, allModules []Module, r *resolver, maxWorkers int) []UpgradeFinding {
+	entries, _ := buildTree(results, graph, allModules)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	directSet := make(map[string]bool)
+	versionByPath := make(map[string]string)
+	for _, m := range allModules {
+		versionByPath[m.Path] = m.Version
+		if m.Direct {
+			directSet[m.Path] = true
+		}
+	}
+
+	// archived indirect module → direct deps it is reachable through
+	viaByArchived := make(map[string]map[string]bool)
+	for _, e := range entries {
+		for _, a := range e.archived {
+			if directSet[a] {
+				continue
+			}
+			if viaByArchived[a] == nil {
+				viaByArchived[a] = make(map[string]bool)
+			}
+			viaByArchived[a][e.directPath] = true
+		}
+	}
+	if len(viaByArchived) == 0 {
+		return nil
+	}
+
+	directs := make(map[string]bool)
+	for _, vias := range viaByArchived {
+		for d := range vias {
+			directs[d] = true
+		}
+	}
+	info := fetchDirectUpgrades(directs, versionByPath, r, maxWorkers)
+
+	var findings []UpgradeFinding
+	for a, vias := range viaByArchived {
+		f := UpgradeFinding{Module: a, Version: versionByPath[a]}
+		for d := range vias {
+			v := UpgradeVia{Path: d, Version: versionByPath[d]}
+			if du, ok := info[d]; ok && du.latest != "" {
+				v.Latest = du.latest
+				v.Drops = du.currentReq[a] && !du.latestReq[a]
+			}
+			f.Via = append(f.Via, v)
+		}
+		sort.Slice(f.Via, func(i, j int) bool {
+			return f.Via[i].Path < f.Via[j].Path
+		})
+		findings = append(findings, f)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Module < findings[j].Module
+	})
+	return findings
+}
+
+// fetchDirectUpgrades looks up the latest release of each direct dependency
+// and, when it is newer than the required version, the requires of both
+// go.mod files. Dependencies without a newer release are omitted.
+func fetchDirectUpgrades(directs map[string]bool, versionByPath map[string]string, r *resolver, maxWorkers int) map[string]directUpgrade {
+	paths := make([]string, 0, len(directs))
+	for d := range directs {
+		paths = append(paths, d)
+	}
+
+	upgrades, _ := mapPool(context.Background(), paths, poolOptions{Workers: maxWorkers}, func(_ context.Context, path string) (*directUpgrade, error) {
+		current := versionByPath[path]
+		latest, _, _ := r.fetchLatestInfo(path)
+		if latest == "" || current == "" || semver.Compare(latest, current) <= 0 {
+			return nil, nil
+		}
+		return &directUpgrade{
+			latest:     latest,
+			currentReq: goModRequires(r.fetchGoMod(path, current)),
+			latestReq:  goModRequires(r.fetchGoMod(path, latest)),
+		}, nil
+	})
+
+	info := make(map[string]directUpgrade)
+	for j, du := range upgrades {
+		if du != nil {
+			info[paths[j]] = *du
+		}
+	}
+	return info
+}
+
+// goModRequires returns the set of module paths required by a go.mod body.
+// Returns nil if the body is empty or cannot be parsed.
+func goModRequires(body string) map[string]bool {
+	if body == "" {
+		return nil
+	}
+	f, err := modfile.ParseLax("go.mod", []byte(body), nil)
+	if err != nil {
+		return nil
+	}
+	reqs := make(map[string]bool, len(f.Require))
+	for _, req := range f.Require {
+		reqs[req.Mod.Path] = true
+	}
+	return reqs
+}
+
+// splitUpgradeFindings separates findings into actionable and unavoidable buckets.
+func splitUpgradeFindings(findings []UpgradeFinding) (actionable, unavoidable []UpgradeFinding) {
+	for _, f := range findings {
+		if f.Actionable() {
+			actionable = append(actionable, f)
+		} else {
+			unavoidable = append(unavoidable, f)
+		}
+	}
+	return actionable, unavoidable
+}
+
+// upgradeHeaders returns the column headers for an upgrade bucket.
+func upgradeHeaders(actionable bool) []string {
+	if actionable {
+		return []string{"Module", "Version", "Upgrade"}
+	}
+	return []string{"Module", "Version", "Via"}
+}
+
+// upgradeRow formats a finding as a table row. Actionable rows list the
+// upgrades that drop the module; unavoidable rows list every path to it.
+func upgradeRow(f UpgradeFinding) []string {
+	var parts []string
+	for _, v := range f.Via {
+		switch {
+		case v.Drops:
+			parts = append(parts, fmt.Sprintf("%s %s → %s", v.Path, v.Version, v.Latest))
+		case !f.Actionable():
+			parts = append(parts, v.Path+"@"+v.Version)
+		}
+	}
+	return []string{f.Module, f.Version, strings.Join(parts, ", ")}
+}
+
+// upgradeCommands returns the shell command applying each upgrade that
+// drops an archived module, one per direct dependency, in the order of
+// planUpgrades.
+func upgradeCommands(findings []UpgradeFinding) []string {
+	plan := planUpgrades(findings, nil)
+	cmds := make([]string, len(plan))
+	for i, pu := range plan {
+		cmds[i] = "go get " + pu.Path + "@" + pu.To + " && go mod tidy"
+	}
+	return cmds
+}
+
+// PrintUpgradeTable outputs archived indirect dependencies split into
+// "actionable via upgrade" and "unavoidable" sections, followed by the
+// commands applying the actionable upgrades.
+func PrintUpgradeTable(findings []UpgradeFinding) {
+	actionable, unavoidable := splitUpgradeFindings(findings)
+	printUpgradeBucket("ACTIONABLE VIA UPGRADE", "a direct dependency upgrade drops", actionable, true)
+	printUpgradeBucket("UNAVOIDABLE", "no direct dependency upgrade drops", unavoidable, false)
+
+	cmds := upgradeCommands(findings)
+	if len(cmds) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nCOMMANDS (%d suggested %s)\n\n", len(cmds), pluralize(len(cmds), "upgrade", "upgrades"))
+	for _, c := range cmds {
+		_, _ = fmt.Fprintln(os.Stdout, c)
+	}
+}
+
+func printUpgradeBucket(title, reason string, findings []UpgradeFinding, actionable bool) {
+	if len(findings) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\n%s (%d archived indirect %s, %s %s)\n\n",
+		title, len(findings), pluralize(len(findings), "module", "modules"),
+		reason, pluralize(len(findings), "it", "them"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(upgradeHeaders(actionable)))
+	for _, f := range findings {
+		writeTabRow(w, upgradeRow(f))
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownUpgrades outputs the upgrade buckets as Markdown tables.
+func PrintMarkdownUpgrades(findings []UpgradeFinding) {
+	actionable, unavoidable := splitUpgradeFindings(findings)
+	for _, b := range []struct {
+		title      string
+		findings   []UpgradeFinding
+		actionable bool
+	}{
+		{"ACTIONABLE VIA UPGRADE", actionable, true},
+		{"UNAVOIDABLE", unavoidable, false},
+	} {
+		if len(b.findings) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(os.Stdout, "\n## %s (%d archived indirect %s)\n\n",
+			b.title, len(b.findings), pluralize(len(b.findings), "module", "modules"))
+		var rows [][]string
+		for _, f := range b.findings {
+			rows = append(rows, upgradeRow(f))
+		}
+		printMarkdownTable(os.Stdout, upgradeHeaders(b.actionable), rows)
+	}
+
+	if cmds := upgradeCommands(findings); len(cmds) > 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "\n## COMMANDS (%d)\n\n```sh\n%s\n```\n", len(cmds), strings.Join(cmds, "\n"))
+	}
+}
+
+// JSONUpgrades is the upgrade_analysis section of JSON output.
+type JSONUpgrades = report.Upgrades
+
+// JSONUpgradeFinding is one archived indirect module in upgrade_analysis.
+type JSONUpgradeFinding = report.UpgradeFinding
+
+// JSONUpgradeVia is one direct dependency path in a JSONUpgradeFinding.
+type JSONUpgradeVia = report.UpgradeVia
+
+// buildJSONUpgrades converts findings to the JSON section. Returns nil when
+// the analysis was not run.
+func buildJSONUpgrades(findings []UpgradeFinding) *JSONUpgrades {
+	if findings == nil {
+		return nil
+	}
+	out := &JSONUpgrades{
+		Actionable:  []JSONUpgradeFinding{},
+		Unavoidable: []JSONUpgradeFinding{},
+		Commands:    upgradeCommands(findings),
+	}
+	for _, f := range findings {
+		jf := JSONUpgradeFinding{Module: f.Module, Version: f.Version}
+		for _, v := range f.Via {
+			jf.Via = append(jf.Via, JSONUpgradeVia{
+				Module:        v.Path,
+				Version:       v.Version,
+				LatestVersion: v.Latest,
+				DropsArchived: v.Drops,
+			})
+		}
+		if f.Actionable() {
+			out.Actionable = append(out.Actionable, jf)
+		} else {
+			out.Unavoidable = append(out.Unavoidable, jf)
+		}
+	}
+	return out
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// upgradeTestProxy serves @latest and .mod for two direct deps:
+// github.com/a/fixable drops github.com/x/old in v1.1.0, while
+// github.com/b/stuck still requires github.com/y/dead at its latest.
+func upgradeTestProxy(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/a/fixable/@latest":
+			_, _ = fmt.Fprint(w, `{"Version":"v1.1.0","Time":"2025-01-01T00:00:00Z"}`)
+		case "/github.com/a/fixable/@v/v1.0.0.mod":
+			_, _ = fmt.Fprint(w, "module github.com/a/fixable\n\ngo 1.21\n\nrequire github.com/x/old v0.1.0\n")
+		case "/github.com/a/fixable/@v/v1.1.0.mod":
+			_, _ = fmt.Fprint(w, "module github.com/a/fixable\n\ngo 1.21\n")
+		case "/github.com/b/stuck/@latest":
+			_, _ = fmt.Fprint(w, `{"Version":"v2.0.0","Time":"2025-01-01T00:00:00Z"}`)
+		case "/github.com/b/stuck/@v/v1.0.0.mod", "/github.com/b/stuck/@v/v2.0.0.mod":
+			_, _ = fmt.Fprint(w, "module github.com/b/stuck\n\ngo 1.21\n\nrequire github.com/y/dead v0.2.0\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func upgradeTestInputs() ([]RepoStatus, map[string][]string, []Module) {
+	allModules := []Module{
+		{Path: "github.com/a/fixable", Version: "v1.0.0", Owner: "a", Repo: "fixable", Direct: true},
+		{Path: "github.com/b/stuck", Version: "v1.0.0", Owner: "b", Repo: "stuck", Direct: true},
+		{Path: "github.com/x/old", Version: "v0.1.0", Owner: "x", Repo: "old"},
+		{Path: "github.com/y/dead", Version: "v0.2.0", Owner: "y", Repo: "dead"},
+	}
+	results := []RepoStatus{
+		{Module: allModules[0]},
+		{Module: allModules[1]},
+		{Module: allModules[2], IsArchived: true},
+		{Module: allModules[3], IsArchived: true},
+	}
+	graph := map[string][]string{
+		"example.com/app":             {"github.com/a/fixable@v1.0.0", "github.com/b/stuck@v1.0.0"},
+		"github.com/a/fixable@v1.0.0": {"github.com/x/old@v0.1.0"},
+		"github.com/b/stuck@v1.0.0":   {"github.com/y/dead@v0.2.0", "github.com/x/old@v0.1.0"},
+	}
+	return results, graph, allModules
+}
+
+func TestAnalyzeUpgrades(t *testing.T) {
+	srv := upgradeTestProxy(t)
+	defer srv.Close()
+
+	results, graph, allModules := upgradeTestInputs()
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+	findings := analyzeUpgrades(results, graph, allModules, r, 4)
+
+	if len(findings) != 2 {
+		t.Fatalf("len(findings) = %d, want 2: %+v", len(findings), findings)
+	}
+
+	old := findings[0]
+	if old.Module != "github.com/x/old" {
+		t.Fatalf("findings[0].Module = %q, want github.com/x/old", old.Module)
+	}
+	if !old.Actionable() {
+		t.Error("x/old should be actionable: upgrading a/fixable drops it")
+	}
+	if len(old.Via) != 2 {
+		t.Fatalf("x/old Via = %+v, want both direct deps", old.Via)
+	}
+	if !old.Via[0].Drops || old.Via[0].Latest != "v1.1.0" {
+		t.Errorf("Via[0] = %+v, want a/fixable dropping it at v1.1.0", old.Via[0])
+	}
+	if old.Via[1].Drops {
+		t.Errorf("Via[1] = %+v, b/stuck does not list x/old in go.mod", old.Via[1])
+	}
+
+	dead := findings[1]
+	if dead.Module != "github.com/y/dead" {
+		t.Fatalf("findings[1].Module = %q, want github.com/y/dead", dead.Module)
+	}
+	if dead.Actionable() {
+		t.Error("y/dead should be unavoidable: b/stuck latest still requires it")
+	}
+}
+
+func TestAnalyzeUpgrades_SkipsArchivedDirect(t *testing.T) {
+	srv := upgradeTestProxy(t)
+	defer srv.Close()
+
+	results, graph, allModules := upgradeTestInputs()
+	results[0].IsArchived = true // a/fixable itself archived; only indirect deps are scored
+
+	r := &resolver{client: srv.Client(), proxyBaseURL: srv.URL}
+	for _, f := range analyzeUpgrades(results, graph, allModules, r, 4) {
+		if f.Module == "github.com/a/fixable" {
+			t.Error("archived direct dependency should not be scored")
+		}
+	}
+}
+
+func TestGoModRequires(t *testing.T) {
+	body := "module example.com/m\n\ngo 1.21\n\nrequire (\n\tgithub.com/a/b v1.0.0\n\tgithub.com/c/d v0.1.0 // indirect\n)\n"
+	reqs := goModRequires(body)
+	if len(reqs) != 2 || !reqs["github.com/a/b"] || !reqs["github.com/c/d"] {
+		t.Errorf("goModRequires() = %v", reqs)
+	}
+	if got := goModRequires(""); got != nil {
+		t.Errorf("goModRequires(\"\") = %v, want nil", got)
+	}
+}
+
+func TestPrintUpgradeTable(t *testing.T) {
+	findings := []UpgradeFinding{
+		{Module: "github.com/x/old", Version: "v0.1.0", Via: []UpgradeVia{
+			{Path: "github.com/a/fixable", Version: "v1.0.0", Latest: "v1.1.0", Drops: true},
+			{Path: "github.com/b/stuck", Version: "v1.0.0", Latest: "v2.0.0"},
+		}},
+		{Module: "github.com/y/dead", Version: "v0.2.0", Via: []UpgradeVia{
+			{Path: "github.com/b/stuck", Version: "v1.0.0", Latest: "v2.0.0"},
+		}},
+	}
+
+	output := captureStdout(t, func() {
+		PrintUpgradeTable(findings)
+	})
+
+	if !strings.Contains(output, "github.com/a/fixable v1.0.0 → v1.1.0") {
+		t.Errorf("expected upgrade suggestion, got:\n%s", output)
+	}
+	if strings.Contains(output, "github.com/x/old  v0.1.0  github.com/b/stuck") {
+		t.Errorf("actionable row should list only dropping upgrades, got:\n%s", output)
+	}
+	if !strings.Contains(output, "github.com/b/stuck@v1.0.0") {
+		t.Errorf("expected unavoidable via path, got:\n%s", output)
+	}
+	if !strings.Contains(output, "\ngo get github.com/a/fixable@v1.1.0 && go mod tidy\n") {
+		t.Errorf("expected go get command, got:\n%s", output)
+	}
+	if strings.Contains(output, "go get github.com/b/stuck") {
+		t.Errorf("non-dropping upgrade should get no command, got:\n%s", output)
+	}
+}
+
+func TestUpgradeCommands(t *testing.T) {
+	findings := []UpgradeFinding{
+		{Module: "github.com/x/old", Via: []UpgradeVia{
+			{Path: "github.com/b/fixable", Version: "v1.0.0", Latest: "v1.2.0", Drops: true},
+		}},
+		{Module: "github.com/y/older", Via: []UpgradeVia{
+			{Path: "github.com/a/fixable", Version: "v0.3.0", Latest: "v0.4.0", Drops: true},
+			{Path: "github.com/b/fixable", Version: "v1.0.0", Latest: "v1.2.0", Drops: true},
+		}},
+		{Module: "github.com/z/stuck", Via: []UpgradeVia{
+			{Path: "github.com/c/stuck", Version: "v1.0.0", Latest: "v1.1.0"},
+		}},
+	}
+	want := []string{
+		"go get github.com/a/fixable@v0.4.0 && go mod tidy",
+		"go get github.com/b/fixable@v1.2.0 && go mod tidy",
+	}
+	if got := upgradeCommands(findings); !slices.Equal(got, want) {
+		t.Errorf("upgradeCommands = %q, want %q", got, want)
+	}
+	if got := upgradeCommands(nil); len(got) != 0 {
+		t.Errorf("upgradeCommands(nil) = %q, want none", got)
+	}
+}
+
+func TestBuildJSONUpgrades(t *testing.T) {
+	if buildJSONUpgrades(nil) != nil {
+		t.Error("nil findings (analysis not run) should omit the section")
+	}
+
+	out := buildJSONUpgrades([]UpgradeFinding{})
+	data, _ := json.Marshal(out)
+	if string(data) != `{"actionable":[],"unavoidable":[],"commands":[]}` {
+		t.Errorf("empty analysis = %s", data)
+	}
+
+	out = buildJSONUpgrades([]UpgradeFinding{
+		{Module: "github.com/x/old", Version: "v0.1.0", Via: []UpgradeVia{
+			{Path: "github.com/a/fixable", Version: "v1.0.0", Latest: "v1.1.0", Drops: true},
+		}},
+	})
+	if len(out.Actionable) != 1 || len(out.Unavoidable) != 0 {
+		t.Fatalf("buckets = %+v", out)
+	}
+	if v := out.Actionable[0].Via[0]; !v.DropsArchived || v.LatestVersion != "v1.1.0" {
+		t.Errorf("via = %+v", v)
+	}
+	if want := []string{"go get github.com/a/fixable@v1.1.0 && go mod tidy"}; !slices.Equal(out.Commands, want) {
+		t.Errorf("commands = %q, want %q", out.Commands, want)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Usage kinds reported in FileMatch.UsageKind.
+const (
+	usageCall       = "call"        // package functions are invoked or package values used
+	usageTypeOnly   = "type-only"   // package is referenced only in type positions
+	usageSideEffect = "side-effect" // blank import (_)
+	usageUnknown    = "unknown"     // dot import, unparsable file, or no references found
+)
+
+// annotateUsageKinds parses each matched file once and records on every
+// match whether the imported package is called into or only used for its
+// types. Type-only users usually have a far easier migration path.
+func annotateUsageKinds(projectDir string, fileMatches map[string][]FileMatch) {
+	parsed := make(map[string]*ast.File)
+	fset := token.NewFileSet()
+	for _, matches := range fileMatches {
+		for i := range matches {
+			name := matches[i].File
+			f, ok := parsed[name]
+			if !ok {
+				f, _ = parser.ParseFile(fset, filepath.Join(projectDir, name), nil, parser.SkipObjectResolution)
+				parsed[name] = f
+			}
+			matches[i].UsageKind = classifyImportUsage(f, matches[i].ImportPath)
+		}
+	}
+}
+
+// classifyImportUsage returns the usage kind of importPath within f.
+// Without type information, conversions such as pkg.T(x) are
+// indistinguishable from calls and count as calls.
+func classifyImportUsage(f *ast.File, importPath string) string {
+	if f == nil {
+		return usageUnknown
+	}
+
+	var name string
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || p != importPath {
+			continue
+		}
+		if spec.Name != nil {
+			name = spec.Name.Name
+		} else {
+			name = guessPackageName(importPath)
+		}
+		break
+	}
+	switch name {
+	case "":
+		return usageUnknown
+	case "_":
+		return usageSideEffect
+	case ".":
+		return usageUnknown
+	}
+
+	typeSels := typePositionSelectors(f)
+	refs := 0
+	kind := usageTypeOnly
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok && id.Name == name {
+			refs++
+			if !typeSels[sel] {
+				kind = usageCall
+			}
+		}
+		return kind != usageCall
+	})
+	if refs == 0 {
+		return usageUnknown
+	}
+	return kind
+}
+
+// typePositionSelectors returns every selector expression that appears in
+// a type position: field, parameter, and result types, var and type
+// declarations, composite literal types, type assertions and type switch
+// cases, the type argument of new and make, and the type arguments of
+// generic instantiations. A single index is only taken for a type argument
+// when the indexed expression is called (pkg.Map[pkg.T](x)): elsewhere
+// x[pkg.K] is more likely an index by a package constant.
+func typePositionSelectors(f *ast.File) map[*ast.SelectorExpr]bool {
+	sels := make(map[*ast.SelectorExpr]bool)
+	mark := func(e ast.Expr) {
+		if e == nil {
+			return
+		}
+		ast.Inspect(e, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				sels[sel] = true
+			}
+			return true
+		})
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Field:
+			mark(x.Type)
+		case *ast.ValueSpec:
+			mark(x.Type)
+		case *ast.TypeSpec:
+			mark(x.Type)
+		case *ast.CompositeLit:
+			mark(x.Type)
+		case *ast.TypeAssertExpr:
+			mark(x.Type)
+		case *ast.TypeSwitchStmt:
+			for _, stmt := range x.Body.List {
+				for _, e := range stmt.(*ast.CaseClause).List {
+					mark(e)
+				}
+			}
+		case *ast.IndexListExpr:
+			for _, e := range x.Indices {
+				mark(e)
+			}
+		case *ast.CallExpr:
+			if id, ok := x.Fun.(*ast.Ident); ok && (id.Name == "new" || id.Name == "make") && len(x.Args) > 0 {
+				mark(x.Args[0])
+			}
+			if ix, ok := x.Fun.(*ast.IndexExpr); ok {
+				mark(ix.Index)
+			}
+		}
+		return true
+	})
+	return sels
+}
+
+var (
+	majorVersionElem = regexp.MustCompile(`^v[0-9]+$`)
+	gopkgVersion     = regexp.MustCompile(`\.v[0-9]+$`)
+)
+
+// guessPackageName derives the conventional package name from an import
+// path: the last element without a major version suffix (/v2, .v3) or a
+// go- prefix / -go suffix, with remaining dashes dropped.
+func guessPackageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if majorVersionElem.MatchString(name) && len(elems) > 1 {
+		name = elems[len(elems)-2]
+	}
+	name = gopkgVersion.ReplaceAllString(name, "")
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	return strings.ReplaceAll(name, "-", "")
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifyImportUsage(t *testing.T) {
+	tests := []struct {
+		name       string
+		importPath string
+		src        string
+		want       string
+	}{
+		{
+			name:       "function call",
+			importPath: "github.com/pkg/errors",
+			src: `package p
+import "github.com/pkg/errors"
+func f() error { return errors.New("x") }
+`,
+			want: usageCall,
+		},
+		{
+			name:       "package value",
+			importPath: "github.com/pkg/errors",
+			src: `package p
+import "github.com/pkg/errors"
+var sentinel = errors.ErrUnsupported
+`,
+			want: usageCall,
+		},
+		{
+			name:       "types only",
+			importPath: "github.com/mitchellh/mapstructure",
+			src: `package p
+import "github.com/mitchellh/mapstructure"
+type cfg struct {
+	hook mapstructure.DecodeHookFunc
+	md   *mapstructure.Metadata
+}
+var m map[string]mapstructure.Metadata
+func f(d mapstructure.DecoderConfig) (mapstructure.Metadata, bool) {
+	var x interface{} = d
+	_, ok := x.(mapstructure.Metadata)
+	return mapstructure.Metadata{}, ok && new(mapstructure.Metadata) != nil
+}
+`,
+			want: usageTypeOnly,
+		},
+		{
+			name:       "type and call",
+			importPath: "github.com/mitchellh/mapstructure",
+			src: `package p
+import "github.com/mitchellh/mapstructure"
+func f(in any) (mapstructure.Metadata, error) {
+	var md mapstructure.Metadata
+	return md, mapstructure.Decode(in, &md)
+}
+`,
+			want: usageCall,
+		},
+		{
+			name:       "type switch",
+			importPath: "github.com/mitchellh/mapstructure",
+			src: `package p
+import "github.com/mitchellh/mapstructure"
+func f(x any) bool {
+	switch x.(type) {
+	case mapstructure.Metadata, *mapstructure.DecoderConfig:
+		return true
+	case nil:
+	}
+	return false
+}
+`,
+			want: usageTypeOnly,
+		},
+		{
+			name:       "generic instantiation",
+			importPath: "github.com/mitchellh/mapstructure",
+			src: `package p
+import "github.com/mitchellh/mapstructure"
+func zero[T any]() (t T) { return }
+func pair[K, V any](k K, v V) {}
+var md = zero[mapstructure.Metadata]()
+var p = pair[string, *mapstructure.DecoderConfig]
+`,
+			want: usageTypeOnly,
+		},
+		{
+			name:       "index by package constant",
+			importPath: "example.com/limits",
+			src: `package p
+import "example.com/limits"
+var sizes [8]int
+var n = sizes[limits.Small]
+`,
+			want: usageCall,
+		},
+		{
+			name:       "aliased import",
+			importPath: "gopkg.in/yaml.v2",
+			src: `package p
+import yml "gopkg.in/yaml.v2"
+type doc struct{ n yml.Node }
+`,
+			want: usageTypeOnly,
+		},
+		{
+			name:       "blank import",
+			importPath: "github.com/lib/pq",
+			src: `package p
+import _ "github.com/lib/pq"
+`,
+			want: usageSideEffect,
+		},
+		{
+			name:       "dot import",
+			importPath: "github.com/onsi/gomega",
+			src: `package p
+import . "github.com/onsi/gomega"
+var _ = Expect
+`,
+			want: usageUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), "p.go", tt.src, parser.SkipObjectResolution)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := classifyImportUsage(f, tt.importPath); got != tt.want {
+				t.Errorf("classifyImportUsage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyImportUsage_NilFile(t *testing.T) {
+	if got := classifyImportUsage(nil, "github.com/pkg/errors"); got != usageUnknown {
+		t.Errorf("classifyImportUsage(nil) = %q, want %q", got, usageUnknown)
+	}
+}
+
+func TestGuessPackageName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"github.com/pkg/errors", "errors"},
+		{"github.com/foo/bar/v2", "bar"},
+		{"gopkg.in/yaml.v3", "yaml"},
+		{"github.com/mitchellh/go-homedir", "homedir"},
+		{"github.com/hashicorp/go-discover/provider/aws", "aws"},
+		{"github.com/aws/aws-sdk-go", "awssdk"},
+	}
+	for _, tt := range tests {
+		if got := guessPackageName(tt.path); got != tt.want {
+			t.Errorf("guessPackageName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotateUsageKinds(t *testing.T) {
+	dir := t.TempDir()
+	src := `package p
+
+import (
+	"github.com/pkg/errors"
+	"github.com/mitchellh/mapstructure"
+)
+
+func f(md mapstructure.Metadata) error { return errors.New("x") }
+`
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fileMatches := map[string][]FileMatch{
+		"github.com/pkg/errors":             {{File: "p.go", Line: 4, ImportPath: "github.com/pkg/errors"}},
+		"github.com/mitchellh/mapstructure": {{File: "p.go", Line: 5, ImportPath: "github.com/mitchellh/mapstructure"}},
+		"github.com/gone/away":              {{File: "missing.go", Line: 1, ImportPath: "github.com/gone/away"}},
+	}
+	annotateUsageKinds(dir, fileMatches)
+
+	if got := fileMatches["github.com/pkg/errors"][0].UsageKind; got != usageCall {
+		t.Errorf("errors UsageKind = %q, want %q", got, usageCall)
+	}
+	if got := fileMatches["github.com/mitchellh/mapstructure"][0].UsageKind; got != usageTypeOnly {
+		t.Errorf("mapstructure UsageKind = %q, want %q", got, usageTypeOnly)
+	}
+	if got := fileMatches["github.com/gone/away"][0].UsageKind; got != usageUnknown {
+		t.Errorf("missing file UsageKind = %q, want %q", got, usageUnknown)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/norman-abramovitz/modrot/report"
+)
+
+// directVCSHosts are the hosts the go command knows how to fetch from
+// without a go-import meta tag, so their modules need no vanity page.
+var directVCSHosts = map[string]bool{
+	"github.com":        true,
+	"bitbucket.org":     true,
+	"hub.jazz.net":      true,
+	"git.apache.org":    true,
+	"git.openstack.org": true,
+	"chiselapp.com":     true,
+}
+
+// Problems --lint-vanity reports for a module's import path.
+const (
+	vanityUnreachable = "unreachable"  // the ?go-get=1 page could not be fetched
+	vanityNoGoImport  = "no-go-import" // the page has no go-import tag for the module
+)
+
+// vanityFinding is a dependency whose import path no longer resolves
+// without the module proxy.
+type vanityFinding struct {
+	Module  Module
+	Problem string // vanityUnreachable or vanityNoGoImport
+}
+
+// needsVanityPage reports whether the go command resolves modulePath
+// through a ?go-get=1 page: it is not on a host with built-in VCS support
+// and carries no explicit VCS qualifier such as ".git".
+func needsVanityPage(modulePath string) bool {
+	host, _, _ := strings.Cut(modulePath, "/")
+	if directVCSHosts[strings.ToLower(host)] {
+		return false
+	}
+	for _, ext := range []string{".git", ".hg", ".svn", ".bzr", ".fossil"} {
+		if strings.HasSuffix(modulePath, ext) || strings.Contains(modulePath, ext+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// servesGoImport reports whether a vanity page has a go-import meta tag
+// whose import prefix covers modulePath.
+func servesGoImport(body, modulePath string) bool {
+	for _, match := range metaRe.FindAllStringSubmatch(body, -1) {
+		var name, content string
+		for _, p := range attrRe.FindAllStringSubmatch(match[1], -1) {
+			switch strings.ToLower(p[1]) {
+			case "name":
+				name = p[2]
+			case "content":
+				content = p[2]
+			}
+		}
+		if name != "go-import" {
+			continue
+		}
+		fields := strings.Fields(content)
+		if len(fields) >= 3 && (modulePath == fields[0] || strings.HasPrefix(modulePath, fields[0]+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintVanityHosts fetches the vanity page of every module that needs one,
+// for --lint-vanity, and returns the modules whose host no longer serves a
+// go-import tag for them, sorted by path. The proxy may still cache such
+// modules, but GOPROXY=direct builds fail on them, and a lapsed vanity
+// domain often precedes formal abandonment.
+func lintVanityHosts(cfg *Config, modules []Module, r *resolver) []vanityFinding {
+	var check []Module
+	seen := make(map[string]bool)
+	for _, m := range modules {
+		if (cfg.DirectOnly && !m.Direct) || seen[m.Path] || !needsVanityPage(m.Path) {
+			continue
+		}
+		seen[m.Path] = true
+		check = append(check, m)
+	}
+	if len(check) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Checking vanity import pages of %d %s...\n", len(check), pluralize(len(check), "module", "modules"))
+	problems, _ := mapPool(context.Background(), check, poolOptions{Workers: 20}, func(_ context.Context, m Module) (string, error) {
+		if !r.hostReachable(m.Path) {
+			return vanityUnreachable, nil
+		}
+		body, ok := r.get("https://" + m.Path + "?go-get=1")
+		if !ok {
+			body, ok = r.getInsecure(m.Path)
+		}
+		switch {
+		case !ok:
+			return vanityUnreachable, nil
+		case !servesGoImport(string(body), m.Path):
+			return vanityNoGoImport, nil
+		}
+		return "", nil
+	})
+
+	var found []vanityFinding
+	for i, p := range problems {
+		if p != "" {
+			found = append(found, vanityFinding{Module: check[i], Problem: p})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Module.Path < found[j].Module.Path })
+	return found
+}
+
+var vanityHeaders = []string{"Module", "Version", "Direct", "Problem"}
+
+// vanityRows formats dead vanity import paths as table rows.
+func vanityRows(found []vanityFinding) [][]string {
+	rows := make([][]string, len(found))
+	for i, f := range found {
+		direct := "no"
+		if f.Module.Direct {
+			direct = "yes"
+		}
+		rows[i] = []string{f.Module.Path, f.Module.Version, direct, vanityProblemText(f.Problem)}
+	}
+	return rows
+}
+
+// vanityProblemText describes a --lint-vanity problem for tables.
+func vanityProblemText(problem string) string {
+	if problem == vanityNoGoImport {
+		return "no go-import meta tag"
+	}
+	return "vanity page unreachable"
+}
+
+// PrintVanityTable outputs dependencies whose vanity host is dead.
+func PrintVanityTable(found []vanityFinding) {
+	if len(found) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nDEAD VANITY IMPORT PATHS (%d %s break GOPROXY=direct builds)\n\n",
+		len(found), pluralize(len(found), "module would", "modules would"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(vanityHeaders))
+	for _, row := range vanityRows(found) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownVanity outputs dependencies whose vanity host is dead in
+// Markdown format.
+func PrintMarkdownVanity(found []vanityFinding) {
+	if len(found) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## DEAD VANITY IMPORT PATHS (%d)\n\n", len(found))
+	printMarkdownTable(os.Stdout, vanityHeaders, vanityRows(found))
+}
+
+// JSONDeadVanity is a dependency whose vanity host is dead in JSON output.
+type JSONDeadVanity = report.DeadVanity
+
+// buildVanityJSON converts dead vanity import paths for JSON output.
+func buildVanityJSON(found []vanityFinding) []JSONDeadVanity {
+	var out []JSONDeadVanity
+	for _, f := range found {
+		out = append(out, JSONDeadVanity{
+			FindingID: findingID(findingDeadVanity, f.Module.Path),
+			Module:    f.Module.Path,
+			Version:   f.Module.Version,
+			Direct:    f.Module.Direct,
+			Problem:   f.Problem,
+		})
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNeedsVanityPage(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"github.com/foo/bar", false},
+		{"GitHub.com/foo/bar", false},
+		{"bitbucket.org/foo/bar", false},
+		{"example.com/repo.git/sub", false},
+		{"example.com/repo.hg", false},
+		{"go.uber.org/zap", true},
+		{"golang.org/x/mod", true},
+		{"gitlab.com/foo/bar", true},
+	}
+	for _, tt := range tests {
+		if got := needsVanityPage(tt.path); got != tt.want {
+			t.Errorf("needsVanityPage(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestServesGoImport(t *testing.T) {
+	page := `<html><head>
+<meta name="go-import" content="go.example.com/lib git https://github.com/example/lib">
+<meta name="go-source" content="go.example.com/lib https://github.com/example/lib _ _">
+</head></html>`
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"go.example.com/lib", true},
+		{"go.example.com/lib/v2", true},
+		{"go.example.com/library", false},
+		{"go.example.com/other", false},
+	}
+	for _, tt := range tests {
+		if got := servesGoImport(page, tt.path); got != tt.want {
+			t.Errorf("servesGoImport(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if servesGoImport("<html></html>", "go.example.com/lib") {
+		t.Error("expected a page without meta tags not to serve go-import")
+	}
+}
+
+func TestLintVanityHosts(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/alive":
+			_, _ = fmt.Fprintf(w, `<meta name="go-import" content="%s/alive git https://github.com/x/alive">`, r.Host)
+		case "/parked":
+			_, _ = fmt.Fprint(w, "<html><body>This domain is for sale</body></html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	modules := []Module{
+		{Path: host + "/alive", Version: "v1.0.0", Direct: true},
+		{Path: host + "/parked", Version: "v0.3.0"},
+		{Path: host + "/gone", Version: "v0.1.0", Direct: true},
+		{Path: host + "/gone", Version: "v0.1.0", Direct: true}, // checked once
+		{Path: "github.com/foo/bar", Version: "v1.0.0", Direct: true, Owner: "foo", Repo: "bar"},
+	}
+	r := &resolver{client: srv.Client(), proxyBaseURL: "http://unused"}
+
+	found := lintVanityHosts(defaultTestConfig(), modules, r)
+	want := []vanityFinding{
+		{Module: modules[2], Problem: vanityUnreachable},
+		{Module: modules[1], Problem: vanityNoGoImport},
+	}
+	if len(found) != len(want) {
+		t.Fatalf("lintVanityHosts() = %+v, want %+v", found, want)
+	}
+	for i := range want {
+		if found[i].Module.Path != want[i].Module.Path || found[i].Problem != want[i].Problem {
+			t.Errorf("finding %d = %s %s, want %s %s", i, found[i].Module.Path, found[i].Problem, want[i].Module.Path, want[i].Problem)
+		}
+	}
+
+	cfg := defaultTestConfig()
+	cfg.DirectOnly = true
+	if found := lintVanityHosts(cfg, modules, r); len(found) != 1 || found[0].Module.Path != host+"/gone" {
+		t.Errorf("with --direct-only: %+v, want only %s/gone", found, host)
+	}
+}
+
+func TestBuildVanityJSON(t *testing.T) {
+	found := []vanityFinding{{Module: Module{Path: "go.dead.dev/x", Version: "v1.2.3", Direct: true}, Problem: vanityNoGoImport}}
+	out := buildVanityJSON(found)
+	if len(out) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(out))
+	}
+	got := out[0]
+	if got.Module != "go.dead.dev/x" || got.Version != "v1.2.3" || !got.Direct || got.Problem != "no-go-import" {
+		t.Errorf("buildVanityJSON() = %+v", got)
+	}
+	if got.FindingID != findingID(findingDeadVanity, "go.dead.dev/x") || !strings.HasPrefix(got.FindingID, "dead_vanity_host-") {
+		t.Errorf("FindingID = %q", got.FindingID)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// splitVendoredForked separates archived results whose module is vendored
+// and carries local patches in vendor/ ("vendored-forked"). The project owns
+// that code, so these do not count as archived failures unless
+// --include-vendored-forked is set. Returns the remaining results and the
+// vendored-forked ones.
+func splitVendoredForked(cfg *Config, results []RepoStatus, dir string) ([]RepoStatus, []RepoStatus) {
+	if cfg.IncludeVendoredForked {
+		return results, nil
+	}
+	vendored, err := readVendoredModules(dir)
+	if err != nil || len(vendored) == 0 {
+		return results, nil
+	}
+
+	var kept, forked []RepoStatus
+	for _, r := range results {
+		if !r.IsArchived || vendored[r.Module.Path] == "" {
+			kept = append(kept, r)
+			continue
+		}
+		srcDir, err := downloadModuleDir(dir, r.Module.Path, vendored[r.Module.Path])
+		if err != nil {
+			warnDegraded(cfg, "go mod download", "could not verify vendored %s: %v", r.Module.Path, err)
+			kept = append(kept, r)
+			continue
+		}
+		modified, err := vendorModified(dir, r.Module.Path, srcDir, vendored)
+		if err != nil {
+			warnDegraded(cfg, "vendor", "could not compare vendored %s: %v", r.Module.Path, err)
+			kept = append(kept, r)
+			continue
+		}
+		if modified {
+			forked = append(forked, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	if len(forked) > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Excluded %d vendored-forked %s (use --include-vendored-forked to fail on them).\n",
+			len(forked), pluralize(len(forked), "module", "modules"))
+	}
+	return kept, forked
+}
+
+// readVendoredModules parses vendor/modules.txt and returns module path →
+// version for every vendored module. Returns nil if there is no vendor
+// directory.
+//
+//	# github.com/pkg/errors v0.9.1
+//	## explicit
+//	github.com/pkg/errors
+func readVendoredModules(dir string) (map[string]string, error) {
+	f, err := os.Open(filepath.Join(dir, "vendor", "modules.txt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	mods := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "# ") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "# "))
+		// Replaced modules ("# path version => ...") still vendor under path.
+		if len(fields) >= 2 && strings.HasPrefix(fields[1], "v") {
+			mods[fields[0]] = fields[1]
+		}
+	}
+	return mods, scanner.Err()
+}
+
+// downloadModuleDir returns the module cache directory for path@version,
+// downloading it if needed. go mod download verifies the content against
+// go.sum, so the directory is the pristine upstream source.
+func downloadModuleDir(dir, modPath, version string) (string, error) {
+	cmd := exec.CommandContext(runCtx, "go", "mod", "download", "-json", modPath+"@"+version)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go mod download: %w", err)
+	}
+	var info struct {
+		Dir   string
+		Error string
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return "", err
+	}
+	if info.Error != "" {
+		return "", fmt.Errorf("%s", info.Error)
+	}
+	return info.Dir, nil
+}
+
+// vendorModified reports whether any file under vendor/<modPath> differs
+// from, or does not exist in, the pristine module source in srcDir.
+// Subdirectories that are themselves vendored modules (e.g. a /v2 path)
+// are skipped.
+func vendorModified(dir, modPath, srcDir string, vendored map[string]string) (bool, error) {
+	root := filepath.Join(dir, "vendor", filepath.FromSlash(modPath))
+	modified := false
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		if d.IsDir() {
+			if rel != "." && vendored[modPath+"/"+filepath.ToSlash(rel)] != "" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		same, err := sameFileContent(path, filepath.Join(srcDir, rel))
+		if err != nil {
+			return err
+		}
+		if !same {
+			modified = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return modified, err
+}
+
+// sameFileContent reports whether two files have identical content.
+// A missing b counts as different.
+func sameFileContent(a, b string) (bool, error) {
+	ha, err := fileSHA256(a)
+	if err != nil {
+		return false, err
+	}
+	hb, err := fileSHA256(b)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ha, hb), nil
+}
+
+// fileSHA256 returns the SHA-256 digest of a file's content.
+func fileSHA256(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// vendoredForkedRows formats vendored-forked modules as table rows.
+func vendoredForkedRows(cfg *Config, forked []RepoStatus) [][]string {
+	sort.Slice(forked, func(i, j int) bool {
+		return forked[i].Module.Path < forked[j].Module.Path
+	})
+	rows := make([][]string, len(forked))
+	for i, r := range forked {
+		rows[i] = []string{r.Module.Path, r.Module.Version, directLabel(r.Module), fmtDate(cfg, r.ArchivedAt)}
+	}
+	return rows
+}
+
+var vendoredForkedHeaders = []string{"Module", "Version", "Direct", "Archived At"}
+
+// PrintVendoredForkedTable outputs archived modules patched in vendor/.
+func PrintVendoredForkedTable(cfg *Config, forked []RepoStatus) {
+	if len(forked) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\nVENDORED-FORKED (%d archived %s patched in vendor/, not counted as failures)\n\n",
+		len(forked), pluralize(len(forked), "module", "modules"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeTabRow(w, toUpper(vendoredForkedHeaders))
+	for _, row := range vendoredForkedRows(cfg, forked) {
+		writeTabRow(w, row)
+	}
+	_ = w.Flush()
+}
+
+// PrintMarkdownVendoredForked outputs vendored-forked modules in Markdown format.
+func PrintMarkdownVendoredForked(cfg *Config, forked []RepoStatus) {
+	if len(forked) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\n## VENDORED-FORKED (%d archived %s patched in vendor/)\n\n",
+		len(forked), pluralize(len(forked), "module", "modules"))
+	printMarkdownTable(os.Stdout, vendoredForkedHeaders, vendoredForkedRows(cfg, forked))
+}
+
+// buildVendoredForkedJSON converts vendored-forked modules for JSON output.
+func buildVendoredForkedJSON(forked []RepoStatus) []JSONModule {
+	var out []JSONModule
+	for _, r := range forked {
+		jm := JSONModule{
+			FindingID:  findingID(findingVendoredForked, r.Module.Path),
+			Module:     r.Module.Path,
+			Version:    r.Module.Version,
+			Direct:     r.Module.Direct,
+			Owner:      r.Module.Owner,
+			Repo:       r.Module.Repo,
+			RequiredAt: requiredAt(r.Module),
+		}
+		if !r.ArchivedAt.IsZero() {
+			jm.ArchivedAt = r.ArchivedAt.Format("2006-01-02T15:04:05Z")
+		}
+		jm.ArchivedAtSource, jm.ArchivedAtPrecision = archivedAtProvenance(r)
+		out = append(out, jm)
+	}
+	return out
+}
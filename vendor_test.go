@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadVendoredModules(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "vendor", "modules.txt"), `# github.com/pkg/errors v0.9.1
+## explicit
+github.com/pkg/errors
+# github.com/foo/bar v1.2.0 => ../bar
+## explicit; go 1.21
+github.com/foo/bar
+# github.com/local/thing => ./thing
+github.com/local/thing
+`)
+
+	mods, err := readVendoredModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mods) != 2 {
+		t.Fatalf("len(mods) = %d, want 2: %v", len(mods), mods)
+	}
+	if mods["github.com/pkg/errors"] != "v0.9.1" || mods["github.com/foo/bar"] != "v1.2.0" {
+		t.Errorf("mods = %v", mods)
+	}
+}
+
+func TestReadVendoredModules_NoVendor(t *testing.T) {
+	mods, err := readVendoredModules(t.TempDir())
+	if err != nil || mods != nil {
+		t.Errorf("readVendoredModules() = %v, %v; want nil, nil", mods, err)
+	}
+}
+
+func TestVendorModified(t *testing.T) {
+	dir := t.TempDir()
+	src := t.TempDir()
+	vendored := map[string]string{
+		"github.com/pkg/errors":    "v0.9.1",
+		"github.com/pkg/errors/v2": "v2.0.0",
+	}
+	writeTestFile(t, filepath.Join(src, "errors.go"), "package errors\n")
+	writeTestFile(t, filepath.Join(src, "stack.go"), "package errors\n// stack\n")
+	writeTestFile(t, filepath.Join(src, "errors_test.go"), "package errors\n")
+
+	vendorDir := filepath.Join(dir, "vendor", "github.com", "pkg", "errors")
+	writeTestFile(t, filepath.Join(vendorDir, "errors.go"), "package errors\n")
+	writeTestFile(t, filepath.Join(vendorDir, "stack.go"), "package errors\n// stack\n")
+	// Nested vendored module with different content must not count
+	writeTestFile(t, filepath.Join(vendorDir, "v2", "errors.go"), "package errors // v2\n")
+
+	modified, err := vendorModified(dir, "github.com/pkg/errors", src, vendored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified {
+		t.Error("pristine vendor copy (tests omitted) should not be modified")
+	}
+
+	writeTestFile(t, filepath.Join(vendorDir, "stack.go"), "package errors\n// patched\n")
+	if modified, _ := vendorModified(dir, "github.com/pkg/errors", src, vendored); !modified {
+		t.Error("patched file should be detected")
+	}
+
+	writeTestFile(t, filepath.Join(vendorDir, "stack.go"), "package errors\n// stack\n")
+	writeTestFile(t, filepath.Join(vendorDir, "patch.go"), "package errors\n")
+	if modified, _ := vendorModified(dir, "github.com/pkg/errors", src, vendored); !modified {
+		t.Error("file added in vendor/ should be detected")
+	}
+}
+
+func TestSplitVendoredForked_NoVendor(t *testing.T) {
+	cfg := defaultTestConfig()
+	results := []RepoStatus{{Module: Module{Path: "github.com/pkg/errors"}, IsArchived: true}}
+
+	kept, forked := splitVendoredForked(cfg, results, t.TempDir())
+	if len(kept) != 1 || forked != nil {
+		t.Errorf("kept = %v, forked = %v; want results unchanged", kept, forked)
+	}
+}
+
+func TestPrintVendoredForkedTable(t *testing.T) {
+	cfg := defaultTestConfig()
+	forked := []RepoStatus{{
+		Module:     Module{Path: "github.com/pkg/errors", Version: "v0.9.1", Direct: true},
+		IsArchived: true,
+		ArchivedAt: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
+	output := captureStdout(t, func() {
+		PrintVendoredForkedTable(cfg, forked)
+	})
+	for _, want := range []string{"MODULE", "github.com/pkg/errors", "v0.9.1", "direct", "2021-12-01"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+
+	jm := buildVendoredForkedJSON(forked)
+	if len(jm) != 1 || jm[0].ArchivedAt != "2021-12-01T00:00:00Z" {
+		t.Errorf("buildVendoredForkedJSON() = %+v", jm)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// warmStats counts what a cache warming run recorded.
+type warmStats struct {
+	Repos    int // unique repositories checked
+	Archived int
+	Active   int
+	Uncached int // not found or disabled: never cached
+	Unknown  int // check failed (rate limit, timeout): not cached, re-queried next run
+}
+
+// warmArchiveCache queries every repository in modules and records the
+// results in the archive cache at path. The --cache-ttl fast path is
+// bypassed so every entry gets a fresh check time; repositories archived
+// for over --archived-skip-days are left as they are, since runs answer for
+// them from the cache regardless of age.
+func warmArchiveCache(cfg *Config, path string, modules []Module, check func([]Module) ([]RepoStatus, error)) (warmStats, error) {
+	cfg.CacheTTL = 0
+	results, err := checkReposCachedWith(cfg, path, modules, check)
+	if err != nil {
+		return warmStats{}, err
+	}
+	st := warmStats{Repos: len(results)}
+	for _, r := range results {
+		switch {
+		case r.Unknown:
+			st.Unknown++
+		case r.NotFound || r.Disabled || r.Error != "":
+			st.Uncached++
+		case r.IsArchived:
+			st.Archived++
+		default:
+			st.Active++
+		}
+	}
+	return st, nil
+}
+
+// orgListPageSize is the per_page value used when listing an org's repos.
+const orgListPageSize = 100
+
+// warmOrgName returns the organization a warm-cache target names, as in
+// github.com/acme or https://github.com/acme: a GitHub host followed by a
+// single path segment, with no local file of that name.
+func warmOrgName(cfg *Config, target string) (string, bool) {
+	if !isRemoteSpec(cfg, target) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(strings.TrimPrefix(target, "https://"), "http://")
+	host, org, ok := strings.Cut(strings.TrimSuffix(rest, "/"), "/")
+	if !ok || host != "github.com" || !remotePathSegmentRe.MatchString(org) {
+		return "", false
+	}
+	return org, true
+}
+
+// expandOrgTargets replaces every organization target with the
+// github.com/org/repo specs of its repositories, as returned by list.
+// Other targets are kept as they are.
+func expandOrgTargets(cfg *Config, targets []string, list func(org string) ([]string, error)) ([]string, error) {
+	var out []string
+	for _, target := range targets {
+		org, ok := warmOrgName(cfg, target)
+		if !ok {
+			out = append(out, target)
+			continue
+		}
+		repos, err := list(org)
+		if err != nil {
+			return nil, fmt.Errorf("listing repositories of %s: %w", org, err)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "Found %d %s in %s.\n", len(repos), pluralize(len(repos), "repository", "repositories"), org)
+		for _, name := range repos {
+			out = append(out, "github.com/"+org+"/"+name)
+		}
+	}
+	return out, nil
+}
+
+// listOrgRepos returns the names of an organization's repositories via
+// GET /orgs/{org}/repos, following pages until a short one. Archived
+// repositories are left out: nothing scans them, so warming their
+// dependencies would only spend rate limit.
+func (g *ghClient) listOrgRepos(token, org string) ([]string, error) {
+	var names []string
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/orgs/%s/repos?type=all&per_page=%d&page=%d", g.restURL, org, orgListPageSize, page)
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+
+		resp, err := g.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("GitHub API request failed: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading response: %w", err)
+		}
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("GitHub API returned %d: %s", resp.StatusCode, string(body))
+		}
+		var repos []struct {
+			Name     string `json:"name"`
+			Archived bool   `json:"archived"`
+		}
+		if err := json.Unmarshal(body, &repos); err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+		for _, r := range repos {
+			if !r.Archived {
+				names = append(names, r.Name)
+			}
+		}
+		if len(repos) < orgListPageSize {
+			return names, nil
+		}
+	}
+}
+
+// warmTargetModules parses every go.mod under each target (a directory,
+// a go.mod file, or a remote repository spec, which is cloned for the
+// duration of the run) into moduleInfo entries.
+func warmTargetModules(cfg *Config, targets []string) ([]moduleInfo, []*workspace, error) {
+	var modules []moduleInfo
+	var workspaces []*workspace
+	for _, target := range targets {
+		dir := target
+		if isRemoteSpec(cfg, target) {
+			d, ws, err := fetchRemote(cfg, target)
+			if err != nil {
+				return nil, workspaces, err
+			}
+			workspaces = append(workspaces, ws)
+			dir = d
+		} else if info, err := os.Stat(target); err != nil {
+			return nil, workspaces, err
+		} else if !info.IsDir() {
+			dir = filepath.Dir(target)
+		}
+
+		gomodPaths, err := findGoModFiles(dir)
+		if err != nil {
+			return nil, workspaces, err
+		}
+		if len(gomodPaths) == 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: no go.mod files found in %s\n", target)
+		}
+		for _, gp := range gomodPaths {
+			allMods, err := ParseGoMod(gp)
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", gp, err)
+				continue
+			}
+			modules = append(modules, moduleInfo{gomodPath: gp, relPath: gp, allModules: allMods})
+		}
+	}
+	return modules, workspaces, nil
+}
+
+// runWarmCache implements `modrot warm-cache [--workers N] [--token-env VAR]
+// [dir | host/owner/repo | github.com/org ...]`: resolves and queries every
+// dependency of the targets purely to populate the archive cache, with no
+// report. Run
+// nightly, it lets interactive and PR-time scans within --cache-ttl be
+// answered from the cache alone.
+// Returns exit code: 0 = success, 2 = error.
+func runWarmCache(args []string) int {
+	fs := flag.NewFlagSet("warm-cache", flag.ContinueOnError)
+	workers := fs.Int("workers", 50, "Number of repos per GitHub GraphQL batch request")
+	tokenEnvFlag := fs.String("token-env", "", "Read the GitHub token from this environment variable instead of gh auth token")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: modrot warm-cache [--workers N] [--token-env VAR] [dir | host/owner/repo | github.com/org ...]
+
+Check every dependency of every go.mod under the targets (default: the
+current directory) against GitHub and record the results in the archive
+cache, without printing a report. A github.com/org target stands for every
+non-archived repository of the organization, each cloned for the run.
+Vanity import paths are resolved first.
+Meant for a nightly job on the machine or CI cache that interactive and
+PR-time scans share: while the entries are younger than --cache-ttl (default
+%s), those scans need no GitHub round trip. Repos that are not found or
+disabled are never cached, so scans depending on them still query GitHub;
+neither are repos whose check failed (rate limit, timeout).
+
+  --workers int     Number of repos per GitHub GraphQL batch request (default 50)
+  --token-env VAR   Read the GitHub token from this environment variable
+`, defaultCacheTTL)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *workers < 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --workers must be at least 1\n")
+		return 2
+	}
+
+	path := archiveCachePath()
+	if path == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: no usable cache directory (run modrot doctor)\n")
+		return 2
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.Workers = *workers
+	tokenEnv = *tokenEnvFlag
+	start := time.Now()
+
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+	targets, err := expandOrgTargets(cfg, targets, func(org string) ([]string, error) {
+		token, err := getGHToken()
+		if err != nil {
+			return nil, err
+		}
+		return newGHClient().listOrgRepos(token, org)
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	modules, workspaces, err := warmTargetModules(cfg, targets)
+	defer func() {
+		for _, ws := range workspaces {
+			ws.Cleanup()
+		}
+	}()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if resolved := resolveAcrossModulesWithResolver(modules, newResolver()); resolved > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Resolved %d non-GitHub modules to GitHub repos.\n", resolved)
+	}
+	var all []Module
+	for _, mi := range modules {
+		all = append(all, mi.allModules...)
+	}
+	githubModules, _ := FilterGitHub(all, false)
+	if len(githubModules) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "No GitHub modules found.\n")
+		return 0
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Checking %d GitHub repos from %d go.mod %s...\n",
+		len(githubModules), len(modules), pluralize(len(modules), "file", "files"))
+	st, err := warmArchiveCache(cfg, path, githubModules, func(ms []Module) ([]RepoStatus, error) {
+		return CheckRepos(ms, cfg.Workers)
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Cached %d %s (%d archived, %d active; %d not found or disabled, %d unknown, not cached) in %s: %s\n",
+		st.Archived+st.Active, pluralize(st.Archived+st.Active, "repo", "repos"), st.Archived, st.Active, st.Uncached, st.Unknown,
+		time.Since(start).Round(time.Millisecond), path)
+	return 0
+}
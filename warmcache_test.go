@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWarmArchiveCache(t *testing.T) {
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "archived.json")
+	if err := saveArchiveCache(path, &archiveCache{
+		Version: archiveCacheVersion,
+		Repos: map[string]archiveCacheEntry{
+			// Fresh entries must be re-checked anyway.
+			"fresh/repo": {Active: true, CheckedAt: now.Add(-time.Hour)},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	modules := []Module{
+		{Path: "github.com/fresh/repo", Owner: "fresh", Repo: "repo"},
+		{Path: "github.com/dead/repo", Owner: "dead", Repo: "repo"},
+		{Path: "github.com/gone/repo", Owner: "gone", Repo: "repo"},
+		{Path: "github.com/flaky/repo", Owner: "flaky", Repo: "repo"},
+	}
+	var queried []string
+	check := func(ms []Module) ([]RepoStatus, error) {
+		out := make([]RepoStatus, len(ms))
+		for i, m := range ms {
+			queried = append(queried, m.Path)
+			out[i] = RepoStatus{Module: m, PushedAt: now.AddDate(0, -1, 0)}
+			switch m.Owner {
+			case "dead":
+				out[i].IsArchived, out[i].ArchivedAt = true, now.AddDate(0, 0, -2)
+			case "gone":
+				out[i].NotFound = true
+			case "flaky":
+				out[i].Unknown, out[i].Error = true, "timeout"
+			}
+		}
+		return out, nil
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.Now = now
+	st, err := warmArchiveCache(cfg, path, modules, check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queried) != 4 {
+		t.Errorf("queried %v, want all 4 repos", queried)
+	}
+	if want := (warmStats{Repos: 4, Archived: 1, Active: 1, Uncached: 1, Unknown: 1}); st != want {
+		t.Errorf("stats = %+v, want %+v", st, want)
+	}
+
+	cache := loadArchiveCache(path)
+	if e := cache.Repos["fresh/repo"]; !e.CheckedAt.Equal(now) {
+		t.Errorf("fresh/repo CheckedAt = %v, want %v", e.CheckedAt, now)
+	}
+
+	// A later interactive run over the warmed repos needs no GitHub query.
+	later := NewDefaultConfig()
+	later.Now = now.Add(time.Hour)
+	if _, ok := serveFromCache(later, cache, modules[:2]); !ok {
+		t.Error("expected the warmed cache to answer a run without GitHub")
+	}
+}
+
+func TestWarmTargetModules(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(rel, content string) {
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("go.mod", "module example.com/a\n\ngo 1.21\n\nrequire github.com/foo/bar v1.0.0\n")
+	writeFile("tools/go.mod", "module example.com/a/tools\n\ngo 1.21\n\nrequire github.com/baz/qux v0.1.0\n")
+
+	modules, workspaces, err := warmTargetModules(NewDefaultConfig(), []string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workspaces) != 0 {
+		t.Errorf("expected no workspaces for a local directory, got %d", len(workspaces))
+	}
+	if len(modules) != 2 {
+		t.Fatalf("expected 2 go.mod files, got %d", len(modules))
+	}
+
+	if _, _, err := warmTargetModules(NewDefaultConfig(), []string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("expected an error for a missing target")
+	}
+}
+
+func TestExpandOrgTargets(t *testing.T) {
+	dir := t.TempDir()
+	var listed []string
+	list := func(org string) ([]string, error) {
+		listed = append(listed, org)
+		return []string{"api", "web"}, nil
+	}
+
+	var got []string
+	captureStderr(t, func() {
+		var err error
+		got, err = expandOrgTargets(NewDefaultConfig(), []string{dir, "github.com/acme", "github.com/acme/tools", "https://github.com/other/"}, list)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	want := []string{dir, "github.com/acme/api", "github.com/acme/web", "github.com/acme/tools", "github.com/other/api", "github.com/other/web"}
+	if !slices.Equal(got, want) {
+		t.Errorf("targets = %v, want %v", got, want)
+	}
+	if !slices.Equal(listed, []string{"acme", "other"}) {
+		t.Errorf("listed orgs %v, want acme and other", listed)
+	}
+}
+
+func TestListOrgRepos(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/acme/repos" {
+			http.NotFound(w, r)
+			return
+		}
+		// A full first page, then a short second one
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "1" {
+			_, _ = fmt.Fprint(w, "[")
+			for i := range orgListPageSize {
+				if i > 0 {
+					_, _ = fmt.Fprint(w, ",")
+				}
+				_, _ = fmt.Fprintf(w, `{"name":"repo%d","archived":%t}`, i, i == 0)
+			}
+			_, _ = fmt.Fprint(w, "]")
+			return
+		}
+		_, _ = fmt.Fprint(w, `[{"name":"last","archived":false}]`)
+	}))
+	defer srv.Close()
+
+	gc := &ghClient{client: srv.Client(), restURL: srv.URL}
+	names, err := gc.listOrgRepos("token", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != orgListPageSize || names[0] != "repo1" || names[len(names)-1] != "last" {
+		t.Errorf("names = %d entries from %q to %q, want %d without the archived repo0", len(names), names[0], names[len(names)-1], orgListPageSize)
+	}
+
+	if _, err := gc.listOrgRepos("token", "missing"); err == nil {
+		t.Error("expected an error for an unknown org")
+	}
+}